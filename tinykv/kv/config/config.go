@@ -20,14 +20,27 @@ type Config struct {
 	RaftBaseTickInterval     time.Duration
 	RaftHeartbeatTicks       int
 	RaftElectionTimeoutTicks int
+	// The max number of consecutive timed out leader transfers to the same peer,
+	// after which the transfers to it are rejected until it catches up.
+	RaftMaxLeaderTransferAttempts int
+	// When sending a raft message through the transport takes longer than this, the
+	// message is dropped and the recipient is reported unreachable to raft. Zero means
+	// waiting until the transport returns.
+	RaftMessageSendTimeout time.Duration
 
 	// Interval to gc unnecessary raft log (ms).
 	RaftLogGCTickInterval time.Duration
-	// When entry count exceed this value, gc will be forced trigger.
+	// When the count of the applied entries in the raft log exceeds this value,
+	// the leader proposes a compact log command on the gc tick automatically.
 	RaftLogGcCountLimit uint64
 
 	// Interval (ms) to check region whether need to be split or not.
 	SplitRegionCheckTickInterval time.Duration
+	// The split checker scans at most this many keys of a region with one engine
+	// snapshot, it releases the snapshot and yields between the batches so a scan
+	// of a large region doesn't monopolize the engine. Zero means scanning the
+	// region in one batch.
+	SplitCheckScanBatchSize int
 	// delay time before deleting a stale peer
 	SchedulerHeartbeatTickInterval      time.Duration
 	SchedulerStoreHeartbeatTickInterval time.Duration
@@ -37,6 +50,54 @@ type Config struct {
 	// [b,c), [c,d) will be regionSplitSize (maybe a little larger).
 	RegionMaxSize   uint64
 	RegionSplitSize uint64
+	// At most SplitLimit splits are asked to the scheduler by the store in every
+	// SplitLimitInterval, the other splits are queued until the following intervals.
+	// Zero means no limit.
+	SplitLimit         int
+	SplitLimitInterval time.Duration
+
+	// When the write batch of the applied entries exceeds this size, it is written
+	// into the kv engine before applying the next entry. Zero means writing after
+	// every entry.
+	ApplyWriteBatchSizeLimit uint64
+	// When the write batch of the applied entries has been built for this long, it
+	// is written into the kv engine even if it doesn't exceed the size limit, so
+	// the writes are not held by a long run of entries. Zero means no limit.
+	ApplyWriteBatchMaxDelay time.Duration
+	// When writing the applied entries into the kv engine fails with a transient
+	// error, it's retried at most this many times before the store panics. The
+	// backoff between retries starts at ApplyWriteRetryBackoff and is doubled
+	// after every retry.
+	ApplyWriteMaxRetry     int
+	ApplyWriteRetryBackoff time.Duration
+
+	// When set, the data of a removed peer is kept after its region is set to
+	// tombstone, so it can still be inspected for debugging.
+	KeepRemovedPeerData bool
+	// Interval to reclaim the data kept for removed peers.
+	RemovedPeerDataGCTickInterval time.Duration
+
+	// The max number of snapshots applied at the same time, the other snapshots
+	// are queued until one of the running applies finishes.
+	ConcurrentSnapApplyLimit int
+
+	// When the number of committed but not applied entries of a region stays above
+	// ApplyLagStallThreshold for ApplyLagStallDuration, the region is reported as
+	// write stalled in its heartbeat.
+	ApplyLagStallThreshold uint64
+	ApplyLagStallDuration  time.Duration
+
+	// When set, a snap command is only served if the term of the region has not
+	// changed since it was proposed, otherwise the leadership may have moved and
+	// a not leader error is returned instead of a possibly stale snapshot.
+	SnapLeaderCheck bool
+
+	// When a conf change proposal is dropped because the leader is absent for a
+	// moment, e.g. it's transferring the leadership, it's proposed again after
+	// ConfChangeRetryInterval, at most ConfChangeRetryLimit times. Zero means the
+	// dropped conf change is returned to the caller without retrying.
+	ConfChangeRetryLimit    int
+	ConfChangeRetryInterval time.Duration
 }
 
 func (c *Config) Validate() error {
@@ -53,6 +114,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("election tick must be greater than heartbeat tick.")
 	}
 
+	if c.ConcurrentSnapApplyLimit <= 0 {
+		return fmt.Errorf("concurrent snapshot apply limit must be greater than 0")
+	}
+
 	return nil
 }
 
@@ -74,10 +139,23 @@ func NewDefaultConfig() *Config {
 		// Assume the average size of entries is 1k.
 		RaftLogGcCountLimit:                 128000,
 		SplitRegionCheckTickInterval:        10 * time.Second,
+		SplitCheckScanBatchSize:             1024,
 		SchedulerHeartbeatTickInterval:      100 * time.Millisecond,
 		SchedulerStoreHeartbeatTickInterval: 10 * time.Second,
 		RegionMaxSize:                       144 * MB,
 		RegionSplitSize:                     96 * MB,
+		SplitLimitInterval:                  1 * time.Second,
+		ApplyWriteBatchSizeLimit:            0,
+		ApplyWriteBatchMaxDelay:             10 * time.Millisecond,
+		ApplyWriteMaxRetry:                  3,
+		ApplyWriteRetryBackoff:              100 * time.Millisecond,
+		RemovedPeerDataGCTickInterval:       1 * time.Hour,
+		ConcurrentSnapApplyLimit:            1,
+		ApplyLagStallThreshold:              10000,
+		ApplyLagStallDuration:               30 * time.Second,
+		RaftMaxLeaderTransferAttempts:       3,
+		SnapLeaderCheck:                     true,
+		ConfChangeRetryInterval:             1 * time.Second,
 		DBPath:                              "/tmp/badger",
 	}
 }
@@ -93,10 +171,24 @@ func NewTestConfig() *Config {
 		// Assume the average size of entries is 1k.
 		RaftLogGcCountLimit:                 128000,
 		SplitRegionCheckTickInterval:        100 * time.Millisecond,
+		SplitCheckScanBatchSize:             1024,
 		SchedulerHeartbeatTickInterval:      100 * time.Millisecond,
 		SchedulerStoreHeartbeatTickInterval: 500 * time.Millisecond,
 		RegionMaxSize:                       144 * MB,
 		RegionSplitSize:                     96 * MB,
+		SplitLimitInterval:                  1 * time.Second,
+		ApplyWriteBatchSizeLimit:            0,
+		ApplyWriteBatchMaxDelay:             10 * time.Millisecond,
+		ApplyWriteMaxRetry:                  3,
+		ApplyWriteRetryBackoff:              10 * time.Millisecond,
+		RemovedPeerDataGCTickInterval:       10 * time.Second,
+		ConcurrentSnapApplyLimit:            2,
+		ApplyLagStallThreshold:              10000,
+		ApplyLagStallDuration:               1 * time.Second,
+		RaftMaxLeaderTransferAttempts:       3,
+		SnapLeaderCheck:                     true,
+		ConfChangeRetryLimit:                3,
+		ConfChangeRetryInterval:             100 * time.Millisecond,
 		DBPath:                              "/tmp/badger",
 	}
 	log.SetLevel(logutil.StringToZapLogLevel(conf.LogLevel))
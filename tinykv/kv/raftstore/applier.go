@@ -2,7 +2,12 @@ package raftstore
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
+	"os"
+	"syscall"
+	"time"
 
 	"github.com/Connor1996/badger"
 	"github.com/Connor1996/badger/y"
@@ -48,6 +53,8 @@ type MsgApplyRes struct {
 	regionID     uint64
 	execResults  []execResult
 	sizeDiffHint uint64
+	// The total number of entries applied for the region.
+	appliedCount uint64
 }
 
 type execResult = interface{}
@@ -112,8 +119,13 @@ type applier struct {
 	/// separate WAL file. When power failure, for current raft log, apply_index may synced
 	/// to file, but KV data may not synced to file, so we will lose data.
 	applyState rspb.RaftApplyState
+	// Whether the apply state has been checked against the last applied snapshot.
+	applyStateChecked bool
 
 	sizeDiffHint uint64
+
+	/// The total number of entries applied, it's kept when the applier is refreshed.
+	appliedCount uint64
 }
 
 func newApplierFromPeer(peer *peer) *applier {
@@ -161,10 +173,11 @@ func (a *applier) handleRefresh(reg *MsgApplyRefresh) {
 		notifyStaleCommand(a.region.Id, a.id, a.term, *cmd)
 	}
 	*a = applier{
-		tag:    fmt.Sprintf("[region %d] %d", reg.region.Id, reg.id),
-		id:     reg.id,
-		term:   reg.term,
-		region: reg.region,
+		tag:          fmt.Sprintf("[region %d] %d", reg.region.Id, reg.id),
+		id:           reg.id,
+		term:         reg.term,
+		region:       reg.region,
+		appliedCount: a.appliedCount,
 	}
 }
 
@@ -191,6 +204,13 @@ func (a *applier) handleProposal(regionProposal *MsgApplyProposal) {
 	}
 	for _, p := range regionProposal.Props {
 		cmd := pendingCmd{index: p.index, term: p.term, cb: p.cb}
+		if p.term < a.term {
+			// The proposal was made by a leader of an older term and arrives after
+			// the applier has moved on, it would otherwise linger in the queue until
+			// an entry of a newer term is applied.
+			notifyStaleCommand(regionID, peerID, a.term, cmd)
+			continue
+		}
 		if p.isConfChange {
 			if confCmd := a.pendingCmds.takeConfChange(); confCmd != nil {
 				// if it loses leadership before conf change is replicated, there may be
@@ -293,17 +313,35 @@ type applyContext struct {
 	applyTaskResList []*MsgApplyRes
 	execCtx          *applyExecContext
 	wb               *engine_util.WriteBatch
+	wbSizeLimit      uint64
+	wbMaxDelay       time.Duration
+	wbStartTime      time.Time
 	lastAppliedIndex uint64
 	committedCount   int
+
+	// writeBatch writes the write batch into the kv engine.
+	writeBatch        func(wb *engine_util.WriteBatch) error
+	writeMaxRetry     int
+	writeRetryBackoff time.Duration
+
+	snapLeaderCheck bool
 }
 
 func newApplyContext(tag string, engines *engine_util.Engines,
 	notifier chan<- message.Msg, cfg *config.Config) *applyContext {
 	return &applyContext{
-		tag:      tag,
-		engines:  engines,
-		notifier: notifier,
-		wb:       new(engine_util.WriteBatch),
+		tag:         tag,
+		engines:     engines,
+		notifier:    notifier,
+		wb:          new(engine_util.WriteBatch),
+		wbSizeLimit: cfg.ApplyWriteBatchSizeLimit,
+		wbMaxDelay:  cfg.ApplyWriteBatchMaxDelay,
+		writeBatch: func(wb *engine_util.WriteBatch) error {
+			return wb.WriteToDB(engines.Kv)
+		},
+		writeMaxRetry:     cfg.ApplyWriteMaxRetry,
+		writeRetryBackoff: cfg.ApplyWriteRetryBackoff,
+		snapLeaderCheck:   cfg.SnapLeaderCheck,
 	}
 }
 
@@ -316,10 +354,47 @@ func (ac *applyContext) prepareFor(d *applier) {
 	if ac.wb == nil {
 		ac.wb = new(engine_util.WriteBatch)
 	}
+	// The apply state is loaded from the engine, so the pending changes must
+	// be written first, otherwise a stale apply state may be loaded.
+	if ac.wb.Len() > 0 {
+		ac.writeToDB()
+	}
 	ac.cbs = append(ac.cbs, applyCallback{region: d.region})
 	applyState, _ := meta.GetApplyState(ac.engines.Kv, d.region.GetId())
 	d.applyState = *applyState
+	if !d.applyStateChecked {
+		ac.repairApplyState(d)
+		d.applyStateChecked = true
+	}
 	ac.lastAppliedIndex = d.applyState.AppliedIndex
+	ac.wbStartTime = time.Now()
+}
+
+/// Repairs the apply state if it's behind the last applied snapshot, which happens when the
+/// snapshot apply is interrupted after the region state is written but before the apply state
+/// is updated. The apply state is rebuilt from the snapshot metadata. It's checked once after
+/// the applier is created or refreshed by a snapshot.
+func (ac *applyContext) repairApplyState(d *applier) {
+	snapRaftState, err := meta.GetSnapshotRaftState(ac.engines.Kv, d.region.GetId())
+	if err != nil {
+		if err != badger.ErrKeyNotFound {
+			log.Error(fmt.Sprintf("%s failed to get snapshot raft state: %v", d.tag, err))
+		}
+		return
+	}
+	if d.applyState.AppliedIndex >= snapRaftState.LastIndex {
+		return
+	}
+	log.Warn(fmt.Sprintf("%s applied index %d is behind the snapshot index %d, repair the apply state",
+		d.tag, d.applyState.AppliedIndex, snapRaftState.LastIndex))
+	d.applyState = rspb.RaftApplyState{
+		AppliedIndex: snapRaftState.LastIndex,
+		TruncatedState: &rspb.RaftTruncatedState{
+			Index: snapRaftState.LastIndex,
+			Term:  snapRaftState.LastTerm,
+		},
+	}
+	d.writeApplyState(ac.wb)
 }
 
 /// Commits all changes have done for applier. `persistent` indicates whether
@@ -335,6 +410,16 @@ func (ac *applyContext) commit(d *applier) {
 	ac.commitOpt(d, true)
 }
 
+/// Returns true if the write batch exceeds the size limit or has been built for longer than the
+/// max delay and needs to be written into badger, so that a large batch of committed entries won't
+/// be held in memory at once, and the writes in it won't wait for the rest of the entries.
+func (ac *applyContext) shouldWriteToEngine() bool {
+	if uint64(ac.wb.Size()) >= ac.wbSizeLimit {
+		return true
+	}
+	return ac.wbMaxDelay > 0 && time.Since(ac.wbStartTime) >= ac.wbMaxDelay
+}
+
 func (ac *applyContext) commitOpt(d *applier, persistent bool) {
 	if persistent {
 		ac.writeToDB()
@@ -344,8 +429,21 @@ func (ac *applyContext) commitOpt(d *applier, persistent bool) {
 
 /// Writes all the changes into badger.
 func (ac *applyContext) writeToDB() {
-	if err := ac.wb.WriteToDB(ac.engines.Kv); err != nil {
-		panic(err)
+	backoff := ac.writeRetryBackoff
+	for retry := 0; ; retry++ {
+		err := ac.writeBatch(ac.wb)
+		if err == nil {
+			break
+		}
+		// The write batch is written in a single transaction, so nothing is written
+		// when it fails and it's safe to write it again.
+		if retry >= ac.writeMaxRetry || !isRetryableWriteError(err) {
+			panic(err)
+		}
+		log.Warn(fmt.Sprintf("%s failed to write apply batch, retry after %v. [retry: %d, err: %v]",
+			ac.tag, backoff, retry+1, err))
+		time.Sleep(backoff)
+		backoff *= 2
 	}
 	ac.wb.Reset()
 	for _, cb := range ac.cbs {
@@ -354,6 +452,24 @@ func (ac *applyContext) writeToDB() {
 	ac.cbs = ac.cbs[:0]
 }
 
+// isRetryableWriteError returns true if a failed write into the kv engine may succeed
+// when it's retried, e.g. a transaction conflict or a transient IO error. Other
+// errors are fatal.
+func isRetryableWriteError(err error) bool {
+	cause := errors.Cause(err)
+	if cause == badger.ErrConflict {
+		return true
+	}
+	if pathErr, ok := cause.(*os.PathError); ok {
+		cause = pathErr.Err
+	}
+	switch cause {
+	case syscall.EIO, syscall.EAGAIN, syscall.EINTR, syscall.EBUSY:
+		return true
+	}
+	return false
+}
+
 /// Finishes `Apply`s for the applier.
 func (ac *applyContext) finishFor(d *applier, results []execResult) {
 	if !d.pendingRemove {
@@ -361,8 +477,9 @@ func (ac *applyContext) finishFor(d *applier, results []execResult) {
 	}
 	ac.commitOpt(d, false)
 	res := &MsgApplyRes{
-		regionID:    d.region.Id,
-		execResults: results,
+		regionID:     d.region.Id,
+		execResults:  results,
+		appliedCount: d.appliedCount,
 	}
 	ac.applyTaskResList = append(ac.applyTaskResList, res)
 }
@@ -413,7 +530,10 @@ func (a *applier) handleRaftCommittedEntries(aCtx *applyContext, committedEntrie
 		case applyResultTypeExecResult:
 			results = append(results, res.data)
 		}
-		aCtx.commit(a)
+		a.appliedCount++
+		if aCtx.shouldWriteToEngine() {
+			aCtx.commit(a)
+		}
 	}
 	aCtx.finishFor(a, results)
 }
@@ -455,6 +575,9 @@ func (a *applier) handleRaftEntryConfChange(aCtx *applyContext, entry *eraftpb.E
 	if err := confChange.Unmarshal(entry.Data); err != nil {
 		panic(err)
 	}
+	if len(confChange.Context) == 0 {
+		return a.applyConfChangeWithoutContext(aCtx, index, term, confChange)
+	}
 	cmd := new(raft_cmdpb.RaftCmdRequest)
 	if err := cmd.Unmarshal(confChange.Context); err != nil {
 		panic(err)
@@ -475,6 +598,18 @@ func (a *applier) handleRaftEntryConfChange(aCtx *applyContext, entry *eraftpb.E
 	}
 }
 
+// applyConfChangeWithoutContext applies a conf change which carries no admin command, so there is
+// no region to update, the change is only applied to the raft membership.
+func (a *applier) applyConfChangeWithoutContext(aCtx *applyContext, index, term uint64, confChange *eraftpb.ConfChange) applyResult {
+	log.Info(fmt.Sprintf("%s apply conf change without context, only the raft membership is changed. index %d, term %d, change %s",
+		a.tag, index, term, confChange))
+	a.applyState.AppliedIndex = index
+	resp := newCmdResp()
+	BindRespTerm(resp, term)
+	aCtx.cbs[len(aCtx.cbs)-1].push(a.findCallback(index, term, true), resp, nil)
+	return applyResult{tp: applyResultTypeExecResult, data: &execResultChangePeer{confChange: confChange}}
+}
+
 func (a *applier) findCallback(index, term uint64, isConfChange bool) *message.Callback {
 	regionID := a.region.Id
 	peerID := a.id
@@ -605,6 +740,10 @@ func (a *applier) execAdminCmd(aCtx *applyContext, req *raft_cmdpb.RaftCmdReques
 		adminResp, result, err = a.execSplit(aCtx, adminReq)
 	case raft_cmdpb.AdminCmdType_CompactLog:
 		adminResp, result, err = a.execCompactLog(aCtx, adminReq)
+	case raft_cmdpb.AdminCmdType_ComputeHash:
+		adminResp, result, err = a.execComputeHash(aCtx, adminReq)
+	case raft_cmdpb.AdminCmdType_VerifyHash:
+		adminResp, result, err = a.execVerifyHash(aCtx, adminReq)
 	case raft_cmdpb.AdminCmdType_TransferLeader:
 		err = errors.New("transfer leader won't execute")
 	case raft_cmdpb.AdminCmdType_InvalidAdmin:
@@ -622,6 +761,16 @@ func (a *applier) execAdminCmd(aCtx *applyContext, req *raft_cmdpb.RaftCmdReques
 func (a *applier) execNormalCmd(aCtx *applyContext, req *raft_cmdpb.RaftCmdRequest) (
 	resp *raft_cmdpb.RaftCmdResponse, txn *badger.Txn, result applyResult, err error) {
 	requests := req.GetRequests()
+	for _, r := range requests {
+		if r.CmdType == raft_cmdpb.CmdType_Get || r.CmdType == raft_cmdpb.CmdType_Snap {
+			// Reads are served by the engine directly, so the changes of the
+			// previous entries must be written before.
+			if aCtx.wb.Len() > 0 {
+				aCtx.commit(a)
+			}
+			break
+		}
+	}
 	resps := make([]*raft_cmdpb.Response, 0, len(requests))
 	hasWrite, hasRead := false, false
 	for _, req := range requests {
@@ -642,6 +791,12 @@ func (a *applier) execNormalCmd(aCtx *applyContext, req *raft_cmdpb.RaftCmdReque
 			resps = append(resps, r)
 			hasRead = true
 		case raft_cmdpb.CmdType_Snap:
+			if aCtx.snapLeaderCheck && a.term > aCtx.execCtx.term {
+				// The region has moved to a newer term since the command was proposed, this
+				// peer may not be the leader anymore and its snapshot may miss newer writes.
+				err = &util.ErrNotLeader{RegionId: a.region.Id}
+				return
+			}
 			resps = append(resps, &raft_cmdpb.Response{
 				CmdType: raft_cmdpb.CmdType_Snap,
 				Snap:    &raft_cmdpb.SnapResponse{Region: a.region},
@@ -662,15 +817,18 @@ func (a *applier) execNormalCmd(aCtx *applyContext, req *raft_cmdpb.RaftCmdReque
 
 func (a *applier) handlePut(aCtx *applyContext, req *raft_cmdpb.PutRequest) (*raft_cmdpb.Response, error) {
 	key, value := req.GetKey(), req.GetValue()
+	cf := req.GetCf()
+	if len(cf) == 0 {
+		cf = engine_util.CfDefault
+	}
+	if err := util.CheckKeyEncoding(key, cf); err != nil {
+		return nil, err
+	}
 	if err := util.CheckKeyInRegion(key, a.region); err != nil {
 		return nil, err
 	}
 
-	if cf := req.GetCf(); len(cf) != 0 {
-		aCtx.wb.SetCF(cf, key, value)
-	} else {
-		aCtx.wb.SetCF(engine_util.CfDefault, key, value)
-	}
+	aCtx.wb.SetCF(cf, key, value)
 	return &raft_cmdpb.Response{
 		CmdType: raft_cmdpb.CmdType_Put,
 	}, nil
@@ -678,15 +836,18 @@ func (a *applier) handlePut(aCtx *applyContext, req *raft_cmdpb.PutRequest) (*ra
 
 func (a *applier) handleDelete(aCtx *applyContext, req *raft_cmdpb.DeleteRequest) (*raft_cmdpb.Response, error) {
 	key := req.GetKey()
+	cf := req.GetCf()
+	if len(cf) == 0 {
+		cf = engine_util.CfDefault
+	}
+	if err := util.CheckKeyEncoding(key, cf); err != nil {
+		return nil, err
+	}
 	if err := util.CheckKeyInRegion(key, a.region); err != nil {
 		return nil, err
 	}
 
-	if cf := req.GetCf(); len(cf) != 0 {
-		aCtx.wb.DeleteCF(cf, key)
-	} else {
-		aCtx.wb.DeleteCF(engine_util.CfDefault, key)
-	}
+	aCtx.wb.DeleteCF(cf, key)
 	return &raft_cmdpb.Response{
 		CmdType: raft_cmdpb.CmdType_Delete,
 	}, nil
@@ -729,7 +890,7 @@ func (a *applier) execChangePeer(aCtx *applyContext, req *raft_cmdpb.AdminReques
 	log.Info(fmt.Sprintf("%s exec ConfChange, peer_id %d, type %s, epoch %s",
 		a.tag, peer.Id, changeType, region.RegionEpoch))
 
-	// TODO: we should need more check, like peer validation, duplicated id, etc.
+	// TODO: we should need more check, like peer validation, etc.
 	region.RegionEpoch.ConfVer++
 
 	switch changeType {
@@ -741,6 +902,15 @@ func (a *applier) execChangePeer(aCtx *applyContext, req *raft_cmdpb.AdminReques
 			err = errors.New(errMsg)
 			return
 		}
+		for _, p := range region.Peers {
+			if p.Id == peer.Id {
+				errMsg := fmt.Sprintf("%s can't add peer with duplicated id, peer %s, existing_peer %s, region %s",
+					a.tag, peer, p, a.region)
+				log.Error(errMsg)
+				err = errors.New(errMsg)
+				return
+			}
+		}
 		region.Peers = append(region.Peers, peer)
 		log.Info(fmt.Sprintf("%s add peer successfully, peer %s, region %s", a.tag, peer, a.region))
 	case eraftpb.ConfChangeType_RemoveNode:
@@ -765,6 +935,35 @@ func (a *applier) execChangePeer(aCtx *applyContext, req *raft_cmdpb.AdminReques
 			return
 		}
 		log.Info(fmt.Sprintf("%s remove peer successfully, peer %s, region %s", a.tag, peer, a.region))
+	case eraftpb.ConfChangeType_MovePeer:
+		var old *metapb.Peer
+		for _, p := range region.Peers {
+			if p.Id == peer.Id {
+				old = p
+				break
+			}
+		}
+		if old == nil {
+			errMsg := fmt.Sprintf("%s moving missing peer, peer %s, region %s", a.tag, peer, a.region)
+			log.Error(errMsg)
+			err = errors.New(errMsg)
+			return
+		}
+		if old.StoreId == storeID {
+			errMsg := fmt.Sprintf("%s can't move peer to the same store, peer %s, region %s", a.tag, peer, a.region)
+			log.Error(errMsg)
+			err = errors.New(errMsg)
+			return
+		}
+		if p := util.FindPeer(region, storeID); p != nil {
+			errMsg := fmt.Sprintf("%s can't move peer to a store with another peer, peer %s, existing_peer %s, region %s",
+				a.tag, peer, p, a.region)
+			log.Error(errMsg)
+			err = errors.New(errMsg)
+			return
+		}
+		old.StoreId = storeID
+		log.Info(fmt.Sprintf("%s move peer successfully, peer %s, region %s", a.tag, peer, a.region))
 	}
 	state := rspb.PeerState_Normal
 	if a.pendingRemove {
@@ -808,9 +1007,8 @@ func (a *applier) execSplit(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
 		err = errors.Errorf("invalid split request:%s", splitReq)
 		return
 	}
-	if len(splitReq.NewPeerIds) != len(derived.Peers) {
-		err = errors.Errorf("invalid new peer id count, need %d but got %d",
-			len(derived.Peers), len(splitReq.NewPeerIds))
+	newPeers, err := splitRegionPeers(derived, splitReq.NewPeerIds)
+	if err != nil {
 		return
 	}
 	keys = append(keys, splitKey)
@@ -834,13 +1032,7 @@ func (a *applier) execSplit(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
 		StartKey:    keys[0],
 		EndKey:      keys[1],
 	}
-	newRegion.Peers = make([]*metapb.Peer, len(derived.Peers))
-	for j := range newRegion.Peers {
-		newRegion.Peers[j] = &metapb.Peer{
-			Id:      splitReq.NewPeerIds[j],
-			StoreId: derived.Peers[j].StoreId,
-		}
-	}
+	newRegion.Peers = newPeers
 	meta.WriteRegionState(aCtx.wb, newRegion, rspb.PeerState_Normal)
 	writeInitialApplyState(aCtx.wb, newRegion.Id)
 	regions = append(regions, newRegion)
@@ -860,6 +1052,119 @@ func (a *applier) execSplit(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
 	return
 }
 
+// splitRegionPeers creates the peers of the new region of a split. The i-th new peer id is
+// allocated for the i-th peer of the derived region, so every new peer is placed on the same
+// store as its counterpart and the new region has exactly the stores of the derived region.
+func splitRegionPeers(derived *metapb.Region, newPeerIds []uint64) ([]*metapb.Peer, error) {
+	if len(newPeerIds) != len(derived.Peers) {
+		return nil, errors.Errorf("invalid new peer id count, need %d but got %d",
+			len(derived.Peers), len(newPeerIds))
+	}
+	usedIds := make(map[uint64]bool, len(derived.Peers)*2)
+	for _, peer := range derived.Peers {
+		usedIds[peer.Id] = true
+	}
+	stores := make(map[uint64]bool, len(derived.Peers))
+	peers := make([]*metapb.Peer, 0, len(derived.Peers))
+	for i, peer := range derived.Peers {
+		if peer.StoreId == 0 || stores[peer.StoreId] {
+			return nil, errors.Errorf("invalid store %d of peer %d in region %d",
+				peer.StoreId, peer.Id, derived.Id)
+		}
+		stores[peer.StoreId] = true
+		id := newPeerIds[i]
+		if id == 0 || usedIds[id] {
+			return nil, errors.Errorf("invalid new peer id %d for store %d", id, peer.StoreId)
+		}
+		usedIds[id] = true
+		peers = append(peers, &metapb.Peer{Id: id, StoreId: peer.StoreId})
+	}
+	return peers, nil
+}
+
+// execComputeHash computes the checksum of the region data and records it in the apply state.
+// Every replica computes it after applying the same entries, so the consistent replicas get
+// the same checksum, which is compared by the following VerifyHash command.
+func (a *applier) execComputeHash(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
+	resp *raft_cmdpb.AdminResponse, result applyResult, err error) {
+	// The data is read from the engine directly, so the changes of the previous entries must be
+	// written before.
+	if aCtx.wb.Len() > 0 {
+		aCtx.commit(a)
+	}
+	txn := aCtx.engines.Kv.NewTransaction(false)
+	defer txn.Discard()
+	hash, err := computeRegionHash(txn, a.region)
+	if err != nil {
+		// Reading the engine fails only on this store, it can't be returned as a deterministic error.
+		panic(fmt.Sprintf("%s failed to compute hash, err %v", a.tag, err))
+	}
+	applyState := &aCtx.execCtx.applyState
+	applyState.HashIndex = aCtx.execCtx.index
+	applyState.Hash = hash
+	log.Info(fmt.Sprintf("%s computed hash %x at index %d", a.tag, hash, applyState.HashIndex))
+	resp = new(raft_cmdpb.AdminResponse)
+	return
+}
+
+// execVerifyHash compares the checksum of the ComputeHash command at the given index with the
+// one recorded by this replica.
+func (a *applier) execVerifyHash(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
+	resp *raft_cmdpb.AdminResponse, result applyResult, err error) {
+	verifyReq := req.VerifyHash
+	applyState := &aCtx.execCtx.applyState
+	resp = new(raft_cmdpb.AdminResponse)
+	if verifyReq.Index != applyState.HashIndex {
+		// The replica has not applied the ComputeHash command, e.g. it's restored from a snapshot
+		// taken after the command, so there is nothing to compare with.
+		log.Info(fmt.Sprintf("%s hash index %d doesn't match the verified index %d, skip",
+			a.tag, applyState.HashIndex, verifyReq.Index))
+		return
+	}
+	if !bytes.Equal(verifyReq.Hash, applyState.Hash) {
+		err = &util.ErrHashMismatch{
+			RegionId: a.region.Id,
+			Index:    verifyReq.Index,
+			Expected: verifyReq.Hash,
+			Actual:   applyState.Hash,
+		}
+		log.Error(fmt.Sprintf("%s %v", a.tag, err))
+	}
+	return
+}
+
+// computeRegionHash returns the CRC32 checksum of the keys and values of the region in all the
+// column families.
+func computeRegionHash(txn *badger.Txn, region *metapb.Region) ([]byte, error) {
+	digest := crc32.NewIEEE()
+	var lenBuf [4]byte
+	write := func(data []byte) {
+		// Prefix the data with its length, so the boundaries of keys and values are hashed too.
+		binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+		digest.Write(lenBuf[:])
+		digest.Write(data)
+	}
+	for _, cf := range engine_util.CFs {
+		write([]byte(cf))
+		iter := engine_util.NewCFIterator(cf, txn)
+		for iter.Seek(region.StartKey); iter.Valid(); iter.Next() {
+			item := iter.Item()
+			if engine_util.ExceedEndKey(item.Key(), region.EndKey) {
+				break
+			}
+			val, err := item.Value()
+			if err != nil {
+				iter.Close()
+				return nil, err
+			}
+			write(item.Key())
+			write(val)
+		}
+		iter.Close()
+	}
+	return digest.Sum(nil), nil
+}
+
 func (a *applier) execCompactLog(aCtx *applyContext, req *raft_cmdpb.AdminRequest) (
 	resp *raft_cmdpb.AdminResponse, result applyResult, err error) {
 	compactIndex := req.CompactLog.CompactIndex
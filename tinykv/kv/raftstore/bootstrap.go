@@ -5,6 +5,7 @@ import (
 
 	"github.com/Connor1996/badger"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore/meta"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/util"
 	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
@@ -90,19 +91,40 @@ func PrepareBootstrapCluster(engines *engine_util.Engines, region *metapb.Region
 	state.Region = region
 	kvWB := new(engine_util.WriteBatch)
 	kvWB.SetMeta(meta.PrepareBootstrapKey, state)
-	kvWB.SetMeta(meta.RegionStateKey(region.Id), state)
-	writeInitialApplyState(kvWB, region.Id)
-	err := engines.WriteKV(kvWB)
-	if err != nil {
-		return err
+	_, err := bootstrapRegion(engines, region, kvWB)
+	return err
+}
+
+// bootstrapRegion writes the initial raft state, apply state and region state of the region, the
+// writes in kvWB are written together with the region state. The raft state is written first, and
+// the region state is written last so it marks the region is bootstrapped, then bootstrapping an
+// existing region is a no-op, and an interrupted bootstrap can be safely done again. It returns
+// false if the region has been bootstrapped.
+func bootstrapRegion(engines *engine_util.Engines, region *metapb.Region, kvWB *engine_util.WriteBatch) (bool, error) {
+	state, err := meta.GetRegionLocalState(engines.Kv, region.Id)
+	if err == nil {
+		if !util.RegionEqual(state.Region, region) {
+			return false, errors.Errorf("region %d has been bootstrapped as %v", region.Id, state.Region)
+		}
+		return false, nil
+	}
+	if err != badger.ErrKeyNotFound {
+		return false, err
 	}
 	raftWB := new(engine_util.WriteBatch)
 	writeInitialRaftState(raftWB, region.Id)
-	err = engines.WriteRaft(raftWB)
-	if err != nil {
-		return err
+	if err = engines.WriteRaft(raftWB); err != nil {
+		return false, err
 	}
-	return nil
+	if kvWB == nil {
+		kvWB = new(engine_util.WriteBatch)
+	}
+	writeInitialApplyState(kvWB, region.Id)
+	kvWB.SetMeta(meta.RegionStateKey(region.Id), &rspb.RegionLocalState{Region: region})
+	if err = engines.WriteKV(kvWB); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func writeInitialApplyState(kvWB *engine_util.WriteBatch, regionID uint64) {
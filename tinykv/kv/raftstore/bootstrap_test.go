@@ -36,3 +36,48 @@ func TestBootstrapStore(t *testing.T) {
 	require.Nil(t, err)
 	require.True(t, empty)
 }
+
+func TestBootstrapRegionTwice(t *testing.T) {
+	engines := util.NewTestEngines()
+	defer engines.Destroy()
+	region := &metapb.Region{
+		Id:          1,
+		RegionEpoch: &metapb.RegionEpoch{Version: InitEpochVer, ConfVer: InitEpochConfVer},
+		Peers:       []*metapb.Peer{{Id: 1, StoreId: 1}},
+	}
+
+	// An interrupted bootstrap leaves only the raft state.
+	raftWB := new(engine_util.WriteBatch)
+	writeInitialRaftState(raftWB, region.Id)
+	require.Nil(t, engines.WriteRaft(raftWB))
+	bootstrapped, err := bootstrapRegion(engines, region, nil)
+	require.Nil(t, err)
+	require.True(t, bootstrapped)
+	state, err := meta.GetRegionLocalState(engines.Kv, region.Id)
+	require.Nil(t, err)
+	require.Equal(t, region.Id, state.Region.Id)
+	applyState, err := meta.GetApplyState(engines.Kv, region.Id)
+	require.Nil(t, err)
+	require.Equal(t, uint64(meta.RaftInitLogIndex), applyState.AppliedIndex)
+	raftState, err := meta.GetRaftLocalState(engines.Raft, region.Id)
+	require.Nil(t, err)
+	require.Equal(t, uint64(meta.RaftInitLogIndex), raftState.LastIndex)
+
+	// The region has advanced, bootstrapping it again doesn't reset the states.
+	applyState.AppliedIndex = meta.RaftInitLogIndex + 10
+	require.Nil(t, engine_util.PutMeta(engines.Kv, meta.ApplyStateKey(region.Id), applyState))
+	bootstrapped, err = bootstrapRegion(engines, region, nil)
+	require.Nil(t, err)
+	require.False(t, bootstrapped)
+	applyState, err = meta.GetApplyState(engines.Kv, region.Id)
+	require.Nil(t, err)
+	require.Equal(t, uint64(meta.RaftInitLogIndex+10), applyState.AppliedIndex)
+
+	// A different region with the same id is rejected.
+	other := &metapb.Region{
+		Id:          1,
+		RegionEpoch: &metapb.RegionEpoch{Version: InitEpochVer, ConfVer: InitEpochConfVer + 1},
+	}
+	_, err = bootstrapRegion(engines, other, nil)
+	require.NotNil(t, err)
+}
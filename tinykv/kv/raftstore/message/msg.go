@@ -32,6 +32,9 @@ const (
 	MsgTypeGcSnap MsgType = 7
 	// message of apply result from apply worker
 	MsgTypeApplyRes MsgType = 8
+	// message to report the peer is unreachable, it's sent by the transport when
+	// it fails to send a message to the peer after the message is handed over
+	MsgTypeUnreachable MsgType = 9
 
 	// message wraps a raft message to the peer not existing on the Store.
 	// It is due to region split or add peer conf change
@@ -25,6 +25,8 @@ const (
 	RaftLogSuffix    byte = 0x01
 	RaftStateSuffix  byte = 0x02
 	ApplyStateSuffix byte = 0x03
+	// The raft state of the last applied snapshot, which is saved in kv engine.
+	SnapshotRaftStateSuffix byte = 0x04
 
 	// For region meta
 	RegionStateSuffix byte = 0x01
@@ -82,6 +84,10 @@ func ApplyStateKey(regionID uint64) []byte {
 	return makeRegionPrefix(regionID, ApplyStateSuffix)
 }
 
+func SnapshotRaftStateKey(regionID uint64) []byte {
+	return makeRegionPrefix(regionID, SnapshotRaftStateSuffix)
+}
+
 func IsRaftStateKey(key []byte) bool {
 	return len(key) == 11 && key[0] == LocalPrefix && key[1] == RegionRaftPrefix
 }
@@ -1,6 +1,7 @@
 package raftstore
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -110,6 +111,38 @@ type peer struct {
 
 	// Index of last scheduled compacted raft log.
 	LastCompactedIdx uint64
+	// Index of the last proposed compact log command. The command is pending
+	// until the first index of the log moves past the index.
+	proposedCompactIdx uint64
+
+	// Set when the apply lag stays above the threshold for a sustained period,
+	// it's reported to the scheduler in the region heartbeat.
+	WriteStall bool
+	// The instant when the apply lag exceeded the threshold, zero if it doesn't.
+	applyLagSince time.Time
+
+	// The total number of entries applied for the region, reported by the applier.
+	// The store can compute the apply rate from it to throttle the proposals.
+	AppliedCount uint64
+
+	// The timeout of sending a raft message, zero means no timeout.
+	sendTimeout time.Duration
+
+	// The dropped conf change waiting to be proposed again, nil if there isn't one.
+	confChangeRetry *confChangeRetry
+
+	// proposeConfChangeToRaft proposes the conf change to the raft group, and
+	// returns the index of the proposal.
+	proposeConfChangeToRaft func(cc eraftpb.ConfChange) (uint64, error)
+}
+
+// confChangeRetry is a conf change proposal which was dropped by raft and is
+// proposed again once retryAt is reached.
+type confChangeRetry struct {
+	req      *raft_cmdpb.RaftCmdRequest
+	cb       *message.Callback
+	attempts int
+	retryAt  time.Time
 }
 
 func NewPeer(storeId uint64, cfg *config.Config, engines *engine_util.Engines, region *metapb.Region, regionSched chan<- worker.Task,
@@ -132,6 +165,8 @@ func NewPeer(storeId uint64, cfg *config.Config, engines *engine_util.Engines, r
 		HeartbeatTick: cfg.RaftHeartbeatTicks,
 		Applied:       appliedIndex,
 		Storage:       ps,
+
+		MaxLeaderTransferAttempts: cfg.RaftMaxLeaderTransferAttempts,
 	}
 
 	raftGroup, err := raft.NewRawNode(raftCfg)
@@ -148,7 +183,9 @@ func NewPeer(storeId uint64, cfg *config.Config, engines *engine_util.Engines, r
 		Tag:                   tag,
 		LastApplyingIdx:       appliedIndex,
 		ticker:                newTicker(region.GetId(), cfg),
+		sendTimeout:           cfg.RaftMessageSendTimeout,
 	}
+	p.proposeConfChangeToRaft = p.raftProposeConfChange
 
 	// If this region has only one peer and I am the one, campaign directly.
 	if len(region.GetPeers()) == 1 && region.GetPeers()[0].GetStoreId() == storeId {
@@ -229,6 +266,10 @@ func (p *peer) Destroy(engine *engine_util.Engines, keepData bool) error {
 		NotifyReqRegionRemoved(region.Id, proposal.cb)
 	}
 	p.applyProposals = nil
+	if p.confChangeRetry != nil {
+		NotifyReqRegionRemoved(region.Id, p.confChangeRetry.cb)
+		p.confChangeRetry = nil
+	}
 
 	log.Info(fmt.Sprintf("%v destroy itself, takes %v", p.Tag, time.Now().Sub(start)))
 	return nil
@@ -246,6 +287,13 @@ func (p *peer) Region() *metapb.Region {
 	return p.peerStorage.Region()
 }
 
+// KeyRange returns the copies of the start and end keys of the region, the range is
+// [startKey, endKey). An empty end key means the range is unbounded.
+func (p *peer) KeyRange() (startKey, endKey []byte) {
+	region := p.Region()
+	return util.SafeCopy(region.GetStartKey()), util.SafeCopy(region.GetEndKey())
+}
+
 /// Set the region of a peer.
 ///
 /// This will update the region of the peer, caller must ensure the region
@@ -272,8 +320,22 @@ func (p *peer) HasPendingSnapshot() bool {
 }
 
 func (p *peer) Send(trans Transport, msgs []eraftpb.Message) {
+	var unreachable map[uint64]struct{}
 	for _, msg := range msgs {
+		if _, ok := unreachable[msg.To]; ok {
+			// Don't wait for the timeout again, the rest messages to the peer are dropped.
+			continue
+		}
 		err := p.sendRaftMessage(msg, trans)
+		if err == context.DeadlineExceeded {
+			log.Warn(fmt.Sprintf("%v send message to %v timed out, report it unreachable", p.Tag, msg.To))
+			p.RaftGroup.ReportUnreachable(msg.To)
+			if unreachable == nil {
+				unreachable = make(map[uint64]struct{})
+			}
+			unreachable[msg.To] = struct{}{}
+			continue
+		}
 		if err != nil {
 			log.Debug(fmt.Sprintf("%v send message err: %v", p.Tag, err))
 		}
@@ -450,6 +512,10 @@ func (p *peer) Term() uint64 {
 	return p.RaftGroup.Raft.Term
 }
 
+func (p *peer) CommittedIndex() uint64 {
+	return p.RaftGroup.Status().Commit
+}
+
 func (p *peer) HeartbeatScheduler(ch chan<- worker.Task) {
 	clonedRegion := new(metapb.Region)
 	err := util.CloneMsg(p.Region(), clonedRegion)
@@ -461,9 +527,44 @@ func (p *peer) HeartbeatScheduler(ch chan<- worker.Task) {
 		Peer:            p.Meta,
 		PendingPeers:    p.CollectPendingPeers(),
 		ApproximateSize: p.ApproximateSize,
+		WriteStall:      p.WriteStall,
+		Term:            p.Term(),
+		CommitIndex:     p.CommittedIndex(),
+		AppliedIndex:    p.peerStorage.AppliedIndex(),
 	}
 }
 
+// applyLag returns the number of committed entries which are scheduled to apply but not applied yet.
+func (p *peer) applyLag() uint64 {
+	appliedIdx := p.peerStorage.AppliedIndex()
+	if p.LastApplyingIdx <= appliedIdx {
+		return 0
+	}
+	return p.LastApplyingIdx - appliedIdx
+}
+
+// checkWriteStall updates the write stall flag with the current apply lag. The flag is set
+// once the lag stays above the threshold for the given duration, and is cleared as soon as
+// the lag drops back. It returns true if the flag is changed.
+func (p *peer) checkWriteStall(now time.Time, threshold uint64, duration time.Duration) bool {
+	if p.applyLag() <= threshold {
+		p.applyLagSince = time.Time{}
+		if p.WriteStall {
+			p.WriteStall = false
+			return true
+		}
+		return false
+	}
+	if p.applyLagSince.IsZero() {
+		p.applyLagSince = now
+	}
+	if !p.WriteStall && now.Sub(p.applyLagSince) >= duration {
+		p.WriteStall = true
+		return true
+	}
+	return false
+}
+
 func (p *peer) sendRaftMessage(msg eraftpb.Message, trans Transport) error {
 	sendMsg := new(rspb.RaftMessage)
 	sendMsg.RegionId = p.regionId
@@ -496,13 +597,25 @@ func (p *peer) sendRaftMessage(msg eraftpb.Message, trans Transport) error {
 		sendMsg.EndKey = append([]byte{}, p.Region().EndKey...)
 	}
 	sendMsg.Message = &msg
-	return trans.Send(sendMsg)
+	ctx := context.Background()
+	if p.sendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.sendTimeout)
+		defer cancel()
+	}
+	return trans.Send(ctx, sendMsg)
 }
 
 // Propose a request.
 //
 // Return true means the request has been proposed successfully.
 func (p *peer) Propose(kv *badger.DB, cfg *config.Config, cb *message.Callback, req *raft_cmdpb.RaftCmdRequest, errResp *raft_cmdpb.RaftCmdResponse) bool {
+	return p.propose(kv, cfg, cb, req, errResp, 0)
+}
+
+// propose proposes a request, attempts is the number of times the request has
+// been dropped by raft and retried.
+func (p *peer) propose(kv *badger.DB, cfg *config.Config, cb *message.Callback, req *raft_cmdpb.RaftCmdRequest, errResp *raft_cmdpb.RaftCmdResponse, attempts int) bool {
 	if p.stopped {
 		return false
 	}
@@ -524,6 +637,9 @@ func (p *peer) Propose(kv *badger.DB, cfg *config.Config, cb *message.Callback,
 	case RequestPolicy_ProposeConfChange:
 		isConfChange = true
 		idx, err = p.ProposeConfChange(cfg, req)
+		if err != nil && p.maybeRetryConfChange(cfg, req, cb, attempts, err) {
+			return false
+		}
 	}
 
 	if err != nil {
@@ -574,6 +690,9 @@ func (p *peer) countHealthyNode(progress map[uint64]raft.Progress) int {
 ///    Then at least '(total - 1)/2 + 1' other nodes (the node about to be removed is excluded)
 ///    need to be up to date for now. If 'allow_remove_leader' is false then
 ///    the peer to be removed should not be the leader.
+/// 3. A `MovePeer` request
+///    Then at least 'total/2 + 1' other nodes (the node about to be moved is excluded)
+///    need to be up to date for now.
 func (p *peer) checkConfChange(cfg *config.Config, cmd *raft_cmdpb.RaftCmdRequest) error {
 	changePeer := GetChangePeerCmd(cmd)
 	changeType := changePeer.GetChangeType()
@@ -596,6 +715,9 @@ func (p *peer) checkConfChange(cfg *config.Config, cmd *raft_cmdpb.RaftCmdReques
 			// It's always safe to remove a not existing node.
 			return nil
 		}
+	case eraftpb.ConfChangeType_MovePeer:
+		// The moved peer starts from scratch on the new store, so it can't be counted as healthy.
+		delete(progress, peer.Id)
 	}
 
 	healthy := p.countHealthyNode(progress)
@@ -682,8 +804,12 @@ func (p *peer) ProposeConfChange(cfg *config.Config, req *raft_cmdpb.RaftCmdRequ
 
 	log.Info(fmt.Sprintf("%v propose conf change %v peer %v", p.Tag, cc.ChangeType, cc.NodeId))
 
+	return p.proposeConfChangeToRaft(cc)
+}
+
+func (p *peer) raftProposeConfChange(cc eraftpb.ConfChange) (uint64, error) {
 	proposeIndex := p.nextProposalIndex()
-	if err = p.RaftGroup.ProposeConfChange(cc); err != nil {
+	if err := p.RaftGroup.ProposeConfChange(cc); err != nil {
 		return 0, err
 	}
 	if p.nextProposalIndex() == proposeIndex {
@@ -695,6 +821,45 @@ func (p *peer) ProposeConfChange(cfg *config.Config, req *raft_cmdpb.RaftCmdRequ
 	return proposeIndex, nil
 }
 
+// maybeRetryConfChange schedules the conf change to be proposed again after
+// ConfChangeRetryInterval if it's dropped because the leader is absent for a
+// moment. It returns false if the conf change isn't retried, then the error is
+// returned to the caller.
+func (p *peer) maybeRetryConfChange(cfg *config.Config, req *raft_cmdpb.RaftCmdRequest, cb *message.Callback, attempts int, err error) bool {
+	if attempts >= cfg.ConfChangeRetryLimit || p.confChangeRetry != nil || !isProposalDropped(err) {
+		return false
+	}
+	log.Info(fmt.Sprintf("%v conf change is dropped, retry it after %v, attempts %v",
+		p.Tag, cfg.ConfChangeRetryInterval, attempts+1))
+	p.confChangeRetry = &confChangeRetry{
+		req:      req,
+		cb:       cb,
+		attempts: attempts + 1,
+		retryAt:  time.Now().Add(cfg.ConfChangeRetryInterval),
+	}
+	return true
+}
+
+// takeConfChangeRetry returns the conf change to be proposed again if its
+// retry is due, nil otherwise.
+func (p *peer) takeConfChangeRetry(now time.Time) *confChangeRetry {
+	retry := p.confChangeRetry
+	if retry == nil || now.Before(retry.retryAt) {
+		return nil
+	}
+	p.confChangeRetry = nil
+	return retry
+}
+
+// isProposalDropped checks whether the proposal is dropped by raft silently or
+// because the leader is transferring the leadership.
+func isProposalDropped(err error) bool {
+	if _, ok := err.(*util.ErrNotLeader); ok {
+		return true
+	}
+	return err == raft.ErrProposalDropped
+}
+
 type RequestPolicy int
 
 const (
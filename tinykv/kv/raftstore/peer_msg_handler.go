@@ -1,6 +1,7 @@
 package raftstore
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -68,9 +69,19 @@ func (d *peerMsgHandler) HandleMsg(msg message.Msg) {
 		d.onGCSnap(gcSnap.Snaps)
 	case message.MsgTypeStart:
 		d.startTicker()
+	case message.MsgTypeUnreachable:
+		d.onUnreachable(msg.Data.(uint64))
 	}
 }
 
+func (d *peerMsgHandler) onUnreachable(peerID uint64) {
+	if d.stopped {
+		return
+	}
+	log.Debug(fmt.Sprintf("%s peer %d is reported unreachable by the transport", d.Tag, peerID))
+	d.RaftGroup.ReportUnreachable(peerID)
+}
+
 func (d *peerMsgHandler) onTick() {
 	if d.stopped {
 		return
@@ -177,9 +188,41 @@ func (d *peerMsgHandler) onRaftBaseTick() {
 	}
 	// TODO: make Tick returns bool to indicate if there is ready.
 	d.RaftGroup.Tick()
+	d.onCheckWriteStall()
+	d.onRetryConfChange()
 	d.ticker.schedule(PeerTickRaft)
 }
 
+// onRetryConfChange proposes the dropped conf change again if its retry is due.
+func (d *peerMsgHandler) onRetryConfChange() {
+	retry := d.takeConfChangeRetry(time.Now())
+	if retry == nil {
+		return
+	}
+	if err := d.preProposeRaftCommand(retry.req); err != nil {
+		retry.cb.Done(ErrResp(err))
+		return
+	}
+	response := &raft_cmdpb.RaftCmdResponse{}
+	d.propose(d.peer.peerStorage.Engines.Kv, d.ctx.cfg, retry.cb, retry.req, response, retry.attempts)
+}
+
+func (d *peerMsgHandler) onCheckWriteStall() {
+	if !d.checkWriteStall(time.Now(), d.ctx.cfg.ApplyLagStallThreshold, d.ctx.cfg.ApplyLagStallDuration) {
+		return
+	}
+	if d.WriteStall {
+		log.Warn(fmt.Sprintf("%s write stall, apply lag %d has exceeded %d for %v", d.Tag,
+			d.applyLag(), d.ctx.cfg.ApplyLagStallThreshold, d.ctx.cfg.ApplyLagStallDuration))
+	} else {
+		log.Info(fmt.Sprintf("%s write stall is cleared", d.Tag))
+	}
+	// Report the change to the scheduler right away instead of waiting for the next heartbeat tick.
+	if d.IsLeader() {
+		d.HeartbeatScheduler(d.ctx.schedulerTaskSender)
+	}
+}
+
 func (d *peerMsgHandler) onApplyResult(res *MsgApplyRes) {
 
 	log.Debug(fmt.Sprintf("%s async apply finished %v", d.Tag, res))
@@ -199,6 +242,8 @@ func (d *peerMsgHandler) onApplyResult(res *MsgApplyRes) {
 		return
 	}
 
+	d.AppliedCount = res.appliedCount
+
 	diff := d.SizeDiffHint + res.sizeDiffHint
 	if diff > 0 {
 		d.SizeDiffHint = diff
@@ -335,7 +380,7 @@ func handleStaleMsg(trans Transport, msg *rspb.RaftMessage, curEpoch *metapb.Reg
 		RegionEpoch: curEpoch,
 		IsTombstone: true,
 	}
-	if err := trans.Send(gcMsg); err != nil {
+	if err := trans.Send(context.Background(), gcMsg); err != nil {
 		log.Error(fmt.Sprintf("[region %d] send message failed %v", regionID, err))
 	}
 }
@@ -419,7 +464,8 @@ func (d *peerMsgHandler) destroyPeer() {
 	meta.Lock()
 	defer meta.Unlock()
 	isInitialized := d.isInitialized()
-	if err := d.Destroy(d.ctx.engine, false); err != nil {
+	keepData := d.ctx.cfg.KeepRemovedPeerData
+	if err := d.Destroy(d.ctx.engine, keepData); err != nil {
 		// If not panic here, the peer will be recreated in the next restart,
 		// then it will be gc again. But if some overlap region is created
 		// before restarting, the gc action will delete the overlap region's
@@ -431,6 +477,9 @@ func (d *peerMsgHandler) destroyPeer() {
 	if isInitialized && meta.regionRanges.Delete(&regionItem{region: d.Region()}) == nil {
 		panic(d.Tag + " meta corruption detected")
 	}
+	if isInitialized && keepData {
+		meta.removedRegions[regionID] = d.Region()
+	}
 	if _, ok := meta.regions[regionID]; !ok {
 		panic(d.Tag + " meta corruption detected")
 	}
@@ -444,6 +493,10 @@ func (d *peerMsgHandler) onReadyChangePeer(cp *execResultChangePeer) {
 		// Apply failed, skip.
 		return
 	}
+	if cp.region == nil {
+		// The conf change has no context, only the raft membership is changed.
+		return
+	}
 	meta := d.ctx.storeMeta
 	meta.Lock()
 	meta.setRegion(cp.region, d.peer)
@@ -463,6 +516,9 @@ func (d *peerMsgHandler) onReadyChangePeer(cp *execResultChangePeer) {
 			delete(d.PeersStartPendingTime, peerID)
 		}
 		d.removePeerCache(peerID)
+	case eraftpb.ConfChangeType_MovePeer:
+		// The peer keeps its id, so the cache entry is replaced with the new store.
+		d.insertPeerCache(cp.peer)
 	}
 
 	// In pattern matching above, if the peer is the leader,
@@ -485,6 +541,10 @@ func (d *peerMsgHandler) onReadyChangePeer(cp *execResultChangePeer) {
 			panic(fmt.Sprintf("%s trying to remove unknown peer %s", d.Tag, cp.peer))
 		}
 	}
+	// The peer moved away from this store is no longer a member of the region here.
+	if changeType == eraftpb.ConfChangeType_MovePeer && myPeerID == peerID && cp.peer.StoreId != d.storeID() {
+		d.destroyPeer()
+	}
 }
 
 func (d *peerMsgHandler) onReadyCompactLog(firstIndex uint64, truncatedIndex uint64) {
@@ -665,6 +725,12 @@ func (d *peerMsgHandler) onRaftGCLogTick() {
 
 	appliedIdx := d.peerStorage.AppliedIndex()
 	firstIdx, _ := d.peerStorage.FirstIndex()
+	if d.proposedCompactIdx >= firstIdx && appliedIdx-d.proposedCompactIdx < d.ctx.cfg.RaftLogGcCountLimit {
+		// The compaction proposed before isn't applied yet, don't propose it again.
+		// The pending proposal may be dropped, so it's proposed again once another
+		// limit of entries are applied, the log is still bounded.
+		return
+	}
 	var compactIdx uint64
 	if appliedIdx > firstIdx && appliedIdx-firstIdx >= d.ctx.cfg.RaftLogGcCountLimit {
 		compactIdx = appliedIdx
@@ -690,10 +756,13 @@ func (d *peerMsgHandler) onRaftGCLogTick() {
 	regionID := d.regionId
 	request := newCompactLogRequest(regionID, d.Meta, compactIdx, term)
 	d.proposeRaftCommand(request, nil)
+	d.proposedCompactIdx = compactIdx
 }
 
 func (d *peerMsgHandler) onSplitRegionCheckTick() {
 	d.ticker.schedule(PeerTickSplitRegionCheck)
+	// The queued splits of the store are asked as the limit allows.
+	d.ctx.splitLimiter.flush(time.Now(), d.ctx.schedulerTaskSender)
 	// To avoid frequent scan, we only add new scan tasks if all previous tasks
 	// have finished.
 	if len(d.ctx.splitCheckTaskSender) > 0 {
@@ -718,12 +787,12 @@ func (d *peerMsgHandler) onPrepareSplitRegion(regionEpoch *metapb.RegionEpoch, s
 		return
 	}
 	region := d.Region()
-	d.ctx.schedulerTaskSender <- &runner.SchedulerAskSplitTask{
+	d.ctx.splitLimiter.ask(&runner.SchedulerAskSplitTask{
 		Region:   region,
 		SplitKey: splitKey,
 		Peer:     d.Meta,
 		Callback: cb,
-	}
+	}, time.Now(), d.ctx.schedulerTaskSender)
 }
 
 func (d *peerMsgHandler) validateSplitRegion(epoch *metapb.RegionEpoch, splitKey []byte) error {
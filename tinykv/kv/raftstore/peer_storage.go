@@ -308,6 +308,7 @@ func ClearMeta(engines *engine_util.Engines, kvWB, raftWB *engine_util.WriteBatc
 	start := time.Now()
 	kvWB.DeleteMeta(meta.RegionStateKey(regionID))
 	kvWB.DeleteMeta(meta.ApplyStateKey(regionID))
+	kvWB.DeleteMeta(meta.SnapshotRaftStateKey(regionID))
 
 	firstIndex := lastIndex + 1
 	beginLogKey := meta.RaftLogKey(regionID, 0)
@@ -379,6 +380,13 @@ func (ps *PeerStorage) ApplySnapshot(snapshot *eraftpb.Snapshot, kvWB *engine_ut
 			Term:  snapshot.Metadata.Term,
 		},
 	}
+	// The snapshot raft state is kept in kv engine, so the applier can repair the apply state
+	// from it if the apply state is found behind the snapshot.
+	snapRaftState := &rspb.RaftLocalState{
+		LastIndex: snapshot.Metadata.Index,
+		LastTerm:  snapshot.Metadata.Term,
+	}
+	kvWB.SetMeta(meta.SnapshotRaftStateKey(ps.region.GetId()), snapRaftState)
 	kvWB.SetMeta(meta.ApplyStateKey(ps.region.GetId()), applyState)
 	meta.WriteRegionState(kvWB, snapData.Region, rspb.PeerState_Normal)
 	ch := make(chan bool)
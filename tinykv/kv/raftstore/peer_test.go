@@ -0,0 +1,427 @@
+package raftstore
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingcap-incubator/tinykv/kv/config"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/meta"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/runner"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/util"
+	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
+	"github.com/pingcap-incubator/tinykv/kv/util/worker"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
+	rspb "github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
+	"github.com/pingcap-incubator/tinykv/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerDestroyKeepData(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	regionSched := make(chan worker.Task, 1)
+	peerStore.regionSched = regionSched
+	p := &peer{peerStorage: peerStore, Tag: "test"}
+
+	kvWB := new(engine_util.WriteBatch)
+	kvWB.SetCF(engine_util.CfDefault, []byte("k1"), []byte("v1"))
+	require.Nil(t, peerStore.Engines.WriteKV(kvWB))
+
+	require.Nil(t, p.Destroy(peerStore.Engines, true))
+	state, err := meta.GetRegionLocalState(peerStore.Engines.Kv, peerStore.region.GetId())
+	require.Nil(t, err)
+	require.Equal(t, rspb.PeerState_Tombstone, state.State)
+	// no destroy task is scheduled, the data is still readable
+	require.Equal(t, 0, len(regionSched))
+	val, err := engine_util.GetCF(peerStore.Engines.Kv, engine_util.CfDefault, []byte("k1"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v1"), val)
+}
+
+func TestRemovedPeerDataGC(t *testing.T) {
+	regionCh := make(chan worker.Task, 4)
+	ctx := &GlobalContext{
+		cfg:              config.NewTestConfig(),
+		storeMeta:        newStoreMeta(),
+		regionTaskSender: regionCh,
+	}
+	_, state := newStoreState(ctx.cfg)
+	sw := newStoreWorker(ctx, state)
+
+	live := &metapb.Region{Id: 3, StartKey: []byte("d"), EndKey: []byte("f")}
+	ctx.storeMeta.regionRanges.ReplaceOrInsert(&regionItem{region: live})
+	ctx.storeMeta.regions[live.Id] = live
+	removed := []*metapb.Region{
+		{Id: 1, StartKey: []byte("a"), EndKey: []byte("c")},
+		// partially overlapped by the live region
+		{Id: 2, StartKey: []byte("c"), EndKey: []byte("e")},
+		{Id: 4, StartKey: []byte("e")},
+		// covered by the live region
+		{Id: 5, StartKey: []byte("d"), EndKey: []byte("e")},
+	}
+	for _, region := range removed {
+		ctx.storeMeta.removedRegions[region.Id] = region
+	}
+
+	sw.handleRemovedPeerDataGC()
+	require.Equal(t, 0, len(ctx.storeMeta.removedRegions))
+	// only the ranges not owned by a live region are reclaimed
+	require.Equal(t, 3, len(regionCh))
+	tasks := make(map[uint64]*runner.RegionTaskDestroy)
+	for len(regionCh) > 0 {
+		task := (<-regionCh).(*runner.RegionTaskDestroy)
+		tasks[task.RegionId] = task
+	}
+	expected := []*runner.RegionTaskDestroy{
+		{RegionId: 1, StartKey: []byte("a"), EndKey: []byte("c")},
+		{RegionId: 2, StartKey: []byte("c"), EndKey: []byte("d")},
+		{RegionId: 4, StartKey: []byte("f")},
+	}
+	for _, task := range expected {
+		require.Equal(t, task, tasks[task.RegionId])
+	}
+}
+
+func TestLoadRemovedPeerData(t *testing.T) {
+	engines := util.NewTestEngines()
+	defer engines.Destroy()
+	// the data was kept when the peers were removed, it's reclaimed though the option is off now
+	cfg := config.NewTestConfig()
+	cfg.KeepRemovedPeerData = false
+	ctx := &GlobalContext{
+		cfg:       cfg,
+		engine:    engines,
+		store:     &metapb.Store{Id: 1},
+		storeMeta: newStoreMeta(),
+	}
+	bs := &Raftstore{ctx: ctx}
+
+	kvWB := new(engine_util.WriteBatch)
+	peers := []*metapb.Peer{{Id: 1, StoreId: 1}}
+	meta.WriteRegionState(kvWB, &metapb.Region{Id: 1, StartKey: []byte("a"), EndKey: []byte("c"), Peers: peers}, rspb.PeerState_Tombstone)
+	// the data of region 2 has been reclaimed
+	meta.WriteRegionState(kvWB, &metapb.Region{Id: 2, StartKey: []byte("c"), EndKey: []byte("e"), Peers: peers}, rspb.PeerState_Tombstone)
+	kvWB.SetCF(engine_util.CfDefault, []byte("b"), []byte("v"))
+	require.Nil(t, engines.WriteKV(kvWB))
+
+	regionPeers, err := bs.loadPeers()
+	require.Nil(t, err)
+	require.Equal(t, 0, len(regionPeers))
+	require.Equal(t, 1, len(ctx.storeMeta.removedRegions))
+	require.NotNil(t, ctx.storeMeta.removedRegions[1])
+}
+
+func TestRegionHasData(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	kvWB := new(engine_util.WriteBatch)
+	kvWB.SetCF(engine_util.CfWrite, []byte("b1"), []byte("v1"))
+	require.Nil(t, peerStore.Engines.WriteKV(kvWB))
+
+	txn := peerStore.Engines.Kv.NewTransaction(false)
+	defer txn.Discard()
+	require.True(t, regionHasData(txn, &metapb.Region{StartKey: []byte("a"), EndKey: []byte("c")}))
+	require.True(t, regionHasData(txn, &metapb.Region{StartKey: []byte("b")}))
+	// the data has been reclaimed or belongs to other ranges
+	require.False(t, regionHasData(txn, &metapb.Region{StartKey: []byte("a"), EndKey: []byte("b1")}))
+	require.False(t, regionHasData(txn, &metapb.Region{StartKey: []byte("c"), EndKey: []byte("d")}))
+}
+
+func TestWriteStall(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	p := &peer{peerStorage: peerStore, Tag: "test"}
+	appliedIdx := peerStore.AppliedIndex()
+	threshold, duration := uint64(10), time.Second
+	now := time.Now()
+
+	// the lag is below the threshold
+	p.LastApplyingIdx = appliedIdx + threshold
+	require.False(t, p.checkWriteStall(now, threshold, duration))
+	require.False(t, p.WriteStall)
+
+	// the lag exceeds the threshold but is not sustained yet
+	p.LastApplyingIdx = appliedIdx + threshold + 1
+	require.False(t, p.checkWriteStall(now, threshold, duration))
+	require.False(t, p.checkWriteStall(now.Add(duration/2), threshold, duration))
+	require.False(t, p.WriteStall)
+
+	// the lag drops back in the middle, the period starts over
+	p.LastApplyingIdx = appliedIdx
+	require.False(t, p.checkWriteStall(now.Add(duration/2), threshold, duration))
+	p.LastApplyingIdx = appliedIdx + 100
+	require.False(t, p.checkWriteStall(now.Add(duration), threshold, duration))
+	require.False(t, p.WriteStall)
+
+	// the lag is sustained
+	require.True(t, p.checkWriteStall(now.Add(2*duration), threshold, duration))
+	require.True(t, p.WriteStall)
+	require.False(t, p.checkWriteStall(now.Add(3*duration), threshold, duration))
+	require.True(t, p.WriteStall)
+
+	// the apply catches up
+	p.LastApplyingIdx = appliedIdx
+	require.True(t, p.checkWriteStall(now.Add(4*duration), threshold, duration))
+	require.False(t, p.WriteStall)
+}
+
+// slowTransport blocks the messages sent to slowStore until unblocked or ctx is done.
+type slowTransport struct {
+	slowStore uint64
+	unblock   chan struct{}
+	slowSent  int32
+	sent      int32
+}
+
+func (t *slowTransport) Send(ctx context.Context, msg *rspb.RaftMessage) error {
+	if msg.ToPeer.StoreId == t.slowStore {
+		atomic.AddInt32(&t.slowSent, 1)
+		select {
+		case <-t.unblock:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	atomic.AddInt32(&t.sent, 1)
+	return nil
+}
+
+func TestSendTimeoutReportUnreachable(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	raftGroup, err := raft.NewRawNode(&raft.Config{
+		ID:            1,
+		ElectionTick:  10,
+		HeartbeatTick: 2,
+		Storage:       peerStore,
+	})
+	require.Nil(t, err)
+	raftGroup.Raft.State = raft.StateLeader
+	raftGroup.Raft.Prs[2] = &raft.Progress{Match: 5, Next: 8}
+	raftGroup.Raft.Prs[3] = &raft.Progress{Match: 5, Next: 8}
+	p := &peer{
+		Meta:        &metapb.Peer{Id: 1, StoreId: 1},
+		regionId:    peerStore.region.GetId(),
+		RaftGroup:   raftGroup,
+		peerStorage: peerStore,
+		peerCache:   make(map[uint64]*metapb.Peer),
+		Tag:         "test",
+		sendTimeout: 50 * time.Millisecond,
+	}
+	p.insertPeerCache(&metapb.Peer{Id: 2, StoreId: 2})
+	p.insertPeerCache(&metapb.Peer{Id: 3, StoreId: 3})
+
+	trans := &slowTransport{slowStore: 2, unblock: make(chan struct{})}
+	defer close(trans.unblock)
+	start := time.Now()
+	p.Send(trans, []eraftpb.Message{
+		{MsgType: eraftpb.MessageType_MsgAppend, To: 2},
+		{MsgType: eraftpb.MessageType_MsgAppend, To: 3},
+		{MsgType: eraftpb.MessageType_MsgHeartbeat, To: 2},
+		{MsgType: eraftpb.MessageType_MsgHeartbeat, To: 3},
+	})
+
+	// Only the first message to the slow peer waits for the timeout, the rest are dropped.
+	require.True(t, time.Since(start) < time.Second)
+	require.Equal(t, int32(1), atomic.LoadInt32(&trans.slowSent))
+	require.Equal(t, int32(2), atomic.LoadInt32(&trans.sent))
+	// The slow peer is reported unreachable, so it's sent from the matched index again.
+	require.Equal(t, uint64(6), raftGroup.Raft.Prs[2].Next)
+	require.Equal(t, uint64(8), raftGroup.Raft.Prs[3].Next)
+}
+
+func TestHeartbeatSchedulerRaftState(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	raftGroup, err := raft.NewRawNode(&raft.Config{
+		ID:            1,
+		ElectionTick:  10,
+		HeartbeatTick: 2,
+		Storage:       peerStore,
+	})
+	require.Nil(t, err)
+	raftGroup.Raft.State = raft.StateLeader
+	raftGroup.Raft.Term = 7
+	p := &peer{
+		Meta:                  &metapb.Peer{Id: 1, StoreId: 1},
+		regionId:              peerStore.region.GetId(),
+		RaftGroup:             raftGroup,
+		peerStorage:           peerStore,
+		peerCache:             make(map[uint64]*metapb.Peer),
+		PeersStartPendingTime: make(map[uint64]time.Time),
+		Tag:                   "test",
+	}
+
+	ch := make(chan worker.Task, 1)
+	p.HeartbeatScheduler(ch)
+	require.Equal(t, 1, len(ch))
+	task := (<-ch).(*runner.SchedulerRegionHeartbeatTask)
+	require.Equal(t, uint64(7), task.Term)
+	require.Equal(t, raftGroup.Status().Commit, task.CommitIndex)
+	require.Equal(t, peerStore.raftState.HardState.Commit, task.CommitIndex)
+	require.Equal(t, peerStore.AppliedIndex(), task.AppliedIndex)
+}
+
+func TestRetryDroppedConfChange(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	raftGroup, err := raft.NewRawNode(&raft.Config{
+		ID:            1,
+		ElectionTick:  10,
+		HeartbeatTick: 2,
+		Storage:       peerStore,
+	})
+	require.Nil(t, err)
+	raftGroup.Raft.State = raft.StateLeader
+	p := &peer{
+		Meta:        &metapb.Peer{Id: 1, StoreId: 1},
+		regionId:    peerStore.region.GetId(),
+		RaftGroup:   raftGroup,
+		peerStorage: peerStore,
+		peerCache:   make(map[uint64]*metapb.Peer),
+		Tag:         "test",
+	}
+	// The raft group accepts the proposal without appending it while the leader is
+	// in the group, the append path of the leader is covered by the raft tests.
+	var proposed []eraftpb.ConfChange
+	p.proposeConfChangeToRaft = func(cc eraftpb.ConfChange) (uint64, error) {
+		if _, ok := raftGroup.Raft.Prs[1]; !ok {
+			return p.raftProposeConfChange(cc)
+		}
+		proposed = append(proposed, cc)
+		return p.nextProposalIndex(), nil
+	}
+	cfg := config.NewTestConfig()
+	cfg.ConfChangeRetryLimit = 2
+	kv := peerStore.Engines.Kv
+	newReq := func() *raft_cmdpb.RaftCmdRequest {
+		return &raft_cmdpb.RaftCmdRequest{
+			Header: &raft_cmdpb.RaftRequestHeader{RegionId: p.regionId, Peer: p.Meta},
+			AdminRequest: &raft_cmdpb.AdminRequest{
+				CmdType: raft_cmdpb.AdminCmdType_ChangePeer,
+				ChangePeer: &raft_cmdpb.ChangePeerRequest{
+					ChangeType: eraftpb.ConfChangeType_AddNode,
+					Peer:       &metapb.Peer{Id: 2, StoreId: 2},
+				},
+			},
+		}
+	}
+
+	// The leader drops the proposals while it's absent from the group for a moment.
+	pr := raftGroup.Raft.Prs[1]
+	delete(raftGroup.Raft.Prs, 1)
+	cb := message.NewCallback()
+	require.False(t, p.Propose(kv, cfg, cb, newReq(), &raft_cmdpb.RaftCmdResponse{}))
+	require.Nil(t, cb.Resp)
+	require.Nil(t, p.takeConfChangeRetry(time.Now()))
+	retry := p.takeConfChangeRetry(time.Now().Add(cfg.ConfChangeRetryInterval))
+	require.NotNil(t, retry)
+	require.Equal(t, 1, retry.attempts)
+
+	// It's still dropped, so it's retried again.
+	require.False(t, p.propose(kv, cfg, retry.cb, retry.req, &raft_cmdpb.RaftCmdResponse{}, retry.attempts))
+	require.Nil(t, cb.Resp)
+	retry = p.takeConfChangeRetry(time.Now().Add(cfg.ConfChangeRetryInterval))
+	require.NotNil(t, retry)
+	require.Equal(t, 2, retry.attempts)
+
+	// It gives up after the retry limit, the error is returned to the callback.
+	require.False(t, p.propose(kv, cfg, retry.cb, retry.req, &raft_cmdpb.RaftCmdResponse{}, retry.attempts))
+	require.Nil(t, p.confChangeRetry)
+	require.NotNil(t, cb.Resp.GetHeader().GetError())
+	require.Empty(t, proposed)
+
+	// The retry is accepted once the leader is back in the group.
+	cb = message.NewCallback()
+	require.False(t, p.Propose(kv, cfg, cb, newReq(), &raft_cmdpb.RaftCmdResponse{}))
+	retry = p.takeConfChangeRetry(time.Now().Add(cfg.ConfChangeRetryInterval))
+	require.NotNil(t, retry)
+	raftGroup.Raft.Prs[1] = pr
+	require.True(t, p.propose(kv, cfg, retry.cb, retry.req, &raft_cmdpb.RaftCmdResponse{}, retry.attempts))
+	require.Nil(t, cb.Resp)
+	require.Nil(t, p.confChangeRetry)
+	require.Len(t, proposed, 1)
+	require.Equal(t, eraftpb.ConfChangeType_AddNode, proposed[0].ChangeType)
+	require.Equal(t, uint64(2), proposed[0].NodeId)
+	require.Len(t, p.applyProposals, 1)
+	require.True(t, p.applyProposals[0].isConfChange)
+	require.Equal(t, cb, p.applyProposals[0].cb)
+}
+
+func TestPeerKeyRangeAfterSplit(t *testing.T) {
+	peerStore := newTestPeerStorage(t)
+	defer cleanUpTestData(peerStore)
+	cfg := config.NewTestConfig()
+	regionCh := make(chan worker.Task, 4)
+	region := peerStore.region
+	raftGroup, err := raft.NewRawNode(&raft.Config{
+		ID:            1,
+		ElectionTick:  10,
+		HeartbeatTick: 2,
+		Storage:       peerStore,
+	})
+	require.Nil(t, err)
+	p := &peer{
+		Meta:        region.Peers[0],
+		regionId:    region.GetId(),
+		RaftGroup:   raftGroup,
+		peerStorage: peerStore,
+		peerCache:   make(map[uint64]*metapb.Peer),
+		Tag:         "test",
+	}
+	raftRouter, _ := CreateRaftstore(cfg)
+	ctx := &GlobalContext{
+		cfg:              cfg,
+		engine:           peerStore.Engines,
+		store:            &metapb.Store{Id: 1},
+		storeMeta:        newStoreMeta(),
+		router:           raftRouter.router,
+		regionTaskSender: regionCh,
+	}
+	ctx.storeMeta.setRegion(region, p)
+	ctx.storeMeta.regionRanges.ReplaceOrInsert(&regionItem{region: region})
+	d := newPeerMsgHandler(p, nil, ctx)
+
+	startKey, endKey := p.KeyRange()
+	require.Empty(t, startKey)
+	require.Empty(t, endKey)
+
+	// the range is copied, modifying it doesn't affect the region
+	region.EndKey = []byte("z")
+	_, endKey = p.KeyRange()
+	endKey[0] = 'y'
+	require.Equal(t, []byte("z"), p.Region().EndKey)
+
+	epoch := &metapb.RegionEpoch{ConfVer: region.RegionEpoch.ConfVer, Version: region.RegionEpoch.Version + 1}
+	newRegion := &metapb.Region{
+		Id:          2,
+		StartKey:    region.StartKey,
+		EndKey:      []byte("k"),
+		RegionEpoch: epoch,
+		// two peers, so the new peer doesn't campaign on creation
+		Peers: []*metapb.Peer{{Id: 2, StoreId: 1}, {Id: 3, StoreId: 2}},
+	}
+	derived := &metapb.Region{
+		Id:          region.Id,
+		StartKey:    []byte("k"),
+		EndKey:      region.EndKey,
+		RegionEpoch: epoch,
+		Peers:       region.Peers,
+	}
+	d.onReadySplitRegion(derived, []*metapb.Region{newRegion, derived})
+
+	// the range is updated as soon as the split is applied
+	startKey, endKey = p.KeyRange()
+	require.Equal(t, []byte("k"), startKey)
+	require.Equal(t, []byte("z"), endKey)
+	newPeer := ctx.router.get(newRegion.Id).peer
+	startKey, endKey = newPeer.KeyRange()
+	require.Empty(t, startKey)
+	require.Equal(t, []byte("k"), endKey)
+}
@@ -2,10 +2,14 @@ package raftstore
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/Connor1996/badger"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pingcap-incubator/tinykv/kv/config"
@@ -16,6 +20,8 @@ import (
 	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
+	rspb "github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
+	"github.com/pingcap/errors"
 )
 
 type EntryBuilder struct {
@@ -71,6 +77,21 @@ func (b *EntryBuilder) delete(cf string, key []byte) *EntryBuilder {
 	return b
 }
 
+func (b *EntryBuilder) computeHash() *EntryBuilder {
+	b.req.AdminRequest = &raft_cmdpb.AdminRequest{
+		CmdType: raft_cmdpb.AdminCmdType_ComputeHash,
+	}
+	return b
+}
+
+func (b *EntryBuilder) verifyHash(index uint64, hash []byte) *EntryBuilder {
+	b.req.AdminRequest = &raft_cmdpb.AdminRequest{
+		CmdType:    raft_cmdpb.AdminCmdType_VerifyHash,
+		VerifyHash: &raft_cmdpb.VerifyHashRequest{Index: index, Hash: hash},
+	}
+	return b
+}
+
 func (b *EntryBuilder) epoch(confVer, version uint64) *EntryBuilder {
 	b.req.Header = &raft_cmdpb.RaftRequestHeader{
 		RegionEpoch: &metapb.RegionEpoch{
@@ -103,6 +124,30 @@ func (b *EntryBuilder) build(applyCh chan<- []message.Msg, peerID, regionID uint
 	return &b.entry
 }
 
+// newChangePeerEntry builds a conf change entry carrying a ChangePeer admin command.
+func newChangePeerEntry(index, term uint64, epoch *metapb.RegionEpoch, changeType eraftpb.ConfChangeType, peer *metapb.Peer) *eraftpb.Entry {
+	req := &raft_cmdpb.RaftCmdRequest{
+		Header: &raft_cmdpb.RaftRequestHeader{RegionEpoch: epoch},
+		AdminRequest: &raft_cmdpb.AdminRequest{
+			CmdType: raft_cmdpb.AdminCmdType_ChangePeer,
+			ChangePeer: &raft_cmdpb.ChangePeerRequest{
+				ChangeType: changeType,
+				Peer:       peer,
+			},
+		},
+	}
+	ctx, err := req.Marshal()
+	if err != nil {
+		panic("marshal err")
+	}
+	cc := eraftpb.ConfChange{ChangeType: changeType, NodeId: peer.Id, Context: ctx}
+	data, err := cc.Marshal()
+	if err != nil {
+		panic("marshal err")
+	}
+	return &eraftpb.Entry{EntryType: eraftpb.EntryType_EntryConfChange, Index: index, Term: term, Data: data}
+}
+
 func commit(applyCh chan<- []message.Msg, entries []eraftpb.Entry, regionID uint64) {
 	apply := &MsgApplyCommitted{
 		regionId: regionID,
@@ -118,8 +163,24 @@ func checkApplyIndex(t *testing.T, engines *engine_util.Engines, expected uint64
 	require.Equal(t, state.AppliedIndex, expected)
 }
 
-func TestHandleRaftCommittedEntries(t *testing.T) {
+// newTestApplier initializes the apply state of region 1 in new test engines, and returns the applier
+// of its peer 3 with an apply context to apply the entries. The caller destroys the engines.
+func newTestApplier(t *testing.T) (*applier, *applyContext, *engine_util.Engines, chan message.Msg) {
 	engines := util.NewTestEngines()
+	notifier := make(chan message.Msg, 1)
+	aCtx := newApplyContext("", engines, notifier, config.NewTestConfig())
+	region := &metapb.Region{
+		Id:          1,
+		Peers:       []*metapb.Peer{{Id: 3, StoreId: 2}},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 3},
+	}
+	_, err := meta.InitApplyState(engines.Kv, region)
+	require.Nil(t, err)
+	return &applier{id: 3, region: region}, aCtx, engines, notifier
+}
+
+func TestHandleRaftCommittedEntries(t *testing.T) {
+	a, _, engines, _ := newTestApplier(t)
 	defer engines.Destroy()
 
 	cfg := config.NewDefaultConfig()
@@ -137,26 +198,8 @@ func TestHandleRaftCommittedEntries(t *testing.T) {
 	go aw.run(wg)
 	defer wg.Wait()
 
-	region := &metapb.Region{
-		Id: 1,
-		Peers: []*metapb.Peer{{
-			Id:      3,
-			StoreId: 2,
-		}},
-		EndKey: []byte("k5"),
-		RegionEpoch: &metapb.RegionEpoch{
-			ConfVer: 1,
-			Version: 3,
-		},
-	}
-	meta.InitApplyState(engines.Kv, region)
-	newPeer := &peerState{
-		apply: &applier{
-			id:     3,
-			region: region,
-		},
-	}
-	router.peers.Store(uint64(1), newPeer)
+	a.region.EndKey = []byte("k5")
+	router.peers.Store(uint64(1), &peerState{apply: a})
 
 	cb := message.NewCallback()
 	entry := NewEntryBuilder(6, 1).
@@ -283,6 +326,516 @@ func TestHandleRaftCommittedEntries(t *testing.T) {
 	applyCh <- nil
 }
 
+func TestApplyWriteBatchSizeLimit(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+	aCtx.wbSizeLimit = 2 * config.KB
+	aCtx.wbMaxDelay = 0
+
+	applyCh := make(chan []message.Msg, 5)
+	value := bytes.Repeat([]byte("v"), int(config.KB))
+	var entries []eraftpb.Entry
+	for i := 6; i <= 10; i++ {
+		entry := NewEntryBuilder(uint64(i), 1).
+			put(engine_util.CfDefault, []byte(fmt.Sprintf("k%d", i)), value).
+			epoch(1, 3).
+			build(applyCh, 3, 1, nil)
+		entries = append(entries, *entry)
+	}
+	a.handleRaftCommittedEntries(aCtx, entries)
+
+	// The write batch is written every two entries, the last entry is still in memory.
+	checkApplyIndex(t, engines, uint64(9))
+	_, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k10"))
+	require.Equal(t, badger.ErrKeyNotFound, err)
+	require.True(t, aCtx.wb.Len() > 0)
+
+	aCtx.flush()
+	checkApplyIndex(t, engines, uint64(10))
+	val, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k10"))
+	require.Nil(t, err)
+	require.Equal(t, value, val)
+	fetchApplyRes(notifier)
+}
+
+func TestApplyWriteBatchPerEntry(t *testing.T) {
+	// The write batch is written after every entry by default.
+	require.Equal(t, uint64(0), config.NewTestConfig().ApplyWriteBatchSizeLimit)
+	require.Equal(t, uint64(0), config.NewDefaultConfig().ApplyWriteBatchSizeLimit)
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+
+	applyCh := make(chan []message.Msg, 2)
+	var entries []eraftpb.Entry
+	for i := 6; i <= 7; i++ {
+		entry := NewEntryBuilder(uint64(i), 1).
+			put(engine_util.CfDefault, []byte(fmt.Sprintf("k%d", i)), []byte("v")).
+			epoch(1, 3).
+			build(applyCh, 3, 1, nil)
+		entries = append(entries, *entry)
+	}
+	a.handleRaftCommittedEntries(aCtx, entries)
+
+	// All the entries are written before the apply context is flushed.
+	checkApplyIndex(t, engines, uint64(7))
+	for i := 6; i <= 7; i++ {
+		val, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte(fmt.Sprintf("k%d", i)))
+		require.Nil(t, err)
+		require.Equal(t, []byte("v"), val)
+	}
+	aCtx.flush()
+	fetchApplyRes(notifier)
+}
+
+func TestApplierAppliedCount(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+
+	applyCh := make(chan []message.Msg, 10)
+	var entries []eraftpb.Entry
+	for i := 6; i <= 11; i++ {
+		entry := NewEntryBuilder(uint64(i), 1).
+			put(engine_util.CfDefault, []byte(fmt.Sprintf("k%d", i)), []byte("v")).
+			epoch(1, 3).
+			build(applyCh, 3, 1, nil)
+		entries = append(entries, *entry)
+	}
+
+	// The applied count accumulates the entries across the apply batches.
+	a.handleRaftCommittedEntries(aCtx, entries[:2])
+	aCtx.flush()
+	require.Equal(t, uint64(2), fetchApplyRes(notifier).appliedCount)
+	a.handleRaftCommittedEntries(aCtx, entries[2:5])
+	aCtx.flush()
+	require.Equal(t, uint64(5), fetchApplyRes(notifier).appliedCount)
+
+	// It's kept when the applier is refreshed.
+	a.handleRefresh(&MsgApplyRefresh{id: 3, term: 1, region: a.region})
+	a.handleRaftCommittedEntries(aCtx, entries[5:])
+	aCtx.flush()
+	require.Equal(t, uint64(6), fetchApplyRes(notifier).appliedCount)
+	checkApplyIndex(t, engines, uint64(11))
+}
+
+func TestApplyConfChangeWithEmptyContext(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+
+	cc := eraftpb.ConfChange{ChangeType: eraftpb.ConfChangeType_AddNode, NodeId: 4}
+	data, err := cc.Marshal()
+	require.Nil(t, err)
+	entry := eraftpb.Entry{EntryType: eraftpb.EntryType_EntryConfChange, Index: 6, Term: 1, Data: data}
+	a.handleRaftCommittedEntries(aCtx, []eraftpb.Entry{entry})
+	aCtx.flush()
+
+	// The conf change is handed over to raft, but the region is left as it is.
+	res := fetchApplyRes(notifier)
+	require.Len(t, res.execResults, 1)
+	cp := res.execResults[0].(*execResultChangePeer)
+	require.Equal(t, cc, *cp.confChange)
+	require.Nil(t, cp.region)
+	require.Len(t, a.region.Peers, 1)
+	require.Equal(t, uint64(1), a.region.RegionEpoch.ConfVer)
+	checkApplyIndex(t, engines, uint64(6))
+}
+
+func TestApplyMovePeer(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+	a.region.Peers = append(a.region.Peers, &metapb.Peer{Id: 4, StoreId: 5})
+
+	movePeer := func(index uint64, peer *metapb.Peer) *execResultChangePeer {
+		entry := newChangePeerEntry(index, 1, a.region.RegionEpoch, eraftpb.ConfChangeType_MovePeer, peer)
+		a.handleRaftCommittedEntries(aCtx, []eraftpb.Entry{*entry})
+		aCtx.flush()
+		res := fetchApplyRes(notifier)
+		require.Len(t, res.execResults, 1)
+		return res.execResults[0].(*execResultChangePeer)
+	}
+
+	// The peer is moved to the new store in a single conf change, its id is kept.
+	cp := movePeer(6, &metapb.Peer{Id: 4, StoreId: 6})
+	require.Equal(t, eraftpb.ConfChangeType_MovePeer, cp.confChange.ChangeType)
+	require.Equal(t, []*metapb.Peer{{Id: 3, StoreId: 2}, {Id: 4, StoreId: 6}}, a.region.Peers)
+	require.Equal(t, uint64(2), a.region.RegionEpoch.ConfVer)
+	state, err := meta.GetRegionLocalState(engines.Kv, 1)
+	require.Nil(t, err)
+	require.Equal(t, a.region.Peers, state.Region.Peers)
+
+	// Moving a missing peer, or moving a peer to the same store or a store with another peer, is rejected.
+	for i, peer := range []*metapb.Peer{{Id: 5, StoreId: 7}, {Id: 4, StoreId: 6}, {Id: 4, StoreId: 2}} {
+		cp = movePeer(uint64(7+i), peer)
+		require.Equal(t, uint64(0), cp.confChange.NodeId)
+		require.Equal(t, []*metapb.Peer{{Id: 3, StoreId: 2}, {Id: 4, StoreId: 6}}, a.region.Peers)
+		require.Equal(t, uint64(2), a.region.RegionEpoch.ConfVer)
+	}
+	checkApplyIndex(t, engines, uint64(9))
+}
+
+func TestApplyAddDuplicatedPeerID(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+	a.region.Peers = append(a.region.Peers, &metapb.Peer{Id: 4, StoreId: 5})
+
+	// The new peer is on a store without any peer of the region, but it reuses the id of peer 4.
+	cb := message.NewCallback()
+	a.handleProposal(&MsgApplyProposal{Id: 3, RegionId: 1, Props: []*proposal{{isConfChange: true, index: 6, term: 1, cb: cb}}})
+	entry := newChangePeerEntry(6, 1, a.region.RegionEpoch, eraftpb.ConfChangeType_AddNode, &metapb.Peer{Id: 4, StoreId: 6})
+	a.handleRaftCommittedEntries(aCtx, []eraftpb.Entry{*entry})
+	aCtx.flush()
+
+	resp := cb.WaitResp()
+	require.NotNil(t, resp.GetHeader().GetError())
+	require.Contains(t, resp.GetHeader().GetError().GetMessage(), "can't add peer with duplicated id")
+	res := fetchApplyRes(notifier)
+	require.Len(t, res.execResults, 1)
+	require.Equal(t, uint64(0), res.execResults[0].(*execResultChangePeer).confChange.NodeId)
+	require.Equal(t, []*metapb.Peer{{Id: 3, StoreId: 2}, {Id: 4, StoreId: 5}}, a.region.Peers)
+	require.Equal(t, uint64(1), a.region.RegionEpoch.ConfVer)
+	checkApplyIndex(t, engines, uint64(6))
+}
+
+func TestApplyRejectStaleProposal(t *testing.T) {
+	region := &metapb.Region{
+		Id:          1,
+		Peers:       []*metapb.Peer{{Id: 3, StoreId: 2}},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}
+	a := &applier{
+		id:     3,
+		term:   1,
+		region: region,
+	}
+	a.handleRefresh(&MsgApplyRefresh{id: 3, term: 2, region: region})
+
+	// Proposals of the old term arriving after the refresh are rejected at once.
+	normalCb, confCb, curCb := message.NewCallback(), message.NewCallback(), message.NewCallback()
+	a.handleProposal(&MsgApplyProposal{Id: 3, RegionId: 1, Props: []*proposal{
+		{index: 5, term: 1, cb: normalCb},
+		{isConfChange: true, index: 6, term: 1, cb: confCb},
+		{index: 7, term: 2, cb: curCb},
+	}})
+	for _, cb := range []*message.Callback{normalCb, confCb} {
+		resp := cb.WaitRespWithTimeout(time.Second)
+		require.NotNil(t, resp)
+		require.NotNil(t, resp.GetHeader().GetError().GetStaleCommand())
+		require.Equal(t, uint64(2), resp.GetHeader().GetCurrentTerm())
+	}
+	require.Nil(t, a.pendingCmds.confChange)
+	require.Len(t, a.pendingCmds.normals, 1)
+	require.Equal(t, uint64(7), a.pendingCmds.normals[0].index)
+}
+
+func TestApplySnapAfterTermChange(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+
+	applyCh := make(chan []message.Msg, 10)
+	staleCb, cb := message.NewCallback(), message.NewCallback()
+	entries := []eraftpb.Entry{
+		*NewEntryBuilder(6, 1).snap().epoch(1, 3).build(applyCh, 3, 1, staleCb),
+		*NewEntryBuilder(7, 2).snap().epoch(1, 3).build(applyCh, 3, 1, cb),
+	}
+	for i := 0; i < 2; i++ {
+		for _, msg := range <-applyCh {
+			a.handleTask(aCtx, msg)
+		}
+	}
+
+	// The snap proposed in term 1 is applied after the region moves to term 2.
+	a.handleApply(aCtx, &MsgApplyCommitted{regionId: 1, term: 2, entries: entries})
+	aCtx.flush()
+	fetchApplyRes(notifier)
+
+	resp := staleCb.WaitResp()
+	require.NotNil(t, resp.GetHeader().GetError().GetNotLeader())
+	require.Nil(t, staleCb.Txn)
+	resp = cb.WaitResp()
+	require.Nil(t, resp.GetHeader().GetError())
+	require.Equal(t, a.region.Id, resp.Responses[0].GetSnap().Region.Id)
+	require.NotNil(t, cb.Txn)
+	cb.Txn.Discard()
+	checkApplyIndex(t, engines, uint64(7))
+}
+
+func TestApplyWriteBatchMaxDelay(t *testing.T) {
+	a, aCtx, engines, notifier := newTestApplier(t)
+	defer engines.Destroy()
+	aCtx.wbSizeLimit = 1 * config.MB
+	aCtx.wbMaxDelay = 50 * time.Millisecond
+
+	// The batch is far below the size limit, it's written once the max delay is passed.
+	aCtx.prepareFor(a)
+	aCtx.wb.SetCF(engine_util.CfDefault, []byte("k1"), []byte("v1"))
+	require.False(t, aCtx.shouldWriteToEngine())
+	time.Sleep(aCtx.wbMaxDelay)
+	require.True(t, aCtx.shouldWriteToEngine())
+	aCtx.commit(a)
+	require.False(t, aCtx.shouldWriteToEngine())
+	val, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k1"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v1"), val)
+
+	// A single write is applied and responded as soon as the max delay is passed,
+	// it doesn't wait for the rest of the entries.
+	cfg := config.NewTestConfig()
+	cfg.ApplyWriteBatchSizeLimit = 1 * config.MB
+	cfg.ApplyWriteBatchMaxDelay = time.Nanosecond
+	aCtx = newApplyContext("", engines, notifier, cfg)
+	applyCh := make(chan []message.Msg, 2)
+	cb := message.NewCallback()
+	entry := NewEntryBuilder(6, 1).
+		put(engine_util.CfDefault, []byte("k2"), []byte("v2")).
+		epoch(1, 3).
+		build(applyCh, 3, 1, cb)
+	a.handleTask(aCtx, (<-applyCh)[0])
+	a.handleRaftCommittedEntries(aCtx, []eraftpb.Entry{*entry})
+	resp := cb.WaitRespWithTimeout(time.Second)
+	require.NotNil(t, resp)
+	require.Nil(t, resp.GetHeader().GetError())
+	checkApplyIndex(t, engines, uint64(6))
+	val, err = engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k2"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v2"), val)
+
+	aCtx.flush()
+	fetchApplyRes(notifier)
+}
+
+func TestApplyStateRepairAfterInterruptedSnapshot(t *testing.T) {
+	a, aCtx, engines, _ := newTestApplier(t)
+	defer engines.Destroy()
+	region := a.region
+	// The snapshot at index 10 is interrupted, the region state is written but the
+	// apply state is not updated.
+	kvWB := new(engine_util.WriteBatch)
+	kvWB.SetMeta(meta.SnapshotRaftStateKey(region.Id), &rspb.RaftLocalState{LastIndex: 10, LastTerm: 6})
+	meta.WriteRegionState(kvWB, region, rspb.PeerState_Normal)
+	require.Nil(t, kvWB.WriteToDB(engines.Kv))
+
+	aCtx.prepareFor(a)
+	require.Equal(t, uint64(10), a.applyState.AppliedIndex)
+	require.Equal(t, uint64(10), a.applyState.TruncatedState.Index)
+	require.Equal(t, uint64(6), a.applyState.TruncatedState.Term)
+	aCtx.commit(a)
+	applyState, err := meta.GetApplyState(engines.Kv, region.Id)
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), applyState.AppliedIndex)
+	require.Equal(t, uint64(10), applyState.TruncatedState.Index)
+
+	// The apply state is not behind the snapshot, nothing is changed.
+	applyState.AppliedIndex = 12
+	require.Nil(t, engine_util.PutMeta(engines.Kv, meta.ApplyStateKey(region.Id), applyState))
+	a.handleRefresh(&MsgApplyRefresh{id: 3, term: 6, region: region})
+	aCtx.prepareFor(a)
+	require.Equal(t, uint64(12), a.applyState.AppliedIndex)
+	require.Equal(t, uint64(10), a.applyState.TruncatedState.Index)
+}
+
+func TestApplyWriteRetry(t *testing.T) {
+	engines := util.NewTestEngines()
+	defer engines.Destroy()
+
+	cfg := config.NewTestConfig()
+	aCtx := newApplyContext("", engines, nil, cfg)
+	writeBatch := aCtx.writeBatch
+	failures := 2
+	aCtx.writeBatch = func(wb *engine_util.WriteBatch) error {
+		if failures > 0 {
+			failures--
+			return errors.WithStack(syscall.EIO)
+		}
+		return writeBatch(wb)
+	}
+	aCtx.wb.SetCF(engine_util.CfDefault, []byte("k1"), []byte("v1"))
+	// the transient errors are retried until the write succeeds
+	aCtx.writeToDB()
+	require.Equal(t, 0, failures)
+	val, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k1"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v1"), val)
+
+	// a transient error still panics once the retries are exhausted
+	failures = cfg.ApplyWriteMaxRetry + 1
+	aCtx.wb.SetCF(engine_util.CfDefault, []byte("k2"), []byte("v2"))
+	require.Panics(t, aCtx.writeToDB)
+
+	// a fatal error is not retried
+	calls := 0
+	aCtx.writeBatch = func(wb *engine_util.WriteBatch) error {
+		calls++
+		return errors.New("corrupted value log")
+	}
+	require.Panics(t, aCtx.writeToDB)
+	require.Equal(t, 1, calls)
+}
+
+func TestApplyMalformedKey(t *testing.T) {
+	a, _, engines, _ := newTestApplier(t)
+	defer engines.Destroy()
+
+	cfg := config.NewDefaultConfig()
+	raftRouter, _ := CreateRaftstore(cfg)
+	router := raftRouter.router
+	ctx := &GlobalContext{
+		cfg:    cfg,
+		engine: engines,
+		router: router,
+	}
+	applyCh := make(chan []message.Msg, 1)
+	aw := newApplyWorker(ctx, applyCh, router)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go aw.run(wg)
+	defer wg.Wait()
+
+	router.peers.Store(uint64(1), &peerState{apply: a})
+
+	localKey := []byte{meta.LocalPrefix, 0x02}
+	builders := []*EntryBuilder{
+		NewEntryBuilder(6, 1).
+			put(engine_util.CfDefault, []byte("k1"), []byte("v1")).
+			put(engine_util.CfDefault, []byte{}, []byte("v")),
+		NewEntryBuilder(7, 1).
+			put(engine_util.CfDefault, localKey, []byte("v")),
+		NewEntryBuilder(8, 1).
+			put("raft", []byte("k2"), []byte("v2")),
+		NewEntryBuilder(9, 1).
+			delete(engine_util.CfDefault, localKey),
+	}
+	for i, b := range builders {
+		cb := message.NewCallback()
+		entry := b.epoch(1, 3).build(applyCh, 3, 1, cb)
+		commit(applyCh, []eraftpb.Entry{*entry}, 1)
+		resp := cb.WaitResp()
+		require.True(t, strings.Contains(resp.GetHeader().GetError().GetMessage(), "malformed key"))
+		fetchApplyRes(router.peerSender)
+		checkApplyIndex(t, engines, uint64(6+i))
+	}
+	// none of the writes of a rejected request should be stored
+	_, err := engine_util.GetCF(engines.Kv, engine_util.CfDefault, []byte("k1"))
+	require.Equal(t, badger.ErrKeyNotFound, err)
+	_, err = engine_util.GetCF(engines.Kv, engine_util.CfDefault, localKey)
+	require.Equal(t, badger.ErrKeyNotFound, err)
+
+	applyCh <- nil
+}
+
+func TestComputeAndVerifyHash(t *testing.T) {
+	type replica struct {
+		engines  *engine_util.Engines
+		aCtx     *applyContext
+		notifier chan message.Msg
+		applier  *applier
+	}
+	newReplica := func(value string) *replica {
+		a, aCtx, engines, notifier := newTestApplier(t)
+		a.region.StartKey, a.region.EndKey = []byte("a"), []byte("m")
+		require.Nil(t, engine_util.PutCF(engines.Kv, engine_util.CfDefault, []byte("k1"), []byte("v1")))
+		require.Nil(t, engine_util.PutCF(engines.Kv, engine_util.CfWrite, []byte("k2"), []byte(value)))
+		return &replica{
+			engines:  engines,
+			aCtx:     aCtx,
+			notifier: notifier,
+			applier:  a,
+		}
+	}
+	replicas := []*replica{newReplica("v2"), newReplica("v2"), newReplica("v3")}
+	defer func() {
+		for _, r := range replicas {
+			r.engines.Destroy()
+		}
+	}()
+	// The data out of the region isn't a part of the hash.
+	require.Nil(t, engine_util.PutCF(replicas[1].engines.Kv, engine_util.CfDefault, []byte("x"), []byte("v")))
+
+	applyCh := make(chan []message.Msg, 10)
+	hashes := make([][]byte, 0, len(replicas))
+	for _, r := range replicas {
+		// The write before ComputeHash in the same batch is included in the hash.
+		entries := []eraftpb.Entry{
+			*NewEntryBuilder(6, 1).put(engine_util.CfDefault, []byte("k3"), []byte("v")).epoch(1, 3).build(applyCh, 3, 1, nil),
+			*NewEntryBuilder(7, 1).computeHash().epoch(1, 3).build(applyCh, 3, 1, nil),
+		}
+		<-applyCh
+		<-applyCh
+		r.applier.handleRaftCommittedEntries(r.aCtx, entries)
+		r.aCtx.flush()
+		fetchApplyRes(r.notifier)
+		state, err := meta.GetApplyState(r.engines.Kv, 1)
+		require.Nil(t, err)
+		require.Equal(t, uint64(7), state.HashIndex)
+		require.NotEmpty(t, state.Hash)
+		hashes = append(hashes, state.Hash)
+	}
+	require.Equal(t, hashes[0], hashes[1])
+	require.NotEqual(t, hashes[0], hashes[2])
+
+	// Every replica verifies the hash of the leader at the same index, the divergent one is detected.
+	for i, r := range replicas {
+		cb := message.NewCallback()
+		entry := NewEntryBuilder(8, 1).verifyHash(7, hashes[0]).epoch(1, 3).build(applyCh, 3, 1, cb)
+		for _, msg := range <-applyCh {
+			r.applier.handleTask(r.aCtx, msg)
+		}
+		r.applier.handleRaftCommittedEntries(r.aCtx, []eraftpb.Entry{*entry})
+		r.aCtx.flush()
+		fetchApplyRes(r.notifier)
+		resp := cb.WaitResp()
+		if i < 2 {
+			require.Nil(t, resp.GetHeader().GetError())
+		} else {
+			require.True(t, strings.Contains(resp.GetHeader().GetError().GetMessage(), "hash at index 7 mismatch"))
+		}
+		checkApplyIndex(t, r.engines, uint64(8))
+	}
+
+	// A hash of another index can't be compared.
+	cb := message.NewCallback()
+	entry := NewEntryBuilder(9, 1).verifyHash(6, hashes[0]).epoch(1, 3).build(applyCh, 3, 1, cb)
+	for _, msg := range <-applyCh {
+		replicas[2].applier.handleTask(replicas[2].aCtx, msg)
+	}
+	replicas[2].applier.handleRaftCommittedEntries(replicas[2].aCtx, []eraftpb.Entry{*entry})
+	replicas[2].aCtx.flush()
+	fetchApplyRes(replicas[2].notifier)
+	require.Nil(t, cb.WaitResp().GetHeader().GetError())
+}
+
+func TestSplitRegionPeers(t *testing.T) {
+	derived := &metapb.Region{
+		Id: 1,
+		Peers: []*metapb.Peer{
+			{Id: 11, StoreId: 3},
+			{Id: 12, StoreId: 1},
+			{Id: 13, StoreId: 2},
+		},
+	}
+	peers, err := splitRegionPeers(derived, []uint64{21, 22, 23})
+	require.Nil(t, err)
+	newRegion := &metapb.Region{Id: 2, Peers: peers}
+	require.Equal(t, len(derived.Peers), len(newRegion.Peers))
+	for i, peer := range derived.Peers {
+		// every new peer is co-located with its pre-split counterpart
+		newPeer := util.FindPeer(newRegion, peer.StoreId)
+		require.NotNil(t, newPeer)
+		require.Equal(t, uint64(21+i), newPeer.Id)
+	}
+
+	_, err = splitRegionPeers(derived, []uint64{21, 22})
+	require.NotNil(t, err)
+	_, err = splitRegionPeers(derived, []uint64{21, 22, 22})
+	require.NotNil(t, err)
+	_, err = splitRegionPeers(derived, []uint64{21, 22, 0})
+	require.NotNil(t, err)
+	// a new peer id must not be used by the derived region
+	_, err = splitRegionPeers(derived, []uint64{21, 22, 11})
+	require.NotNil(t, err)
+	derived.Peers[2].StoreId = 1
+	_, err = splitRegionPeers(derived, []uint64{21, 22, 23})
+	require.NotNil(t, err)
+}
+
 func fetchApplyRes(raftCh <-chan message.Msg) *MsgApplyRes {
 	select {
 	case msg := <-raftCh:
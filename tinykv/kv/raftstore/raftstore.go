@@ -2,6 +2,7 @@ package raftstore
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -45,12 +46,16 @@ type storeMeta struct {
 	/// `MsgRequestVote` messages from newly split Regions shouldn't be dropped if there is no
 	/// such Region in this store now. So the messages are recorded temporarily and will be handled later.
 	pendingVotes []*rspb.RaftMessage
+	/// region_id -> region of the removed peers whose data is kept, it will be
+	/// reclaimed by the removed peer data gc.
+	removedRegions map[uint64]*metapb.Region
 }
 
 func newStoreMeta() *storeMeta {
 	return &storeMeta{
-		regionRanges: btree.New(2),
-		regions:      map[uint64]*metapb.Region{},
+		regionRanges:   btree.New(2),
+		regions:        map[uint64]*metapb.Region{},
+		removedRegions: map[uint64]*metapb.Region{},
 	}
 }
 
@@ -85,6 +90,26 @@ func (m *storeMeta) getOverlapRegions(region *metapb.Region) []*metapb.Region {
 	return overlaps
 }
 
+// getUncoveredRanges returns the sub-ranges of the specified region range which aren't
+// covered by the regions on this store, the returned regions only have the id and the range.
+func (m *storeMeta) getUncoveredRanges(region *metapb.Region) []*metapb.Region {
+	var ranges []*metapb.Region
+	start := region.GetStartKey()
+	for _, over := range m.getOverlapRegions(region) {
+		if bytes.Compare(start, over.GetStartKey()) < 0 {
+			ranges = append(ranges, &metapb.Region{Id: region.Id, StartKey: start, EndKey: over.GetStartKey()})
+		}
+		if len(over.GetEndKey()) == 0 {
+			return ranges
+		}
+		start = over.GetEndKey()
+	}
+	if engine_util.ExceedEndKey(start, region.GetEndKey()) {
+		return ranges
+	}
+	return append(ranges, &metapb.Region{Id: region.Id, StartKey: start, EndKey: region.GetEndKey()})
+}
+
 type GlobalContext struct {
 	cfg                  *config.Config
 	engine               *engine_util.Engines
@@ -97,12 +122,17 @@ type GlobalContext struct {
 	regionTaskSender     chan<- worker.Task
 	raftLogGCTaskSender  chan<- worker.Task
 	splitCheckTaskSender chan<- worker.Task
+	splitLimiter         *splitLimiter
 	schedulerClient      scheduler_client.Client
 	tickDriverSender     chan uint64
 }
 
 type Transport interface {
-	Send(msg *rspb.RaftMessage) error
+	// Send sends the message to the store of its recipient. If the message can't be handed over
+	// before ctx is done, it's dropped and ctx.Err() is returned, it's never sent in that case.
+	// A message may still fail after it's handed over, e.g. the connection breaks, then the
+	// transport reports the recipient unreachable to the region by MsgTypeUnreachable.
+	Send(ctx context.Context, msg *rspb.RaftMessage) error
 }
 
 /// loadPeers loads peers in this store. It scans the db engine, loads all regions and their peers from it
@@ -117,6 +147,7 @@ func (bs *Raftstore) loadPeers() ([]*peer, error) {
 
 	var totalCount, tombStoneCount int
 	var regionPeers []*peer
+	var removedRegions []*metapb.Region
 
 	t := time.Now()
 	kvWB := new(engine_util.WriteBatch)
@@ -151,6 +182,9 @@ func (bs *Raftstore) loadPeers() ([]*peer, error) {
 			if localState.State == rspb.PeerState_Tombstone {
 				tombStoneCount++
 				bs.clearStaleMeta(kvWB, raftWB, localState)
+				if len(region.Peers) > 0 {
+					removedRegions = append(removedRegions, region)
+				}
 				continue
 			}
 
@@ -164,6 +198,18 @@ func (bs *Raftstore) loadPeers() ([]*peer, error) {
 			// in DB.
 			regionPeers = append(regionPeers, peer)
 		}
+		// The data may be kept when the peer was removed, let gc reclaim it even if the data
+		// isn't kept anymore since the restart. Skip the regions whose data out of the ranges
+		// owned by the live regions has been reclaimed already, so the same tombstones are not
+		// queued again on every restart.
+		for _, region := range removedRegions {
+			for _, uncovered := range ctx.storeMeta.getUncoveredRanges(region) {
+				if regionHasData(txn, uncovered) {
+					ctx.storeMeta.removedRegions[region.Id] = region
+					break
+				}
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -177,6 +223,20 @@ func (bs *Raftstore) loadPeers() ([]*peer, error) {
 	return regionPeers, nil
 }
 
+// regionHasData checks whether there is any data left in the range of the region.
+func regionHasData(txn *badger.Txn, region *metapb.Region) bool {
+	for _, cf := range engine_util.CFs {
+		it := engine_util.NewCFIterator(cf, txn)
+		it.Seek(region.StartKey)
+		exist := it.Valid() && !engine_util.ExceedEndKey(it.Item().Key(), region.EndKey)
+		it.Close()
+		if exist {
+			return true
+		}
+	}
+	return false
+}
+
 func (bs *Raftstore) clearStaleMeta(kvWB, raftWB *engine_util.WriteBatch, originState *rspb.RegionLocalState) {
 	region := originState.Region
 	raftState, err := meta.GetRaftLocalState(bs.ctx.engine.Raft, region.Id)
@@ -246,6 +306,7 @@ func (bs *Raftstore) start(
 		schedulerTaskSender:  bs.workers.schedulerWorker.Sender(),
 		regionTaskSender:     bs.workers.regionWorker.Sender(),
 		splitCheckTaskSender: bs.workers.splitCheckWorker.Sender(),
+		splitLimiter:         newSplitLimiter(cfg.SplitLimit, cfg.SplitLimitInterval),
 		raftLogGCTaskSender:  bs.workers.raftLogGCWorker.Sender(),
 		schedulerClient:      schedulerClient,
 		tickDriverSender:     bs.tickDriver.newRegionCh,
@@ -281,7 +342,7 @@ func (bs *Raftstore) startWorkers(peers []*peer) {
 	engines := ctx.engine
 	cfg := ctx.cfg
 	workers.splitCheckWorker.Start(runner.NewSplitCheckHandler(engines.Kv, NewRaftstoreRouter(router), cfg))
-	workers.regionWorker.Start(runner.NewRegionTaskHandler(engines, ctx.snapMgr))
+	workers.regionWorker.Start(runner.NewRegionTaskHandler(engines, ctx.snapMgr, cfg.ConcurrentSnapApplyLimit))
 	workers.raftLogGCWorker.Start(runner.NewRaftLogGCTaskHandler())
 	workers.schedulerWorker.Start(runner.NewSchedulerTaskHandler(ctx.store.Id, ctx.schedulerClient, NewRaftstoreRouter(router)))
 	go bs.tickDriver.run()
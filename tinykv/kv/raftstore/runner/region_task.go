@@ -3,6 +3,7 @@ package runner
 import (
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Connor1996/badger"
@@ -44,15 +45,30 @@ type RegionTaskDestroy struct {
 
 type regionTaskHandler struct {
 	ctx *snapContext
+	// applyLimiter bounds the number of snapshots applied at the same time, an apply
+	// task is queued in the worker until one of the running applies finishes.
+	applyLimiter chan struct{}
+	// applyWg waits for the running snapshot applies.
+	applyWg sync.WaitGroup
+	// applyFn applies the snapshot of the task, it is replaced in tests.
+	applyFn func(task *RegionTaskApply)
 }
 
-func NewRegionTaskHandler(engines *engine_util.Engines, mgr *snap.SnapManager) *regionTaskHandler {
-	return &regionTaskHandler{
+func NewRegionTaskHandler(engines *engine_util.Engines, mgr *snap.SnapManager, applyConcurrency int) *regionTaskHandler {
+	if applyConcurrency <= 0 {
+		applyConcurrency = 1
+	}
+	r := &regionTaskHandler{
 		ctx: &snapContext{
 			engines: engines,
 			mgr:     mgr,
 		},
+		applyLimiter: make(chan struct{}, applyConcurrency),
+	}
+	r.applyFn = func(task *RegionTaskApply) {
+		r.ctx.handleApply(task.RegionId, task.Notifier, task.StartKey, task.EndKey, task.SnapMeta)
 	}
+	return r
 }
 
 func (r *regionTaskHandler) Handle(t worker.Task) {
@@ -64,13 +80,28 @@ func (r *regionTaskHandler) Handle(t worker.Task) {
 		r.ctx.handleGen(task.RegionId, task.Notifier)
 	case *RegionTaskApply:
 		task := t.(*RegionTaskApply)
-		r.ctx.handleApply(task.RegionId, task.Notifier, task.StartKey, task.EndKey, task.SnapMeta)
+		r.applyLimiter <- struct{}{}
+		r.applyWg.Add(1)
+		go func() {
+			defer func() {
+				<-r.applyLimiter
+				r.applyWg.Done()
+			}()
+			r.applyFn(task)
+		}()
 	case *RegionTaskDestroy:
 		task := t.(*RegionTaskDestroy)
+		// The range may overlap with the range of a snapshot being applied.
+		r.applyWg.Wait()
 		r.ctx.cleanUpRange(task.RegionId, task.StartKey, task.EndKey)
 	}
 }
 
+// Stop waits for the running snapshot applies.
+func (r *regionTaskHandler) Stop() {
+	r.applyWg.Wait()
+}
+
 type snapContext struct {
 	engines   *engine_util.Engines
 	batchSize uint64
@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"io"
 	"io/ioutil"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/Connor1996/badger"
@@ -276,3 +278,109 @@ func TestSplitCheck(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, codec.EncodeBytes([]byte("k2")), split.SplitKey)
 }
+
+func TestSplitCheckScanBatch(t *testing.T) {
+	engines := util.NewTestEngines()
+	defer cleanUpTestEngineData(engines)
+	db := engines.Kv
+	taskResCh := make(chan message.Msg, 1)
+
+	kvWb := new(engine_util.WriteBatch)
+	// the length of each kv pair is 22
+	kvWb.SetCF(engine_util.CfDefault, encodeKey([]byte("k1"), 1), []byte("entry"))
+	kvWb.SetCF(engine_util.CfDefault, encodeKey([]byte("k1"), 2), []byte("entry"))
+	kvWb.SetCF(engine_util.CfDefault, encodeKey([]byte("k2"), 1), []byte("entry"))
+	kvWb.SetCF(engine_util.CfDefault, encodeKey([]byte("k2"), 2), []byte("entry"))
+	kvWb.SetCF(engine_util.CfDefault, encodeKey([]byte("k3"), 3), []byte("entry"))
+	kvWb.MustWriteToDB(db)
+
+	yields := 0
+	runner := &splitCheckHandler{
+		engine:        db,
+		router:        &TaskResRouter{ch: taskResCh},
+		checker:       newSizeSplitChecker(100, 50),
+		scanBatchSize: 2,
+		yield:         func() { yields++ },
+	}
+
+	// The scan stops at the 5th key as the size exceeds the max size, it's done in 3 batches.
+	runner.Handle(&SplitCheckTask{Region: &metapb.Region{Id: 1}})
+	msg := <-taskResCh
+	split, ok := msg.Data.(*message.MsgSplitRegion)
+	require.True(t, ok)
+	assert.Equal(t, codec.EncodeBytes([]byte("k2")), split.SplitKey)
+	assert.Equal(t, 2, yields)
+
+	// The size of the keys before the end key is reported, the end key is checked before
+	// the batch size, so the batch which reaches the end key finishes the scan without
+	// yielding again.
+	yields = 0
+	runner.checker = newSizeSplitChecker(1000, 50)
+	runner.Handle(&SplitCheckTask{Region: &metapb.Region{Id: 1, EndKey: codec.EncodeBytes([]byte("k3"))}})
+	msg = <-taskResCh
+	assert.Equal(t, message.MsgTypeRegionApproximateSize, msg.Type)
+	assert.Equal(t, uint64(88), msg.Data)
+	assert.Equal(t, 1, yields)
+	select {
+	case msg := <-taskResCh:
+		t.Fatalf("unexpected msg %v", msg)
+	default:
+	}
+
+	// Scanning in one batch gets the same result without yielding.
+	yields = 0
+	runner.scanBatchSize = 0
+	runner.checker = newSizeSplitChecker(100, 50)
+	runner.Handle(&SplitCheckTask{Region: &metapb.Region{Id: 1}})
+	msg = <-taskResCh
+	assert.Equal(t, codec.EncodeBytes([]byte("k2")), msg.Data.(*message.MsgSplitRegion).SplitKey)
+	assert.Equal(t, 0, yields)
+}
+
+func TestRegionTaskApplyConcurrency(t *testing.T) {
+	for _, limit := range []int{1, 2} {
+		runner := NewRegionTaskHandler(nil, nil, limit)
+		var running, maxRunning int32
+		started := make(chan struct{}, 5)
+		release := make(chan struct{})
+		runner.applyFn = func(task *RegionTaskApply) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			started <- struct{}{}
+			<-release
+			atomic.AddInt32(&running, -1)
+			task.Notifier <- true
+		}
+
+		notifier := make(chan bool, 5)
+		wg := new(sync.WaitGroup)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				runner.Handle(&RegionTaskApply{RegionId: uint64(i + 1), Notifier: notifier})
+			}
+		}()
+		// the applies beyond the limit are queued until a running one finishes
+		for i := 0; i < limit; i++ {
+			<-started
+		}
+		require.Equal(t, int32(limit), atomic.LoadInt32(&running))
+		for i := 0; i < 5; i++ {
+			release <- struct{}{}
+			require.True(t, <-notifier)
+			if i+limit < 5 {
+				<-started
+			}
+		}
+		wg.Wait()
+		runner.Stop()
+		require.Equal(t, int32(limit), atomic.LoadInt32(&maxRunning))
+		require.Equal(t, int32(0), atomic.LoadInt32(&running))
+	}
+}
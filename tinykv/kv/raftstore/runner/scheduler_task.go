@@ -28,6 +28,10 @@ type SchedulerRegionHeartbeatTask struct {
 	Peer            *metapb.Peer
 	PendingPeers    []*metapb.Peer
 	ApproximateSize *uint64
+	WriteStall      bool
+	Term            uint64
+	CommitIndex     uint64
+	AppliedIndex    uint64
 }
 
 type SchedulerStoreHeartbeatTask struct {
@@ -115,6 +119,10 @@ func (r *SchedulerTaskHandler) onHeartbeat(t *SchedulerRegionHeartbeatTask) {
 		Leader:          t.Peer,
 		PendingPeers:    t.PendingPeers,
 		ApproximateSize: uint64(size),
+		WriteStall:      t.WriteStall,
+		Term:            t.Term,
+		CommitIndex:     t.CommitIndex,
+		AppliedIndex:    t.AppliedIndex,
 	}
 	r.SchedulerClient.RegionHeartbeat(req)
 }
@@ -3,6 +3,7 @@ package runner
 import (
 	"encoding/hex"
 	"fmt"
+	"runtime"
 
 	"github.com/Connor1996/badger"
 	"github.com/pingcap-incubator/tinykv/kv/config"
@@ -23,13 +24,19 @@ type splitCheckHandler struct {
 	engine  *badger.DB
 	router  message.RaftRouter
 	checker *sizeSplitChecker
+	// the max number of keys scanned with one snapshot, zero means no limit.
+	scanBatchSize int
+	// yield is called between the scan batches.
+	yield func()
 }
 
 func NewSplitCheckHandler(engine *badger.DB, router message.RaftRouter, conf *config.Config) *splitCheckHandler {
 	runner := &splitCheckHandler{
-		engine:  engine,
-		router:  router,
-		checker: newSizeSplitChecker(conf.RegionMaxSize, conf.RegionSplitSize),
+		engine:        engine,
+		router:        router,
+		checker:       newSizeSplitChecker(conf.RegionMaxSize, conf.RegionSplitSize),
+		scanBatchSize: conf.SplitCheckScanBatchSize,
+		yield:         runtime.Gosched,
 	}
 	return runner
 }
@@ -72,13 +79,26 @@ func (r *splitCheckHandler) Handle(t worker.Task) {
 
 /// SplitCheck gets the split keys by scanning the range.
 func (r *splitCheckHandler) splitCheck(regionID uint64, startKey, endKey []byte) []byte {
+	r.checker.reset()
+	for seekKey := r.scanBatch(regionID, startKey, endKey); seekKey != nil; {
+		if r.yield != nil {
+			r.yield()
+		}
+		seekKey = r.scanBatch(regionID, seekKey, endKey)
+	}
+	return r.checker.getSplitKey()
+}
+
+/// scanBatch scans at most scanBatchSize keys from seekKey with a new snapshot of the
+/// engine. It returns the key to continue the scan from, or nil if the scan is finished.
+func (r *splitCheckHandler) scanBatch(regionID uint64, seekKey, endKey []byte) []byte {
 	txn := r.engine.NewTransaction(false)
 	defer txn.Discard()
 
-	r.checker.reset()
 	it := engine_util.NewCFIterator(engine_util.CfDefault, txn)
 	defer it.Close()
-	for it.Seek(startKey); it.Valid(); it.Next() {
+	scanned := 0
+	for it.Seek(seekKey); it.Valid(); it.Next() {
 		item := it.Item()
 		key := item.Key()
 		if engine_util.ExceedEndKey(key, endKey) {
@@ -87,13 +107,17 @@ func (r *splitCheckHandler) splitCheck(regionID uint64, startKey, endKey []byte)
 				Type: message.MsgTypeRegionApproximateSize,
 				Data: r.checker.currentSize,
 			})
-			break
+			return nil
+		}
+		if r.scanBatchSize > 0 && scanned == r.scanBatchSize {
+			return util.SafeCopy(key)
 		}
 		if r.checker.onKv(key, item) {
-			break
+			return nil
 		}
+		scanned++
 	}
-	return r.checker.getSplitKey()
+	return nil
 }
 
 type sizeSplitChecker struct {
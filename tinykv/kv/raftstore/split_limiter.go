@@ -0,0 +1,73 @@
+package raftstore
+
+import (
+	"time"
+
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/runner"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/util"
+	"github.com/pingcap-incubator/tinykv/kv/util/worker"
+)
+
+// splitLimiter limits the number of splits the store asks the scheduler for in every interval,
+// so a burst of split checks doesn't overwhelm the scheduler. The splits over the limit are
+// queued and asked in the following intervals. It's only accessed by the raft worker.
+type splitLimiter struct {
+	limit    int
+	interval time.Duration
+
+	windowStart time.Time
+	asked       int
+	pending     []*runner.SchedulerAskSplitTask
+}
+
+func newSplitLimiter(limit int, interval time.Duration) *splitLimiter {
+	return &splitLimiter{
+		limit:    limit,
+		interval: interval,
+	}
+}
+
+// ask sends the split task to the scheduler if the limit of the current interval allows,
+// otherwise the task is queued. A queued task of the same region is replaced, since the
+// region has been checked again and its split key is outdated.
+func (l *splitLimiter) ask(task *runner.SchedulerAskSplitTask, now time.Time, sender chan<- worker.Task) {
+	l.flush(now, sender)
+	if len(l.pending) == 0 && l.allow(now) {
+		sender <- task
+		return
+	}
+	for i, p := range l.pending {
+		if p.Region.Id == task.Region.Id {
+			p.Callback.Done(ErrResp(&util.ErrStaleCommand{}))
+			l.pending[i] = task
+			return
+		}
+	}
+	l.pending = append(l.pending, task)
+}
+
+// flush sends the queued tasks in order as long as the limit of the current interval allows.
+func (l *splitLimiter) flush(now time.Time, sender chan<- worker.Task) {
+	sent := 0
+	for sent < len(l.pending) && l.allow(now) {
+		sender <- l.pending[sent]
+		l.pending[sent] = nil
+		sent++
+	}
+	l.pending = l.pending[sent:]
+}
+
+func (l *splitLimiter) allow(now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	if now.Sub(l.windowStart) >= l.interval {
+		l.windowStart = now
+		l.asked = 0
+	}
+	if l.asked >= l.limit {
+		return false
+	}
+	l.asked++
+	return true
+}
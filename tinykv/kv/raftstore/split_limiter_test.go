@@ -0,0 +1,70 @@
+package raftstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/runner"
+	"github.com/pingcap-incubator/tinykv/kv/util/worker"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+)
+
+func askedSplitRegions(sender chan worker.Task) []uint64 {
+	var regions []uint64
+	for len(sender) > 0 {
+		regions = append(regions, (<-sender).(*runner.SchedulerAskSplitTask).Region.Id)
+	}
+	return regions
+}
+
+func TestSplitLimiter(t *testing.T) {
+	sender := make(chan worker.Task, 100)
+	limiter := newSplitLimiter(2, time.Second)
+	newTask := func(regionID uint64, cb *message.Callback) *runner.SchedulerAskSplitTask {
+		return &runner.SchedulerAskSplitTask{Region: &metapb.Region{Id: regionID}, Callback: cb}
+	}
+
+	// Only 2 of the 10 split triggers in the interval are asked, the others are queued in order.
+	now := time.Now()
+	staleCb := message.NewCallback()
+	for i := uint64(1); i <= 10; i++ {
+		var cb *message.Callback
+		if i == 5 {
+			cb = staleCb
+		}
+		limiter.ask(newTask(i, cb), now.Add(time.Duration(i)*time.Millisecond), sender)
+	}
+	require.Equal(t, []uint64{1, 2}, askedSplitRegions(sender))
+	require.Len(t, limiter.pending, 8)
+
+	// Checking a queued region again replaces its queued split.
+	limiter.ask(newTask(5, nil), now.Add(20*time.Millisecond), sender)
+	require.NotNil(t, staleCb.WaitResp().GetHeader().GetError().GetStaleCommand())
+	require.Len(t, limiter.pending, 8)
+
+	// Nothing more is asked in the same interval.
+	limiter.flush(now.Add(500*time.Millisecond), sender)
+	require.Empty(t, askedSplitRegions(sender))
+
+	// The queued splits are asked at the configured rate in the following intervals, before the
+	// new ones.
+	limiter.flush(now.Add(1100*time.Millisecond), sender)
+	require.Equal(t, []uint64{3, 4}, askedSplitRegions(sender))
+	limiter.ask(newTask(11, nil), now.Add(2200*time.Millisecond), sender)
+	require.Equal(t, []uint64{5, 6}, askedSplitRegions(sender))
+	limiter.flush(now.Add(3300*time.Millisecond), sender)
+	limiter.flush(now.Add(4400*time.Millisecond), sender)
+	require.Equal(t, []uint64{7, 8, 9, 10}, askedSplitRegions(sender))
+	limiter.flush(now.Add(5500*time.Millisecond), sender)
+	require.Equal(t, []uint64{11}, askedSplitRegions(sender))
+	require.Empty(t, limiter.pending)
+
+	// No limit.
+	limiter = newSplitLimiter(0, time.Second)
+	for i := uint64(1); i <= 10; i++ {
+		limiter.ask(newTask(i, nil), now, sender)
+	}
+	require.Len(t, askedSplitRegions(sender), 10)
+}
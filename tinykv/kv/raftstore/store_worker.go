@@ -24,6 +24,7 @@ type StoreTick int
 const (
 	StoreTickSchedulerStoreHeartbeat StoreTick = 1
 	StoreTickSnapGC                  StoreTick = 2
+	StoreTickRemovedPeerDataGC       StoreTick = 3
 )
 
 type storeState struct {
@@ -73,6 +74,8 @@ func (d *storeWorker) onTick(tick StoreTick) {
 		d.onSchedulerStoreHearbeatTick()
 	case StoreTickSnapGC:
 		d.onSnapMgrGC()
+	case StoreTickRemovedPeerDataGC:
+		d.onRemovedPeerDataGC()
 	}
 }
 
@@ -93,6 +96,7 @@ func (d *storeWorker) start(store *metapb.Store) {
 	d.id = store.Id
 	d.ticker.scheduleStore(StoreTickSchedulerStoreHeartbeat)
 	d.ticker.scheduleStore(StoreTickSnapGC)
+	d.ticker.scheduleStore(StoreTickRemovedPeerDataGC)
 }
 
 /// Checks if the message is targeting a stale peer.
@@ -315,3 +319,40 @@ func (d *storeWorker) onSnapMgrGC() {
 	}
 	d.ticker.scheduleStore(StoreTickSnapGC)
 }
+
+/// Reclaims the data kept for removed peers. If the range of a removed region
+/// is overlapped by a region on this store now, the data in the overlapped part
+/// has been replaced by the new region and must not be deleted, only the rest
+/// of the range is reclaimed.
+func (d *storeWorker) handleRemovedPeerDataGC() {
+	var tasks []*runner.RegionTaskDestroy
+	meta := d.ctx.storeMeta
+	meta.Lock()
+	for regionID, region := range meta.removedRegions {
+		delete(meta.removedRegions, regionID)
+		uncovered := meta.getUncoveredRanges(region)
+		if len(uncovered) == 0 {
+			log.Info(fmt.Sprintf("skip reclaiming data of removed region %v covered by the regions on the store", region))
+			continue
+		}
+		log.Info(fmt.Sprintf("reclaim data of removed region %v in ranges %v", region, uncovered))
+		for _, r := range uncovered {
+			tasks = append(tasks, &runner.RegionTaskDestroy{
+				RegionId: regionID,
+				StartKey: r.GetStartKey(),
+				EndKey:   r.GetEndKey(),
+			})
+		}
+	}
+	meta.Unlock()
+
+	// The region worker may be busy, don't block the others waiting for the meta.
+	for _, task := range tasks {
+		d.ctx.regionTaskSender <- task
+	}
+}
+
+func (d *storeWorker) onRemovedPeerDataGC() {
+	d.handleRemovedPeerDataGC()
+	d.ticker.scheduleStore(StoreTickRemovedPeerDataGC)
+}
@@ -40,6 +40,7 @@ func newStoreTicker(cfg *config.Config) *ticker {
 	}
 	t.schedules[int(StoreTickSchedulerStoreHeartbeat)].interval = int64(cfg.SchedulerStoreHeartbeatTickInterval / baseInterval)
 	t.schedules[int(StoreTickSnapGC)].interval = int64(SnapMgrGcTickInterval / baseInterval)
+	t.schedules[int(StoreTickRemovedPeerDataGC)].interval = int64(cfg.RemovedPeerDataGCTickInterval / baseInterval)
 	return t
 }
 
@@ -34,6 +34,16 @@ func (e *ErrKeyNotInRegion) Error() string {
 	return fmt.Sprintf("key %v is not in region %v", e.Key, e.Region)
 }
 
+type ErrMalformedKey struct {
+	Key    []byte
+	Cf     string
+	Reason string
+}
+
+func (e *ErrMalformedKey) Error() string {
+	return fmt.Sprintf("malformed key %v in cf %v: %v", e.Key, e.Cf, e.Reason)
+}
+
 type ErrEpochNotMatch struct {
 	Message string
 	Regions []*metapb.Region
@@ -58,6 +68,17 @@ func (e *ErrStoreNotMatch) Error() string {
 	return fmt.Sprintf("store not match, request store id is %v, but actual store id is %v", e.RequestStoreId, e.ActualStoreId)
 }
 
+type ErrHashMismatch struct {
+	RegionId uint64
+	Index    uint64
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *ErrHashMismatch) Error() string {
+	return fmt.Sprintf("region %v hash at index %v mismatch, expected %x, but actual %x", e.RegionId, e.Index, e.Expected, e.Actual)
+}
+
 func RaftstoreErrToPbError(e error) *errorpb.Error {
 	ret := new(errorpb.Error)
 	switch err := errors.Cause(e).(type) {
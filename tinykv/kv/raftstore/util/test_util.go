@@ -7,6 +7,10 @@ import (
 	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
 )
 
+// testCacheSize is the block cache size of the test engines. Closing a badger DB doesn't release
+// its block cache, so the default 1GB one adds up when a test package opens many engines.
+const testCacheSize = 16 << 20
+
 func NewTestEngines() *engine_util.Engines {
 	engines := new(engine_util.Engines)
 	var err error
@@ -18,6 +22,7 @@ func NewTestEngines() *engine_util.Engines {
 	kvOpts.Dir = engines.KvPath
 	kvOpts.ValueDir = engines.KvPath
 	kvOpts.ValueThreshold = 256
+	kvOpts.MaxCacheSize = testCacheSize
 	engines.Kv, err = badger.Open(kvOpts)
 	if err != nil {
 		panic("open kv db failed")
@@ -30,6 +35,7 @@ func NewTestEngines() *engine_util.Engines {
 	raftOpts.Dir = engines.RaftPath
 	raftOpts.ValueDir = engines.RaftPath
 	raftOpts.ValueThreshold = 256
+	raftOpts.MaxCacheSize = testCacheSize
 	engines.Raft, err = badger.Open(raftOpts)
 	if err != nil {
 		panic("open raft db failed")
@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore/meta"
+	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
@@ -58,6 +59,23 @@ func CheckKeyInRegionInclusive(key []byte, region *metapb.Region) error {
 	}
 }
 
+/// Check if key is well-formed to be written to the column family `cf`.
+/// Empty keys, keys in the local key space and unknown column families are rejected.
+func CheckKeyEncoding(key []byte, cf string) error {
+	if len(key) == 0 {
+		return &ErrMalformedKey{Key: key, Cf: cf, Reason: "empty key"}
+	}
+	if key[0] == meta.LocalPrefix {
+		return &ErrMalformedKey{Key: key, Cf: cf, Reason: "key in local key space"}
+	}
+	for _, c := range engine_util.CFs {
+		if c == cf {
+			return nil
+		}
+	}
+	return &ErrMalformedKey{Key: key, Cf: cf, Reason: "unknown column family"}
+}
+
 /// check whether epoch is staler than check_epoch.
 func IsEpochStale(epoch *metapb.RegionEpoch, checkEpoch *metapb.RegionEpoch) bool {
 	return epoch.Version < checkEpoch.Version || epoch.ConfVer < checkEpoch.ConfVer
@@ -82,7 +100,8 @@ func CheckRegionEpoch(req *raft_cmdpb.RaftCmdRequest, region *metapb.Region, inc
 		checkVer = true
 	} else {
 		switch req.AdminRequest.CmdType {
-		case raft_cmdpb.AdminCmdType_CompactLog, raft_cmdpb.AdminCmdType_InvalidAdmin:
+		case raft_cmdpb.AdminCmdType_CompactLog, raft_cmdpb.AdminCmdType_InvalidAdmin,
+			raft_cmdpb.AdminCmdType_ComputeHash, raft_cmdpb.AdminCmdType_VerifyHash:
 		case raft_cmdpb.AdminCmdType_ChangePeer:
 			checkConfVer = true
 		case raft_cmdpb.AdminCmdType_Split, raft_cmdpb.AdminCmdType_TransferLeader:
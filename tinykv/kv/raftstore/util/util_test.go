@@ -3,6 +3,8 @@ package util
 import (
 	"testing"
 
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/meta"
+	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
@@ -43,6 +45,32 @@ func TestCheckKeyInRegion(t *testing.T) {
 	}
 }
 
+func TestCheckKeyEncoding(t *testing.T) {
+	type Case struct {
+		Key   []byte
+		Cf    string
+		Valid bool
+	}
+	test_cases := []Case{
+		{Key: []byte("k1"), Cf: engine_util.CfDefault, Valid: true},
+		{Key: []byte("k1"), Cf: engine_util.CfWrite, Valid: true},
+		{Key: []byte("k1"), Cf: engine_util.CfLock, Valid: true},
+		{Key: []byte{}, Cf: engine_util.CfDefault, Valid: false},
+		{Key: nil, Cf: engine_util.CfDefault, Valid: false},
+		{Key: []byte{meta.LocalPrefix, 0x02}, Cf: engine_util.CfDefault, Valid: false},
+		{Key: []byte("k1"), Cf: "raft", Valid: false},
+		{Key: []byte("k1"), Cf: "", Valid: false},
+	}
+	for _, c := range test_cases {
+		err := CheckKeyEncoding(c.Key, c.Cf)
+		assert.Equal(t, c.Valid, err == nil)
+		if err != nil {
+			_, ok := err.(*ErrMalformedKey)
+			assert.True(t, ok)
+		}
+	}
+}
+
 func TestIsInitialMsg(t *testing.T) {
 	type MsgInfo struct {
 		MessageType  eraftpb.MessageType
@@ -2,10 +2,13 @@ package raft_storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/pingcap-incubator/tinykv/kv/config"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/tinykvpb"
 	"github.com/pingcap/log"
@@ -13,14 +16,23 @@ import (
 	"google.golang.org/grpc/keepalive"
 )
 
+// raftConnQueueSize is the max number of messages waiting to be sent by a connection.
+const raftConnQueueSize = 1024
+
+var errRaftConnClosed = errors.New("raft connection is closed")
+
+// raftConn sends the messages queued by Send through the stream in its own goroutine,
+// so the senders never block on the stream.
 type raftConn struct {
-	streamMu sync.Mutex
-	stream   tinykvpb.TinyKv_RaftClient
-	ctx      context.Context
-	cancel   context.CancelFunc
+	stream tinykvpb.TinyKv_RaftClient
+	msgCh  chan *raft_serverpb.RaftMessage
+	ctx    context.Context
+	cancel context.CancelFunc
+	// onFailure is called with the messages dropped when the stream fails.
+	onFailure func(msg *raft_serverpb.RaftMessage)
 }
 
-func newRaftConn(addr string, cfg *config.Config) (*raftConn, error) {
+func newRaftConn(addr string, cfg *config.Config, onFailure func(msg *raft_serverpb.RaftMessage)) (*raftConn, error) {
 	cc, err := grpc.Dial(addr, grpc.WithInsecure(),
 		grpc.WithInitialWindowSize(2*1024*1024),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
@@ -37,40 +49,105 @@ func newRaftConn(addr string, cfg *config.Config) (*raftConn, error) {
 		cancel()
 		return nil, err
 	}
-	return &raftConn{
-		stream: stream,
-		ctx:    ctx,
-		cancel: cancel,
-	}, nil
+	conn := &raftConn{
+		stream:    stream,
+		msgCh:     make(chan *raft_serverpb.RaftMessage, raftConnQueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		onFailure: onFailure,
+	}
+	go conn.run()
+	return conn, nil
+}
+
+func (c *raftConn) run() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case msg := <-c.msgCh:
+			if err := c.stream.Send(msg); err != nil {
+				log.Error(fmt.Sprintf("raft connection failed to send. err: %v", err))
+				// The queued messages are dropped, raft resends them if necessary.
+				c.cancel()
+				c.dropQueued(msg)
+				return
+			}
+		}
+	}
+}
+
+// dropQueued drops the failed message and the queued ones, their recipients are reported
+// by onFailure, once for each of them.
+func (c *raftConn) dropQueued(failed *raft_serverpb.RaftMessage) {
+	reported := make(map[uint64]struct{})
+	report := func(msg *raft_serverpb.RaftMessage) {
+		toPeerID := msg.GetToPeer().GetId()
+		if _, ok := reported[toPeerID]; ok {
+			return
+		}
+		reported[toPeerID] = struct{}{}
+		if c.onFailure != nil {
+			c.onFailure(msg)
+		}
+	}
+	report(failed)
+	for {
+		select {
+		case msg := <-c.msgCh:
+			report(msg)
+		default:
+			return
+		}
+	}
 }
 
 func (c *raftConn) Stop() {
 	c.cancel()
 }
 
-func (c *raftConn) Send(msg *raft_serverpb.RaftMessage) error {
-	c.streamMu.Lock()
-	defer c.streamMu.Unlock()
-	return c.stream.Send(msg)
+// Send queues the message to be sent. If the queue is still full when ctx is done, the
+// message is dropped and ctx.Err() is returned, the message is never sent in that case.
+func (c *raftConn) Send(ctx context.Context, msg *raft_serverpb.RaftMessage) error {
+	select {
+	case <-c.ctx.Done():
+		return errRaftConnClosed
+	default:
+	}
+	select {
+	case c.msgCh <- msg:
+		return nil
+	case <-c.ctx.Done():
+		return errRaftConnClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // RaftClient is used to do the real work building and sending messages to other
 // raft peers. It's wrapped by the ServerTransport.
 type RaftClient struct {
 	config *config.Config
+	// router receives the reports of the peers which are unreachable by the connections.
+	router message.RaftRouter
 	sync.RWMutex
 	conns map[string]*raftConn
 	addrs map[uint64]string
 }
 
-func newRaftClient(config *config.Config) *RaftClient {
+func newRaftClient(config *config.Config, router message.RaftRouter) *RaftClient {
 	return &RaftClient{
 		config: config,
+		router: router,
 		conns:  make(map[string]*raftConn),
 		addrs:  make(map[uint64]string),
 	}
 }
 
+func (c *RaftClient) reportUnreachable(msg *raft_serverpb.RaftMessage) {
+	reportUnreachable(c.router, msg)
+}
+
 func (c *RaftClient) getConn(addr string, regionID uint64) (*raftConn, error) {
 	c.RLock()
 	conn, ok := c.conns[addr]
@@ -79,7 +156,7 @@ func (c *RaftClient) getConn(addr string, regionID uint64) (*raftConn, error) {
 		return conn, nil
 	}
 	c.RUnlock()
-	newConn, err := newRaftConn(addr, c.config)
+	newConn, err := newRaftConn(addr, c.config, c.reportUnreachable)
 	if err != nil {
 		return nil, err
 	}
@@ -93,17 +170,20 @@ func (c *RaftClient) getConn(addr string, regionID uint64) (*raftConn, error) {
 	return newConn, nil
 }
 
-func (c *RaftClient) Send(storeID uint64, addr string, msg *raft_serverpb.RaftMessage) error {
+func (c *RaftClient) Send(ctx context.Context, storeID uint64, addr string, msg *raft_serverpb.RaftMessage) error {
 	conn, err := c.getConn(addr, msg.GetRegionId())
 	if err != nil {
+		c.reportUnreachable(msg)
 		return err
 	}
-	err = conn.Send(msg)
-	if err == nil {
-		return nil
+	err = conn.Send(ctx, msg)
+	if err == nil || err == ctx.Err() {
+		// The connection is kept if the message is only dropped for ctx.
+		return err
 	}
 
 	log.Error("raft client failed to send")
+	c.reportUnreachable(msg)
 	c.Lock()
 	defer c.Unlock()
 	conn.Stop()
@@ -0,0 +1,115 @@
+package raft_storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/tinykvpb"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingStream blocks sending until unblocked, then it passes the messages to sent.
+type blockingStream struct {
+	tinykvpb.TinyKv_RaftClient
+	unblock chan struct{}
+	sent    chan *raft_serverpb.RaftMessage
+}
+
+func (s *blockingStream) Send(msg *raft_serverpb.RaftMessage) error {
+	<-s.unblock
+	s.sent <- msg
+	return nil
+}
+
+// failingStream fails sending once unblocked.
+type failingStream struct {
+	tinykvpb.TinyKv_RaftClient
+	unblock chan struct{}
+}
+
+func (s *failingStream) Send(msg *raft_serverpb.RaftMessage) error {
+	<-s.unblock
+	return errors.New("stream is broken")
+}
+
+func TestRaftConnReportFailure(t *testing.T) {
+	stream := &failingStream{unblock: make(chan struct{})}
+	reported := make(chan uint64, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &raftConn{
+		stream: stream,
+		msgCh:  make(chan *raft_serverpb.RaftMessage, 3),
+		ctx:    ctx,
+		cancel: cancel,
+		onFailure: func(msg *raft_serverpb.RaftMessage) {
+			reported <- msg.GetToPeer().GetId()
+		},
+	}
+	go conn.run()
+	defer conn.Stop()
+
+	// The messages are handed over before the stream fails.
+	for _, id := range []uint64{2, 3, 2, 3} {
+		require.Nil(t, conn.Send(context.Background(), &raft_serverpb.RaftMessage{ToPeer: &metapb.Peer{Id: id}}))
+	}
+	close(stream.unblock)
+
+	// The recipients of the dropped messages are reported once for each of them.
+	ids := make(map[uint64]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-reported:
+			ids[id] = true
+		case <-time.After(time.Second):
+			t.Fatalf("the recipients are not reported")
+		}
+	}
+	require.Equal(t, map[uint64]bool{2: true, 3: true}, ids)
+	select {
+	case id := <-reported:
+		t.Fatalf("peer %d is reported again", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Equal(t, errRaftConnClosed, conn.Send(context.Background(), &raft_serverpb.RaftMessage{RegionId: 1}))
+}
+
+func TestRaftConnSendDeadline(t *testing.T) {
+	stream := &blockingStream{
+		unblock: make(chan struct{}),
+		sent:    make(chan *raft_serverpb.RaftMessage, 3),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &raftConn{
+		stream: stream,
+		msgCh:  make(chan *raft_serverpb.RaftMessage, 1),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go conn.run()
+	defer conn.Stop()
+
+	// The first message is taken by the blocked stream and the second one fills the queue.
+	require.Nil(t, conn.Send(context.Background(), &raft_serverpb.RaftMessage{RegionId: 1}))
+	require.Nil(t, conn.Send(context.Background(), &raft_serverpb.RaftMessage{RegionId: 2}))
+	// The third message is dropped at the deadline without waiting for the stream.
+	sendCtx, sendCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer sendCancel()
+	require.Equal(t, context.DeadlineExceeded, conn.Send(sendCtx, &raft_serverpb.RaftMessage{RegionId: 3}))
+
+	close(stream.unblock)
+	require.Equal(t, uint64(1), (<-stream.sent).RegionId)
+	require.Equal(t, uint64(2), (<-stream.sent).RegionId)
+	// The dropped message never arrives.
+	select {
+	case msg := <-stream.sent:
+		t.Fatalf("the dropped message %v is sent", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	conn.Stop()
+	require.Equal(t, errRaftConnClosed, conn.Send(context.Background(), &raft_serverpb.RaftMessage{RegionId: 4}))
+}
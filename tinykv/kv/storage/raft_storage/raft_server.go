@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/Connor1996/badger"
 	"github.com/pingcap-incubator/tinykv/kv/config"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
@@ -16,6 +17,7 @@ import (
 	"github.com/pingcap-incubator/tinykv/kv/util/worker"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/errorpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/kvrpcpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/tinykvpb"
 	"github.com/pingcap/errors"
@@ -46,7 +48,7 @@ func (re *RegionError) Error() string {
 	return re.RequestErr.String()
 }
 
-func (rs *RaftStorage) checkResponse(resp *raft_cmdpb.RaftCmdResponse, reqCount int) error {
+func checkResponse(resp *raft_cmdpb.RaftCmdResponse, reqCount int) error {
 	if resp.Header.Error != nil {
 		return &RegionError{RequestErr: resp.Header.Error}
 	}
@@ -120,7 +122,7 @@ func (rs *RaftStorage) Write(ctx *kvrpcpb.Context, batch []storage.Modify) error
 		return err
 	}
 
-	return rs.checkResponse(cb.WaitResp(), len(reqs))
+	return checkResponse(cb.WaitResp(), len(reqs))
 }
 
 // Reader is main entrance to get a snapshot of current state machine for read. Only
@@ -129,26 +131,13 @@ func (rs *RaftStorage) Write(ctx *kvrpcpb.Context, batch []storage.Modify) error
 // better ways to reduce the cost of read request processing, more information about
 // this could be found in the raft paper 6.4.
 func (rs *RaftStorage) Reader(ctx *kvrpcpb.Context) (storage.StorageReader, error) {
-	header := &raft_cmdpb.RaftRequestHeader{
-		RegionId:    ctx.RegionId,
-		Peer:        ctx.Peer,
-		RegionEpoch: ctx.RegionEpoch,
-		Term:        ctx.Term,
-	}
-	request := &raft_cmdpb.RaftCmdRequest{
-		Header: header,
-		Requests: []*raft_cmdpb.Request{{
-			CmdType: raft_cmdpb.CmdType_Snap,
-			Snap:    &raft_cmdpb.SnapRequest{},
-		}},
-	}
 	cb := message.NewCallback()
-	if err := rs.raftRouter.SendRaftCommand(request, cb); err != nil {
+	if err := rs.raftRouter.SendRaftCommand(newSnapRequest(ctx), cb); err != nil {
 		return nil, err
 	}
 
 	resp := cb.WaitResp()
-	if err := rs.checkResponse(resp, 1); err != nil {
+	if err := checkResponse(resp, 1); err != nil {
 		if cb.Txn != nil {
 			cb.Txn.Discard()
 		}
@@ -163,6 +152,67 @@ func (rs *RaftStorage) Reader(ctx *kvrpcpb.Context) (storage.StorageReader, erro
 	return NewRegionReader(cb.Txn, *resp.Responses[0].GetSnap().Region), nil
 }
 
+// ConsistentReader gets a snapshot of several regions at a single consistent point, while reading
+// every region by Reader may see each of them at an independent point. It's used by the transactions
+// reading across the regions whose leaders are on this store.
+func (rs *RaftStorage) ConsistentReader(ctxs []*kvrpcpb.Context) (storage.StorageReader, error) {
+	return readConsistentSnapshot(rs.raftRouter, rs.engines.Kv, ctxs)
+}
+
+// readConsistentSnapshot proposes a snap command to every region before waiting for any of them, so
+// like a read index, each region responds once its apply reaches the index the command is appended at.
+// After all the regions respond, they are read by a single transaction of the kv engine, which reflects
+// every write committed to any of the regions before the call.
+func readConsistentSnapshot(router message.RaftRouter, kvDB *badger.DB, ctxs []*kvrpcpb.Context) (*MultiRegionReader, error) {
+	if len(ctxs) == 0 {
+		return nil, errors.New("no region to read")
+	}
+	cbs := make([]*message.Callback, 0, len(ctxs))
+	var err error
+	for _, ctx := range ctxs {
+		cb := message.NewCallback()
+		if err = router.SendRaftCommand(newSnapRequest(ctx), cb); err != nil {
+			break
+		}
+		cbs = append(cbs, cb)
+	}
+	// Wait for all the sent commands even if some of them fail, so their snapshots are discarded.
+	regions := make([]*metapb.Region, 0, len(cbs))
+	for _, cb := range cbs {
+		resp := cb.WaitResp()
+		if cb.Txn != nil {
+			cb.Txn.Discard()
+		}
+		if err != nil {
+			continue
+		}
+		if err = checkResponse(resp, 1); err != nil {
+			continue
+		}
+		regions = append(regions, resp.Responses[0].GetSnap().Region)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewMultiRegionReader(kvDB.NewTransaction(false), regions), nil
+}
+
+func newSnapRequest(ctx *kvrpcpb.Context) *raft_cmdpb.RaftCmdRequest {
+	header := &raft_cmdpb.RaftRequestHeader{
+		RegionId:    ctx.RegionId,
+		Peer:        ctx.Peer,
+		RegionEpoch: ctx.RegionEpoch,
+		Term:        ctx.Term,
+	}
+	return &raft_cmdpb.RaftCmdRequest{
+		Header: header,
+		Requests: []*raft_cmdpb.Request{{
+			CmdType: raft_cmdpb.CmdType_Snap,
+			Snap:    &raft_cmdpb.SnapRequest{},
+		}},
+	}
+}
+
 func (rs *RaftStorage) Raft(stream tinykvpb.TinyKv_RaftServer) error {
 	for {
 		msg, err := stream.Recv()
@@ -204,7 +254,7 @@ func (rs *RaftStorage) Start(client scheduler_client.Client) error {
 	snapRunner := newSnapRunner(rs.snapManager, rs.config, rs.raftRouter)
 	rs.snapWorker.Start(snapRunner)
 
-	raftClient := newRaftClient(cfg)
+	raftClient := newRaftClient(cfg, rs.raftRouter)
 	trans := NewServerTransport(raftClient, snapSender, rs.raftRouter, resolveSender)
 
 	rs.node = raftstore.NewNode(rs.raftSystem, rs.config, client)
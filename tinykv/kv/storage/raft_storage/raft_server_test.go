@@ -0,0 +1,110 @@
+package raft_storage
+
+import (
+	"testing"
+
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/util"
+	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/kvrpcpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
+	"github.com/stretchr/testify/require"
+)
+
+// snapRouter responds to the snap command of a region once the region is released,
+// with a snapshot of the kv engine at that time.
+type snapRouter struct {
+	engines *engine_util.Engines
+	regions map[uint64]*metapb.Region
+	sent    chan uint64
+	release map[uint64]chan struct{}
+}
+
+func (r *snapRouter) Send(regionID uint64, msg message.Msg) error {
+	return nil
+}
+
+func (r *snapRouter) SendRaftMessage(msg *raft_serverpb.RaftMessage) error {
+	return nil
+}
+
+func (r *snapRouter) SendRaftCommand(req *raft_cmdpb.RaftCmdRequest, cb *message.Callback) error {
+	regionID := req.Header.RegionId
+	r.sent <- regionID
+	go func() {
+		<-r.release[regionID]
+		cb.Txn = r.engines.Kv.NewTransaction(false)
+		cb.Done(&raft_cmdpb.RaftCmdResponse{
+			Header: &raft_cmdpb.RaftResponseHeader{},
+			Responses: []*raft_cmdpb.Response{{
+				CmdType: raft_cmdpb.CmdType_Snap,
+				Snap:    &raft_cmdpb.SnapResponse{Region: r.regions[regionID]},
+			}},
+		})
+	}()
+	return nil
+}
+
+func TestConsistentReaderAcrossRegions(t *testing.T) {
+	engines := util.NewTestEngines()
+	defer engines.Destroy()
+	router := &snapRouter{
+		engines: engines,
+		regions: map[uint64]*metapb.Region{
+			1: {Id: 1, StartKey: []byte("a"), EndKey: []byte("m")},
+			2: {Id: 2, StartKey: []byte("m"), EndKey: []byte("z")},
+		},
+		sent: make(chan uint64, 2),
+		release: map[uint64]chan struct{}{
+			1: make(chan struct{}),
+			2: make(chan struct{}),
+		},
+	}
+	for _, key := range []string{"k1", "k2", "zz"} {
+		require.Nil(t, engine_util.PutCF(engines.Kv, engine_util.CfDefault, []byte(key), []byte("v1")))
+	}
+
+	type result struct {
+		reader *MultiRegionReader
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		reader, err := readConsistentSnapshot(router, engines.Kv, []*kvrpcpb.Context{{RegionId: 2}, {RegionId: 1}})
+		done <- result{reader, err}
+	}()
+	// The read index of every region is requested before waiting for any of them.
+	require.Equal(t, uint64(2), <-router.sent)
+	require.Equal(t, uint64(1), <-router.sent)
+
+	// Region 1 responds before the writes to both regions, region 2 responds after them.
+	close(router.release[1])
+	require.Nil(t, engine_util.PutCF(engines.Kv, engine_util.CfDefault, []byte("k1"), []byte("v2")))
+	require.Nil(t, engine_util.PutCF(engines.Kv, engine_util.CfDefault, []byte("y"), []byte("v2")))
+	close(router.release[2])
+	res := <-done
+	require.Nil(t, res.err)
+	reader := res.reader
+	defer reader.Close()
+
+	// Both regions are read at the same point, which reflects the writes to region 1 committed before
+	// region 2 responds.
+	val, err := reader.GetCF(engine_util.CfDefault, []byte("k1"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v2"), val)
+	val, err = reader.GetCF(engine_util.CfDefault, []byte("y"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("v2"), val)
+	_, err = reader.GetCF(engine_util.CfDefault, []byte("zz"))
+	require.NotNil(t, err)
+
+	iter := reader.IterCF(engine_util.CfDefault)
+	defer iter.Close()
+	var keys []string
+	for iter.Seek([]byte("a")); iter.Valid(); iter.Next() {
+		keys = append(keys, string(iter.Item().Key()))
+	}
+	require.Equal(t, []string{"k1", "k2", "y"}, keys)
+}
@@ -1,6 +1,9 @@
 package raft_storage
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/Connor1996/badger"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore/util"
 	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
@@ -88,3 +91,117 @@ func (it *RegionIterator) Seek(key []byte) {
 func (it *RegionIterator) Rewind() {
 	it.iter.Rewind()
 }
+
+// MultiRegionReader reads several regions by a single transaction.
+type MultiRegionReader struct {
+	txn *badger.Txn
+	// regions are sorted by the start key.
+	regions []*metapb.Region
+}
+
+func NewMultiRegionReader(txn *badger.Txn, regions []*metapb.Region) *MultiRegionReader {
+	sort.Slice(regions, func(i, j int) bool {
+		return bytes.Compare(regions[i].StartKey, regions[j].StartKey) < 0
+	})
+	return &MultiRegionReader{
+		txn:     txn,
+		regions: regions,
+	}
+}
+
+func (r *MultiRegionReader) GetCF(cf string, key []byte) ([]byte, error) {
+	idx := searchRegion(r.regions, key)
+	if idx == len(r.regions) {
+		idx--
+	}
+	if err := util.CheckKeyInRegion(key, r.regions[idx]); err != nil {
+		return nil, err
+	}
+	val, err := engine_util.GetCFFromTxn(r.txn, cf, key)
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	return val, err
+}
+
+func (r *MultiRegionReader) IterCF(cf string) engine_util.DBIterator {
+	return NewMultiRegionIterator(engine_util.NewCFIterator(cf, r.txn), r.regions)
+}
+
+func (r *MultiRegionReader) Close() {
+	r.txn.Discard()
+}
+
+// searchRegion returns the index of the first region which doesn't end before the key.
+func searchRegion(regions []*metapb.Region, key []byte) int {
+	return sort.Search(len(regions), func(i int) bool {
+		return !engine_util.ExceedEndKey(key, regions[i].EndKey)
+	})
+}
+
+// MultiRegionIterator wraps a db iterator and only allow it to iterate in the sorted regions, the keys
+// in the gaps between the regions are skipped.
+type MultiRegionIterator struct {
+	iter    *engine_util.BadgerIterator
+	regions []*metapb.Region
+	// cur is the index of the region the iterator is in.
+	cur int
+}
+
+func NewMultiRegionIterator(iter *engine_util.BadgerIterator, regions []*metapb.Region) *MultiRegionIterator {
+	it := &MultiRegionIterator{
+		iter:    iter,
+		regions: regions,
+	}
+	it.skipGaps()
+	return it
+}
+
+// skipGaps moves the iterator forward until it's in a region.
+func (it *MultiRegionIterator) skipGaps() {
+	for it.iter.Valid() && it.cur < len(it.regions) {
+		region := it.regions[it.cur]
+		key := it.iter.Item().Key()
+		if bytes.Compare(key, region.StartKey) < 0 {
+			it.iter.Seek(region.StartKey)
+			continue
+		}
+		if !engine_util.ExceedEndKey(key, region.EndKey) {
+			return
+		}
+		it.cur++
+	}
+}
+
+func (it *MultiRegionIterator) Item() engine_util.DBItem {
+	return it.iter.Item()
+}
+
+func (it *MultiRegionIterator) Valid() bool {
+	return it.iter.Valid() && it.cur < len(it.regions)
+}
+
+func (it *MultiRegionIterator) ValidForPrefix(prefix []byte) bool {
+	return it.iter.ValidForPrefix(prefix) && it.cur < len(it.regions)
+}
+
+func (it *MultiRegionIterator) Close() {
+	it.iter.Close()
+}
+
+func (it *MultiRegionIterator) Next() {
+	it.iter.Next()
+	it.skipGaps()
+}
+
+func (it *MultiRegionIterator) Seek(key []byte) {
+	it.cur = searchRegion(it.regions, key)
+	it.iter.Seek(key)
+	it.skipGaps()
+}
+
+func (it *MultiRegionIterator) Rewind() {
+	it.cur = 0
+	it.iter.Rewind()
+	it.skipGaps()
+}
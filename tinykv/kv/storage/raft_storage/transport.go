@@ -1,6 +1,7 @@
 package raft_storage
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -30,70 +31,104 @@ func NewServerTransport(raftClient *RaftClient, snapScheduler chan<- worker.Task
 	}
 }
 
-func (t *ServerTransport) Send(msg *raft_serverpb.RaftMessage) error {
+func (t *ServerTransport) Send(ctx context.Context, msg *raft_serverpb.RaftMessage) error {
 	storeID := msg.GetToPeer().GetStoreId()
-	t.SendStore(storeID, msg)
-	return nil
+	return t.SendStore(ctx, storeID, msg)
 }
 
-func (t *ServerTransport) SendStore(storeID uint64, msg *raft_serverpb.RaftMessage) {
+func (t *ServerTransport) SendStore(ctx context.Context, storeID uint64, msg *raft_serverpb.RaftMessage) error {
 	addr := t.raftClient.GetAddr(storeID)
 	if addr != "" {
-		t.WriteData(storeID, addr, msg)
-		return
+		return t.WriteData(ctx, storeID, addr, msg)
 	}
 	if _, ok := t.resolving.Load(storeID); ok {
 		log.Debug(fmt.Sprintf("store address is being resolved, msg dropped. storeID: %v, msg: %s", storeID, msg))
-		return
+		return nil
 	}
 	log.Debug(fmt.Sprintf("begin to resolve store address. storeID: %v", storeID))
 	t.resolving.Store(storeID, struct{}{})
-	t.Resolve(storeID, msg)
+	if err := t.Resolve(ctx, storeID, msg); err != nil {
+		t.resolving.Delete(storeID)
+		return err
+	}
+	return nil
 }
 
-func (t *ServerTransport) Resolve(storeID uint64, msg *raft_serverpb.RaftMessage) {
+// Resolve schedules a task resolving the address of the store, the message is sent once
+// it's resolved. If the task can't be scheduled before ctx is done, ctx.Err() is returned.
+func (t *ServerTransport) Resolve(ctx context.Context, storeID uint64, msg *raft_serverpb.RaftMessage) error {
 	callback := func(addr string, err error) {
 		// clear resolving
 		t.resolving.Delete(storeID)
 		if err != nil {
 			log.Error(fmt.Sprintf("resolve store address failed. storeID: %v, err: %v", storeID, err))
+			reportUnreachable(t.raftRouter, msg)
 			return
 		}
 		t.raftClient.InsertAddr(storeID, addr)
-		t.WriteData(storeID, addr, msg)
+		// The message is sent after Send returned, so there is no deadline for it.
+		t.WriteData(context.Background(), storeID, addr, msg)
 		t.raftClient.Flush()
 	}
-	t.resolverScheduler <- &resolveAddrTask{
+	task := &resolveAddrTask{
 		storeID:  storeID,
 		callback: callback,
 	}
+	select {
+	case t.resolverScheduler <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (t *ServerTransport) WriteData(storeID uint64, addr string, msg *raft_serverpb.RaftMessage) {
+func (t *ServerTransport) WriteData(ctx context.Context, storeID uint64, addr string, msg *raft_serverpb.RaftMessage) error {
 	if msg.GetMessage().GetSnapshot() != nil {
-		t.SendSnapshotSock(addr, msg)
-		return
+		return t.SendSnapshotSock(ctx, addr, msg)
 	}
-	if err := t.raftClient.Send(storeID, addr, msg); err != nil {
+	if err := t.raftClient.Send(ctx, storeID, addr, msg); err != nil {
 		log.Error(fmt.Sprintf("send raft msg err. err: %v", err))
+		return err
 	}
+	return nil
 }
 
-func (t *ServerTransport) SendSnapshotSock(addr string, msg *raft_serverpb.RaftMessage) {
+// SendSnapshotSock schedules a task sending the snapshot. If the task can't be scheduled
+// before ctx is done, ctx.Err() is returned.
+func (t *ServerTransport) SendSnapshotSock(ctx context.Context, addr string, msg *raft_serverpb.RaftMessage) error {
 	callback := func(err error) {
 		regionID := msg.GetRegionId()
 		toPeerID := msg.GetToPeer().GetId()
 		toStoreID := msg.GetToPeer().GetStoreId()
 		log.Debug(fmt.Sprintf("send snapshot. toPeerID: %v, toStoreID: %v, regionID: %v, status: %v", toPeerID, toStoreID, regionID, err))
+		if err != nil {
+			reportUnreachable(t.raftRouter, msg)
+		}
 	}
 
-	t.snapScheduler <- &sendSnapTask{
+	task := &sendSnapTask{
 		addr:     addr,
 		msg:      msg,
 		callback: callback,
 	}
+	select {
+	case t.snapScheduler <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (t *ServerTransport) Flush() {
 	t.raftClient.Flush()
 }
+
+// reportUnreachable reports the recipient of the message unreachable to its region, so
+// raft sends the following messages to the recipient from the matched index.
+func reportUnreachable(router message.RaftRouter, msg *raft_serverpb.RaftMessage) {
+	regionID := msg.GetRegionId()
+	toPeerID := msg.GetToPeer().GetId()
+	if err := router.Send(regionID, message.NewPeerMsg(message.MsgTypeUnreachable, regionID, toPeerID)); err != nil {
+		log.Debug(fmt.Sprintf("report peer unreachable failed. toPeerID: %v, regionID: %v, err: %v", toPeerID, regionID, err))
+	}
+}
@@ -0,0 +1,88 @@
+package raft_storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pingcap-incubator/tinykv/kv/config"
+	"github.com/pingcap-incubator/tinykv/kv/raftstore/message"
+	"github.com/pingcap-incubator/tinykv/kv/util/worker"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_serverpb"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRouter records the messages sent to the peers.
+type mockRouter struct {
+	msgs chan message.Msg
+}
+
+func (r *mockRouter) Send(regionID uint64, msg message.Msg) error {
+	r.msgs <- msg
+	return nil
+}
+
+func (r *mockRouter) SendRaftMessage(msg *raft_serverpb.RaftMessage) error {
+	return nil
+}
+
+func (r *mockRouter) SendRaftCommand(req *raft_cmdpb.RaftCmdRequest, cb *message.Callback) error {
+	return nil
+}
+
+func TestTransportSendDeadline(t *testing.T) {
+	router := &mockRouter{msgs: make(chan message.Msg, 1)}
+	// Nobody takes the tasks, so the transport waits until the deadline.
+	snapCh := make(chan worker.Task)
+	resolveCh := make(chan worker.Task)
+	trans := NewServerTransport(newRaftClient(config.NewTestConfig(), router), snapCh, router, resolveCh)
+
+	send := func(msg *raft_serverpb.RaftMessage) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		return trans.Send(ctx, msg)
+	}
+	msg := &raft_serverpb.RaftMessage{
+		RegionId: 1,
+		ToPeer:   &metapb.Peer{Id: 2, StoreId: 2},
+		Message:  &eraftpb.Message{},
+	}
+	require.Equal(t, context.DeadlineExceeded, send(msg))
+	// The address isn't being resolved, the next message resolves it again.
+	_, ok := trans.resolving.Load(uint64(2))
+	require.False(t, ok)
+
+	trans.raftClient.InsertAddr(2, "127.0.0.1:0")
+	snapMsg := &raft_serverpb.RaftMessage{
+		RegionId: 1,
+		ToPeer:   &metapb.Peer{Id: 2, StoreId: 2},
+		Message:  &eraftpb.Message{Snapshot: &eraftpb.Snapshot{}},
+	}
+	require.Equal(t, context.DeadlineExceeded, send(snapMsg))
+}
+
+func TestTransportReportUnreachable(t *testing.T) {
+	router := &mockRouter{msgs: make(chan message.Msg, 1)}
+	resolveCh := make(chan worker.Task, 1)
+	trans := NewServerTransport(newRaftClient(config.NewTestConfig(), router), nil, router, resolveCh)
+
+	msg := &raft_serverpb.RaftMessage{
+		RegionId: 1,
+		ToPeer:   &metapb.Peer{Id: 2, StoreId: 2},
+		Message:  &eraftpb.Message{},
+	}
+	require.Nil(t, trans.Send(context.Background(), msg))
+	task := (<-resolveCh).(*resolveAddrTask)
+	require.Equal(t, uint64(2), task.storeID)
+
+	// The message is dropped after Send returned, the recipient is reported to its region.
+	task.callback("", errors.New("store is not found"))
+	reported := <-router.msgs
+	require.Equal(t, message.MsgTypeUnreachable, reported.Type)
+	require.Equal(t, uint64(1), reported.RegionID)
+	require.Equal(t, uint64(2), reported.Data.(uint64))
+}
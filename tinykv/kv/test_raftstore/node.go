@@ -66,7 +66,7 @@ func (t *MockTransport) ClearFilters() {
 	t.filters = nil
 }
 
-func (t *MockTransport) Send(msg *raft_serverpb.RaftMessage) error {
+func (t *MockTransport) Send(ctx context.Context, msg *raft_serverpb.RaftMessage) error {
 	t.RLock()
 	defer t.RUnlock()
 
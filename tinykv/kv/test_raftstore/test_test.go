@@ -15,6 +15,7 @@ import (
 	"github.com/pingcap-incubator/tinykv/kv/config"
 	"github.com/pingcap-incubator/tinykv/kv/raftstore/meta"
 	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
 	"github.com/pingcap/log"
 	"github.com/stretchr/testify/assert"
@@ -495,6 +496,59 @@ func TestOneSnapshot2BLab1P3a(t *testing.T) {
 	}
 }
 
+func TestAutoCompactLog2B(t *testing.T) {
+	cfg := config.NewTestConfig()
+	cfg.RaftLogGcCountLimit = 10
+	cluster := NewTestCluster(3, cfg)
+	cluster.Start()
+	defer cluster.Shutdown()
+
+	truncatedIndexes := func() []uint64 {
+		var indexes []uint64
+		for _, engine := range cluster.engines {
+			state, err := meta.GetApplyState(engine.Kv, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			indexes = append(indexes, state.TruncatedState.Index)
+		}
+		return indexes
+	}
+
+	// The applied entries are below the limit, the log isn't compacted.
+	for i := 0; i < 3; i++ {
+		cluster.MustPut([]byte(fmt.Sprintf("k%d", i)), []byte("v"))
+	}
+	time.Sleep(10 * cfg.RaftLogGCTickInterval)
+	for _, idx := range truncatedIndexes() {
+		if idx != meta.RaftInitLogIndex {
+			t.Fatalf("log is compacted to %d before reaching the limit", idx)
+		}
+	}
+
+	// The leader proposes the compaction by itself once the limit is crossed.
+	for i := 3; i < 20; i++ {
+		cluster.MustPut([]byte(fmt.Sprintf("k%d", i)), []byte("v"))
+	}
+	for _, engine := range cluster.engines {
+		MustGetEqual(engine, []byte("k19"), []byte("v"))
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		compacted := true
+		for _, idx := range truncatedIndexes() {
+			compacted = compacted && idx > meta.RaftInitLogIndex
+		}
+		if compacted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log is not compacted after crossing the limit, truncated indexes %v", truncatedIndexes())
+		}
+		time.Sleep(cfg.RaftLogGCTickInterval)
+	}
+}
+
 func TestSnapshotRecover2BLab1(t *testing.T) {
 	// Test: restarts, snapshots, one client (2B) ...
 	GenericTest(t, "2B", 1, false, true, false, 100, false, false)
@@ -626,6 +680,41 @@ func TestConfChangeSnapshotUnreliableRecoverConcurrentPartition3B(t *testing.T)
 	GenericTest(t, "3B", 5, true, true, true, 100, true, false)
 }
 
+func TestRetryDroppedConfChange3B(t *testing.T) {
+	cfg := config.NewTestConfig()
+	// Retry for longer than the election timeout, after which the leader transfer is aborted.
+	cfg.ConfChangeRetryInterval = 300 * time.Millisecond
+	cluster := NewTestCluster(4, cfg)
+	cluster.Start()
+	defer cluster.Shutdown()
+
+	cluster.MustTransferLeader(1, NewPeer(1, 1))
+	cluster.MustRemovePeer(1, NewPeer(4, 4))
+
+	// The transferee is isolated, so the leader keeps transferring the leadership and
+	// drops the proposals until the transfer times out.
+	cluster.AddFilter(&PartitionFilter{
+		s1: []uint64{1, 3},
+		s2: []uint64{2},
+	})
+	cluster.TransferLeader(1, NewPeer(2, 2))
+
+	epoch := cluster.GetRegion([]byte("")).GetRegionEpoch()
+	req := NewAdminRequest(1, epoch, NewChangePeerCmd(eraftpb.ConfChangeType_AddNode, NewPeer(4, 5)))
+	req.Header.Peer = NewPeer(1, 1)
+	resp, _, err := cluster.CallCommand(req, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, resp.GetHeader().GetError())
+	assert.Equal(t, raft_cmdpb.AdminCmdType_ChangePeer, resp.GetAdminResponse().GetCmdType())
+
+	cluster.ClearFilters()
+	cluster.MustHavePeer(1, NewPeer(4, 5))
+	cluster.MustPut([]byte("k1"), []byte("v1"))
+	MustGetEqual(cluster.engines[4], []byte("k1"), []byte("v1"))
+}
+
 func TestOneSplit3BLab1P4a(t *testing.T) {
 	cfg := config.NewTestConfig()
 	cfg.RegionMaxSize = 800
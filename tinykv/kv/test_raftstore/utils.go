@@ -9,6 +9,7 @@ import (
 	"github.com/Connor1996/badger"
 	"github.com/pingcap-incubator/tinykv/kv/config"
 	"github.com/pingcap-incubator/tinykv/kv/util/engine_util"
+	"github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/metapb"
 	"github.com/pingcap-incubator/tinykv/proto/pkg/raft_cmdpb"
 	"github.com/pingcap/log"
@@ -92,6 +93,15 @@ func NewTransferLeaderCmd(peer *metapb.Peer) *raft_cmdpb.AdminRequest {
 	return cmd
 }
 
+func NewChangePeerCmd(changeType eraftpb.ConfChangeType, peer *metapb.Peer) *raft_cmdpb.AdminRequest {
+	changePeer := raft_cmdpb.ChangePeerRequest{ChangeType: changeType, Peer: peer}
+	cmd := &raft_cmdpb.AdminRequest{
+		CmdType:    raft_cmdpb.AdminCmdType_ChangePeer,
+		ChangePeer: &changePeer,
+	}
+	return cmd
+}
+
 func MustGetCf(engine *engine_util.Engines, cf string, key []byte, value []byte) {
 	for i := 0; i < 300; i++ {
 		val, err := engine_util.GetCF(engine.Kv, cf, key)
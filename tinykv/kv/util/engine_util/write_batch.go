@@ -26,6 +26,10 @@ func (wb *WriteBatch) Len() int {
 	return len(wb.entries)
 }
 
+func (wb *WriteBatch) Size() int {
+	return wb.size
+}
+
 func (wb *WriteBatch) SetCF(cf string, key, val []byte) {
 	wb.entries = append(wb.entries, &badger.Entry{
 		Key:   KeyWithCF(cf, key),
@@ -22,6 +22,10 @@ type Starter interface {
 	Start()
 }
 
+type Stopper interface {
+	Stop()
+}
+
 func (w *Worker) Start(handler TaskHandler) {
 	w.wg.Add(1)
 	go func() {
@@ -32,6 +36,9 @@ func (w *Worker) Start(handler TaskHandler) {
 		for {
 			Task := <-w.receiver
 			if _, ok := Task.(TaskStop); ok {
+				if s, ok := handler.(Stopper); ok {
+					s.Stop()
+				}
 				return
 			}
 			handler.Handle(Task)
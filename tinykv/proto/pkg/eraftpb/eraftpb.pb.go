@@ -42,7 +42,7 @@ func (x EntryType) String() string {
 	return proto.EnumName(EntryType_name, int32(x))
 }
 func (EntryType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{0}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{0}
 }
 
 // Some MessageType defined here are local messages which not come from the network, but should
@@ -77,6 +77,17 @@ const (
 	// 'MessageType_MsgTimeoutNow' send from the leader to the leadership transfer target, to let
 	// the transfer target timeout immediately and start a new election.
 	MessageType_MsgTimeoutNow MessageType = 12
+	// 'MessageType_MsgPreVote' asks whether the candidate could win an election of the next term,
+	// it's sent before the candidate increases its term.
+	MessageType_MsgPreVote MessageType = 13
+	// 'MessageType_MsgPreVoteResponse' is a response to 'MessageType_MsgPreVote'.
+	MessageType_MsgPreVoteResponse MessageType = 14
+	// 'MessageType_MsgReadIndex' requests a read index for a linearizable read, the request
+	// context is carried by the data of its only entry.
+	MessageType_MsgReadIndex MessageType = 15
+	// 'MessageType_MsgReadIndexResponse' returns the read index to the follower which forwarded
+	// the 'MessageType_MsgReadIndex' to the leader.
+	MessageType_MsgReadIndexResponse MessageType = 16
 )
 
 var MessageType_name = map[int32]string{
@@ -92,6 +103,10 @@ var MessageType_name = map[int32]string{
 	9:  "MsgHeartbeatResponse",
 	11: "MsgTransferLeader",
 	12: "MsgTimeoutNow",
+	13: "MsgPreVote",
+	14: "MsgPreVoteResponse",
+	15: "MsgReadIndex",
+	16: "MsgReadIndexResponse",
 }
 var MessageType_value = map[string]int32{
 	"MsgHup":                 0,
@@ -106,13 +121,17 @@ var MessageType_value = map[string]int32{
 	"MsgHeartbeatResponse":   9,
 	"MsgTransferLeader":      11,
 	"MsgTimeoutNow":          12,
+	"MsgPreVote":             13,
+	"MsgPreVoteResponse":     14,
+	"MsgReadIndex":           15,
+	"MsgReadIndexResponse":   16,
 }
 
 func (x MessageType) String() string {
 	return proto.EnumName(MessageType_name, int32(x))
 }
 func (MessageType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{1}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{1}
 }
 
 type ConfChangeType int32
@@ -120,22 +139,39 @@ type ConfChangeType int32
 const (
 	ConfChangeType_AddNode    ConfChangeType = 0
 	ConfChangeType_RemoveNode ConfChangeType = 1
+	// The peer keeps its id but is moved to another store, the raft membership is unchanged.
+	ConfChangeType_MovePeer ConfChangeType = 2
+	// The node is added as a learner, which receives the log entries but doesn't vote.
+	ConfChangeType_AddLearnerNode ConfChangeType = 3
+	// The joint state of the membership change is entered, the context contains the
+	// ConfState of the incoming voters, the current voters are the outgoing ones.
+	ConfChangeType_EnterJoint ConfChangeType = 4
+	// The joint state is left, only the incoming voters are kept.
+	ConfChangeType_LeaveJoint ConfChangeType = 5
 )
 
 var ConfChangeType_name = map[int32]string{
 	0: "AddNode",
 	1: "RemoveNode",
+	2: "MovePeer",
+	3: "AddLearnerNode",
+	4: "EnterJoint",
+	5: "LeaveJoint",
 }
 var ConfChangeType_value = map[string]int32{
-	"AddNode":    0,
-	"RemoveNode": 1,
+	"AddNode":        0,
+	"RemoveNode":     1,
+	"MovePeer":       2,
+	"AddLearnerNode": 3,
+	"EnterJoint":     4,
+	"LeaveJoint":     5,
 }
 
 func (x ConfChangeType) String() string {
 	return proto.EnumName(ConfChangeType_name, int32(x))
 }
 func (ConfChangeType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{2}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{2}
 }
 
 // The entry is a type of change that needs to be applied. It contains two data fields.
@@ -162,7 +198,7 @@ func (m *Entry) Reset()         { *m = Entry{} }
 func (m *Entry) String() string { return proto.CompactTextString(m) }
 func (*Entry) ProtoMessage()    {}
 func (*Entry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{0}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{0}
 }
 func (m *Entry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -234,7 +270,7 @@ func (m *SnapshotMetadata) Reset()         { *m = SnapshotMetadata{} }
 func (m *SnapshotMetadata) String() string { return proto.CompactTextString(m) }
 func (*SnapshotMetadata) ProtoMessage()    {}
 func (*SnapshotMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{1}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{1}
 }
 func (m *SnapshotMetadata) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -296,7 +332,7 @@ func (m *Snapshot) Reset()         { *m = Snapshot{} }
 func (m *Snapshot) String() string { return proto.CompactTextString(m) }
 func (*Snapshot) ProtoMessage()    {}
 func (*Snapshot) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{2}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{2}
 }
 func (m *Snapshot) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -340,27 +376,34 @@ func (m *Snapshot) GetMetadata() *SnapshotMetadata {
 }
 
 type Message struct {
-	MsgType              MessageType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=eraftpb.MessageType" json:"msg_type,omitempty"`
-	To                   uint64      `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
-	From                 uint64      `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
-	Term                 uint64      `protobuf:"varint,4,opt,name=term,proto3" json:"term,omitempty"`
-	LogTerm              uint64      `protobuf:"varint,5,opt,name=log_term,json=logTerm,proto3" json:"log_term,omitempty"`
-	Index                uint64      `protobuf:"varint,6,opt,name=index,proto3" json:"index,omitempty"`
-	Entries              []*Entry    `protobuf:"bytes,7,rep,name=entries" json:"entries,omitempty"`
-	Commit               uint64      `protobuf:"varint,8,opt,name=commit,proto3" json:"commit,omitempty"`
-	Snapshot             *Snapshot   `protobuf:"bytes,9,opt,name=snapshot" json:"snapshot,omitempty"`
-	Reject               bool        `protobuf:"varint,10,opt,name=reject,proto3" json:"reject,omitempty"`
-	RejectHint           uint64      `protobuf:"varint,11,opt,name=reject_hint,json=rejectHint,proto3" json:"reject_hint,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	MsgType  MessageType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=eraftpb.MessageType" json:"msg_type,omitempty"`
+	To       uint64      `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	From     uint64      `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
+	Term     uint64      `protobuf:"varint,4,opt,name=term,proto3" json:"term,omitempty"`
+	LogTerm  uint64      `protobuf:"varint,5,opt,name=log_term,json=logTerm,proto3" json:"log_term,omitempty"`
+	Index    uint64      `protobuf:"varint,6,opt,name=index,proto3" json:"index,omitempty"`
+	Entries  []*Entry    `protobuf:"bytes,7,rep,name=entries" json:"entries,omitempty"`
+	Commit   uint64      `protobuf:"varint,8,opt,name=commit,proto3" json:"commit,omitempty"`
+	Snapshot *Snapshot   `protobuf:"bytes,9,opt,name=snapshot" json:"snapshot,omitempty"`
+	Reject   bool        `protobuf:"varint,10,opt,name=reject,proto3" json:"reject,omitempty"`
+	// TODO: Delete Start
+	RejectHint uint64 `protobuf:"varint,11,opt,name=reject_hint,json=rejectHint,proto3" json:"reject_hint,omitempty"`
+	// TODO: Delete End
+	// The election priority of the candidate, carried by 'MessageType_MsgRequestVote'.
+	Priority uint64 `protobuf:"varint,12,opt,name=priority,proto3" json:"priority,omitempty"`
+	// The context of the read index request confirmed by 'MessageType_MsgHeartbeat' and
+	// 'MessageType_MsgHeartbeatResponse'.
+	Context              []byte   `protobuf:"bytes,13,opt,name=context,proto3" json:"context,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{3}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{3}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -466,6 +509,20 @@ func (m *Message) GetRejectHint() uint64 {
 	return 0
 }
 
+func (m *Message) GetPriority() uint64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *Message) GetContext() []byte {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
 // HardState contains the state of a node, including the current term, commit index
 // and the vote record
 type HardState struct {
@@ -481,7 +538,7 @@ func (m *HardState) Reset()         { *m = HardState{} }
 func (m *HardState) String() string { return proto.CompactTextString(m) }
 func (*HardState) ProtoMessage()    {}
 func (*HardState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{4}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{4}
 }
 func (m *HardState) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -534,7 +591,9 @@ func (m *HardState) GetCommit() uint64 {
 // ConfState contains the current membership information of the raft group
 type ConfState struct {
 	// all node id
-	Nodes                []uint64 `protobuf:"varint,1,rep,packed,name=nodes" json:"nodes,omitempty"`
+	Nodes []uint64 `protobuf:"varint,1,rep,packed,name=nodes" json:"nodes,omitempty"`
+	// all learner node id
+	Learners             []uint64 `protobuf:"varint,2,rep,packed,name=learners" json:"learners,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -544,7 +603,7 @@ func (m *ConfState) Reset()         { *m = ConfState{} }
 func (m *ConfState) String() string { return proto.CompactTextString(m) }
 func (*ConfState) ProtoMessage()    {}
 func (*ConfState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{5}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{5}
 }
 func (m *ConfState) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -580,6 +639,13 @@ func (m *ConfState) GetNodes() []uint64 {
 	return nil
 }
 
+func (m *ConfState) GetLearners() []uint64 {
+	if m != nil {
+		return m.Learners
+	}
+	return nil
+}
+
 // ConfChange is the data that attach on entry with EntryConfChange type
 type ConfChange struct {
 	ChangeType ConfChangeType `protobuf:"varint,1,opt,name=change_type,json=changeType,proto3,enum=eraftpb.ConfChangeType" json:"change_type,omitempty"`
@@ -595,7 +661,7 @@ func (m *ConfChange) Reset()         { *m = ConfChange{} }
 func (m *ConfChange) String() string { return proto.CompactTextString(m) }
 func (*ConfChange) ProtoMessage()    {}
 func (*ConfChange) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eraftpb_2f2e0bcef614736b, []int{6}
+	return fileDescriptor_eraftpb_d4372713441b9bf7, []int{6}
 }
 func (m *ConfChange) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -864,6 +930,17 @@ func (m *Message) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintEraftpb(dAtA, i, uint64(m.RejectHint))
 	}
+	if m.Priority != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintEraftpb(dAtA, i, uint64(m.Priority))
+	}
+	if len(m.Context) > 0 {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintEraftpb(dAtA, i, uint64(len(m.Context)))
+		i += copy(dAtA[i:], m.Context)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -938,6 +1015,23 @@ func (m *ConfState) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintEraftpb(dAtA, i, uint64(j4))
 		i += copy(dAtA[i:], dAtA5[:j4])
 	}
+	if len(m.Learners) > 0 {
+		dAtA7 := make([]byte, len(m.Learners)*10)
+		var j6 int
+		for _, num := range m.Learners {
+			for num >= 1<<7 {
+				dAtA7[j6] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j6++
+			}
+			dAtA7[j6] = uint8(num)
+			j6++
+		}
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintEraftpb(dAtA, i, uint64(j6))
+		i += copy(dAtA[i:], dAtA7[:j6])
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1088,6 +1182,13 @@ func (m *Message) Size() (n int) {
 	if m.RejectHint != 0 {
 		n += 1 + sovEraftpb(uint64(m.RejectHint))
 	}
+	if m.Priority != 0 {
+		n += 1 + sovEraftpb(uint64(m.Priority))
+	}
+	l = len(m.Context)
+	if l > 0 {
+		n += 1 + l + sovEraftpb(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1122,6 +1223,13 @@ func (m *ConfState) Size() (n int) {
 		}
 		n += 1 + sovEraftpb(uint64(l)) + l
 	}
+	if len(m.Learners) > 0 {
+		l = 0
+		for _, e := range m.Learners {
+			l += sovEraftpb(uint64(e))
+		}
+		n += 1 + sovEraftpb(uint64(l)) + l
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1801,6 +1909,56 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEraftpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Context", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEraftpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEraftpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Context = append(m.Context[:0], dAtA[iNdEx:postIndex]...)
+			if m.Context == nil {
+				m.Context = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEraftpb(dAtA[iNdEx:])
@@ -2022,6 +2180,68 @@ func (m *ConfState) Unmarshal(dAtA []byte) error {
 			} else {
 				return fmt.Errorf("proto: wrong wireType = %d for field Nodes", wireType)
 			}
+		case 2:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowEraftpb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Learners = append(m.Learners, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowEraftpb
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthEraftpb
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowEraftpb
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Learners = append(m.Learners, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Learners", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEraftpb(dAtA[iNdEx:])
@@ -2269,50 +2489,57 @@ var (
 	ErrIntOverflowEraftpb   = fmt.Errorf("proto: integer overflow")
 )
 
-func init() { proto.RegisterFile("eraftpb.proto", fileDescriptor_eraftpb_2f2e0bcef614736b) }
-
-var fileDescriptor_eraftpb_2f2e0bcef614736b = []byte{
-	// 666 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x94, 0x5f, 0x4e, 0xdb, 0x4e,
-	0x10, 0xc7, 0xb3, 0xf9, 0x67, 0x7b, 0x1c, 0xc2, 0xb2, 0x3f, 0x7e, 0x60, 0xfa, 0x10, 0xa5, 0x7e,
-	0x8a, 0x90, 0xa0, 0x82, 0xaa, 0x52, 0x5f, 0x01, 0x55, 0xa2, 0x6a, 0x8d, 0x2a, 0x43, 0xfb, 0x1a,
-	0x2d, 0xf6, 0xc4, 0xa4, 0xc2, 0x5e, 0xd7, 0xbb, 0x50, 0x72, 0x93, 0x1e, 0xa2, 0x07, 0xe9, 0x63,
-	0x8f, 0x50, 0xd1, 0x23, 0xf4, 0x02, 0xd5, 0x6e, 0x6c, 0xc7, 0xa1, 0x6f, 0xf3, 0x1d, 0xcf, 0xee,
-	0x7c, 0xe6, 0x3b, 0x9b, 0xc0, 0x06, 0x16, 0x7c, 0xa6, 0xf2, 0xeb, 0xc3, 0xbc, 0x10, 0x4a, 0x30,
-	0xab, 0x94, 0xfe, 0x03, 0xf4, 0xde, 0x64, 0xaa, 0x58, 0xb0, 0x23, 0x00, 0xd4, 0xc1, 0x54, 0x2d,
-	0x72, 0xf4, 0xc8, 0x98, 0x4c, 0x86, 0xc7, 0xec, 0xb0, 0x3a, 0x65, 0x6a, 0xae, 0x16, 0x39, 0x86,
-	0x0e, 0x56, 0x21, 0x63, 0xd0, 0x55, 0x58, 0xa4, 0x5e, 0x7b, 0x4c, 0x26, 0xdd, 0xd0, 0xc4, 0x6c,
-	0x1b, 0x7a, 0xf3, 0x2c, 0xc6, 0x07, 0xaf, 0x63, 0x92, 0x4b, 0xa1, 0x2b, 0x63, 0xae, 0xb8, 0xd7,
-	0x1d, 0x93, 0xc9, 0x20, 0x34, 0xb1, 0x2f, 0x80, 0x5e, 0x66, 0x3c, 0x97, 0x37, 0x42, 0x05, 0xa8,
-	0xb8, 0xce, 0x69, 0x88, 0x48, 0x64, 0xb3, 0xa9, 0x54, 0x5c, 0x2d, 0x21, 0xdc, 0x06, 0xc4, 0x99,
-	0xc8, 0x66, 0x97, 0xfa, 0x4b, 0xe8, 0x44, 0x55, 0xb8, 0x6a, 0xd8, 0x7e, 0xd2, 0xd0, 0xa0, 0x75,
-	0x56, 0x68, 0xfe, 0x47, 0xb0, 0xab, 0x86, 0x35, 0x10, 0x59, 0x01, 0xb1, 0x57, 0x60, 0xa7, 0x25,
-	0x88, 0xb9, 0xcc, 0x3d, 0xde, 0xab, 0x5b, 0x3f, 0x25, 0x0d, 0xeb, 0x52, 0xff, 0x7b, 0x1b, 0xac,
-	0x00, 0xa5, 0xe4, 0x09, 0xb2, 0x17, 0x60, 0xa7, 0x32, 0x69, 0x5a, 0xb8, 0x5d, 0x5f, 0x51, 0xd6,
-	0x18, 0x13, 0xad, 0x54, 0x26, 0xc6, 0xc2, 0x21, 0xb4, 0x95, 0x28, 0xd1, 0xdb, 0x4a, 0x68, 0xae,
-	0x59, 0x21, 0x6a, 0x6e, 0x1d, 0xd7, 0xb3, 0x74, 0x1b, 0x36, 0xef, 0x81, 0x7d, 0x2b, 0x92, 0xa9,
-	0xc9, 0xf7, 0x4c, 0xde, 0xba, 0x15, 0xc9, 0xd5, 0xda, 0x06, 0xfa, 0x4d, 0x43, 0x26, 0x60, 0xe9,
-	0xc5, 0xcd, 0x51, 0x7a, 0xd6, 0xb8, 0x33, 0x71, 0x8f, 0x87, 0xeb, 0xbb, 0x0d, 0xab, 0xcf, 0x6c,
-	0x07, 0xfa, 0x91, 0x48, 0xd3, 0xb9, 0xf2, 0x6c, 0x73, 0x41, 0xa9, 0xd8, 0x01, 0xd8, 0xb2, 0x74,
-	0xc1, 0x73, 0x8c, 0x3d, 0x5b, 0xff, 0xd8, 0x13, 0xd6, 0x25, 0xfa, 0x9a, 0x02, 0x3f, 0x63, 0xa4,
-	0x3c, 0x18, 0x93, 0x89, 0x1d, 0x96, 0xca, 0x7f, 0x07, 0xce, 0x39, 0x2f, 0xe2, 0xe5, 0xf2, 0xaa,
-	0xd1, 0x48, 0x63, 0x34, 0x06, 0xdd, 0x7b, 0xa1, 0xb0, 0x7a, 0x55, 0x3a, 0x6e, 0x30, 0x75, 0x9a,
-	0x4c, 0xfe, 0x73, 0x70, 0xce, 0x9a, 0x2f, 0x21, 0x13, 0x31, 0x4a, 0x8f, 0x8c, 0x3b, 0x7a, 0x70,
-	0x23, 0xfc, 0x05, 0x80, 0x2e, 0x39, 0xbb, 0xe1, 0x59, 0x82, 0xec, 0x35, 0xb8, 0x91, 0x89, 0x9a,
-	0x3b, 0xda, 0x5d, 0x7b, 0x61, 0xcb, 0x4a, 0xb3, 0x26, 0x88, 0xea, 0x98, 0xed, 0x82, 0xa5, 0x2f,
-	0x9c, 0xce, 0xe3, 0x92, 0xac, 0xaf, 0xe5, 0xdb, 0x98, 0x79, 0x60, 0x45, 0x22, 0x53, 0xf8, 0xb0,
-	0x84, 0x1b, 0x84, 0x95, 0xdc, 0x3f, 0x02, 0xa7, 0xfe, 0xdd, 0xb0, 0x4d, 0x70, 0x8d, 0xb8, 0x10,
-	0x45, 0xca, 0x6f, 0x69, 0x8b, 0xfd, 0x07, 0x9b, 0x26, 0xb1, 0xea, 0x49, 0xc9, 0xfe, 0x1f, 0x02,
-	0x6e, 0xe3, 0xa1, 0x30, 0x80, 0x7e, 0x20, 0x93, 0xf3, 0xbb, 0x9c, 0xb6, 0x98, 0x0b, 0x56, 0x20,
-	0x93, 0x53, 0xe4, 0x8a, 0x12, 0x36, 0x04, 0x08, 0x64, 0xf2, 0xa1, 0x10, 0xb9, 0x90, 0x48, 0xdb,
-	0x6c, 0x03, 0x9c, 0x40, 0x26, 0x27, 0x79, 0x8e, 0x59, 0x4c, 0x3b, 0xec, 0x7f, 0xd8, 0xaa, 0x65,
-	0x88, 0x32, 0x17, 0x99, 0x44, 0xda, 0x65, 0x0c, 0x86, 0x81, 0x4c, 0x42, 0xfc, 0x72, 0x87, 0x52,
-	0x7d, 0x12, 0x0a, 0x69, 0x8f, 0x3d, 0x83, 0x9d, 0xf5, 0x5c, 0x5d, 0xdf, 0xd7, 0xd0, 0x81, 0x4c,
-	0xaa, 0xed, 0x52, 0x8b, 0x51, 0x18, 0x68, 0x1e, 0xe4, 0x85, 0xba, 0xd6, 0x20, 0x36, 0xf3, 0x60,
-	0xbb, 0x99, 0xa9, 0x0f, 0x3b, 0x25, 0xc3, 0x55, 0xc1, 0x33, 0x39, 0xc3, 0xe2, 0x3d, 0xf2, 0x18,
-	0x0b, 0xea, 0xb2, 0x2d, 0xd8, 0xd0, 0xe9, 0x79, 0x8a, 0xe2, 0x4e, 0x5d, 0x88, 0xaf, 0x74, 0xb0,
-	0x7f, 0x00, 0xc3, 0x75, 0xe7, 0xf5, 0xac, 0x27, 0x71, 0x7c, 0x21, 0x62, 0xa4, 0x2d, 0x3d, 0x6b,
-	0x88, 0xa9, 0xb8, 0x47, 0xa3, 0xc9, 0x29, 0xfd, 0xf1, 0x38, 0x22, 0x3f, 0x1f, 0x47, 0xe4, 0xd7,
-	0xe3, 0x88, 0x7c, 0xfb, 0x3d, 0x6a, 0x5d, 0xf7, 0xcd, 0xbf, 0xda, 0xcb, 0xbf, 0x01, 0x00, 0x00,
-	0xff, 0xff, 0x2c, 0x70, 0xc6, 0xf6, 0xe6, 0x04, 0x00, 0x00,
+func init() { proto.RegisterFile("eraftpb.proto", fileDescriptor_eraftpb_d4372713441b9bf7) }
+
+var fileDescriptor_eraftpb_d4372713441b9bf7 = []byte{
+	// 778 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x54, 0xc1, 0x6e, 0xe3, 0x36,
+	0x10, 0x8d, 0x2c, 0xc7, 0x92, 0x47, 0xb6, 0xc3, 0x4c, 0xd3, 0xac, 0x76, 0x0f, 0xa9, 0xe1, 0x93,
+	0x11, 0xa0, 0x5b, 0x6c, 0x8a, 0x02, 0xbd, 0xf4, 0x90, 0x0d, 0x16, 0xc8, 0xb6, 0x51, 0xb0, 0xd0,
+	0xa6, 0xbd, 0x1a, 0x8c, 0x35, 0x56, 0x54, 0x44, 0xa2, 0x4a, 0x32, 0x69, 0x7c, 0xee, 0x4f, 0xf4,
+	0x8f, 0xda, 0x63, 0x3f, 0xa1, 0x48, 0x7f, 0xa4, 0x20, 0x2d, 0xd1, 0xf2, 0xee, 0xed, 0xbd, 0xe1,
+	0x90, 0xef, 0xf1, 0x71, 0x24, 0x18, 0x93, 0xe4, 0x2b, 0x5d, 0xdf, 0xbe, 0xae, 0xa5, 0xd0, 0x02,
+	0x83, 0x86, 0xce, 0x9e, 0x60, 0xff, 0x5d, 0xa5, 0xe5, 0x1a, 0xdf, 0x00, 0x90, 0x01, 0x0b, 0xbd,
+	0xae, 0x29, 0xf6, 0xa6, 0xde, 0x7c, 0x72, 0x86, 0xaf, 0xdb, 0x5d, 0xb6, 0xe7, 0x66, 0x5d, 0x53,
+	0x3a, 0xa4, 0x16, 0x22, 0x42, 0x5f, 0x93, 0x2c, 0xe3, 0xde, 0xd4, 0x9b, 0xf7, 0x53, 0x8b, 0xf1,
+	0x08, 0xf6, 0x8b, 0x2a, 0xa3, 0xa7, 0xd8, 0xb7, 0xc5, 0x0d, 0x31, 0x9d, 0x19, 0xd7, 0x3c, 0xee,
+	0x4f, 0xbd, 0xf9, 0x28, 0xb5, 0x78, 0x26, 0x80, 0x7d, 0xac, 0x78, 0xad, 0xee, 0x84, 0x4e, 0x48,
+	0x73, 0x53, 0x33, 0x26, 0x96, 0xa2, 0x5a, 0x2d, 0x94, 0xe6, 0x7a, 0x63, 0x22, 0xea, 0x98, 0xb8,
+	0x10, 0xd5, 0xea, 0xa3, 0x59, 0x49, 0x87, 0xcb, 0x16, 0x6e, 0x05, 0x7b, 0x9f, 0x08, 0x5a, 0x6b,
+	0xfe, 0xd6, 0xda, 0xec, 0x67, 0x08, 0x5b, 0x41, 0x67, 0xc8, 0xdb, 0x1a, 0xc2, 0xef, 0x20, 0x2c,
+	0x1b, 0x23, 0xf6, 0xb0, 0xe8, 0xec, 0xa5, 0x93, 0xfe, 0xd4, 0x69, 0xea, 0x5a, 0x67, 0x7f, 0xf8,
+	0x10, 0x24, 0xa4, 0x14, 0xcf, 0x09, 0xbf, 0x81, 0xb0, 0x54, 0x79, 0x37, 0xc2, 0x23, 0x77, 0x44,
+	0xd3, 0x63, 0x43, 0x0c, 0x4a, 0x95, 0x1b, 0x80, 0x13, 0xe8, 0x69, 0xd1, 0x58, 0xef, 0x69, 0x61,
+	0x7c, 0xad, 0xa4, 0x70, 0xbe, 0x0d, 0x76, 0x77, 0xe9, 0x77, 0x62, 0x7e, 0x09, 0xe1, 0xbd, 0xc8,
+	0x17, 0xb6, 0xbe, 0x6f, 0xeb, 0xc1, 0xbd, 0xc8, 0x6f, 0x76, 0x5e, 0x60, 0xd0, 0x0d, 0x64, 0x0e,
+	0x81, 0x79, 0xb8, 0x82, 0x54, 0x1c, 0x4c, 0xfd, 0x79, 0x74, 0x36, 0xd9, 0x7d, 0xdb, 0xb4, 0x5d,
+	0xc6, 0x63, 0x18, 0x2c, 0x45, 0x59, 0x16, 0x3a, 0x0e, 0xed, 0x01, 0x0d, 0xc3, 0xaf, 0x21, 0x54,
+	0x4d, 0x0a, 0xf1, 0xd0, 0xc6, 0x73, 0xf8, 0x59, 0x3c, 0xa9, 0x6b, 0x31, 0xc7, 0x48, 0xfa, 0x95,
+	0x96, 0x3a, 0x86, 0xa9, 0x37, 0x0f, 0xd3, 0x86, 0xe1, 0x57, 0x10, 0x6d, 0xd0, 0xe2, 0xae, 0xa8,
+	0x74, 0x1c, 0x59, 0x0d, 0xd8, 0x94, 0x2e, 0x8b, 0x4a, 0xe3, 0x2b, 0x08, 0x6b, 0x59, 0x08, 0x59,
+	0xe8, 0x75, 0x3c, 0xb2, 0xab, 0x8e, 0x63, 0x0c, 0xc1, 0x52, 0x54, 0x9a, 0x9e, 0x74, 0x3c, 0xb6,
+	0x2f, 0xd7, 0xd2, 0xd9, 0x4f, 0x30, 0xbc, 0xe4, 0x32, 0xdb, 0xcc, 0x44, 0x9b, 0x98, 0xd7, 0x49,
+	0x0c, 0xa1, 0xff, 0x28, 0x34, 0xb5, 0xc3, 0x6a, 0x70, 0xe7, 0xaa, 0x7e, 0xf7, 0xaa, 0xb3, 0x1f,
+	0x60, 0x78, 0xd1, 0x1d, 0xb0, 0x4a, 0x64, 0xa4, 0x62, 0x6f, 0xea, 0x9b, 0x3c, 0x2d, 0x31, 0x2e,
+	0xef, 0x89, 0xcb, 0x8a, 0xa4, 0x8a, 0x7b, 0x76, 0xc1, 0xf1, 0xd9, 0x1a, 0xc0, 0x6c, 0xbf, 0xb8,
+	0xe3, 0x55, 0x4e, 0xf8, 0x3d, 0x44, 0x4b, 0x8b, 0xba, 0x63, 0xf1, 0x62, 0x67, 0xa8, 0x37, 0x9d,
+	0x76, 0x32, 0x60, 0xe9, 0x30, 0xbe, 0x80, 0xc0, 0x88, 0x2d, 0x8a, 0xac, 0x71, 0x3d, 0x30, 0xf4,
+	0x7d, 0xd6, 0x8d, 0xc1, 0xdf, 0x89, 0xe1, 0xf4, 0x0d, 0x0c, 0xdd, 0xa7, 0x8a, 0x07, 0x10, 0x59,
+	0x72, 0x2d, 0x64, 0xc9, 0xef, 0xd9, 0x1e, 0x7e, 0x01, 0x07, 0xb6, 0xb0, 0xd5, 0x64, 0xde, 0xe9,
+	0x5f, 0x3d, 0x88, 0x3a, 0xb3, 0x89, 0x00, 0x83, 0x44, 0xe5, 0x97, 0x0f, 0x35, 0xdb, 0xc3, 0x08,
+	0x82, 0x44, 0xe5, 0x6f, 0x89, 0x6b, 0xe6, 0xe1, 0x04, 0x20, 0x51, 0xf9, 0x07, 0x29, 0x6a, 0xa1,
+	0x88, 0xf5, 0x70, 0x0c, 0xc3, 0x44, 0xe5, 0xe7, 0x75, 0x4d, 0x55, 0xc6, 0x7c, 0xfc, 0x12, 0x0e,
+	0x1d, 0x4d, 0x49, 0xd5, 0xa2, 0x52, 0xc4, 0xfa, 0x88, 0x30, 0x49, 0x54, 0x9e, 0xd2, 0x6f, 0x0f,
+	0xa4, 0xf4, 0x2f, 0x42, 0x13, 0xdb, 0xc7, 0x57, 0x70, 0xbc, 0x5b, 0x73, 0xfd, 0x03, 0x63, 0x3a,
+	0x51, 0x79, 0x3b, 0x50, 0x2c, 0x40, 0x06, 0x23, 0xe3, 0x87, 0xb8, 0xd4, 0xb7, 0xc6, 0x48, 0x88,
+	0x31, 0x1c, 0x75, 0x2b, 0x6e, 0xf3, 0xb0, 0xf1, 0x70, 0x23, 0x79, 0xa5, 0x56, 0x24, 0xaf, 0x88,
+	0x67, 0x24, 0x59, 0x84, 0x87, 0x30, 0x36, 0xe5, 0xa2, 0x24, 0xf1, 0xa0, 0xaf, 0xc5, 0xef, 0x6c,
+	0xe4, 0x2e, 0x43, 0xd6, 0xd2, 0x18, 0x8f, 0x01, 0xb7, 0xdc, 0x9d, 0x38, 0x69, 0xd4, 0x53, 0xe2,
+	0xd9, 0x7b, 0xf3, 0x1d, 0xb1, 0x83, 0x46, 0xdd, 0x55, 0x5c, 0x2f, 0x3b, 0x15, 0x30, 0xd9, 0x7d,
+	0x4d, 0x93, 0xdf, 0x79, 0x96, 0x5d, 0x8b, 0x8c, 0xd8, 0x9e, 0x91, 0x4c, 0xa9, 0x14, 0x8f, 0x64,
+	0xb9, 0x87, 0x23, 0x08, 0x13, 0xf1, 0x48, 0x1f, 0x88, 0x24, 0xeb, 0x99, 0x9c, 0xce, 0xb3, 0xec,
+	0x6a, 0x33, 0x43, 0xb6, 0xc3, 0x37, 0x3b, 0xde, 0x55, 0x9a, 0xe4, 0x8f, 0xa2, 0xa8, 0x34, 0xeb,
+	0x1b, 0x7e, 0x45, 0xfc, 0x91, 0x36, 0x7c, 0xff, 0x2d, 0xfb, 0xfb, 0xf9, 0xc4, 0xfb, 0xe7, 0xf9,
+	0xc4, 0xfb, 0xf7, 0xf9, 0xc4, 0xfb, 0xf3, 0xbf, 0x93, 0xbd, 0xdb, 0x81, 0xfd, 0xbd, 0x7f, 0xfb,
+	0xff, 0x00, 0xe1, 0x67, 0xe4, 0xec, 0xef, 0x05, 0x00, 0x00,
 }
@@ -1798,7 +1798,15 @@ type RegionHeartbeatRequest struct {
 	// working followers.
 	PendingPeers []*metapb.Peer `protobuf:"bytes,5,rep,name=pending_peers,json=pendingPeers" json:"pending_peers,omitempty"`
 	// Approximate region size.
-	ApproximateSize      uint64   `protobuf:"varint,10,opt,name=approximate_size,json=approximateSize,proto3" json:"approximate_size,omitempty"`
+	ApproximateSize uint64 `protobuf:"varint,10,opt,name=approximate_size,json=approximateSize,proto3" json:"approximate_size,omitempty"`
+	// Set when applying falls behind committing for a sustained period.
+	WriteStall bool `protobuf:"varint,11,opt,name=write_stall,json=writeStall,proto3" json:"write_stall,omitempty"`
+	// The raft term of the leader.
+	Term uint64 `protobuf:"varint,12,opt,name=term,proto3" json:"term,omitempty"`
+	// The committed index of the leader.
+	CommitIndex uint64 `protobuf:"varint,13,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	// The applied index of the leader.
+	AppliedIndex         uint64   `protobuf:"varint,14,opt,name=applied_index,json=appliedIndex,proto3" json:"applied_index,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1872,6 +1880,34 @@ func (m *RegionHeartbeatRequest) GetApproximateSize() uint64 {
 	return 0
 }
 
+func (m *RegionHeartbeatRequest) GetWriteStall() bool {
+	if m != nil {
+		return m.WriteStall
+	}
+	return false
+}
+
+func (m *RegionHeartbeatRequest) GetTerm() uint64 {
+	if m != nil {
+		return m.Term
+	}
+	return 0
+}
+
+func (m *RegionHeartbeatRequest) GetCommitIndex() uint64 {
+	if m != nil {
+		return m.CommitIndex
+	}
+	return 0
+}
+
+func (m *RegionHeartbeatRequest) GetAppliedIndex() uint64 {
+	if m != nil {
+		return m.AppliedIndex
+	}
+	return 0
+}
+
 type ChangePeer struct {
 	Peer                 *metapb.Peer           `protobuf:"bytes,1,opt,name=peer" json:"peer,omitempty"`
 	ChangeType           eraftpb.ConfChangeType `protobuf:"varint,2,opt,name=change_type,json=changeType,proto3,enum=eraftpb.ConfChangeType" json:"change_type,omitempty"`
@@ -5343,6 +5379,31 @@ func (m *RegionHeartbeatRequest) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintSchedulerpb(dAtA, i, uint64(m.ApproximateSize))
 	}
+	if m.WriteStall {
+		dAtA[i] = 0x58
+		i++
+		if m.WriteStall {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Term != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintSchedulerpb(dAtA, i, uint64(m.Term))
+	}
+	if m.CommitIndex != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintSchedulerpb(dAtA, i, uint64(m.CommitIndex))
+	}
+	if m.AppliedIndex != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintSchedulerpb(dAtA, i, uint64(m.AppliedIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -6861,6 +6922,18 @@ func (m *RegionHeartbeatRequest) Size() (n int) {
 	if m.ApproximateSize != 0 {
 		n += 1 + sovSchedulerpb(uint64(m.ApproximateSize))
 	}
+	if m.WriteStall {
+		n += 2
+	}
+	if m.Term != 0 {
+		n += 1 + sovSchedulerpb(uint64(m.Term))
+	}
+	if m.CommitIndex != 0 {
+		n += 1 + sovSchedulerpb(uint64(m.CommitIndex))
+	}
+	if m.AppliedIndex != 0 {
+		n += 1 + sovSchedulerpb(uint64(m.AppliedIndex))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -10883,6 +10956,83 @@ func (m *RegionHeartbeatRequest) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WriteStall", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchedulerpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.WriteStall = bool(v != 0)
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchedulerpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitIndex", wireType)
+			}
+			m.CommitIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchedulerpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CommitIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedIndex", wireType)
+			}
+			m.AppliedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchedulerpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AppliedIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSchedulerpb(dAtA[iNdEx:])
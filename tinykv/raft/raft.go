@@ -37,12 +37,14 @@ const (
 	StateFollower StateType = iota
 	StateCandidate
 	StateLeader
+	StatePreCandidate
 )
 
 var stmap = [...]string{
 	"StateFollower",
 	"StateCandidate",
 	"StateLeader",
+	"StatePreCandidate",
 }
 
 func (st StateType) String() string {
@@ -53,6 +55,11 @@ func (st StateType) String() string {
 // so that the proposer can be notified and fail fast.
 var ErrProposalDropped = errors.New("raft proposal dropped")
 
+// ErrConfChangeProposalDropped is returned when a conf change is proposed to a node
+// which is not the leader. Conf changes are not forwarded to the leader, so the
+// caller needs to find the leader and propose it again.
+var ErrConfChangeProposalDropped = errors.New("raft conf change proposal dropped as the node is not leader")
+
 // lockedRand is a small wrapper around rand.Rand to provide
 // synchronization among multiple raft groups. Only the methods needed
 // by the code are exposed (e.g. Intn).
@@ -82,6 +89,9 @@ type Config struct {
 	// previous configuration will panic if peers is set. peer is private and only
 	// used for testing right now.
 	peers []uint64
+	// learners contains the IDs of all learner nodes in the raft cluster, it's
+	// set like peers. learners is private and only used for testing right now.
+	learners []uint64
 
 	// ElectionTick is the number of Node.Tick invocations that must pass between
 	// elections. That is, if a follower does not receive any message from the
@@ -105,6 +115,38 @@ type Config struct {
 	// Applied. If Applied is unset when restarting, raft might return previous
 	// applied entries. This is a very application dependent configuration.
 	Applied uint64
+
+	// MaxLeaderTransferAttempts is the max number of consecutive leader transfers
+	// to the same transferee which don't finish in an election timeout. Once it's
+	// reached, the later transfers to the transferee are rejected until it catches
+	// up with the leader's log, so a transferee which keeps lagging behind doesn't
+	// block the proposals again and again. Zero means no limit.
+	MaxLeaderTransferAttempts int
+
+	// Priority is the election priority of this node, nodes with higher priority
+	// are preferred to become leader. A node with higher priority rejects to vote
+	// for a candidate with lower priority if its log is as up-to-date as the
+	// candidate's, so it can campaign and win instead, and a candidate defers to
+	// a candidate with higher priority in the same term. All nodes have the same
+	// priority by default.
+	Priority uint64
+
+	// Rand is the source of the randomized election timeouts. A seeded source
+	// makes the election timing reproducible in tests. The process-global source
+	// is used if it's nil.
+	Rand *rand.Rand
+
+	// StateHistorySize is the number of the latest SoftState transitions recorded
+	// for debugging, e.g. the leadership flapping. The history is reported by
+	// Status. Zero means no history is recorded.
+	StateHistorySize int
+
+	// PreVote enables the Pre-Vote algorithm described in raft thesis section
+	// 9.6. A node asks whether it could win an election before it increases its
+	// term, and the nodes which have heard from a leader in the election timeout
+	// refuse it. This prevents a partitioned node from disrupting the cluster
+	// when it rejoins.
+	PreVote bool
 }
 
 func (c *Config) validate() error {
@@ -124,6 +166,14 @@ func (c *Config) validate() error {
 		return errors.New("storage cannot be nil")
 	}
 
+	if c.MaxLeaderTransferAttempts < 0 {
+		return errors.New("max leader transfer attempts must not be negative")
+	}
+
+	if c.StateHistorySize < 0 {
+		return errors.New("state history size must not be negative")
+	}
+
 	return nil
 }
 
@@ -138,6 +188,13 @@ type Raft struct {
 
 	// log replication progress of each peers
 	Prs map[uint64]*Progress
+	// log replication progress of the learners, which receive the log entries
+	// but are never counted in the quorum of the votes or the commit.
+	learnerPrs map[uint64]*Progress
+	// the voters of the old and the new configurations during a joint consensus
+	// membership change, Prs tracks the voters of both of them in the joint state.
+	// nil if the node isn't in the joint state.
+	joint *jointConfig
 
 	// this peer's role
 	State StateType
@@ -158,10 +215,23 @@ type Raft struct {
 	// randomizedElectionTimeout is a random number between
 	// [electiontimeout, 2 * electiontimeout - 1].
 	randomizedElectionTimeout int
+	// rand is the source of randomizedElectionTimeout.
+	rand *lockedRand
 
 	// leadTransferee is id of the leader transfer target when its value is not zero.
 	// Follow the procedure defined in raft thesis 3.10.
 	leadTransferee uint64
+	// failedTransferee is the transferee of the last leader transfer which timed out,
+	// and failedTransferAttempts is the number of consecutive timed out transfers to it.
+	failedTransferee          uint64
+	failedTransferAttempts    int
+	maxLeaderTransferAttempts int
+
+	// election priority of this peer
+	priority uint64
+
+	// whether a pre-vote round is run before each election
+	preVote bool
 
 	// Only one conf change may be pending (in the log, but not yet
 	// applied) at a time. This is enforced via PendingConfIndex, which
@@ -177,6 +247,28 @@ type Raft struct {
 	// number of ticks since it reached last heartbeatTimeout.
 	// only leader keeps heartbeatElapsed.
 	heartbeatElapsed int
+
+	// number of ticks since this peer started, it's used as the clock of commitLatency.
+	ticks uint64
+	// the ticks when the proposed entries which are not committed yet were appended,
+	// in the order of the entry index. only leader keeps proposeTicks.
+	proposeTicks  []proposeTick
+	commitLatency CommitLatency
+
+	// the latest SoftState transitions, nil if they're not recorded.
+	stateHistory *stateHistory
+
+	// the tick of the latest response of each peer in the current term, a response
+	// confirms the leadership. only leader keeps leaseAcks.
+	leaseAcks map[uint64]uint64
+
+	// the read index requests waiting for the leadership to be confirmed.
+	readOnly *readOnly
+	// the read index requests received before the leader commits an entry of its
+	// term, the commit index may be behind the last leader's until then.
+	pendingReadIndexMessages []pb.Message
+	// the read states of the confirmed read index requests, which are taken by Ready.
+	readStates []ReadState
 }
 
 // newRaft return a raft peer with the given config
@@ -189,24 +281,42 @@ func newRaft(c *Config) *Raft {
 	if err != nil {
 		panic(err)
 	}
-	peers := c.peers
-	if len(cs.Nodes) > 0 {
-		if len(peers) > 0 {
-			panic("cannot specify both newRaft (peers) and ConfState.(Nodes)")
+	peers, learners := c.peers, c.learners
+	if len(cs.Nodes) > 0 || len(cs.Learners) > 0 {
+		if len(peers) > 0 || len(learners) > 0 {
+			panic("cannot specify both newRaft (peers, learners) and ConfState.(Nodes, Learners)")
 		}
-		peers = cs.Nodes
+		peers, learners = cs.Nodes, cs.Learners
 	}
 	r := &Raft{
 		id:               c.ID,
 		Lead:             None,
 		RaftLog:          raftlog,
 		Prs:              make(map[uint64]*Progress),
+		learnerPrs:       make(map[uint64]*Progress),
 		electionTimeout:  c.ElectionTick,
 		heartbeatTimeout: c.HeartbeatTick,
+
+		maxLeaderTransferAttempts: c.MaxLeaderTransferAttempts,
+		priority:                  c.Priority,
+		preVote:                   c.PreVote,
+		rand:                      globalRand,
+	}
+	if c.Rand != nil {
+		r.rand = &lockedRand{rand: c.Rand}
+	}
+	if c.StateHistorySize > 0 {
+		r.stateHistory = newStateHistory(c.StateHistorySize)
 	}
 	for _, p := range peers {
 		r.Prs[p] = &Progress{Next: 1}
 	}
+	for _, p := range learners {
+		if _, ok := r.Prs[p]; ok {
+			panic(fmt.Sprintf("node %d is in both peers and learners", p))
+		}
+		r.learnerPrs[p] = &Progress{Next: 1}
+	}
 
 	if !IsEmptyHardState(hs) {
 		r.loadState(hs)
@@ -235,6 +345,13 @@ func (r *Raft) softState() *SoftState {
 	return &SoftState{Lead: r.Lead, RaftState: r.State}
 }
 
+// recordStateTransition records the current SoftState in the state history if it's enabled.
+func (r *Raft) recordStateTransition() {
+	if r.stateHistory != nil {
+		r.stateHistory.record(StateTransition{SoftState: *r.softState(), Term: r.Term})
+	}
+}
+
 // hardState return the hardState of this peer
 func (r *Raft) hardState() pb.HardState {
 	return pb.HardState{
@@ -244,41 +361,243 @@ func (r *Raft) hardState() pb.HardState {
 	}
 }
 
-func (r *Raft) quorum() int { return len(r.Prs)/2 + 1 }
+// jointConfig is the configurations a joint consensus membership change is
+// between, a decision needs the majorities of both of them in the joint state.
+type jointConfig struct {
+	outgoing map[uint64]struct{}
+	incoming map[uint64]struct{}
+}
+
+func newVoterSet(ids []uint64) map[uint64]struct{} {
+	voters := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		voters[id] = struct{}{}
+	}
+	return voters
+}
+
+// enterJoint enters the joint state of changing the configuration from the
+// outgoing voters to the incoming voters, the progress of the voters in
+// either configuration is tracked.
+func (r *Raft) enterJoint(outgoing, incoming []uint64) {
+	r.joint = &jointConfig{outgoing: newVoterSet(outgoing), incoming: newVoterSet(incoming)}
+	for _, ids := range [][]uint64{outgoing, incoming} {
+		for _, id := range ids {
+			r.addNode(id, false)
+		}
+	}
+}
+
+// leaveJoint leaves the joint state, only the voters of the incoming
+// configuration are kept.
+func (r *Raft) leaveJoint() {
+	if r.joint == nil {
+		return
+	}
+	for id := range r.Prs {
+		if _, ok := r.joint.incoming[id]; !ok {
+			delete(r.Prs, id)
+		}
+	}
+	r.joint = nil
+	if r.State != StateLeader {
+		return
+	}
+	// Only the incoming configuration decides the commit index now, so see if any
+	// pending entries can be committed.
+	if r.maybeCommit() {
+		r.bcastAppend()
+	}
+	if _, ok := r.Prs[r.leadTransferee]; r.leadTransferee != None && !ok {
+		r.abortLeaderTransfer()
+	}
+}
+
+// voteResult is the result of an election by the votes received so far.
+type voteResult int
+
+const (
+	votePending voteResult = iota
+	voteWon
+	voteLost
+)
+
+// configVoteResult returns the result of the votes of the voters, all the
+// voters are in Prs if it's nil. The learners are not in Prs, so their votes
+// are never counted.
+func (r *Raft) configVoteResult(voters map[uint64]struct{}) voteResult {
+	n, granted, rejected := 0, 0, 0
+	for id := range r.Prs {
+		if voters != nil {
+			if _, ok := voters[id]; !ok {
+				continue
+			}
+		}
+		n++
+		if v, ok := r.votes[id]; ok {
+			if v {
+				granted++
+			} else {
+				rejected++
+			}
+		}
+	}
+	q := n/2 + 1
+	switch {
+	case granted >= q:
+		return voteWon
+	case rejected >= q:
+		return voteLost
+	}
+	return votePending
+}
+
+// electionResult returns the result of the election. In the joint state, it's
+// won only if both the outgoing and the incoming configurations grant it, and
+// lost if either of them rejects it.
+func (r *Raft) electionResult() voteResult {
+	if r.joint == nil {
+		return r.configVoteResult(nil)
+	}
+	outgoing, incoming := r.configVoteResult(r.joint.outgoing), r.configVoteResult(r.joint.incoming)
+	switch {
+	case outgoing == voteWon && incoming == voteWon:
+		return voteWon
+	case outgoing == voteLost || incoming == voteLost:
+		return voteLost
+	}
+	return votePending
+}
+
+// handleElectionResult changes the state of the candidate by the result of the
+// election. It becomes the leader and broadcasts the result if the election is
+// won, or a follower if it's lost, otherwise it keeps waiting for the votes.
+func (r *Raft) handleElectionResult() {
+	switch r.electionResult() {
+	case voteWon:
+		r.becomeLeader()
+		r.bcastAppend()
+	case voteLost:
+		r.becomeFollower(r.Term, None)
+	}
+}
+
+// quorumValue returns the largest value which is reached by a majority of the
+// voters, all the voters are in Prs if it's nil.
+func (r *Raft) quorumValue(voters map[uint64]struct{}, value func(id uint64, pr *Progress) uint64) uint64 {
+	values := make(uint64Slice, 0, len(r.Prs))
+	for id, p := range r.Prs {
+		if voters != nil {
+			if _, ok := voters[id]; !ok {
+				continue
+			}
+		}
+		values = append(values, value(id, p))
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Sort(values)
+	return values[len(values)-(len(values)/2+1)]
+}
+
+// quorumMatchIndex returns the largest index which is matched by a majority
+// of the voters, all the voters are in Prs if it's nil.
+func (r *Raft) quorumMatchIndex(voters map[uint64]struct{}) uint64 {
+	return r.quorumValue(voters, func(_ uint64, pr *Progress) uint64 { return pr.Match })
+}
+
+// committedIndex returns the largest index which can be committed by the
+// matched indexes. In the joint state, the index needs to be matched by the
+// majorities of both the outgoing and the incoming configurations.
+func (r *Raft) committedIndex() uint64 {
+	if r.joint == nil {
+		return r.quorumMatchIndex(nil)
+	}
+	return min(r.quorumMatchIndex(r.joint.outgoing), r.quorumMatchIndex(r.joint.incoming))
+}
+
+// leaseExpiry returns the tick when the lease of the leader expires. The lease
+// starts from the latest tick by which a majority of the voters have confirmed
+// the leadership, the leader confirms itself at the current tick, and lasts for
+// an election timeout. It returns zero if the peer isn't the leader or no
+// majority has confirmed it yet.
+func (r *Raft) leaseExpiry() uint64 {
+	if r.State != StateLeader {
+		return 0
+	}
+	ackTick := func(id uint64, _ *Progress) uint64 {
+		if id == r.id {
+			return r.ticks
+		}
+		return r.leaseAcks[id]
+	}
+	var start uint64
+	if r.joint == nil {
+		start = r.quorumValue(nil, ackTick)
+	} else {
+		start = min(r.quorumValue(r.joint.outgoing, ackTick), r.quorumValue(r.joint.incoming, ackTick))
+	}
+	if start == 0 {
+		return 0
+	}
+	return start + uint64(r.electionTimeout)
+}
 
 // send persists state to stable storage and then sends to its mailbox.
 func (r *Raft) send(m pb.Message) {
 	m.From = r.id
-	if m.MsgType == pb.MessageType_MsgRequestVote || m.MsgType == pb.MessageType_MsgRequestVoteResponse {
+	switch m.MsgType {
+	case pb.MessageType_MsgRequestVote, pb.MessageType_MsgRequestVoteResponse,
+		pb.MessageType_MsgPreVote, pb.MessageType_MsgPreVoteResponse:
 		if m.Term == 0 {
 			// All campaign messages need to have the term set when sending.
 			// - MessageType_MsgRequestVote: m.Term is the term the node is campaigning for,
 			//   non-zero as we increment the term when campaigning.
 			// - MessageType_MsgRequestVoteResponse: m.Term is the new r.Term if the MessageType_MsgRequestVote was
 			//   granted, non-zero for the same reason MessageType_MsgRequestVote is
+			// - MessageType_MsgPreVote: m.Term is the term the node will campaign for, non-zero as it's
+			//   the current term plus one.
+			// - MessageType_MsgPreVoteResponse: m.Term is the term of the MessageType_MsgPreVote if it was
+			//   granted, otherwise the current term of the responder.
 			panic(fmt.Sprintf("term should be set when sending %s", m.MsgType))
 		}
-	} else {
+	default:
 		if m.Term != 0 {
 			panic(fmt.Sprintf("term should not be set when sending %s (was %d)", m.MsgType, m.Term))
 		}
-		// do not attach term to MessageType_MsgPropose
+		// do not attach term to MessageType_MsgPropose and MessageType_MsgReadIndex
 		// proposals are a way to forward to the leader and
 		// should be treated as local message.
-		if m.MsgType != pb.MessageType_MsgPropose {
+		if m.MsgType != pb.MessageType_MsgPropose && m.MsgType != pb.MessageType_MsgReadIndex {
 			m.Term = r.Term
 		}
 	}
 	r.msgs = append(r.msgs, m)
 }
 
+// getProgress returns the progress of the given voter or learner, nil if the
+// node isn't in the raft group.
 func (r *Raft) getProgress(id uint64) *Progress {
-	return r.Prs[id]
+	if pr, ok := r.Prs[id]; ok {
+		return pr
+	}
+	return r.learnerPrs[id]
+}
+
+// isLearner returns true if the given node is a learner.
+func (r *Raft) isLearner(id uint64) bool {
+	_, ok := r.learnerPrs[id]
+	return ok
 }
 
 // sendAppend sends an append RPC with new entries (if any) and the
 // current commit index to the given peer. Returns true if a message was sent.
 func (r *Raft) sendAppend(to uint64) bool {
+	if to == r.id {
+		// The leader never replicates to itself, its own entries are appended locally.
+		return false
+	}
 	pr := r.getProgress(to)
 	m := pb.Message{}
 	m.To = to
@@ -289,23 +608,7 @@ func (r *Raft) sendAppend(to uint64) bool {
 	log.Debug(fmt.Sprintf("The last entry's info: %d %v", term, ents))
 
 	if errt != nil || erre != nil { // send snapshot if we failed to get term or entries
-		m.MsgType = pb.MessageType_MsgSnapshot
-		snapshot, err := r.RaftLog.snapshot()
-		if err != nil {
-			if err == ErrSnapshotTemporarilyUnavailable {
-				log.Debug(fmt.Sprintf("%d failed to send snapshot to %d because snapshot is temporarily unavailable", r.id, to))
-				return false
-			}
-			panic(err)
-		}
-		if IsEmptySnap(&snapshot) {
-			panic("need non-empty snapshot")
-		}
-		m.Snapshot = &snapshot
-		sindex, sterm := snapshot.Metadata.Index, snapshot.Metadata.Term
-		log.Debug(fmt.Sprintf("%d [firstindex: %d, commit: %d] sent snapshot[index: %d, term: %d] to %d [%v]",
-			r.id, r.RaftLog.firstIndex(), r.RaftLog.committed, sindex, sterm, to, pr))
-		log.Debug(fmt.Sprintf("%d paused sending replication messages to %d [%v]", r.id, to, pr))
+		return r.sendSnapshot(to)
 	} else {
 		// Raft: Replication_Step2:::Send entries.
 		// You need to set the info of MsgAppend_message, which include logTerm, index, msgType, entries and commit.
@@ -319,8 +622,38 @@ func (r *Raft) sendAppend(to uint64) bool {
 	return true
 }
 
-// sendHeartbeat sends a heartbeat RPC to the given peer.
-func (r *Raft) sendHeartbeat(to uint64) {
+// sendSnapshot sends the snapshot of the leader to the given peer, it's used when
+// the entries the peer needs have been compacted. Returns true if a message was sent.
+func (r *Raft) sendSnapshot(to uint64) bool {
+	if to == r.id {
+		return false
+	}
+	pr := r.getProgress(to)
+	m := pb.Message{To: to, MsgType: pb.MessageType_MsgSnapshot}
+	snapshot, err := r.RaftLog.snapshot()
+	if err != nil {
+		if err == ErrSnapshotTemporarilyUnavailable {
+			log.Debug(fmt.Sprintf("%d failed to send snapshot to %d because snapshot is temporarily unavailable", r.id, to))
+			return false
+		}
+		panic(err)
+	}
+	if IsEmptySnap(&snapshot) {
+		panic("need non-empty snapshot")
+	}
+	m.Snapshot = &snapshot
+	sindex, sterm := snapshot.Metadata.Index, snapshot.Metadata.Term
+	log.Debug(fmt.Sprintf("%d [firstindex: %d, commit: %d] sent snapshot[index: %d, term: %d] to %d [%v]",
+		r.id, r.RaftLog.firstIndex(), r.RaftLog.committed, sindex, sterm, to, pr))
+	log.Debug(fmt.Sprintf("%d paused sending replication messages to %d [%v]", r.id, to, pr))
+	r.send(m)
+	return true
+}
+
+// sendHeartbeat sends a heartbeat RPC to the given peer. The context of a read
+// index request is attached if ctx isn't nil, the peer returns it in the response
+// to confirm the leadership for the request.
+func (r *Raft) sendHeartbeat(to uint64, ctx []byte) {
 	// Attach the commit as min(to.matched, r.committed).
 	// When the leader sends out heartbeat message,
 	// the receiver(follower) might not be matched with the leader
@@ -329,54 +662,127 @@ func (r *Raft) sendHeartbeat(to uint64) {
 	// an unmatched index.
 
 	// Raft: Leader_Election_Step7:::send heartbeat.
-	// You need to fill in pb.MessageType_MsgHeartbeat and send it. And don't forget to carry the commit index.
+	// You need to send the heartbeat built by r.heartbeatMessage(to, ctx), it carries the commit index
+	// and the read index context.
 	panic("Raft: Leader_Election_Step7:::Your code here.")
 
 
 
 }
 
+// heartbeatMessage builds the heartbeat to the given peer, the context of a read
+// index request is attached as the Context if ctx isn't nil.
+func (r *Raft) heartbeatMessage(to uint64, ctx []byte) pb.Message {
+	return pb.Message{
+		To:      to,
+		MsgType: pb.MessageType_MsgHeartbeat,
+		Commit:  r.heartbeatCommit(to),
+		Context: ctx,
+	}
+}
+
+// heartbeatCommit returns the commit index carried by the heartbeat to the given peer.
+// It's always attached, so an idle follower which has all the entries learns the
+// latest commit index from the heartbeats without waiting for new appends.
+func (r *Raft) heartbeatCommit(to uint64) uint64 {
+	return min(r.getProgress(to).Match, r.RaftLog.committed)
+}
+
+// forEachProgress calls f with the progress of each voter and learner.
 func (r *Raft) forEachProgress(f func(id uint64, pr *Progress)) {
 	for id, pr := range r.Prs {
 		f(id, pr)
 	}
+	for id, pr := range r.learnerPrs {
+		f(id, pr)
+	}
+}
+
+// maybeSendAppend sends an append RPC to the given peer if it has entries to
+// receive, or sendIfEmpty is true. Returns true if a message was sent.
+func (r *Raft) maybeSendAppend(to uint64, sendIfEmpty bool) bool {
+	pr := r.getProgress(to)
+	if !sendIfEmpty && pr.Next > r.RaftLog.LastIndex() {
+		return false
+	}
+	return r.sendAppend(to)
 }
 
 // bcastAppend sends RPC, with entries to all peers that are not up-to-date
-// according to the progress recorded in r.Prs.
+// according to the progress recorded in r.Prs and r.learnerPrs.
 func (r *Raft) bcastAppend() {
+	r.bcastAppendIfNeeded(true)
+}
+
+// bcastAppendIfNeeded sends RPC to the peers that have entries to receive. The
+// peers which are already up-to-date are skipped unless commitAdvanced is true,
+// in which case they need to learn the new commit index.
+func (r *Raft) bcastAppendIfNeeded(commitAdvanced bool) {
 	r.forEachProgress(func(id uint64, _ *Progress) {
 		if id == r.id {
 			return
 		}
 
-		r.sendAppend(id)
+		r.maybeSendAppend(id, commitAdvanced)
 	})
 }
 
-// bcastHeartbeat sends RPC, without entries to all the peers.
+// bcastHeartbeat sends RPC, without entries to all the peers. The heartbeats
+// confirm the last pending read index request, and the ones before it.
 func (r *Raft) bcastHeartbeat() {
+	r.bcastHeartbeatWithCtx(r.readOnly.lastPendingRequestCtx())
+}
+
+func (r *Raft) bcastHeartbeatWithCtx(ctx []byte) {
 	r.forEachProgress(func(id uint64, _ *Progress) {
 		if id == r.id {
 			return
 		}
-		r.sendHeartbeat(id)
+		r.sendHeartbeat(id, ctx)
 	})
 }
 
 // maybeCommit attempts to advance the commit index. Returns true if
 // the commit index changed (in which case the caller should call
-// r.bcastAppend).
+// r.bcastAppend). Only the voters' match indexes are counted, the
+// learners can't commit an entry.
 func (r *Raft) maybeCommit() bool {
-	matchIndex := make(uint64Slice, len(r.Prs))
-	idx := 0
-	for _, p := range r.Prs {
-		matchIndex[idx] = p.Match
-		idx++
+	mci := r.committedIndex()
+	if !r.RaftLog.maybeCommit(mci, r.Term) {
+		return false
+	}
+	r.observeCommitLatency()
+	r.releasePendingReadIndexMessages()
+	return true
+}
+
+// commitAndBcastAppend attempts to advance the commit index after a match index
+// is updated, and sends the appends to the peers. The peers which are up-to-date
+// only need them to learn the new commit index, so they are skipped if it doesn't
+// advance. Returns true if the commit index changed.
+func (r *Raft) commitAndBcastAppend() bool {
+	commitAdvanced := r.maybeCommit()
+	r.bcastAppendIfNeeded(commitAdvanced)
+	return commitAdvanced
+}
+
+// trackProposals records the current tick as the propose time of the n entries
+// which are going to be appended after the last index.
+func (r *Raft) trackProposals(n int) {
+	li := r.RaftLog.LastIndex()
+	for i := 1; i <= n; i++ {
+		r.proposeTicks = append(r.proposeTicks, proposeTick{index: li + uint64(i), tick: r.ticks})
+	}
+}
+
+// observeCommitLatency records the commit latency of the proposed entries which
+// have been committed.
+func (r *Raft) observeCommitLatency() {
+	i := 0
+	for ; i < len(r.proposeTicks) && r.proposeTicks[i].index <= r.RaftLog.committed; i++ {
+		r.commitLatency.observe(r.ticks - r.proposeTicks[i].tick)
 	}
-	sort.Sort(matchIndex)
-	mci := matchIndex[len(matchIndex)-r.quorum()]
-	return r.RaftLog.maybeCommit(mci, r.Term)
+	r.proposeTicks = r.proposeTicks[i:]
 }
 
 func (r *Raft) reset(term uint64) {
@@ -391,6 +797,8 @@ func (r *Raft) reset(term uint64) {
 	r.resetRandomizedElectionTimeout()
 
 	r.abortLeaderTransfer()
+	r.failedTransferee = None
+	r.failedTransferAttempts = 0
 
 	r.votes = make(map[uint64]bool)
 	r.forEachProgress(func(id uint64, pr *Progress) {
@@ -401,9 +809,15 @@ func (r *Raft) reset(term uint64) {
 	})
 
 	r.PendingConfIndex = 0
+	r.proposeTicks = nil
+	r.leaseAcks = make(map[uint64]uint64)
+	r.readOnly = newReadOnly()
+	r.pendingReadIndexMessages = nil
 }
 
-func (r *Raft) appendEntry(es ...pb.Entry) {
+// appendEntry appends the entries to the leader's log. Returns true if the
+// commit index changed.
+func (r *Raft) appendEntry(es ...pb.Entry) bool {
 	li := r.RaftLog.LastIndex()
 	// Raft: Replication_Step1:::Append entries.
 	// You need to set the term and the index of the entries.
@@ -414,14 +828,15 @@ func (r *Raft) appendEntry(es ...pb.Entry) {
 	// use latest "last" index after truncate/append
 	li = r.RaftLog.append(es...)
 	r.getProgress(r.id).maybeUpdate(li)
-	// Regardless of maybeCommit's return, our caller will call bcastAppend.
-	r.maybeCommit()
+	// The caller broadcasts the new entries, and the new commit index if it changed.
+	return r.maybeCommit()
 }
 
 // tick advances the internal logical clock by a single tick.
 func (r *Raft) tick() {
+	r.ticks++
 	switch r.State {
-	case StateFollower, StateCandidate:
+	case StateFollower, StateCandidate, StatePreCandidate:
 		r.tickElection()
 	case StateLeader:
 		r.tickHeartbeat()
@@ -455,7 +870,7 @@ func (r *Raft) tickHeartbeat() {
 		r.electionElapsed = 0
 		// If current leader cannot transfer leadership in electionTimeout, it becomes leader again.
 		if r.State == StateLeader && r.leadTransferee != None {
-			r.abortLeaderTransfer()
+			r.leaderTransferTimeout()
 		}
 	}
 
@@ -471,12 +886,29 @@ func (r *Raft) tickHeartbeat() {
 
 // becomeFollower transform this peer's state to Follower
 func (r *Raft) becomeFollower(term uint64, lead uint64) {
+	if r.State == StateCandidate || r.State == StatePreCandidate {
+		r.abortCampaign()
+	}
 	r.reset(term)
 	r.Lead = lead
 	r.State = StateFollower
+	r.recordStateTransition()
 	log.Info(fmt.Sprintf("%d became follower at term %d", r.id, r.Term))
 }
 
+// abortCampaign drops the vote requests of the campaign which haven't been
+// sent yet, they can't make this peer win once it steps down. The votes it has
+// received are cleared by reset.
+func (r *Raft) abortCampaign() {
+	var msgs []pb.Message
+	for _, m := range r.msgs {
+		if m.MsgType != pb.MessageType_MsgRequestVote && m.MsgType != pb.MessageType_MsgPreVote {
+			msgs = append(msgs, m)
+		}
+	}
+	r.msgs = msgs
+}
+
 // becomeCandidate transform this peer's state to candidate
 func (r *Raft) becomeCandidate() {
 	// Raft: Leader_Election_Step3:::becomeCandidate.
@@ -485,9 +917,21 @@ func (r *Raft) becomeCandidate() {
 
 
 
+	r.recordStateTransition()
 	log.Info(fmt.Sprintf("%d became candidate at term %d", r.id, r.Term))
 }
 
+// becomePreCandidate transform this peer's state to pre-candidate. Unlike a
+// candidate, the term and the vote are unchanged, so the pre-vote round doesn't
+// disturb the cluster if this peer can't win the election.
+func (r *Raft) becomePreCandidate() {
+	r.votes = make(map[uint64]bool)
+	r.Lead = None
+	r.State = StatePreCandidate
+	r.recordStateTransition()
+	log.Info(fmt.Sprintf("%d became pre-candidate at term %d", r.id, r.Term))
+}
+
 // becomeLeader transform this peer's state to leader
 func (r *Raft) becomeLeader() {
 	// NOTE: Leader should propose a noop entry on its term
@@ -504,15 +948,44 @@ func (r *Raft) becomeLeader() {
 
 	emptyEnt := pb.Entry{Data: nil}
 	r.appendEntry(emptyEnt)
+	r.recordStateTransition()
 	log.Info(fmt.Sprintf("%d became leader at term %d", r.id, r.Term))
 }
 
-func (r *Raft) campaign() {
-	r.becomeCandidate()
-	voteMsg := pb.MessageType_MsgRequestVote
-	term := r.Term
+// campaignType represents the type of campaigning.
+type campaignType int
+
+const (
+	// campaignPreElection represents the pre-vote round before an election when
+	// Config.PreVote is true.
+	campaignPreElection campaignType = iota
+	// campaignElection represents an election, it's run directly by a leader
+	// transfer as the followers still have the leader and refuse the pre-votes.
+	campaignElection
+)
 
-	if r.quorum() == r.poll(r.id, pb.MessageType_MsgRequestVoteResponse, true) {
+func (r *Raft) campaign(t campaignType) {
+	var voteMsg pb.MessageType
+	var term uint64
+	if t == campaignPreElection {
+		r.becomePreCandidate()
+		voteMsg = pb.MessageType_MsgPreVote
+		// The pre-votes are asked for the next term without increasing our term.
+		term = r.Term + 1
+	} else {
+		r.becomeCandidate()
+		voteMsg = pb.MessageType_MsgRequestVote
+		term = r.Term
+	}
+
+	r.poll(r.id, voteRespMsgType(voteMsg), true)
+	if r.electionResult() == voteWon {
+		if t == campaignPreElection {
+			// A single-node cluster wins the pre-vote round at once, so it starts
+			// the election immediately.
+			r.campaign(campaignElection)
+			return
+		}
 		// Raft: Leader_Election_Step4:::becomeLeader.
 		// We won the election after voting for ourselves (which must mean that
 		// this is a single-node cluster). Advance to the next state.
@@ -521,24 +994,35 @@ func (r *Raft) campaign() {
 
 		return
 	}
+	// The vote requests are only sent to the voters, the learners are not in Prs.
 	for id := range r.Prs {
 		if id == r.id {
 			continue
 		}
 		log.Info(fmt.Sprintf("%d [logterm: %d, index: %d] sent %s request to %d at term %d", r.id,
-			r.RaftLog.lastTerm(), r.RaftLog.LastIndex(), voteMsg, id, r.Term))
+			r.RaftLog.lastTerm(), r.RaftLog.LastIndex(), voteMsg, id, term))
 
-		r.send(pb.Message{Term: term, To: id, MsgType: voteMsg, Index: r.RaftLog.LastIndex(), LogTerm: r.RaftLog.lastTerm()})
+		r.send(pb.Message{Term: term, To: id, MsgType: voteMsg, Index: r.RaftLog.LastIndex(), LogTerm: r.RaftLog.lastTerm(),
+			Priority: r.priority})
 	}
 }
 
+// preferSelf returns true if this node has higher priority than the candidate of
+// the vote request and has the same log as the candidate, so it can win the
+// election with the vote of the candidate.
+func (r *Raft) preferSelf(m pb.Message) bool {
+	return r.priority > m.Priority && r.promotable() &&
+		m.LogTerm == r.RaftLog.lastTerm() && m.Index == r.RaftLog.LastIndex()
+}
+
 func (r *Raft) poll(id uint64, t pb.MessageType, v bool) (granted int) {
 	if v {
 		log.Info(fmt.Sprintf("%d received %s from %d at term %d", r.id, t, id, r.Term))
 	} else {
 		log.Info(fmt.Sprintf("%d received %s rejection from %d at term %d", r.id, t, id, r.Term))
 	}
-	if _, ok := r.votes[id]; !ok {
+	// A learner isn't a voter, its vote is never counted.
+	if _, ok := r.votes[id]; !ok && !r.isLearner(id) {
 		r.votes[id] = v
 	}
 	for _, vv := range r.votes {
@@ -559,18 +1043,34 @@ func (r *Raft) Step(m pb.Message) error {
 	case m.Term > r.Term:
 		log.Info(fmt.Sprintf("%d [term: %d] received a %s message with higher term from %d [term: %d]",
 			r.id, r.Term, m.MsgType, m.From, m.Term))
-		if m.MsgType == pb.MessageType_MsgAppend || m.MsgType == pb.MessageType_MsgHeartbeat || m.MsgType == pb.MessageType_MsgSnapshot {
+		switch {
+		case m.MsgType == pb.MessageType_MsgPreVote:
+			// Never change our term in response to a pre-vote.
+		case m.MsgType == pb.MessageType_MsgPreVoteResponse && !m.Reject:
+			// The pre-votes are asked for the next term, the term is increased when
+			// the pre-candidate wins. A rejection carries the term of the rejecting
+			// node, so we become a follower at that term.
+		case m.MsgType == pb.MessageType_MsgAppend || m.MsgType == pb.MessageType_MsgHeartbeat || m.MsgType == pb.MessageType_MsgSnapshot:
 			r.becomeFollower(m.Term, m.From)
-		} else {
+		default:
 			r.becomeFollower(m.Term, None)
 		}
 	case m.Term < r.Term:
 		log.Info(fmt.Sprintf("%d [term: %d] ignored a %s message with lower term from %d [term: %d]", r.id, r.Term, m.MsgType, m.From, m.Term))
+		if m.MsgType == pb.MessageType_MsgPreVote {
+			// Reject it with our term, or the pre-candidate which is behind keeps
+			// waiting for the responses.
+			r.send(pb.Message{To: m.From, Term: r.Term, MsgType: pb.MessageType_MsgPreVoteResponse, Reject: true})
+		}
 		return nil
 	}
 
 	switch m.MsgType {
 	case pb.MessageType_MsgHup:
+		if r.isLearner(r.id) {
+			log.Debug(fmt.Sprintf("%d is learner and can not campaign at term %d", r.id, r.Term))
+			return nil
+		}
 		if r.State != StateLeader {
 			ents, err := r.RaftLog.slice(r.RaftLog.applied+1, r.RaftLog.committed+1)
 			if err != nil {
@@ -583,12 +1083,24 @@ func (r *Raft) Step(m pb.Message) error {
 
 			log.Info(fmt.Sprintf("%d is starting a new election at term %d", r.id, r.Term))
 
-			r.campaign()
+			if r.preVote {
+				r.campaign(campaignPreElection)
+			} else {
+				r.campaign(campaignElection)
+			}
 		} else {
 			log.Debug(fmt.Sprintf("%d ignoring MessageType_MsgHup because already leader", r.id))
 		}
 
 	case pb.MessageType_MsgRequestVote:
+		// A candidate defers to the candidate with higher priority in the same term, it stops
+		// campaigning and waits for an election timeout, so the other one can win the next
+		// election. The vote of this term is still kept.
+		if r.State == StateCandidate && m.Priority > r.priority {
+			log.Info(fmt.Sprintf("%d [priority: %d] defers to candidate %d [priority: %d] at term %d",
+				r.id, r.priority, m.From, m.Priority, r.Term))
+			r.becomeFollower(r.Term, None)
+		}
 		canVote := false
 
 		// Raft: Leader_Election_Step5:::Voting or not.
@@ -597,8 +1109,8 @@ func (r *Raft) Step(m pb.Message) error {
 		panic("Raft: Leader_Election_Step5:::Your code here.")
 
 
-		// ...and we believe the candidate is up to date.
-		if canVote && r.RaftLog.isUpToDate(m.Index, m.LogTerm) {
+		// ...and we believe the candidate is up to date, and we can't win the election instead.
+		if canVote && r.RaftLog.isUpToDate(m.Index, m.LogTerm) && !r.preferSelf(m) {
 			log.Info(fmt.Sprintf("%d [logterm: %d, index: %d, vote: %d] cast %s for %d [logterm: %d, index: %d] at term %d",
 				r.id, r.RaftLog.lastTerm(), r.RaftLog.LastIndex(), r.Vote, m.MsgType, m.From, m.LogTerm, m.Index, r.Term))
 			r.send(pb.Message{To: m.From, Term: m.Term, MsgType: pb.MessageType_MsgRequestVoteResponse})
@@ -611,6 +1123,20 @@ func (r *Raft) Step(m pb.Message) error {
 			r.send(pb.Message{To: m.From, Term: r.Term, MsgType: pb.MessageType_MsgRequestVoteResponse, Reject: true})
 		}
 
+	case pb.MessageType_MsgPreVote:
+		// A pre-vote is granted if the candidate is up to date and we haven't heard from a
+		// leader in the election timeout, even if its term is higher. The term and the vote
+		// are unchanged.
+		if m.Term > r.Term && !r.hasLeaderInTimeout() && r.RaftLog.isUpToDate(m.Index, m.LogTerm) && !r.preferSelf(m) {
+			log.Info(fmt.Sprintf("%d [logterm: %d, index: %d, vote: %d] cast %s for %d [logterm: %d, index: %d] at term %d",
+				r.id, r.RaftLog.lastTerm(), r.RaftLog.LastIndex(), r.Vote, m.MsgType, m.From, m.LogTerm, m.Index, r.Term))
+			r.send(pb.Message{To: m.From, Term: m.Term, MsgType: pb.MessageType_MsgPreVoteResponse})
+		} else {
+			log.Info(fmt.Sprintf("%d [logterm: %d, index: %d, vote: %d, lead: %d] rejected %s from %d [logterm: %d, index: %d] at term %d",
+				r.id, r.RaftLog.lastTerm(), r.RaftLog.LastIndex(), r.Vote, r.Lead, m.MsgType, m.From, m.LogTerm, m.Index, r.Term))
+			r.send(pb.Message{To: m.From, Term: r.Term, MsgType: pb.MessageType_MsgPreVoteResponse, Reject: true})
+		}
+
 	default:
 		switch r.State {
 		case StateFollower:
@@ -618,7 +1144,7 @@ func (r *Raft) Step(m pb.Message) error {
 			if err != nil {
 				return err
 			}
-		case StateCandidate:
+		case StateCandidate, StatePreCandidate:
 			err := r.stepCandidate(m)
 			if err != nil {
 				return err
@@ -638,7 +1164,8 @@ type stepFunc func(r *Raft, m pb.Message) error
 // stepLeader handle leader's message
 func (r *Raft) stepLeader(m pb.Message) error {
 	pr := r.getProgress(m.From)
-	if pr == nil && m.MsgType != pb.MessageType_MsgBeat && m.MsgType != pb.MessageType_MsgPropose {
+	if pr == nil && m.MsgType != pb.MessageType_MsgBeat && m.MsgType != pb.MessageType_MsgPropose &&
+		m.MsgType != pb.MessageType_MsgReadIndex {
 		log.Debug(fmt.Sprintf("%d no progress available for %d", r.id, m.From))
 		return nil
 	}
@@ -678,21 +1205,41 @@ func (r *Raft) stepLeader(m pb.Message) error {
 			es = append(es, *e)
 		}
 
-		r.appendEntry(es...)
-		r.bcastAppend()
+		r.trackProposals(len(es))
+		commitAdvanced := r.appendEntry(es...)
+		r.bcastAppendIfNeeded(commitAdvanced)
+		return nil
+	case pb.MessageType_MsgReadIndex:
+		if !r.committedEntryInCurrentTerm() {
+			// The read waits until an entry of this term is committed, the commit index
+			// may be behind the one of the last leader before that.
+			r.pendingReadIndexMessages = append(r.pendingReadIndexMessages, m)
+			return nil
+		}
+		r.handleReadIndex(m)
 		return nil
 	case pb.MessageType_MsgAppendResponse:
+		r.leaseAcks[m.From] = r.ticks
 		if m.Reject {
 			log.Debug(fmt.Sprintf("%d received MessageType_MsgAppend rejection(lastindex: %d) from %d for index %d",
 				r.id, m.RejectHint, m.From, m.Index))
 			if pr.maybeDecrTo(m.Index, m.RejectHint) {
-				r.sendAppend(m.From)
+				if m.RejectHint+1 < r.RaftLog.firstIndex() {
+					// The follower's last entry is behind the compaction point, probing
+					// backward can't find a match in the log, so send a snapshot directly.
+					log.Debug(fmt.Sprintf("%d [firstindex: %d] found %d is behind the compaction point (lastindex: %d)",
+						r.id, r.RaftLog.firstIndex(), m.From, m.RejectHint))
+					r.sendSnapshot(m.From)
+				} else {
+					r.sendAppend(m.From)
+				}
 			}
 		} else {
 			if pr.maybeUpdate(m.Index) {
 				// Raft: Replication_Step3:::Broadcast commit index.
 				// You need to determine if the current log entry needs to be committed. And broadcast the result if the
-				// entry need to be committed.
+				// entry need to be committed. Use r.commitAndBcastAppend(), it skips the peers which are up-to-date
+				// when the commit index doesn't advance.
 				panic("Raft: Replication_Step3:::Your code here.")
 
 
@@ -704,9 +1251,15 @@ func (r *Raft) stepLeader(m pb.Message) error {
 			}
 		}
 	case pb.MessageType_MsgHeartbeatResponse:
+		r.leaseAcks[m.From] = r.ticks
 		if pr.Match < r.RaftLog.LastIndex() {
 			r.sendAppend(m.From)
 		}
+		if len(m.Context) > 0 {
+			if acks := r.readOnly.recvAck(m.From, m.Context); acks != nil {
+				r.maybeConfirmReadIndex(acks, m.Context)
+			}
+		}
 	case pb.MessageType_MsgTransferLeader:
 		leadTransferee := m.From
 		lastLeadTransferee := r.leadTransferee
@@ -723,6 +1276,18 @@ func (r *Raft) stepLeader(m pb.Message) error {
 			log.Debug(fmt.Sprintf("%d is already leader. Ignored transferring leadership to self", r.id))
 			return nil
 		}
+		if r.isLearner(leadTransferee) {
+			log.Debug(fmt.Sprintf("%d is learner. Ignored transferring leadership", leadTransferee))
+			return nil
+		}
+		if pr.Match == r.RaftLog.LastIndex() {
+			r.failedTransferee, r.failedTransferAttempts = None, 0
+		} else if r.maxLeaderTransferAttempts > 0 && leadTransferee == r.failedTransferee &&
+			r.failedTransferAttempts >= r.maxLeaderTransferAttempts {
+			log.Warn(fmt.Sprintf("%d [term %d] rejects transferring leadership to %d as it has failed %d times and %d is still lagging [match: %d, lastindex: %d]",
+				r.id, r.Term, leadTransferee, r.failedTransferAttempts, leadTransferee, pr.Match, r.RaftLog.LastIndex()))
+			return nil
+		}
 		// Transfer leadership to third party.
 		log.Info(fmt.Sprintf("%d [term %d] starts to transfer leadership to %d", r.id, r.Term, leadTransferee))
 		// Transfer leadership should be finished in one electionTimeout, so reset r.electionElapsed.
@@ -743,6 +1308,9 @@ func (r *Raft) stepCandidate(m pb.Message) error {
 	switch m.MsgType {
 	case pb.MessageType_MsgPropose:
 		log.Info(fmt.Sprintf("%d no leader at term %d; dropping proposal", r.id, r.Term))
+		return nonLeaderProposalErr(m)
+	case pb.MessageType_MsgReadIndex:
+		log.Info(fmt.Sprintf("%d no leader at term %d; dropping read index", r.id, r.Term))
 		return ErrProposalDropped
 	case pb.MessageType_MsgAppend:
 		r.becomeFollower(m.Term, m.From) // always m.Term == r.Term
@@ -753,12 +1321,30 @@ func (r *Raft) stepCandidate(m pb.Message) error {
 	case pb.MessageType_MsgSnapshot:
 		r.becomeFollower(m.Term, m.From) // always m.Term == r.Term
 		r.handleSnapshot(m)
+	case pb.MessageType_MsgPreVoteResponse:
+		if r.State != StatePreCandidate {
+			// a stale response of the pre-vote round of this election
+			return nil
+		}
+		gr := r.poll(m.From, m.MsgType, !m.Reject)
+		log.Info(fmt.Sprintf("%d has received %d %s votes and %d vote rejections", r.id, gr, m.MsgType, len(r.votes)-gr))
+		switch r.electionResult() {
+		case voteWon:
+			r.campaign(campaignElection)
+		case voteLost:
+			r.becomeFollower(r.Term, None)
+		}
 	case pb.MessageType_MsgRequestVoteResponse:
+		if r.State != StateCandidate {
+			// a response of the last election, the term is unchanged by the pre-vote round
+			return nil
+		}
 		gr := r.poll(m.From, m.MsgType, !m.Reject)
-		log.Info(fmt.Sprintf("%d [quorum:%d] has received %d %s votes and %d vote rejections", r.id, r.quorum(), gr, m.MsgType, len(r.votes)-gr))
+		log.Info(fmt.Sprintf("%d has received %d %s votes and %d vote rejections", r.id, gr, m.MsgType, len(r.votes)-gr))
 		// Raft: Leader_Election_Step6:::Change state.
 		// If the candidate receives majority of the active votes, it can become the leader and broadcast the result.
 		// But if the candidate receives majority of the negative votes, it can become the follower.
+		// Use r.handleElectionResult(), it needs the majorities of both configurations in the joint state.
 		panic("Raft: Leader_Election_Step6:::Your code here.")
 
 
@@ -775,7 +1361,7 @@ func (r *Raft) stepFollower(m pb.Message) error {
 	switch m.MsgType {
 	case pb.MessageType_MsgPropose:
 		log.Info(fmt.Sprintf("%d is no leader at term %d; dropping proposal", r.id, r.Term))
-		return ErrProposalDropped
+		return nonLeaderProposalErr(m)
 	case pb.MessageType_MsgAppend:
 		r.electionElapsed = 0
 		r.Lead = m.From
@@ -795,10 +1381,23 @@ func (r *Raft) stepFollower(m pb.Message) error {
 		}
 		m.To = r.Lead
 		r.send(m)
+	case pb.MessageType_MsgReadIndex:
+		if r.Lead == None {
+			log.Info(fmt.Sprintf("%d no leader at term %d; dropping read index", r.id, r.Term))
+			return ErrProposalDropped
+		}
+		m.To = r.Lead
+		r.send(m)
+	case pb.MessageType_MsgReadIndexResponse:
+		if len(m.Entries) != 1 {
+			log.Error(fmt.Sprintf("%d invalid format of MessageType_MsgReadIndexResponse from %d, entries count: %d", r.id, m.From, len(m.Entries)))
+			return nil
+		}
+		r.readStates = append(r.readStates, ReadState{Index: m.Index, RequestCtx: m.Entries[0].Data})
 	case pb.MessageType_MsgTimeoutNow:
 		if r.promotable() {
 			log.Info(fmt.Sprintf("%d [term %d] received MessageType_MsgTimeoutNow from %d and starts an election to get leadership.", r.id, r.Term, m.From))
-			r.campaign()
+			r.campaign(campaignElection)
 		} else {
 			log.Info(fmt.Sprintf("%d received MessageType_MsgTimeoutNow from %d but is not promotable", r.id, m.From))
 		}
@@ -829,7 +1428,71 @@ func (r *Raft) handleAppendEntries(m pb.Message) {
 // handleHeartbeat handle Heartbeat RPC request
 func (r *Raft) handleHeartbeat(m pb.Message) {
 	r.RaftLog.commitTo(m.Commit)
-	r.send(pb.Message{To: m.From, MsgType: pb.MessageType_MsgHeartbeatResponse})
+	r.send(pb.Message{To: m.From, MsgType: pb.MessageType_MsgHeartbeatResponse, Context: m.Context})
+}
+
+// committedEntryInCurrentTerm returns true if the leader has committed an entry
+// of its term, its commit index is up to date since then.
+func (r *Raft) committedEntryInCurrentTerm() bool {
+	return r.RaftLog.zeroTermOnRangeErr(r.RaftLog.Term(r.RaftLog.committed)) == r.Term
+}
+
+// handleReadIndex records the commit index as the read index of the request, and
+// confirms the leadership with a round of heartbeats. The leader acknowledges the
+// request itself, so a single-node cluster confirms it at once.
+func (r *Raft) handleReadIndex(m pb.Message) {
+	if len(m.Entries) != 1 {
+		log.Error(fmt.Sprintf("%d invalid format of MessageType_MsgReadIndex from %d, entries count: %d", r.id, m.From, len(m.Entries)))
+		return
+	}
+	ctx := m.Entries[0].Data
+	r.readOnly.addRequest(r.RaftLog.committed, m)
+	acks := r.readOnly.recvAck(r.id, ctx)
+	if !r.maybeConfirmReadIndex(acks, ctx) {
+		r.bcastHeartbeatWithCtx(ctx)
+	}
+}
+
+// maybeConfirmReadIndex confirms the request with the context and the ones before
+// it if a majority of the voters have acknowledged it. The read states of the local
+// requests are recorded, and the read indexes of the forwarded ones are returned to
+// the followers. Returns true if they are confirmed.
+func (r *Raft) maybeConfirmReadIndex(acks map[uint64]bool, ctx []byte) bool {
+	acked := func(id uint64, _ *Progress) uint64 {
+		if acks[id] {
+			return 1
+		}
+		return 0
+	}
+	if r.joint == nil {
+		if r.quorumValue(nil, acked) == 0 {
+			return false
+		}
+	} else if r.quorumValue(r.joint.outgoing, acked) == 0 || r.quorumValue(r.joint.incoming, acked) == 0 {
+		return false
+	}
+	for _, rs := range r.readOnly.advance(ctx) {
+		req := rs.req
+		if req.From == None || req.From == r.id {
+			r.readStates = append(r.readStates, ReadState{Index: rs.index, RequestCtx: req.Entries[0].Data})
+		} else {
+			r.send(pb.Message{To: req.From, MsgType: pb.MessageType_MsgReadIndexResponse, Index: rs.index, Entries: req.Entries})
+		}
+	}
+	return true
+}
+
+// releasePendingReadIndexMessages handles the read index requests which are
+// waiting for the leader to commit an entry of its term.
+func (r *Raft) releasePendingReadIndexMessages() {
+	if r.State != StateLeader || len(r.pendingReadIndexMessages) == 0 || !r.committedEntryInCurrentTerm() {
+		return
+	}
+	msgs := r.pendingReadIndexMessages
+	r.pendingReadIndexMessages = nil
+	for _, m := range msgs {
+		r.handleReadIndex(m)
+	}
 }
 
 // handleSnapshot handle Snapshot RPC request
@@ -864,40 +1527,59 @@ func (r *Raft) restore(s pb.Snapshot) bool {
 
 	r.RaftLog.restore(s)
 	r.Prs = make(map[uint64]*Progress)
-	r.restoreNode(s.Metadata.ConfState.Nodes)
+	r.learnerPrs = make(map[uint64]*Progress)
+	r.restoreNode(s.Metadata.ConfState.Nodes, false)
+	r.restoreNode(s.Metadata.ConfState.Learners, true)
 	return true
 }
 
-func (r *Raft) restoreNode(nodes []uint64) {
+func (r *Raft) restoreNode(nodes []uint64, isLearner bool) {
 	for _, n := range nodes {
 		match, next := uint64(0), r.RaftLog.LastIndex()+1
 		if n == r.id {
 			match = next - 1
 		}
-		r.setProgress(n, match, next)
+		r.setProgress(n, match, next, isLearner)
 		log.Info(fmt.Sprintf("%d restored progress of %d [%+v]", r.id, n, r.getProgress(n)))
 	}
 }
 
 // promotable indicates whether state machine can be promoted to Leader,
-// which is true when its own id is in progress list.
+// which is true when its own id is in progress list. A learner isn't in it.
 func (r *Raft) promotable() bool {
 	_, ok := r.Prs[r.id]
 	return ok
 }
 
-// addNode add a new node to raft group
-func (r *Raft) addNode(id uint64) {
-	if r.getProgress(id) == nil {
-		r.setProgress(id, 0, r.RaftLog.LastIndex()+1)
-	} else {
+// addNode add a new node to raft group, as a learner if isLearner is true. A
+// learner which is added as a voter is promoted, its progress is kept. A voter
+// can't be demoted to a learner.
+func (r *Raft) addNode(id uint64, isLearner bool) {
+	pr := r.getProgress(id)
+	if pr == nil {
+		r.setProgress(id, 0, r.RaftLog.LastIndex()+1, isLearner)
 		return
 	}
+	if !r.isLearner(id) {
+		if isLearner {
+			log.Warn(fmt.Sprintf("%d can't demote voter %d to learner", r.id, id))
+		}
+		return
+	}
+	if isLearner {
+		return
+	}
+	// Promote the learner, it has received the entries as a learner, so the
+	// progress is kept.
+	delete(r.learnerPrs, id)
+	r.Prs[id] = pr
+	log.Info(fmt.Sprintf("%d promoted learner %d [%+v]", r.id, id, pr))
 }
 
 // removeNode remove a node from raft group
 func (r *Raft) removeNode(id uint64) {
 	delete(r.Prs, id)
+	delete(r.learnerPrs, id)
 
 	// do not try to commit or abort transferring if there is no nodes in the cluster.
 	if len(r.Prs) == 0 {
@@ -915,9 +1597,12 @@ func (r *Raft) removeNode(id uint64) {
 	}
 }
 
-func (r *Raft) setProgress(id, match, next uint64) {
+func (r *Raft) setProgress(id, match, next uint64, isLearner bool) {
+	if isLearner {
+		r.learnerPrs[id] = &Progress{Next: next, Match: match}
+		return
+	}
 	r.Prs[id] = &Progress{Next: next, Match: match}
-	return
 }
 
 func (r *Raft) loadState(state pb.HardState) {
@@ -929,6 +1614,12 @@ func (r *Raft) loadState(state pb.HardState) {
 	r.Vote = state.Vote
 }
 
+// hasLeaderInTimeout returns true if this node is the leader or it has heard from
+// the leader in the election timeout.
+func (r *Raft) hasLeaderInTimeout() bool {
+	return r.Lead != None && r.electionElapsed < r.electionTimeout
+}
+
 // pastElectionTimeout returns true iff r.electionElapsed is greater
 // than or equal to the randomized election timeout in
 // [electiontimeout, 2 * electiontimeout - 1].
@@ -937,7 +1628,7 @@ func (r *Raft) pastElectionTimeout() bool {
 }
 
 func (r *Raft) resetRandomizedElectionTimeout() {
-	r.randomizedElectionTimeout = r.electionTimeout + globalRand.Intn(r.electionTimeout)
+	r.randomizedElectionTimeout = r.electionTimeout + r.rand.Intn(r.electionTimeout)
 }
 
 func (r *Raft) sendTimeoutNow(to uint64) {
@@ -948,6 +1639,30 @@ func (r *Raft) abortLeaderTransfer() {
 	r.leadTransferee = None
 }
 
+// leaderTransferTimeout aborts the leader transfer which doesn't finish in an election
+// timeout, and records the failure of the transferee.
+func (r *Raft) leaderTransferTimeout() {
+	if r.leadTransferee == r.failedTransferee {
+		r.failedTransferAttempts++
+	} else {
+		r.failedTransferee, r.failedTransferAttempts = r.leadTransferee, 1
+	}
+	log.Warn(fmt.Sprintf("%d [term %d] failed to transfer leadership to %d in an election timeout, attempts: %d",
+		r.id, r.Term, r.leadTransferee, r.failedTransferAttempts))
+	r.abortLeaderTransfer()
+}
+
+// nonLeaderProposalErr returns the error of dropping the proposal on a non-leader,
+// the conf change proposals are distinguished from the normal ones.
+func nonLeaderProposalErr(m pb.Message) error {
+	for _, e := range m.Entries {
+		if e.EntryType == pb.EntryType_EntryConfChange {
+			return ErrConfChangeProposalDropped
+		}
+	}
+	return ErrProposalDropped
+}
+
 func numOfPendingConf(ents []pb.Entry) int {
 	n := 0
 	for i := range ents {
@@ -113,6 +113,190 @@ func TestLeaderCycle2A(t *testing.T) {
 	}
 }
 
+// TestElectionPriority2A verifies that a candidate is rejected by the peers
+// with higher priority and the same log, and the node with the highest priority
+// becomes leader.
+func TestElectionPriority2A(t *testing.T) {
+	priorities := map[uint64]uint64{1: 1, 2: 3, 3: 2}
+	cfg := func(c *Config) {
+		c.Priority = priorities[c.ID]
+	}
+	n := newNetworkWithConfig(cfg, nil, nil, nil)
+
+	// Both peers have higher priority, so node 1 loses the election.
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	sm1 := n.peers[1].(*Raft)
+	if sm1.State != StateFollower {
+		t.Fatalf("node 1 state = %s, want %s", sm1.State, StateFollower)
+	}
+	if sm1.Term != 1 {
+		t.Fatalf("node 1 term = %d, want 1", sm1.Term)
+	}
+
+	n.send(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgHup})
+	if sm2 := n.peers[2].(*Raft); sm2.State != StateLeader {
+		t.Fatalf("node 2 state = %s, want %s", sm2.State, StateLeader)
+	}
+	for id, p := range n.peers {
+		if sm := p.(*Raft); sm.Lead != 2 {
+			t.Errorf("node %d lead = %d, want 2", id, sm.Lead)
+		}
+	}
+}
+
+// TestCandidateDefersToHigherPriority2A verifies that a candidate stops
+// campaigning when it receives the vote request from a candidate with higher
+// priority in the same term, and keeps its vote.
+func TestCandidateDefersToHigherPriority2A(t *testing.T) {
+	c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c.Priority = 1
+	r := newRaft(c)
+	r.becomeCandidate()
+
+	// a candidate with lower priority is ignored
+	r.Step(pb.Message{From: 3, To: 1, Term: r.Term, MsgType: pb.MessageType_MsgRequestVote, Priority: 0})
+	if r.State != StateCandidate {
+		t.Fatalf("state = %s, want %s", r.State, StateCandidate)
+	}
+
+	r.Step(pb.Message{From: 2, To: 1, Term: r.Term, MsgType: pb.MessageType_MsgRequestVote, Priority: 2})
+	if r.State != StateFollower {
+		t.Fatalf("state = %s, want %s", r.State, StateFollower)
+	}
+	if r.Term != 1 {
+		t.Errorf("term = %d, want 1", r.Term)
+	}
+	if r.Vote != 1 {
+		t.Errorf("vote = %d, want 1", r.Vote)
+	}
+}
+
+// TestPreVoteSingleNode2A verifies that a single-node cluster with pre-vote
+// enabled wins the pre-vote round and the election at once.
+func TestPreVoteSingleNode2A(t *testing.T) {
+	c := newTestConfig(1, []uint64{1}, 10, 1, NewMemoryStorage())
+	c.PreVote = true
+	r := newRaft(c)
+
+	r.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	if r.State != StateLeader {
+		t.Fatalf("state = %s, want %s", r.State, StateLeader)
+	}
+	if r.Term != 1 {
+		t.Errorf("term = %d, want 1", r.Term)
+	}
+}
+
+// TestPreCandidateKeepsTerm2A verifies that a pre-candidate asks the pre-votes
+// for the next term without changing its term and vote, and starts the election
+// after it wins the pre-vote quorum.
+func TestPreCandidateKeepsTerm2A(t *testing.T) {
+	c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c.PreVote = true
+	r := newRaft(c)
+
+	r.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	if r.State != StatePreCandidate {
+		t.Fatalf("state = %s, want %s", r.State, StatePreCandidate)
+	}
+	if r.Term != 0 || r.Vote != None {
+		t.Errorf("term, vote = %d, %d, want 0, %d", r.Term, r.Vote, None)
+	}
+	msgs := r.readMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("len(msgs) = %d, want 2", len(msgs))
+	}
+	for _, m := range msgs {
+		if m.MsgType != pb.MessageType_MsgPreVote || m.Term != 1 {
+			t.Errorf("msg = %s at term %d, want %s at term 1", m.MsgType, m.Term, pb.MessageType_MsgPreVote)
+		}
+	}
+
+	r.Step(pb.Message{From: 2, To: 1, Term: 1, MsgType: pb.MessageType_MsgPreVoteResponse})
+	if r.State != StateCandidate {
+		t.Fatalf("state = %s, want %s", r.State, StateCandidate)
+	}
+	if r.Term != 1 || r.Vote != 1 {
+		t.Errorf("term, vote = %d, %d, want 1, 1", r.Term, r.Vote)
+	}
+	for _, m := range r.readMessages() {
+		if m.MsgType != pb.MessageType_MsgRequestVote {
+			t.Errorf("msg type = %s, want %s", m.MsgType, pb.MessageType_MsgRequestVote)
+		}
+	}
+}
+
+// TestPreVoteRejectedWithLeader2A verifies that a node which has heard from the
+// leader in the election timeout rejects a pre-vote even for a higher term, and
+// its term and vote are unchanged.
+func TestPreVoteRejectedWithLeader2A(t *testing.T) {
+	c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c.PreVote = true
+	r := newRaft(c)
+	r.becomeFollower(2, 2)
+	r.Vote = 2
+
+	r.Step(pb.Message{From: 3, To: 1, Term: 5, MsgType: pb.MessageType_MsgPreVote, LogTerm: 5, Index: 10})
+	msgs := r.readMessages()
+	if len(msgs) != 1 || msgs[0].MsgType != pb.MessageType_MsgPreVoteResponse || !msgs[0].Reject {
+		t.Fatalf("msgs = %+v, want a rejected %s", msgs, pb.MessageType_MsgPreVoteResponse)
+	}
+	if msgs[0].Term != 2 {
+		t.Errorf("response term = %d, want 2", msgs[0].Term)
+	}
+	if r.Term != 2 || r.Vote != 2 || r.Lead != 2 {
+		t.Errorf("term, vote, lead = %d, %d, %d, want 2, 2, 2", r.Term, r.Vote, r.Lead)
+	}
+
+	// it's granted once the leader is absent for an election timeout
+	r.electionElapsed = r.electionTimeout
+	r.Step(pb.Message{From: 3, To: 1, Term: 5, MsgType: pb.MessageType_MsgPreVote, LogTerm: 5, Index: 10})
+	msgs = r.readMessages()
+	if len(msgs) != 1 || msgs[0].MsgType != pb.MessageType_MsgPreVoteResponse || msgs[0].Reject {
+		t.Fatalf("msgs = %+v, want a granted %s", msgs, pb.MessageType_MsgPreVoteResponse)
+	}
+	if r.Term != 2 || r.Vote != 2 {
+		t.Errorf("term, vote = %d, %d, want 2, 2", r.Term, r.Vote)
+	}
+}
+
+// TestPreVoteRejoinNoDisruption verifies that a partitioned node doesn't
+// increase its term with pre-vote enabled, and it can't disrupt the leader
+// when it rejoins the cluster.
+func TestPreVoteRejoinNoDisruption2A(t *testing.T) {
+	n := newNetworkWithConfig(func(c *Config) { c.PreVote = true }, nil, nil, nil)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	sm1 := n.peers[1].(*Raft)
+	if sm1.State != StateLeader {
+		t.Fatalf("node 1 state = %s, want %s", sm1.State, StateLeader)
+	}
+	term := sm1.Term
+
+	n.isolate(3)
+	for i := 0; i < 3; i++ {
+		n.send(pb.Message{From: 3, To: 3, MsgType: pb.MessageType_MsgHup})
+	}
+	sm3 := n.peers[3].(*Raft)
+	if sm3.State != StatePreCandidate {
+		t.Fatalf("node 3 state = %s, want %s", sm3.State, StatePreCandidate)
+	}
+	if sm3.Term != term {
+		t.Errorf("node 3 term = %d, want %d", sm3.Term, term)
+	}
+
+	n.recover()
+	n.send(pb.Message{From: 3, To: 3, MsgType: pb.MessageType_MsgHup})
+	if sm3.State != StateFollower {
+		t.Errorf("node 3 state = %s, want %s", sm3.State, StateFollower)
+	}
+	if sm1.State != StateLeader || sm1.Term != term {
+		t.Errorf("node 1 state, term = %s, %d, want %s, %d", sm1.State, sm1.Term, StateLeader, term)
+	}
+	if sm3.Term != term {
+		t.Errorf("node 3 term = %d, want %d", sm3.Term, term)
+	}
+}
+
 // TestLeaderElectionOverwriteNewerLogs tests a scenario in which a
 // newly-elected leader does *not* have the newest (i.e. highest term)
 // log entries, and must overwrite higher-term log entries with
@@ -989,6 +1173,30 @@ func TestRestoreSnapshot2B(t *testing.T) {
 	}
 }
 
+func TestRestoreLearnersFromSnapshot3A(t *testing.T) {
+	s := pb.Snapshot{
+		Metadata: &pb.SnapshotMetadata{
+			Index:     11, // magic number
+			Term:      11, // magic number
+			ConfState: &pb.ConfState{Nodes: []uint64{1, 2}, Learners: []uint64{3}},
+		},
+	}
+
+	storage := NewMemoryStorage()
+	sm := newTestRaft(3, []uint64{1, 2, 3}, 10, 1, storage)
+	sm.handleSnapshot(pb.Message{Snapshot: &s})
+
+	if sg := nodes(sm); !reflect.DeepEqual(sg, s.Metadata.ConfState.Nodes) {
+		t.Errorf("sm.Nodes = %+v, want %+v", sg, s.Metadata.ConfState.Nodes)
+	}
+	if sg := learnerNodes(sm); !reflect.DeepEqual(sg, s.Metadata.ConfState.Learners) {
+		t.Errorf("sm.Learners = %+v, want %+v", sg, s.Metadata.ConfState.Learners)
+	}
+	if sm.promotable() {
+		t.Errorf("learner 3 is promotable")
+	}
+}
+
 func TestRestoreIgnoreSnapshot2B(t *testing.T) {
 	previousEnts := []pb.Entry{{Term: 1, Index: 1}, {Term: 1, Index: 2}, {Term: 1, Index: 3}}
 	storage := NewMemoryStorage()
@@ -1043,6 +1251,47 @@ func TestProvideSnap2B(t *testing.T) {
 	}
 }
 
+func TestSnapshotForFollowerBehindCompaction2B(t *testing.T) {
+	// restore the state machine from a snapshot so it has a compacted log and a snapshot
+	s := pb.Snapshot{
+		Metadata: &pb.SnapshotMetadata{
+			Index:     11, // magic number
+			Term:      11, // magic number
+			ConfState: &pb.ConfState{Nodes: []uint64{1, 2}},
+		},
+	}
+	storage := NewMemoryStorage()
+	sm := newTestRaft(1, []uint64{1}, 10, 1, storage)
+	sm.handleSnapshot(pb.Message{Snapshot: &s})
+
+	sm.becomeCandidate()
+	sm.becomeLeader()
+	sm.readMessages() // clear message
+
+	// node 2 only has the entries up to 5, which are compacted in the leader,
+	// it should be repaired by a snapshot instead of probing backward.
+	index := sm.Prs[2].Next - 1
+	sm.Step(pb.Message{From: 2, To: 1, Term: sm.Term, MsgType: pb.MessageType_MsgAppendResponse, Index: index, Reject: true, RejectHint: 5})
+
+	msgs := sm.readMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("len(msgs) = %d, want 1", len(msgs))
+	}
+	m := msgs[0]
+	if m.MsgType != pb.MessageType_MsgSnapshot {
+		t.Fatalf("m.MsgType = %v, want %v", m.MsgType, pb.MessageType_MsgSnapshot)
+	}
+	if m.Snapshot.Metadata.Index != s.Metadata.Index {
+		t.Errorf("snapshot index = %d, want %d", m.Snapshot.Metadata.Index, s.Metadata.Index)
+	}
+
+	follower := newTestRaft(2, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	follower.Step(m)
+	if follower.RaftLog.committed != s.Metadata.Index {
+		t.Errorf("follower committed = %d, want %d", follower.RaftLog.committed, s.Metadata.Index)
+	}
+}
+
 func TestRestoreFromSnapMsg2B(t *testing.T) {
 	s := pb.Snapshot{
 		Metadata: &pb.SnapshotMetadata{
@@ -1095,7 +1344,7 @@ func TestSlowNodeRestore2B(t *testing.T) {
 // TestAddNode tests that addNode could update nodes correctly.
 func TestAddNode3A(t *testing.T) {
 	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
-	r.addNode(2)
+	r.addNode(2, false)
 	nodes := nodes(r)
 	wnodes := []uint64{1, 2}
 	if !reflect.DeepEqual(nodes, wnodes) {
@@ -1121,6 +1370,80 @@ func TestRemoveNode3A(t *testing.T) {
 	}
 }
 
+// TestAddLearner tests that addNode could add a learner, which isn't counted in
+// the quorum, and a voter can't be demoted to a learner.
+func TestAddLearner3A(t *testing.T) {
+	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
+	r.addNode(2, true)
+	r.addNode(1, true)
+	if g, w := nodes(r), []uint64{1}; !reflect.DeepEqual(g, w) {
+		t.Errorf("nodes = %v, want %v", g, w)
+	}
+	if g, w := learnerNodes(r), []uint64{2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("learners = %v, want %v", g, w)
+	}
+	r.votes = map[uint64]bool{1: true}
+	if g := r.electionResult(); g != voteWon {
+		t.Errorf("election result = %v, want %v", g, voteWon)
+	}
+	if r.getProgress(2) == nil {
+		t.Errorf("progress of learner 2 is missing")
+	}
+}
+
+// TestPromoteLearner tests that a learner added as a voter is moved into Prs
+// and keeps its progress.
+func TestPromoteLearner3A(t *testing.T) {
+	r := newTestLearnerRaft(1, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	r.getProgress(2).Match = 5
+	r.addNode(2, false)
+	if g, w := nodes(r), []uint64{1, 2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("nodes = %v, want %v", g, w)
+	}
+	if g := learnerNodes(r); len(g) != 0 {
+		t.Errorf("learners = %v, want []", g)
+	}
+	if m := r.Prs[2].Match; m != 5 {
+		t.Errorf("match = %d, want 5", m)
+	}
+}
+
+// TestLearnerNotCounted tests that the votes and the match indexes of the
+// learners are never counted.
+func TestLearnerNotCounted3A(t *testing.T) {
+	r := newTestLearnerRaft(1, []uint64{1, 2, 3}, []uint64{4, 5}, 10, 1, NewMemoryStorage())
+	r.votes = make(map[uint64]bool)
+	r.poll(1, pb.MessageType_MsgRequestVoteResponse, true)
+	for _, id := range []uint64{4, 5} {
+		if g := r.poll(id, pb.MessageType_MsgRequestVoteResponse, true); g != 1 {
+			t.Errorf("#%d: granted = %d, want 1", id, g)
+		}
+	}
+
+	r.Prs[1].Match = 1
+	r.learnerPrs[4].Match = 1
+	r.learnerPrs[5].Match = 1
+	if g := r.committedIndex(); g != 0 {
+		t.Errorf("committed index = %d, want 0", g)
+	}
+	r.Prs[2].Match = 1
+	if g := r.committedIndex(); g != 1 {
+		t.Errorf("committed index = %d, want 1", g)
+	}
+}
+
+// TestLearnerCannotCampaign tests that a learner doesn't campaign.
+func TestLearnerCannotCampaign3A(t *testing.T) {
+	r := newTestLearnerRaft(2, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	r.Step(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgHup})
+	if r.State != StateFollower {
+		t.Errorf("state = %s, want %s", r.State, StateFollower)
+	}
+	if len(r.msgs) != 0 {
+		t.Errorf("msgs = %v, want []", r.msgs)
+	}
+}
+
 func TestCampaignWhileLeader2A(t *testing.T) {
 	cfg := newTestConfig(1, []uint64{1}, 5, 1, NewMemoryStorage())
 	r := newRaft(cfg)
@@ -1143,6 +1466,125 @@ func TestCampaignWhileLeader2A(t *testing.T) {
 	}
 }
 
+// TestJointConsensusCommit ensures that in the joint state an entry is committed
+// only when it is matched by the majorities of both the old and new configurations.
+func TestJointConsensusCommit3A(t *testing.T) {
+	s := NewMemoryStorage()
+	s.Append([]pb.Entry{{Term: 1, Index: 1}, {Term: 1, Index: 2}, {Term: 1, Index: 3}})
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, s)
+	r.Term = 1
+	r.enterJoint([]uint64{1, 2, 3}, []uint64{1, 4, 5})
+	if len(r.Prs) != 5 {
+		t.Fatalf("len(prs) = %d, want 5", len(r.Prs))
+	}
+
+	tests := []struct {
+		id, match uint64
+		wcommit   uint64
+	}{
+		{1, 3, 0},
+		// The old configuration has a majority, but the new one doesn't.
+		{2, 3, 0},
+		{3, 3, 0},
+		{4, 2, 2},
+		// The new configuration has a majority, but the old one doesn't.
+		{2, 2, 2},
+		{3, 2, 2},
+		{5, 3, 2},
+		{2, 3, 3},
+	}
+	for i, tt := range tests {
+		r.Prs[tt.id].Match = tt.match
+		r.maybeCommit()
+		if g := r.RaftLog.committed; g != tt.wcommit {
+			t.Errorf("#%d: committed = %d, want %d", i, g, tt.wcommit)
+		}
+	}
+
+	// Only the new configuration decides the commit index after leaving the joint state.
+	r.RaftLog.committed = 0
+	r.Prs[2].Match, r.Prs[3].Match, r.Prs[5].Match = 3, 3, 0
+	r.leaveJoint()
+	if len(r.Prs) != 3 || r.Prs[2] != nil || r.Prs[4] == nil {
+		t.Fatalf("prs = %v, want the progress of 1, 4 and 5", r.Prs)
+	}
+	r.maybeCommit()
+	if g := r.RaftLog.committed; g != 2 {
+		t.Errorf("committed = %d, want %d", g, 2)
+	}
+}
+
+// TestJointConsensusElection ensures that in the joint state an election is won
+// only when it's granted by the majorities of both the old and new configurations,
+// and lost when either of them rejects it.
+func TestJointConsensusElection3A(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.enterJoint([]uint64{1, 2, 3}, []uint64{1, 4, 5})
+
+	tests := []struct {
+		votes map[uint64]bool
+		w     voteResult
+	}{
+		{map[uint64]bool{1: true}, votePending},
+		// The old configuration grants it, but the new one doesn't yet.
+		{map[uint64]bool{1: true, 2: true, 3: true}, votePending},
+		// The new configuration grants it, but the old one doesn't yet.
+		{map[uint64]bool{1: true, 4: true, 5: true}, votePending},
+		{map[uint64]bool{1: true, 2: true, 4: true}, voteWon},
+		// The new configuration rejects it though the old one grants it.
+		{map[uint64]bool{1: true, 2: true, 3: true, 4: false, 5: false}, voteLost},
+		{map[uint64]bool{1: true, 2: false, 3: false, 4: true, 5: true}, voteLost},
+	}
+	for i, tt := range tests {
+		r.votes = tt.votes
+		if g := r.electionResult(); g != tt.w {
+			t.Errorf("#%d: election result = %v, want %v", i, g, tt.w)
+		}
+	}
+
+	// Only the new configuration decides the election after leaving the joint state.
+	r.leaveJoint()
+	r.votes = map[uint64]bool{1: true, 2: true, 3: true, 4: false}
+	if g := r.electionResult(); g != votePending {
+		t.Errorf("election result = %v, want %v", g, votePending)
+	}
+	r.votes[5] = true
+	if g := r.electionResult(); g != voteWon {
+		t.Errorf("election result = %v, want %v", g, voteWon)
+	}
+}
+
+// TestJointConsensusElectionResult ensures that in the joint state the candidate
+// becomes a follower when either configuration rejects it, and keeps waiting for
+// the votes when the election is still pending.
+func TestJointConsensusElectionResult3A(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.enterJoint([]uint64{1, 2, 3}, []uint64{1, 4, 5})
+	r.Term = 2
+	r.State = StateCandidate
+	r.Vote = 1
+
+	// The old configuration grants it, but the new one doesn't yet.
+	r.votes = map[uint64]bool{1: true, 2: true, 3: true, 4: false}
+	r.handleElectionResult()
+	if r.State != StateCandidate {
+		t.Fatalf("state = %s, want %s", r.State, StateCandidate)
+	}
+
+	// The new configuration rejects it.
+	r.votes[5] = false
+	r.handleElectionResult()
+	if r.State != StateFollower {
+		t.Errorf("state = %s, want %s", r.State, StateFollower)
+	}
+	if r.Term != 2 {
+		t.Errorf("term = %d, want %d", r.Term, 2)
+	}
+	if r.Lead != None {
+		t.Errorf("lead = %d, want %d", r.Lead, None)
+	}
+}
+
 // TestCommitAfterRemoveNode verifies that pending commands can become
 // committed when a config change reduces the quorum requirements.
 func TestCommitAfterRemoveNode3A(t *testing.T) {
@@ -1387,6 +1829,51 @@ func TestLeaderTransferSecondTransferToAnotherNode3C(t *testing.T) {
 	checkLeaderTransferState(t, lead, StateFollower, 2)
 }
 
+// TestLeaderTransferGiveUpAfterMaxAttempts verifies that the leader rejects
+// transferring leadership to a transferee which keeps lagging behind once the
+// transfers to it have timed out for the max attempts.
+func TestLeaderTransferGiveUpAfterMaxAttempts3C(t *testing.T) {
+	c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c.MaxLeaderTransferAttempts = 2
+	lead := newRaft(c)
+	nt := newNetwork(lead, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+
+	nt.isolate(3)
+	nt.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{}}})
+
+	for i := 0; i < c.MaxLeaderTransferAttempts; i++ {
+		nt.send(pb.Message{From: 3, To: 1, MsgType: pb.MessageType_MsgTransferLeader})
+		if lead.leadTransferee != 3 {
+			t.Fatalf("#%d: wait transferring, leadTransferee = %v, want %v", i, lead.leadTransferee, 3)
+		}
+		for j := 0; j < lead.electionTimeout; j++ {
+			lead.tick()
+		}
+		checkLeaderTransferState(t, lead, StateLeader, 1)
+		if lead.leadTransferee != None {
+			t.Fatalf("#%d: leadTransferee = %v, want %v", i, lead.leadTransferee, None)
+		}
+	}
+
+	// The transfer is rejected and the proposals are not dropped.
+	nt.send(pb.Message{From: 3, To: 1, MsgType: pb.MessageType_MsgTransferLeader})
+	if lead.leadTransferee != None {
+		t.Fatalf("leadTransferee = %v, want %v", lead.leadTransferee, None)
+	}
+	lastIndex := lead.RaftLog.LastIndex()
+	nt.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{}}})
+	if li := lead.RaftLog.LastIndex(); li != lastIndex+1 {
+		t.Fatalf("lastIndex = %d, want %d", li, lastIndex+1)
+	}
+
+	// The transfer is allowed again once the transferee catches up.
+	nt.recover()
+	nt.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{}}})
+	nt.send(pb.Message{From: 3, To: 1, MsgType: pb.MessageType_MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateFollower, 3)
+}
+
 func checkLeaderTransferState(t *testing.T, r *Raft, state StateType, lead uint64) {
 	if r.State != state || r.Lead != lead {
 		t.Fatalf("after transferring, node has state %v lead %v, want state %v lead %v", r.State, r.Lead, state, lead)
@@ -1481,6 +1968,97 @@ func TestSplitVote2A(t *testing.T) {
 	}
 }
 
+// TestBcastAppendSkipUpToDatePeers2B ensures that the leader doesn't send
+// redundant appends to the followers which are already up-to-date when the
+// commit index doesn't advance.
+func TestBcastAppendSkipUpToDatePeers2B(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.Append([]pb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}, {Index: 3, Term: 1}})
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, storage)
+	r.State = StateLeader
+	r.Term = 1
+	r.Lead = 1
+	for _, pr := range r.Prs {
+		pr.Match, pr.Next = 3, 4
+	}
+
+	r.bcastAppendIfNeeded(false)
+	if msgs := r.readMessages(); len(msgs) != 0 {
+		t.Errorf("unexpected msgs: %+v", msgs)
+	}
+	if r.maybeSendAppend(2, false) {
+		t.Errorf("maybeSendAppend = true, want false")
+	}
+}
+
+// TestBcastAppendIfNeededSkipsOnlyUpToDatePeers2B ensures that when the commit
+// index doesn't advance, the up-to-date followers are skipped while the
+// followers which are behind still receive what they need.
+func TestBcastAppendIfNeededSkipsOnlyUpToDatePeers2B(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.ApplySnapshot(pb.Snapshot{Metadata: &pb.SnapshotMetadata{
+		Index: 3, Term: 1, ConfState: &pb.ConfState{Nodes: []uint64{1, 2, 3}},
+	}})
+	storage.Append([]pb.Entry{{Index: 4, Term: 1}, {Index: 5, Term: 1}})
+	r := newTestRaft(1, nil, 10, 1, storage)
+	r.State = StateLeader
+	r.Term = 1
+	r.Lead = 1
+	// 2 is up-to-date, the entries 3 needs are compacted so it gets a snapshot.
+	r.Prs[2].Match, r.Prs[2].Next = 5, 6
+	r.Prs[3].Match, r.Prs[3].Next = 0, 2
+
+	r.bcastAppendIfNeeded(false)
+	msgs := r.readMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("len(msgs) = %d, want 1: %+v", len(msgs), msgs)
+	}
+	if msgs[0].To != 3 || msgs[0].MsgType != pb.MessageType_MsgSnapshot {
+		t.Errorf("msg = %+v, want snapshot to 3", msgs[0])
+	}
+	if !r.maybeSendAppend(3, false) {
+		t.Errorf("maybeSendAppend(3) = false, want true")
+	}
+}
+
+// TestCommitAndBcastAppend2B ensures that the leader commits the entries matched
+// by a majority, and doesn't send the appends to the up-to-date followers when
+// the commit index doesn't advance.
+func TestCommitAndBcastAppend2B(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.Append([]pb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}, {Index: 3, Term: 1}})
+	r := newTestRaft(1, []uint64{1}, 10, 1, storage)
+	r.State = StateLeader
+	r.Term = 1
+	r.Lead = 1
+	r.Prs[1].maybeUpdate(3)
+	if !r.commitAndBcastAppend() {
+		t.Errorf("commitAndBcastAppend = false, want true")
+	}
+	if r.RaftLog.committed != 3 {
+		t.Errorf("committed = %d, want %d", r.RaftLog.committed, 3)
+	}
+
+	storage = NewMemoryStorage()
+	storage.Append([]pb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}, {Index: 3, Term: 1}})
+	r = newTestRaft(1, []uint64{1, 2, 3}, 10, 1, storage)
+	r.State = StateLeader
+	r.Term = 1
+	r.Lead = 1
+	r.RaftLog.commitTo(2)
+	for _, pr := range r.Prs {
+		pr.Match, pr.Next = 2, 4
+	}
+	r.Prs[1].maybeUpdate(3)
+	// 3 is matched by the leader only, the commit index doesn't advance.
+	if r.commitAndBcastAppend() {
+		t.Errorf("commitAndBcastAppend = true, want false")
+	}
+	if msgs := r.readMessages(); len(msgs) != 0 {
+		t.Errorf("unexpected msgs: %+v", msgs)
+	}
+}
+
 func entsWithConfig(configFunc func(*Config), terms ...uint64) *Raft {
 	storage := NewMemoryStorage()
 	for i, term := range terms {
@@ -1661,3 +2239,480 @@ func newTestConfig(id uint64, peers []uint64, election, heartbeat int, storage S
 func newTestRaft(id uint64, peers []uint64, election, heartbeat int, storage Storage) *Raft {
 	return newRaft(newTestConfig(id, peers, election, heartbeat, storage))
 }
+
+func newTestLearnerRaft(id uint64, peers, learners []uint64, election, heartbeat int, storage Storage) *Raft {
+	cfg := newTestConfig(id, peers, election, heartbeat, storage)
+	cfg.learners = learners
+	return newRaft(cfg)
+}
+
+func TestCandidateAbortCampaignOnHigherTerm2A(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3, 4, 5}, 10, 1, NewMemoryStorage())
+	// Peer 1 is campaigning at term 2, it has voted for itself and got the vote of
+	// peer 2, the vote requests to peer 4 and peer 5 are not sent yet.
+	r.Term = 2
+	r.Vote = 1
+	r.State = StateCandidate
+	r.votes = map[uint64]bool{1: true, 2: true}
+	for _, id := range []uint64{4, 5} {
+		r.send(pb.Message{To: id, Term: 2, MsgType: pb.MessageType_MsgRequestVote})
+	}
+
+	r.Step(pb.Message{From: 3, To: 1, Term: 3, MsgType: pb.MessageType_MsgAppend})
+	if r.State != StateFollower || r.Term != 3 || r.Lead != 3 || r.Vote != None {
+		t.Fatalf("state = %v, term = %d, lead = %d, vote = %d, want follower at term 3 led by 3 without vote",
+			r.State, r.Term, r.Lead, r.Vote)
+	}
+	if len(r.votes) != 0 {
+		t.Errorf("votes = %v, want empty", r.votes)
+	}
+	msgs := r.readMessages()
+	if len(msgs) != 1 || msgs[0].MsgType != pb.MessageType_MsgAppendResponse {
+		t.Errorf("msgs = %v, want only the append response", msgs)
+	}
+
+	// The votes of the aborted campaign arrive late, they are not counted.
+	for _, m := range []pb.Message{
+		{From: 4, To: 1, Term: 2, MsgType: pb.MessageType_MsgRequestVoteResponse},
+		{From: 5, To: 1, Term: 3, MsgType: pb.MessageType_MsgRequestVoteResponse},
+	} {
+		r.Step(m)
+	}
+	if r.State != StateFollower || len(r.votes) != 0 {
+		t.Errorf("state = %v, votes = %v, want follower without votes", r.State, r.votes)
+	}
+}
+
+func TestLeaseExpiry2A(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.Term = 1
+	r.State = StateLeader
+	r.Lead = 1
+	if g := getStatus(r).LeaseExpiry; g != 0 {
+		t.Fatalf("lease expiry = %d, want 0 before any confirmation", g)
+	}
+
+	confirm := func(from uint64) {
+		r.Step(pb.Message{From: from, To: 1, Term: 1, MsgType: pb.MessageType_MsgHeartbeatResponse})
+	}
+	tests := []struct {
+		ticks   uint64
+		from    uint64
+		wexpiry uint64
+	}{
+		// A single confirmation makes a majority with the leader itself.
+		{1, 2, 11},
+		{5, 3, 15},
+		{8, 2, 18},
+		// The lease only advances with the latest majority.
+		{9, None, 18},
+		// Peer 2 and peer 3 stop responding, the lease isn't extended any more.
+		{20, None, 18},
+		{30, None, 18},
+	}
+	for i, tt := range tests {
+		r.ticks = tt.ticks
+		if tt.from != None {
+			confirm(tt.from)
+		}
+		st := getStatus(r)
+		if st.Tick != tt.ticks {
+			t.Errorf("#%d: tick = %d, want %d", i, st.Tick, tt.ticks)
+		}
+		if st.LeaseExpiry != tt.wexpiry {
+			t.Errorf("#%d: lease expiry = %d, want %d", i, st.LeaseExpiry, tt.wexpiry)
+		}
+	}
+	// The quorum is lost, the lease has expired.
+	if st := getStatus(r); st.LeaseExpiry > st.Tick {
+		t.Errorf("lease expiry = %d, want expired at tick %d", st.LeaseExpiry, st.Tick)
+	}
+
+	// Peer 3 comes back and renews the lease.
+	r.ticks = 31
+	confirm(3)
+	if g := getStatus(r).LeaseExpiry; g != 41 {
+		t.Errorf("lease expiry = %d, want %d", g, 41)
+	}
+	// The group grows to five nodes, the confirmations of the leader and peer 3
+	// aren't a majority any more, so the lease regresses.
+	r.Prs[4], r.Prs[5] = &Progress{Next: 1}, &Progress{Next: 1}
+	if g := getStatus(r).LeaseExpiry; g != 18 {
+		t.Errorf("lease expiry = %d, want %d", g, 18)
+	}
+	r.becomeFollower(2, 2)
+	if g := getStatus(r).LeaseExpiry; g != 0 {
+		t.Errorf("lease expiry = %d, want 0 after stepping down", g)
+	}
+}
+
+func TestCommitLatency2B(t *testing.T) {
+	n := newNetwork(nil, nil, nil)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	lead := n.peers[1].(*Raft)
+
+	// The proposal is committed in the same tick in a healthy cluster.
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("a")}}})
+	// The appends of the proposal are lost, so it's committed after the next heartbeat.
+	n.ignore(pb.MessageType_MsgAppend)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("b")}}})
+	for i := 0; i < 3; i++ {
+		lead.tick()
+		lead.readMessages()
+	}
+	n.recover()
+	lead.tick()
+	n.send(lead.readMessages()...)
+
+	if lead.RaftLog.committed != 3 {
+		t.Fatalf("committed = %d, want %d", lead.RaftLog.committed, 3)
+	}
+	latency := getStatus(lead).CommitLatency
+	wlatency := CommitLatency{Count: 2, Sum: 4, Max: 4}
+	wlatency.Buckets[0], wlatency.Buckets[3] = 1, 1
+	if !reflect.DeepEqual(latency, wlatency) {
+		t.Errorf("commit latency = %+v, want %+v", latency, wlatency)
+	}
+	if len(lead.proposeTicks) != 0 {
+		t.Errorf("len(proposeTicks) = %d, want 0", len(lead.proposeTicks))
+	}
+
+	// The followers don't collect the commit latency.
+	if latency := getStatus(n.peers[2].(*Raft)).CommitLatency; latency.Count != 0 {
+		t.Errorf("follower commit latency count = %d, want 0", latency.Count)
+	}
+}
+
+// TestHeartbeatCommit ensures that the commit index carried by the heartbeat is
+// clamped to the match of the follower, and an idle follower which has all the
+// entries learns the commit index from it.
+func TestHeartbeatCommit2B(t *testing.T) {
+	s := NewMemoryStorage()
+	s.Append([]pb.Entry{{Term: 1, Index: 1}, {Term: 1, Index: 2}, {Term: 1, Index: 3}})
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, s)
+	r.Term = 1
+	r.RaftLog.commitTo(3)
+	r.Prs[2].Match = 3
+	r.Prs[3].Match = 1
+
+	tests := []struct {
+		to      uint64
+		wcommit uint64
+	}{
+		{2, 3},
+		{3, 1},
+	}
+	for i, tt := range tests {
+		if g := r.heartbeatCommit(tt.to); g != tt.wcommit {
+			t.Errorf("#%d: heartbeat commit = %d, want %d", i, g, tt.wcommit)
+		}
+	}
+
+	fs := NewMemoryStorage()
+	fs.Append([]pb.Entry{{Term: 1, Index: 1}, {Term: 1, Index: 2}, {Term: 1, Index: 3}})
+	f := newTestRaft(2, []uint64{1, 2, 3}, 10, 1, fs)
+	f.becomeFollower(1, 1)
+	f.handleHeartbeat(pb.Message{From: 1, To: 2, Term: 1, MsgType: pb.MessageType_MsgHeartbeat, Commit: r.heartbeatCommit(2)})
+	if f.RaftLog.committed != 3 {
+		t.Errorf("follower committed = %d, want %d", f.RaftLog.committed, 3)
+	}
+}
+
+// TestHeartbeatConfirmsReadIndex ensures that the heartbeat carries the context
+// of the read index request, and the leader confirms the request when a majority
+// of the voters return it in the responses.
+func TestHeartbeatConfirmsReadIndex2B(t *testing.T) {
+	s := NewMemoryStorage()
+	s.Append([]pb.Entry{{Term: 1, Index: 1}})
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, s)
+	r.Term = 1
+	r.State = StateLeader
+	r.Lead = 1
+	r.RaftLog.commitTo(1)
+	for _, pr := range r.Prs {
+		pr.Match, pr.Next = 1, 2
+	}
+
+	ctx := []byte("ctx1")
+	r.readOnly.addRequest(r.RaftLog.committed, pb.Message{From: 1, MsgType: pb.MessageType_MsgReadIndex, Entries: []*pb.Entry{{Data: ctx}}})
+	r.readOnly.recvAck(1, ctx)
+
+	hb := r.heartbeatMessage(2, ctx)
+	if hb.MsgType != pb.MessageType_MsgHeartbeat || hb.To != 2 || hb.Commit != 1 || !bytes.Equal(hb.Context, ctx) {
+		t.Fatalf("heartbeat = %+v, want a heartbeat to %d with commit %d and context %q", hb, 2, 1, ctx)
+	}
+
+	fs := NewMemoryStorage()
+	fs.Append([]pb.Entry{{Term: 1, Index: 1}})
+	f := newTestRaft(2, []uint64{1, 2, 3}, 10, 1, fs)
+	f.becomeFollower(1, 1)
+	hb.From, hb.Term = 1, 1
+	f.handleHeartbeat(hb)
+	msgs := f.readMessages()
+	if len(msgs) != 1 || msgs[0].MsgType != pb.MessageType_MsgHeartbeatResponse || !bytes.Equal(msgs[0].Context, ctx) {
+		t.Fatalf("msgs = %+v, want a heartbeat response with context %q", msgs, ctx)
+	}
+
+	if err := r.Step(msgs[0]); err != nil {
+		t.Fatal(err)
+	}
+	wrs := []ReadState{{Index: 1, RequestCtx: ctx}}
+	if !reflect.DeepEqual(r.readStates, wrs) {
+		t.Errorf("read states = %+v, want %+v", r.readStates, wrs)
+	}
+}
+
+func TestHeartbeatAdvancesIdleFollowerCommit2B(t *testing.T) {
+	n := newNetwork(nil, nil, nil)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	lead := n.peers[1].(*Raft)
+
+	// The followers append the proposed entry, but the appends carrying the new
+	// commit index are lost, so they don't know the entry is committed.
+	lead.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("somedata")}}})
+	var resps []pb.Message
+	for _, m := range lead.readMessages() {
+		follower := n.peers[m.To].(*Raft)
+		follower.Step(m)
+		resps = append(resps, follower.readMessages()...)
+	}
+	n.ignore(pb.MessageType_MsgAppend)
+	n.send(resps...)
+	if lead.RaftLog.committed != 2 {
+		t.Fatalf("leader committed = %d, want %d", lead.RaftLog.committed, 2)
+	}
+	for id := uint64(2); id <= 3; id++ {
+		if committed := n.peers[id].(*Raft).RaftLog.committed; committed != 1 {
+			t.Fatalf("peer %d committed = %d, want %d", id, committed, 1)
+		}
+	}
+
+	// The followers learn the commit index from the heartbeats.
+	n.recover()
+	n.ignore(pb.MessageType_MsgAppend)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgBeat})
+	for id := uint64(2); id <= 3; id++ {
+		if committed := n.peers[id].(*Raft).RaftLog.committed; committed != lead.RaftLog.committed {
+			t.Errorf("peer %d committed = %d, want %d", id, committed, lead.RaftLog.committed)
+		}
+	}
+
+	// The commit index is clamped to the match of the follower, so a lagging
+	// follower never commits the entries it may not have.
+	lead.Prs[2].Match = 1
+	lead.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgBeat})
+	for _, m := range lead.readMessages() {
+		wcommit := lead.RaftLog.committed
+		if m.To == 2 {
+			wcommit = 1
+		}
+		if m.Commit != wcommit {
+			t.Errorf("heartbeat to %d commit = %d, want %d", m.To, m.Commit, wcommit)
+		}
+	}
+}
+
+func TestCommittedEntriesAfterTermChange2B(t *testing.T) {
+	n := newNetwork(nil, nil, nil)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("a")}}})
+	old := n.peers[1].(*Raft)
+	applied := nextEnts(old, n.storage[1])
+
+	// The isolated old leader appends the entries which can never be committed.
+	n.isolate(1)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("b")}}})
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("c")}}})
+	if ents := nextEnts(old, n.storage[1]); len(ents) != 0 {
+		t.Fatalf("isolated leader applies %+v, want none", ents)
+	}
+
+	// The new leader commits its own entries at the same indexes, and they
+	// overwrite the uncommitted tail of the old leader after the recovery.
+	n.send(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgHup})
+	n.send(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("d")}}})
+	n.recover()
+	n.send(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("e")}}})
+	// The append carrying the latest commit index may be ignored by the old leader if
+	// its previous index is behind the commit index of the old leader, the heartbeat
+	// brings the commit index then.
+	n.send(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgBeat})
+	applied = append(applied, nextEnts(old, n.storage[1])...)
+
+	lead := n.peers[2].(*Raft)
+	if old.State != StateFollower || old.RaftLog.committed != lead.RaftLog.committed {
+		t.Fatalf("old leader state = %v, committed = %d, want %v, %d",
+			old.State, old.RaftLog.committed, StateFollower, lead.RaftLog.committed)
+	}
+	wents, err := lead.RaftLog.slice(1, lead.RaftLog.committed+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(wents) {
+		t.Fatalf("len(applied) = %d, want %d", len(applied), len(wents))
+	}
+	for i, ent := range applied {
+		// The applied entries are contiguous and are the ones committed by the new leader.
+		if ent.Index != uint64(i+1) {
+			t.Fatalf("#%d: index = %d, want %d", i, ent.Index, i+1)
+		}
+		if ent.Term != wents[i].Term || !bytes.Equal(ent.Data, wents[i].Data) {
+			t.Errorf("#%d: entry = %+v, want %+v", i, ent, wents[i])
+		}
+	}
+}
+
+func TestSendAppendToSelf2B(t *testing.T) {
+	s := NewMemoryStorage()
+	s.ApplySnapshot(pb.Snapshot{Metadata: &pb.SnapshotMetadata{Index: 10, Term: 1, ConfState: &pb.ConfState{}}})
+	r := newTestRaft(1, []uint64{1, 2}, 10, 1, s)
+	// The next index of the node itself is compacted, which would make sendAppend fall back to a snapshot.
+	r.Prs[1].Next = 1
+
+	if r.sendAppend(1) {
+		t.Errorf("sendAppend to self = true, want false")
+	}
+	if r.sendSnapshot(1) {
+		t.Errorf("sendSnapshot to self = true, want false")
+	}
+	if msgs := r.readMessages(); len(msgs) != 0 {
+		t.Errorf("msgs = %+v, want none", msgs)
+	}
+}
+
+func TestSeededElectionTimeout2A(t *testing.T) {
+	et := 10
+	timeouts := func(id uint64, seed int64) []int {
+		c := newTestConfig(id, []uint64{1, 2}, et, 1, NewMemoryStorage())
+		c.Rand = rand.New(rand.NewSource(seed))
+		r := newRaft(c)
+		var ts []int
+		for i := 0; i < 20; i++ {
+			r.resetRandomizedElectionTimeout()
+			if r.randomizedElectionTimeout < et || r.randomizedElectionTimeout >= 2*et {
+				t.Fatalf("randomized election timeout = %d, want in [%d, %d)", r.randomizedElectionTimeout, et, 2*et)
+			}
+			ts = append(ts, r.randomizedElectionTimeout)
+		}
+		return ts
+	}
+
+	ts1, ts2 := timeouts(1, 1), timeouts(2, 2)
+	if got := timeouts(1, 1); !reflect.DeepEqual(got, ts1) {
+		t.Errorf("timeouts of node 1 = %v, want %v", got, ts1)
+	}
+	if got := timeouts(2, 2); !reflect.DeepEqual(got, ts2) {
+		t.Errorf("timeouts of node 2 = %v, want %v", got, ts2)
+	}
+	if reflect.DeepEqual(ts1, ts2) {
+		t.Errorf("timeouts of the nodes with different seeds are both %v", ts1)
+	}
+}
+
+// TestReadIndex2B verifies that the read index requests to the leader and the
+// follower get the commit index of the leader after a quorum of the heartbeats
+// confirms the leadership.
+func TestReadIndex2B(t *testing.T) {
+	n := newNetwork(nil, nil, nil)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgHup})
+	sm1 := n.peers[1].(*Raft)
+	sm2 := n.peers[2].(*Raft)
+	n.send(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgPropose, Entries: []*pb.Entry{{Data: []byte("somedata")}}})
+	committed := sm1.RaftLog.committed
+
+	tests := []struct {
+		sm   *Raft
+		from uint64
+		ctx  []byte
+	}{
+		{sm1, 1, []byte("ctx1")},
+		{sm2, 2, []byte("ctx2")},
+		{sm1, 1, []byte("ctx3")},
+	}
+	for i, tt := range tests {
+		n.send(pb.Message{From: tt.from, To: tt.from, MsgType: pb.MessageType_MsgReadIndex, Entries: []*pb.Entry{{Data: tt.ctx}}})
+		if len(tt.sm.readStates) != 1 {
+			t.Fatalf("#%d: len(readStates) = %d, want 1", i, len(tt.sm.readStates))
+		}
+		rs := tt.sm.readStates[0]
+		if rs.Index != committed {
+			t.Errorf("#%d: read index = %d, want %d", i, rs.Index, committed)
+		}
+		if !bytes.Equal(rs.RequestCtx, tt.ctx) {
+			t.Errorf("#%d: request ctx = %s, want %s", i, rs.RequestCtx, tt.ctx)
+		}
+		tt.sm.readStates = nil
+	}
+
+	// the read is dropped without a leader
+	sm2.becomeFollower(sm2.Term+1, None)
+	err := sm2.Step(pb.Message{From: 2, To: 2, MsgType: pb.MessageType_MsgReadIndex, Entries: []*pb.Entry{{Data: []byte("ctx4")}}})
+	if err != ErrProposalDropped {
+		t.Errorf("err = %v, want %v", err, ErrProposalDropped)
+	}
+}
+
+// TestReadIndexSingleNode2B verifies that the leader of a single-node cluster
+// confirms the read index at once.
+func TestReadIndexSingleNode2B(t *testing.T) {
+	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.readMessages()
+
+	ctx := []byte("ctx")
+	r.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgReadIndex, Entries: []*pb.Entry{{Data: ctx}}})
+	wrs := []ReadState{{Index: r.RaftLog.committed, RequestCtx: ctx}}
+	if !reflect.DeepEqual(r.readStates, wrs) {
+		t.Errorf("readStates = %+v, want %+v", r.readStates, wrs)
+	}
+	if msgs := r.readMessages(); len(msgs) != 0 {
+		t.Errorf("msgs = %+v, want none", msgs)
+	}
+}
+
+// TestReadIndexWaitsForCommitInTerm2B verifies that the read index request waits
+// until the leader commits an entry of its term, as its commit index may be behind
+// the one of the last leader before that.
+func TestReadIndexWaitsForCommitInTerm2B(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.readMessages()
+
+	ctx := []byte("ctx")
+	r.Step(pb.Message{From: 1, To: 1, MsgType: pb.MessageType_MsgReadIndex, Entries: []*pb.Entry{{Data: ctx}}})
+	if len(r.readStates) != 0 {
+		t.Fatalf("readStates = %+v, want none", r.readStates)
+	}
+	if msgs := r.readMessages(); len(msgs) != 0 {
+		t.Fatalf("msgs = %+v, want none", msgs)
+	}
+
+	// the noop entry of the term is committed, the heartbeats are sent with the read
+	r.Step(pb.Message{From: 2, To: 1, Term: r.Term, MsgType: pb.MessageType_MsgAppendResponse, Index: 1})
+	if r.RaftLog.committed != 1 {
+		t.Fatalf("committed = %d, want 1", r.RaftLog.committed)
+	}
+	heartbeats := 0
+	for _, m := range r.readMessages() {
+		if m.MsgType == pb.MessageType_MsgHeartbeat {
+			heartbeats++
+			if !bytes.Equal(m.Context, ctx) {
+				t.Errorf("heartbeat context = %s, want %s", m.Context, ctx)
+			}
+		}
+	}
+	if heartbeats != 2 {
+		t.Errorf("heartbeats = %d, want 2", heartbeats)
+	}
+	if len(r.readStates) != 0 {
+		t.Fatalf("readStates = %+v, want none before the confirmation", r.readStates)
+	}
+
+	r.Step(pb.Message{From: 3, To: 1, Term: r.Term, MsgType: pb.MessageType_MsgHeartbeatResponse, Context: ctx})
+	wrs := []ReadState{{Index: 1, RequestCtx: ctx}}
+	if !reflect.DeepEqual(r.readStates, wrs) {
+		t.Errorf("readStates = %+v, want %+v", r.readStates, wrs)
+	}
+}
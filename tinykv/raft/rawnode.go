@@ -16,8 +16,10 @@ package raft
 
 import (
 	"errors"
+	"fmt"
 
 	pb "github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
+	"github.com/pingcap/log"
 )
 
 // ErrStepLocalMsg is returned when try to step a local raft message
@@ -27,6 +29,14 @@ var ErrStepLocalMsg = errors.New("raft: cannot step raft local message")
 // but there is no peer found in raft.Prs for that node.
 var ErrStepPeerNotFound = errors.New("raft: cannot step as peer not found")
 
+// ErrStepInvalidMsg is returned when try to step a message which breaks
+// the invariants of its type, e.g. a vote request without the term set.
+var ErrStepInvalidMsg = errors.New("raft: cannot step invalid message")
+
+// ErrNotPromotable is returned when try to campaign but the node is not
+// in its own progress list, e.g. it has been removed from the raft group.
+var ErrNotPromotable = errors.New("raft: cannot campaign as node is not promotable")
+
 // SoftState provides state that is volatile and does not need to be persisted to the WAL.
 type SoftState struct {
 	Lead      uint64
@@ -68,6 +78,12 @@ type Ready struct {
 	// If it contains a MessageType_MsgSnapshot message, the application MUST report back to raft
 	// when the snapshot has been received or has failed by calling ReportSnapshot.
 	Messages []pb.Message
+
+	// ReadStates can be used for node to serve linearizable read requests locally
+	// when its applied index is greater than the index in ReadState.
+	// Note that the readState will be returned when raft receives MessageType_MsgReadIndex.
+	// The returned is only valid for the request that requested to read.
+	ReadStates []ReadState
 }
 
 func newReady(r *Raft, prevSoftSt *SoftState, prevHardSt pb.HardState) Ready {
@@ -79,6 +95,10 @@ func newReady(r *Raft, prevSoftSt *SoftState, prevHardSt pb.HardState) Ready {
 		rd.Messages = r.msgs
 		r.msgs = nil
 	}
+	if len(r.readStates) != 0 {
+		rd.ReadStates = r.readStates
+		r.readStates = nil
+	}
 	if softSt := r.softState(); !softSt.equal(prevSoftSt) {
 		rd.SoftState = softSt
 	}
@@ -119,6 +139,9 @@ func (rn *RawNode) Tick() {
 
 // Campaign causes this RawNode to transition to candidate state.
 func (rn *RawNode) Campaign() error {
+	if !rn.Raft.promotable() {
+		return ErrNotPromotable
+	}
 	return rn.Raft.Step(pb.Message{
 		MsgType: pb.MessageType_MsgHup,
 	})
@@ -133,6 +156,55 @@ func (rn *RawNode) Propose(data []byte) error {
 		Entries: []*pb.Entry{&ent}})
 }
 
+// ReadIndex requests a read state. The read state will be set in the ready.
+// Read state has a read index. Once the application advances further than the read
+// index, any linearizable read requests issued before the read request can be
+// processed safely. The read state will have the same rctx attached. The read
+// waits if the leader hasn't committed an entry of its term yet.
+func (rn *RawNode) ReadIndex(rctx []byte) error {
+	return rn.Raft.Step(pb.Message{
+		MsgType: pb.MessageType_MsgReadIndex,
+		From:    rn.Raft.id,
+		Entries: []*pb.Entry{{Data: rctx}}})
+}
+
+// validateMsg checks the invariants the raft state machine relies on for a
+// message received over network, so a malformed message is rejected here
+// instead of crashing the node deep inside Step.
+func (rn *RawNode) validateMsg(m pb.Message) error {
+	if _, ok := pb.MessageType_name[int32(m.MsgType)]; !ok {
+		return fmt.Errorf("%w: unknown message type %d", ErrStepInvalidMsg, m.MsgType)
+	}
+	if m.From == None {
+		return fmt.Errorf("%w: %s has no sender", ErrStepInvalidMsg, m.MsgType)
+	}
+	if m.To != None && m.To != rn.Raft.id {
+		return fmt.Errorf("%w: %s is sent to %x, not %x", ErrStepInvalidMsg, m.MsgType, m.To, rn.Raft.id)
+	}
+	switch m.MsgType {
+	case pb.MessageType_MsgRequestVote, pb.MessageType_MsgRequestVoteResponse:
+		// The term is always set when sending campaign messages.
+		if m.Term == 0 {
+			return fmt.Errorf("%w: %s has no term", ErrStepInvalidMsg, m.MsgType)
+		}
+	case pb.MessageType_MsgReadIndex, pb.MessageType_MsgReadIndexResponse:
+		// The request context is carried by the only entry.
+		if len(m.Entries) != 1 {
+			return fmt.Errorf("%w: %s has %d entries", ErrStepInvalidMsg, m.MsgType, len(m.Entries))
+		}
+	case pb.MessageType_MsgSnapshot:
+		if m.Snapshot == nil || m.Snapshot.Metadata == nil {
+			return fmt.Errorf("%w: %s has no snapshot metadata", ErrStepInvalidMsg, m.MsgType)
+		}
+	}
+	for _, ent := range m.Entries {
+		if ent == nil {
+			return fmt.Errorf("%w: %s has a nil entry", ErrStepInvalidMsg, m.MsgType)
+		}
+	}
+	return nil
+}
+
 // ProposeConfChange proposes a config change.
 func (rn *RawNode) ProposeConfChange(cc pb.ConfChange) error {
 	data, err := cc.Marshal()
@@ -148,18 +220,36 @@ func (rn *RawNode) ProposeConfChange(cc pb.ConfChange) error {
 
 // ApplyConfChange applies a config change to the local node.
 func (rn *RawNode) ApplyConfChange(cc pb.ConfChange) *pb.ConfState {
+	switch cc.ChangeType {
+	case pb.ConfChangeType_EnterJoint:
+		var incoming pb.ConfState
+		if err := incoming.Unmarshal(cc.Context); err != nil {
+			// The entry is committed, so it's skipped rather than failing all the peers applying it.
+			log.Error(fmt.Sprintf("%d ignores the conf change entering the joint state with invalid context: %v", rn.Raft.id, err))
+			return &pb.ConfState{Nodes: nodes(rn.Raft), Learners: learnerNodes(rn.Raft)}
+		}
+		rn.Raft.enterJoint(nodes(rn.Raft), incoming.Nodes)
+		return &pb.ConfState{Nodes: nodes(rn.Raft), Learners: learnerNodes(rn.Raft)}
+	case pb.ConfChangeType_LeaveJoint:
+		rn.Raft.leaveJoint()
+		return &pb.ConfState{Nodes: nodes(rn.Raft), Learners: learnerNodes(rn.Raft)}
+	}
 	if cc.NodeId == None {
-		return &pb.ConfState{Nodes: nodes(rn.Raft)}
+		return &pb.ConfState{Nodes: nodes(rn.Raft), Learners: learnerNodes(rn.Raft)}
 	}
 	switch cc.ChangeType {
 	case pb.ConfChangeType_AddNode:
-		rn.Raft.addNode(cc.NodeId)
+		rn.Raft.addNode(cc.NodeId, false)
+	case pb.ConfChangeType_AddLearnerNode:
+		rn.Raft.addNode(cc.NodeId, true)
 	case pb.ConfChangeType_RemoveNode:
 		rn.Raft.removeNode(cc.NodeId)
+	case pb.ConfChangeType_MovePeer:
+		// The node keeps its id, only the store it lives on is changed.
 	default:
 		panic("unexpected conf type")
 	}
-	return &pb.ConfState{Nodes: nodes(rn.Raft)}
+	return &pb.ConfState{Nodes: nodes(rn.Raft), Learners: learnerNodes(rn.Raft)}
 }
 
 // Step advances the state machine using the given message.
@@ -168,6 +258,9 @@ func (rn *RawNode) Step(m pb.Message) error {
 	if IsLocalMsg(m.MsgType) {
 		return ErrStepLocalMsg
 	}
+	if err := rn.validateMsg(m); err != nil {
+		return err
+	}
 	if pr := rn.Raft.getProgress(m.From); pr != nil || !IsResponseMsg(m.MsgType) {
 		return rn.Raft.Step(m)
 	}
@@ -196,6 +289,9 @@ func (rn *RawNode) HasReady() bool {
 	if len(r.msgs) > 0 || len(r.RaftLog.unstableEntries()) > 0 || r.RaftLog.hasNextEnts() {
 		return true
 	}
+	if len(r.readStates) > 0 {
+		return true
+	}
 	return false
 }
 
@@ -232,10 +328,48 @@ func (rn *RawNode) GetProgress() map[uint64]Progress {
 	return prs
 }
 
+// IsCaughtUp returns true if this node is the leader and the Match of the given
+// peer is at most lagTolerance entries behind the last index of the leader's log.
+func (rn *RawNode) IsCaughtUp(id uint64, lagTolerance uint64) bool {
+	if rn.Raft.State != StateLeader {
+		return false
+	}
+	pr := rn.Raft.getProgress(id)
+	if pr == nil {
+		return false
+	}
+	return pr.Match+lagTolerance >= rn.Raft.RaftLog.LastIndex()
+}
+
+// Status returns the current status of this node. The commit latency in it
+// is only collected while this node is the leader.
+func (rn *RawNode) Status() Status {
+	return getStatus(rn.Raft)
+}
+
+// LogRange returns the first and the last index of the raft log entries
+// which have not been compacted yet.
+func (rn *RawNode) LogRange() (firstIndex, lastIndex uint64) {
+	l := rn.Raft.RaftLog
+	return l.firstIndex(), l.LastIndex()
+}
+
 func (rn *RawNode) GetSnap() *pb.Snapshot {
 	return rn.Raft.GetSnap()
 }
 
+// ReportUnreachable reports the given node is not reachable for the last send.
+// The leader no longer assumes the entries sent to it are on the way, and sends
+// the entries from the last matched index again.
+func (rn *RawNode) ReportUnreachable(id uint64) {
+	if rn.Raft.State != StateLeader {
+		return
+	}
+	if pr := rn.Raft.getProgress(id); pr != nil {
+		pr.Next = pr.Match + 1
+	}
+}
+
 // TransferLeader tries to transfer leadership to the given transferee.
 func (rn *RawNode) TransferLeader(transferee uint64) {
 	_ = rn.Raft.Step(pb.Message{MsgType: pb.MessageType_MsgTransferLeader, From: transferee})
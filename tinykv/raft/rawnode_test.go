@@ -16,6 +16,7 @@ package raft
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -155,6 +156,43 @@ func TestRawNodeProposeAddDuplicateNode3A(t *testing.T) {
 	}
 }
 
+// TestRawNodeJointConfChange ensures that RawNode.ApplyConfChange enters the joint
+// state with the incoming voters in the context, and leaves it keeping only them.
+func TestRawNodeJointConfChange3A(t *testing.T) {
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	incoming, err := (&pb.ConfState{Nodes: []uint64{1, 4, 5}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := rawNode.ApplyConfChange(pb.ConfChange{ChangeType: pb.ConfChangeType_EnterJoint, Context: incoming})
+	if w := []uint64{1, 2, 3, 4, 5}; !reflect.DeepEqual(cs.Nodes, w) {
+		t.Errorf("nodes = %v, want %v", cs.Nodes, w)
+	}
+	if rawNode.Raft.joint == nil {
+		t.Fatalf("expected to be in the joint state")
+	}
+
+	cs = rawNode.ApplyConfChange(pb.ConfChange{ChangeType: pb.ConfChangeType_LeaveJoint})
+	if w := []uint64{1, 4, 5}; !reflect.DeepEqual(cs.Nodes, w) {
+		t.Errorf("nodes = %v, want %v", cs.Nodes, w)
+	}
+	if rawNode.Raft.joint != nil {
+		t.Errorf("expected to leave the joint state")
+	}
+
+	// The conf change with an invalid context is ignored.
+	cs = rawNode.ApplyConfChange(pb.ConfChange{ChangeType: pb.ConfChangeType_EnterJoint, Context: []byte("invalid")})
+	if w := []uint64{1, 4, 5}; !reflect.DeepEqual(cs.Nodes, w) {
+		t.Errorf("nodes = %v, want %v", cs.Nodes, w)
+	}
+	if rawNode.Raft.joint != nil {
+		t.Errorf("expected not to enter the joint state")
+	}
+}
+
 // TestRawNodeStart ensures that a node can be started correctly, and can accept and commit
 // proposals.
 func TestRawNodeStart2C(t *testing.T) {
@@ -184,6 +222,99 @@ func TestRawNodeStart2C(t *testing.T) {
 	}
 }
 
+// TestRawNodeCampaignNotPromotable ensures that a node which is not in its own
+// progress list refuses to campaign and does not start an election.
+func TestRawNodeCampaignNotPromotable2C(t *testing.T) {
+	storage := NewMemoryStorage()
+	rawNode, err := NewRawNode(newTestConfig(3, []uint64{1, 2}, 10, 1, storage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = rawNode.Campaign(); err != ErrNotPromotable {
+		t.Fatalf("err = %v, want %v", err, ErrNotPromotable)
+	}
+	if rawNode.Raft.State != StateFollower {
+		t.Errorf("state = %s, want %s", rawNode.Raft.State, StateFollower)
+	}
+	if rawNode.Raft.Term != 0 {
+		t.Errorf("term = %d, want 0", rawNode.Raft.Term)
+	}
+	if msgs := rawNode.Raft.msgs; len(msgs) != 0 {
+		t.Errorf("unexpected msgs: %+v", msgs)
+	}
+}
+
+// TestRawNodeLogRange ensures that the reported log range starts after the
+// compacted entries and ends at the last appended entry.
+func TestRawNodeLogRange2C(t *testing.T) {
+	storage := NewMemoryStorage()
+	var entries []pb.Entry
+	for i := uint64(1); i <= 10; i++ {
+		entries = append(entries, pb.Entry{Term: 1, Index: i})
+	}
+	storage.Append(entries)
+	storage.SetHardState(pb.HardState{Term: 1, Commit: 10})
+	if err := storage.Compact(5); err != nil {
+		t.Fatal(err)
+	}
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1}, 10, 1, storage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, last := rawNode.LogRange(); first != 6 || last != 10 {
+		t.Errorf("log range = [%d, %d], want [%d, %d]", first, last, 6, 10)
+	}
+
+	if err = storage.Compact(7); err != nil {
+		t.Fatal(err)
+	}
+	rawNode.Raft.RaftLog.maybeCompact()
+	if first, last := rawNode.LogRange(); first != 8 || last != 10 {
+		t.Errorf("log range = [%d, %d], want [%d, %d]", first, last, 8, 10)
+	}
+}
+
+// TestRawNodeIsCaughtUp ensures that a peer is reported as caught up only when
+// its match index is within the tolerance of the leader's last index.
+func TestRawNodeIsCaughtUp2C(t *testing.T) {
+	storage := NewMemoryStorage()
+	var entries []pb.Entry
+	for i := uint64(1); i <= 10; i++ {
+		entries = append(entries, pb.Entry{Term: 1, Index: i})
+	}
+	storage.Append(entries)
+	storage.SetHardState(pb.HardState{Term: 1, Commit: 10})
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, storage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawNode.Raft.Prs[2].Match = 10
+	rawNode.Raft.Prs[3].Match = 5
+	if rawNode.IsCaughtUp(2, 0) {
+		t.Errorf("follower reports peer 2 caught up")
+	}
+
+	rawNode.Raft.State = StateLeader
+	tests := []struct {
+		id        uint64
+		tolerance uint64
+		wcaughtUp bool
+	}{
+		{2, 0, true},
+		{3, 0, false},
+		{3, 4, false},
+		{3, 5, true},
+		{3, 10, true},
+		// unknown peer
+		{4, 10, false},
+	}
+	for i, tt := range tests {
+		if got := rawNode.IsCaughtUp(tt.id, tt.tolerance); got != tt.wcaughtUp {
+			t.Errorf("#%d: caught up = %v, want %v", i, got, tt.wcaughtUp)
+		}
+	}
+}
+
 func TestRawNodeRestart2C(t *testing.T) {
 	entries := []pb.Entry{
 		{Term: 1, Index: 1},
@@ -250,3 +381,170 @@ func TestRawNodeRestartFromSnapshot2C(t *testing.T) {
 		t.Errorf("unexpected Ready: %+v", rawNode.HasReady())
 	}
 }
+
+// TestRawNodeProposeConfChangeOnNonLeader3A ensures that proposing a conf change to a
+// non-leader returns ErrConfChangeProposalDropped, which is different from dropping a
+// normal proposal.
+func TestRawNodeProposeConfChangeOnNonLeader3A(t *testing.T) {
+	for _, state := range []StateType{StateFollower, StateCandidate} {
+		rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rawNode.Raft.State = state
+
+		cc := pb.ConfChange{ChangeType: pb.ConfChangeType_AddNode, NodeId: 4}
+		if err = rawNode.ProposeConfChange(cc); err != ErrConfChangeProposalDropped {
+			t.Errorf("%v: ProposeConfChange err = %v, want %v", state, err, ErrConfChangeProposalDropped)
+		}
+		if err = rawNode.Propose([]byte("somedata")); err != ErrProposalDropped {
+			t.Errorf("%v: Propose err = %v, want %v", state, err, ErrProposalDropped)
+		}
+		if last := rawNode.Raft.RaftLog.LastIndex(); last != 0 {
+			t.Errorf("%v: last index = %d, want 0", state, last)
+		}
+	}
+}
+
+func TestRawNodeReportUnreachable2B(t *testing.T) {
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawNode.Raft.Prs[2] = &Progress{Match: 5, Next: 8}
+
+	// Followers don't track the progress of the others.
+	rawNode.ReportUnreachable(2)
+	if pr := rawNode.Raft.Prs[2]; pr.Next != 8 {
+		t.Errorf("follower: next = %d, want %d", pr.Next, 8)
+	}
+
+	rawNode.Raft.State = StateLeader
+	rawNode.ReportUnreachable(2)
+	if pr := rawNode.Raft.Prs[2]; pr.Match != 5 || pr.Next != 6 {
+		t.Errorf("progress = %+v, want match 5, next 6", *pr)
+	}
+	// The unknown node is ignored.
+	rawNode.ReportUnreachable(4)
+	if _, ok := rawNode.Raft.Prs[4]; ok {
+		t.Errorf("progress of unknown node 4 is created")
+	}
+}
+
+func TestRawNodeStateHistory2A(t *testing.T) {
+	c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c.StateHistorySize = 3
+	rawNode, err := NewRawNode(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rawNode.Raft
+	want := []StateTransition{{SoftState: SoftState{Lead: None, RaftState: StateFollower}, Term: 0}}
+	if got := rawNode.Status().StateHistory; !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %+v, want %+v", got, want)
+	}
+
+	r.becomeFollower(1, 2)
+	r.becomeFollower(2, 3)
+	r.Term = 3
+	r.becomeLeader()
+	r.becomeFollower(4, 2)
+	// Only the latest 3 transitions are kept.
+	want = []StateTransition{
+		{SoftState: SoftState{Lead: 3, RaftState: StateFollower}, Term: 2},
+		{SoftState: SoftState{Lead: 1, RaftState: StateLeader}, Term: 3},
+		{SoftState: SoftState{Lead: 2, RaftState: StateFollower}, Term: 4},
+	}
+	status := rawNode.Status()
+	if !reflect.DeepEqual(status.StateHistory, want) {
+		t.Errorf("history = %+v, want %+v", status.StateHistory, want)
+	}
+	// The status has a copy of the history.
+	status.StateHistory[0].Term = 10
+	if got := rawNode.Status().StateHistory; !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %+v, want %+v", got, want)
+	}
+
+	// No history is recorded by default.
+	rawNode, err = NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawNode.Raft.becomeFollower(1, 2)
+	if got := rawNode.Status().StateHistory; got != nil {
+		t.Errorf("history = %+v, want nil", got)
+	}
+}
+
+// TestRawNodeStepInvalidMsg ensures that malformed messages are rejected with
+// an error instead of panicking inside the raft state machine.
+func TestRawNodeStepInvalidMsg2A(t *testing.T) {
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []pb.Message{
+		{MsgType: pb.MessageType(100), From: 2, To: 1, Term: 1},
+		{MsgType: pb.MessageType_MsgHeartbeat, To: 1, Term: 1},
+		{MsgType: pb.MessageType_MsgHeartbeat, From: 2, To: 3, Term: 1},
+		{MsgType: pb.MessageType_MsgRequestVote, From: 2, To: 1},
+		{MsgType: pb.MessageType_MsgRequestVoteResponse, From: 2, To: 1},
+		{MsgType: pb.MessageType_MsgSnapshot, From: 2, To: 1, Term: 1},
+		{MsgType: pb.MessageType_MsgSnapshot, From: 2, To: 1, Term: 1, Snapshot: &pb.Snapshot{}},
+		{MsgType: pb.MessageType_MsgAppend, From: 2, To: 1, Term: 1, Entries: []*pb.Entry{{Index: 1, Term: 1}, nil}},
+		{MsgType: pb.MessageType_MsgReadIndex, From: 2, To: 1},
+		{MsgType: pb.MessageType_MsgReadIndexResponse, From: 2, To: 1, Term: 1, Index: 1},
+	}
+	for i, m := range tests {
+		if err := rawNode.Step(m); !errors.Is(err, ErrStepInvalidMsg) {
+			t.Errorf("#%d: err = %v, want %v", i, err, ErrStepInvalidMsg)
+		}
+	}
+	if rawNode.Raft.Term != 0 || rawNode.Raft.State != StateFollower || len(rawNode.Raft.msgs) != 0 {
+		t.Errorf("raft is changed by invalid messages, term %d, state %s, msgs %+v",
+			rawNode.Raft.Term, rawNode.Raft.State, rawNode.Raft.msgs)
+	}
+
+	// A well formed message passes the validation.
+	m := pb.Message{MsgType: pb.MessageType_MsgAppendResponse, From: 4, To: 1, Term: 1}
+	if err := rawNode.Step(m); err != ErrStepPeerNotFound {
+		t.Errorf("err = %v, want %v", err, ErrStepPeerNotFound)
+	}
+}
+
+func TestRawNodeReadIndex2B(t *testing.T) {
+	rawNode, err := NewRawNode(newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawNode.Raft.becomeFollower(1, 2)
+	rawNode.Advance(rawNode.Ready())
+
+	// The follower forwards the read to the leader.
+	ctx := []byte("ctx")
+	if err := rawNode.ReadIndex(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rd := rawNode.Ready()
+	if len(rd.Messages) != 1 || rd.Messages[0].MsgType != pb.MessageType_MsgReadIndex || rd.Messages[0].To != 2 {
+		t.Fatalf("messages = %+v, want a %s to 2", rd.Messages, pb.MessageType_MsgReadIndex)
+	}
+	rawNode.Advance(rd)
+
+	m := pb.Message{MsgType: pb.MessageType_MsgReadIndexResponse, From: 2, To: 1, Term: 1, Index: 5, Entries: []*pb.Entry{{Data: ctx}}}
+	if err := rawNode.Step(m); err != nil {
+		t.Fatal(err)
+	}
+	if !rawNode.HasReady() {
+		t.Fatal("HasReady() = false, want true")
+	}
+	rd = rawNode.Ready()
+	wrs := []ReadState{{Index: 5, RequestCtx: ctx}}
+	if !reflect.DeepEqual(rd.ReadStates, wrs) {
+		t.Errorf("read states = %+v, want %+v", rd.ReadStates, wrs)
+	}
+	rawNode.Advance(rd)
+	if rawNode.HasReady() {
+		t.Errorf("HasReady() = true, want false after the read states are taken")
+	}
+}
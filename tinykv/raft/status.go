@@ -0,0 +1,139 @@
+// Copyright 2015 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raft
+
+import (
+	"math/bits"
+
+	pb "github.com/pingcap-incubator/tinykv/proto/pkg/eraftpb"
+)
+
+// CommitLatencyBuckets is the number of buckets of CommitLatency.
+const CommitLatencyBuckets = 8
+
+// CommitLatency is the histogram of the number of ticks between proposing an entry
+// to the leader and committing it. Buckets[0] counts the entries committed in the
+// tick they are proposed, Buckets[i] counts the entries committed in [2^(i-1), 2^i)
+// ticks, and the last bucket also counts all the larger latencies.
+type CommitLatency struct {
+	Count   uint64
+	Sum     uint64
+	Max     uint64
+	Buckets [CommitLatencyBuckets]uint64
+}
+
+func (c *CommitLatency) observe(ticks uint64) {
+	c.Count++
+	c.Sum += ticks
+	if ticks > c.Max {
+		c.Max = ticks
+	}
+	bucket := bits.Len64(ticks)
+	if bucket >= CommitLatencyBuckets {
+		bucket = CommitLatencyBuckets - 1
+	}
+	c.Buckets[bucket]++
+}
+
+// proposeTick is the tick when the leader appended the proposed entry at index.
+type proposeTick struct {
+	index uint64
+	tick  uint64
+}
+
+// StateTransition is a transition of the SoftState of a peer, at the term it happens.
+type StateTransition struct {
+	SoftState
+	Term uint64
+}
+
+// stateHistory is a ring buffer of the latest state transitions.
+type stateHistory struct {
+	transitions []StateTransition
+	// next is the position of the next transition to record.
+	next int
+	full bool
+}
+
+func newStateHistory(size int) *stateHistory {
+	return &stateHistory{transitions: make([]StateTransition, size)}
+}
+
+func (h *stateHistory) record(t StateTransition) {
+	h.transitions[h.next] = t
+	h.next++
+	if h.next == len(h.transitions) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// list returns a copy of the recorded transitions, from the oldest to the latest.
+func (h *stateHistory) list() []StateTransition {
+	if !h.full {
+		return append([]StateTransition(nil), h.transitions[:h.next]...)
+	}
+	ts := make([]StateTransition, 0, len(h.transitions))
+	ts = append(ts, h.transitions[h.next:]...)
+	return append(ts, h.transitions[:h.next]...)
+}
+
+// Status contains information about this Raft peer and its view of the system.
+type Status struct {
+	ID uint64
+
+	pb.HardState
+	SoftState
+
+	Applied  uint64
+	Progress map[uint64]Progress
+
+	// CommitLatency of the entries proposed to this peer while it's the leader.
+	CommitLatency CommitLatency
+
+	// StateHistory is the latest SoftState transitions of this peer from the oldest
+	// to the latest, it's only recorded if Config.StateHistorySize is set.
+	StateHistory []StateTransition
+
+	// Tick is the number of ticks since this peer started.
+	Tick uint64
+	// LeaseExpiry is the tick when the lease of the leader expires, the lease is
+	// valid while it's greater than Tick. It's zero if this peer isn't the leader
+	// or a majority of the voters hasn't confirmed the leadership yet.
+	LeaseExpiry uint64
+}
+
+// getStatus gets a copy of the current raft status.
+func getStatus(r *Raft) Status {
+	s := Status{
+		ID:            r.id,
+		HardState:     r.hardState(),
+		SoftState:     *r.softState(),
+		Applied:       r.RaftLog.applied,
+		CommitLatency: r.commitLatency,
+		Tick:          r.ticks,
+		LeaseExpiry:   r.leaseExpiry(),
+	}
+	if r.stateHistory != nil {
+		s.StateHistory = r.stateHistory.list()
+	}
+	if s.RaftState == StateLeader {
+		s.Progress = make(map[uint64]Progress, len(r.Prs))
+		for id, p := range r.Prs {
+			s.Progress[id] = *p
+		}
+	}
+	return s
+}
@@ -69,6 +69,15 @@ func nodes(r *Raft) []uint64 {
 	return nodes
 }
 
+func learnerNodes(r *Raft) []uint64 {
+	nodes := make([]uint64, 0, len(r.learnerPrs))
+	for id := range r.learnerPrs {
+		nodes = append(nodes, id)
+	}
+	sort.Sort(uint64Slice(nodes))
+	return nodes
+}
+
 func diffu(a, b string) string {
 	if a == b {
 		return ""
@@ -122,7 +131,20 @@ func IsLocalMsg(msgt pb.MessageType) bool {
 }
 
 func IsResponseMsg(msgt pb.MessageType) bool {
-	return msgt == pb.MessageType_MsgAppendResponse || msgt == pb.MessageType_MsgRequestVoteResponse || msgt == pb.MessageType_MsgHeartbeatResponse
+	return msgt == pb.MessageType_MsgAppendResponse || msgt == pb.MessageType_MsgRequestVoteResponse ||
+		msgt == pb.MessageType_MsgHeartbeatResponse || msgt == pb.MessageType_MsgPreVoteResponse
+}
+
+// voteRespMsgType maps vote and pre-vote message types to their response types.
+func voteRespMsgType(msgt pb.MessageType) pb.MessageType {
+	switch msgt {
+	case pb.MessageType_MsgRequestVote:
+		return pb.MessageType_MsgRequestVoteResponse
+	case pb.MessageType_MsgPreVote:
+		return pb.MessageType_MsgPreVoteResponse
+	default:
+		panic(fmt.Sprintf("not a vote message: %s", msgt))
+	}
 }
 
 func isHardStateEqual(a, b pb.HardState) bool {
@@ -486,6 +486,19 @@ func isColumnWithIndex(colName string, indices []*model.IndexInfo) bool {
 				return true
 			}
 		}
+		if indexInfo.Condition == "" {
+			continue
+		}
+		// The column in the condition of a partial index can't be dropped either.
+		condCols, err := getPartialIndexCondColumns(indexInfo)
+		if err != nil {
+			return true
+		}
+		for _, col := range condCols {
+			if col.Name.L == colName {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -174,6 +174,37 @@ func (s *testIntegrationSuite1) TestUniqueKeyNullValue(c *C) {
 	res.Check(testkit.Rows("2"))
 }
 
+func (s *testIntegrationSuite1) TestPartialIndex(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("USE test")
+	tk.MustExec("create table t_partial(a int primary key, b int, c int)")
+	tk.MustExec("insert into t_partial values (1, 5, 1), (2, 20, 2), (3, 30, 3)")
+
+	// The existing rows are backfilled by the condition.
+	tk.MustExec("create unique index c on t_partial(c) where b > 10")
+	query := "select a from t_partial use index(c) where b > 10 and c > 0 order by a"
+	c.Assert(tk.MustUseIndex(query, "c"), IsTrue)
+	tk.MustQuery(query).Check(testkit.Rows("2", "3"))
+	c.Assert(tk.MustUseIndex("select a from t_partial use index(c) where b > 0 and c > 0", "c"), IsFalse)
+
+	// Only the rows in the index are checked for the unique key.
+	tk.MustExec("insert into t_partial values (4, 1, 2)")
+	tk.MustGetErrCode("insert into t_partial values (5, 50, 3)", mysql.ErrDupEntry)
+
+	// The rows move in and out of the index by the condition.
+	tk.MustExec("delete from t_partial where a in (1, 2, 3)")
+	tk.MustExec("insert into t_partial values (1, 15, 1), (2, 0, 2)")
+	tk.MustQuery(query).Check(testkit.Rows("1"))
+	tk.MustExec("insert into t_partial values (5, 50, 3)")
+	tk.MustQuery(query).Check(testkit.Rows("1", "5"))
+	tk.MustQuery("select a from t_partial where b > 0 and c > 0 order by a").Check(testkit.Rows("1", "4", "5"))
+
+	tk.MustGetErrCode("alter table t_partial drop column b", mysql.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create index d on t_partial(c) where d > 10", mysql.ErrBadField)
+	tk.MustGetErrCode("create index d on t_partial(c) where b > (select 1)", mysql.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create index d on t_partial(c) where b > @a", mysql.ErrUnsupportedDDLOperation)
+}
+
 func (s *testIntegrationSuite3) TestEndIncluded(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 
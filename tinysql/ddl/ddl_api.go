@@ -948,10 +948,10 @@ func (d *ddl) AlterTable(ctx sessionctx.Context, ident ast.Ident, specs []*ast.A
 			switch spec.Constraint.Tp {
 			case ast.ConstraintKey, ast.ConstraintIndex:
 				err = d.CreateIndex(ctx, ident, ast.IndexKeyTypeNone, model.NewCIStr(constr.Name),
-					spec.Constraint.Keys, constr.Option, constr.IfNotExists)
+					spec.Constraint.Keys, constr.Option, nil, constr.IfNotExists)
 			case ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
 				err = d.CreateIndex(ctx, ident, ast.IndexKeyTypeUnique, model.NewCIStr(constr.Name),
-					spec.Constraint.Keys, constr.Option, false) // IfNotExists should be not applied
+					spec.Constraint.Keys, constr.Option, nil, false) // IfNotExists should be not applied
 			case ast.ConstraintPrimaryKey:
 				err = d.CreatePrimaryKey()
 			case ast.ConstraintFulltext:
@@ -1778,7 +1778,7 @@ func (d *ddl) CreatePrimaryKey() error {
 }
 
 func (d *ddl) CreateIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.IndexKeyType, indexName model.CIStr,
-	idxColNames []*ast.IndexPartSpecification, indexOption *ast.IndexOption, ifNotExists bool) error {
+	idxColNames []*ast.IndexPartSpecification, indexOption *ast.IndexOption, where ast.ExprNode, ifNotExists bool) error {
 
 	// not support Spatial and FullText index
 	if keyType == ast.IndexKeyTypeFullText || keyType == ast.IndexKeyTypeSpatial {
@@ -1822,6 +1822,14 @@ func (d *ddl) CreateIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.Inde
 	if _, err = validateCommentLength(ctx.GetSessionVars(), indexName.String(), indexOption); err != nil {
 		return errors.Trace(err)
 	}
+	// The condition of a partial index is kept as text, it's built on the rows of the table when they are written.
+	var condition string
+	if where != nil {
+		if err = checkPartialIndexCond(ctx, tblInfo, indexName, where); err != nil {
+			return errors.Trace(err)
+		}
+		condition = where.Text()
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1829,7 +1837,7 @@ func (d *ddl) CreateIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.Inde
 		SchemaName: schema.Name.L,
 		Type:       model.ActionAddIndex,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{unique, indexName, idxColNames, indexOption},
+		Args:       []interface{}{unique, indexName, idxColNames, indexOption, condition},
 		Priority:   ctx.GetSessionVars().DDLReorgPriority,
 	}
 
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
@@ -216,6 +217,65 @@ func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, idxColNames
 	return idxInfo, nil
 }
 
+// partialIndexCondVisitor collects the columns in the condition of a partial index,
+// and finds the expressions which can't be evaluated on a single row.
+type partialIndexCondVisitor struct {
+	cols    []*ast.ColumnName
+	invalid string
+}
+
+func (v *partialIndexCondVisitor) Enter(inNode ast.Node) (ast.Node, bool) {
+	switch x := inNode.(type) {
+	case *ast.ColumnNameExpr:
+		v.cols = append(v.cols, x.Name)
+	case *ast.SubqueryExpr:
+		v.invalid = "subquery"
+	case *ast.AggregateFuncExpr:
+		v.invalid = "aggregate function"
+	case *ast.VariableExpr:
+		v.invalid = "variable"
+	case *ast.DefaultExpr:
+		v.invalid = "DEFAULT"
+	case *ast.ValuesExpr:
+		v.invalid = "VALUES"
+	}
+	return inNode, v.invalid != ""
+}
+
+func (v *partialIndexCondVisitor) Leave(inNode ast.Node) (ast.Node, bool) {
+	return inNode, true
+}
+
+// checkPartialIndexCond checks the condition of a partial index only refers to the public columns
+// of the table, and can be built on the rows of the table.
+func checkPartialIndexCond(ctx sessionctx.Context, tblInfo *model.TableInfo, indexName model.CIStr, cond ast.ExprNode) error {
+	v := &partialIndexCondVisitor{}
+	cond.Accept(v)
+	if v.invalid != "" {
+		return errUnsupportedPartialIndexCond.GenWithStackByArgs(v.invalid)
+	}
+	for _, colName := range v.cols {
+		col := model.FindColumnInfo(tblInfo.Columns, colName.Name.L)
+		if colName.Schema.L != "" || (colName.Table.L != "" && colName.Table.L != tblInfo.Name.L) ||
+			col == nil || col.State != model.StatePublic {
+			return ErrBadField.GenWithStackByArgs(colName.String(), "partial index condition")
+		}
+	}
+	_, err := tables.BuildPartialIndexCond(ctx, tblInfo, &model.IndexInfo{Name: indexName, Condition: cond.Text()})
+	return errors.Trace(err)
+}
+
+// getPartialIndexCondColumns gets the names of the columns in the condition of a partial index.
+func getPartialIndexCondColumns(indexInfo *model.IndexInfo) ([]*ast.ColumnName, error) {
+	stmt, err := parser.New().ParseOneStmt("select "+indexInfo.Condition, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	v := &partialIndexCondVisitor{}
+	stmt.Accept(v)
+	return v.cols, nil
+}
+
 func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 	if indexInfo.Primary {
 		for _, col := range indexInfo.Columns {
@@ -319,6 +379,7 @@ func (w *worker) onCreateIndex(d *ddlCtx, t *meta.Meta, job *model.Job, isPK boo
 		indexName   model.CIStr
 		idxColNames []*ast.IndexPartSpecification
 		indexOption *ast.IndexOption
+		condition   string
 		sqlMode     mysql.SQLMode
 		warnings    []string
 	)
@@ -326,7 +387,7 @@ func (w *worker) onCreateIndex(d *ddlCtx, t *meta.Meta, job *model.Job, isPK boo
 		// Notice: sqlMode and warnings is used to support non-strict mode.
 		err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption, &sqlMode, &warnings)
 	} else {
-		err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption)
+		err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption, &condition)
 	}
 	if err != nil {
 		job.State = model.JobStateCancelled
@@ -370,6 +431,7 @@ func (w *worker) onCreateIndex(d *ddlCtx, t *meta.Meta, job *model.Job, isPK boo
 			indexInfo.Primary = true
 		}
 		indexInfo.Unique = unique
+		indexInfo.Condition = condition
 		indexInfo.ID = allocateIndexID(tblInfo)
 		tblInfo.Indices = append(tblInfo.Indices, indexInfo)
 		logutil.BgLogger().Info("[ddl] run add index job", zap.String("job", job.String()), zap.Reflect("indexInfo", indexInfo))
@@ -683,6 +745,17 @@ func (w *addIndexWorker) getIndexRecord(handle int64, recordKey []byte, rawRecor
 	if err != nil {
 		return nil, errors.Trace(errCantDecodeIndex.GenWithStackByArgs(err))
 	}
+	covered := true
+	if idxInfo.Condition != "" {
+		row, err := w.getFullRow(handle)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		covered, err = w.index.Covers(w.sessCtx, row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	idxVal := make([]types.Datum, len(idxInfo.Columns))
 	for j, v := range idxInfo.Columns {
 		col := cols[v.Offset]
@@ -708,13 +781,41 @@ func (w *addIndexWorker) getIndexRecord(handle int64, recordKey []byte, rawRecor
 
 		idxVal[j] = idxColumnVal
 	}
+	// Only the rows satisfying the condition are added to a partial index.
+	idxRecord := &indexRecord{handle: handle, key: recordKey, vals: idxVal, skip: !covered}
 	// If there are generated column, rowDecoder will use column value that not in idxInfo.Columns to calculate
 	// the generated value, so we need to clear up the reusing map.
 	w.cleanRowMap()
-	idxRecord := &indexRecord{handle: handle, key: recordKey, vals: idxVal}
 	return idxRecord, nil
 }
 
+// getFullRow gets the values of all the columns of the decoded row, they're needed by the condition of a partial index.
+func (w *addIndexWorker) getFullRow(handle int64) ([]types.Datum, error) {
+	t := w.table
+	cols := t.Cols()
+	row := make([]types.Datum, len(cols))
+	for i, col := range cols {
+		if col.IsPKHandleColumn(t.Meta()) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[i].SetUint64(uint64(handle))
+			} else {
+				row[i].SetInt64(handle)
+			}
+			continue
+		}
+		if val, ok := w.rowMap[col.ID]; ok {
+			row[i] = val
+			continue
+		}
+		val, err := tables.GetColDefaultValue(w.sessCtx, col, w.defaultVals)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row[i] = val
+	}
+	return row, nil
+}
+
 func (w *addIndexWorker) cleanRowMap() {
 	for id := range w.rowMap {
 		delete(w.rowMap, id)
@@ -833,6 +934,10 @@ func (w *addIndexWorker) checkUniqueKey(txn kv.Transaction, idxRecords []*indexR
 	// 2. unique-key/primary-key is duplicate and the handle is not equal, return duplicate error.
 	// 3. non-unique-key is duplicate, skip it.
 	for i, key := range w.batchCheckKeys {
+		// The row is out of the partial index.
+		if idxRecords[i].skip {
+			continue
+		}
 		if val, found := vals[string(key)]; found {
 			if w.distinctCheckFlags[i] {
 				handle, err1 := tables.DecodeHandle(val)
@@ -1004,6 +1109,10 @@ func makeupDecodeColMap(t table.Table, indexInfo *model.IndexInfo) (map[int64]de
 	for i, v := range indexInfo.Columns {
 		indexedCols[i] = cols[v.Offset]
 	}
+	// The condition of a partial index may refer to any column.
+	if indexInfo.Condition != "" {
+		indexedCols = cols
+	}
 
 	decodeColMap, err := decoder.BuildFullDecodeColMap(indexedCols)
 	if err != nil {
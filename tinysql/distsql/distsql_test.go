@@ -92,6 +92,32 @@ func (s *testSuite) TestSelectNormal(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *testSuite) TestSelectStreaming(c *C) {
+	batch, totalRows := 10, 100000
+	response, colTypes := s.createSelectNormal(batch, totalRows, c)
+	resp := response.resp.(*mockResponse)
+
+	// The responses are fetched on demand while reading, so at most one partial
+	// result is buffered no matter how large the table is.
+	chk := chunk.New(colTypes, 32, 32)
+	numAllRows := 0
+	for {
+		err := response.Next(context.TODO(), chk)
+		c.Assert(err, IsNil)
+		numAllRows += chk.NumRows()
+		resp.Lock()
+		fetched := resp.count
+		resp.Unlock()
+		c.Assert(fetched-numAllRows, LessEqual, batch)
+		if chk.NumRows() == 0 {
+			break
+		}
+	}
+	c.Assert(numAllRows, Equals, totalRows)
+	c.Assert(response.partialCount, Equals, int64(totalRows/batch))
+	c.Assert(response.Close(), IsNil)
+}
+
 func (s *testSuite) TestAnalyze(c *C) {
 	request, err := (&RequestBuilder{}).SetKeyRanges(nil).
 		SetAnalyzeRequest(&tipb.AnalyzeReq{}).
@@ -16,6 +16,7 @@ package domain
 import (
 	"context"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -58,6 +59,8 @@ type Domain struct {
 	etcdClient      *clientv3.Client
 	gvc             GlobalVariableCache
 	wg              sync.WaitGroup
+	processList     sync.Map // connection id -> *util.ProcessInfo
+	sessionManager  atomic.Value
 }
 
 // loadInfoSchema loads infoschema at startTS into handle, usedSchemaVersion is the currently used
@@ -609,6 +612,38 @@ func (do *Domain) SysSessionPool() *sessionPool {
 	return do.sysSessionPool
 }
 
+// SetProcessInfo records the statement running on the connection pi.ID.
+func (do *Domain) SetProcessInfo(pi *util.ProcessInfo) {
+	do.processList.Store(pi.ID, pi)
+}
+
+// ClearProcessInfo removes the statement record of the connection.
+func (do *Domain) ClearProcessInfo(connID uint64) {
+	do.processList.Delete(connID)
+}
+
+// SetSessionManager sets the manager of the connections served with the domain.
+func (do *Domain) SetSessionManager(sm util.SessionManager) {
+	do.sessionManager.Store(sm)
+}
+
+// SessionManager returns the manager of the connections, it is nil if no connection is served with the domain.
+func (do *Domain) SessionManager() util.SessionManager {
+	sm, _ := do.sessionManager.Load().(util.SessionManager)
+	return sm
+}
+
+// ShowProcessList returns the statements running on all the connections, ordered by the connection id.
+func (do *Domain) ShowProcessList() []*util.ProcessInfo {
+	var pl []*util.ProcessInfo
+	do.processList.Range(func(_, value interface{}) bool {
+		pl = append(pl, value.(*util.ProcessInfo))
+		return true
+	})
+	sort.Slice(pl, func(i, j int) bool { return pl[i].ID < pl[j].ID })
+	return pl
+}
+
 // GetEtcdClient returns the etcd client.
 func (do *Domain) GetEtcdClient() *clientv3.Client {
 	return do.etcdClient
@@ -688,6 +723,16 @@ var (
 		mysql.MySQLErrName[mysql.ErrInfoSchemaChanged]+". "+kv.TxnRetryableMark)
 )
 
+// IsRetryableSchemaError checks if the statement failed with err can be retried
+// with a reloaded information schema. ErrInfoSchemaExpired is not retryable,
+// because the schema can't be reloaded in time.
+func IsRetryableSchemaError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return ErrInfoSchemaChanged.Equal(err)
+}
+
 func init() {
 	// Map error codes to mysql error codes.
 	domainMySQLErrCodes := map[terror.ErrCode]uint16{
@@ -148,6 +148,9 @@ type ExecStmt struct {
 
 	// OutputNames will be set if using cached plan
 	OutputNames []*types.FieldName
+
+	// Sink receives the result of the statement instead of the client if it's set.
+	Sink ResultSink
 }
 
 // OriginText returns original statement as a string.
@@ -199,6 +202,10 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 		return result, err
 	}
 
+	if a.Sink != nil {
+		return nil, a.handleSink(ctx, e)
+	}
+
 	var txnStartTS uint64
 	txn, err := sctx.Txn(false)
 	if err != nil {
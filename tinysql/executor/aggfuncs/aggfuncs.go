@@ -52,6 +52,10 @@ var (
 	// All the AggFunc implementations for "SUM" are listed here.
 	_ AggFunc = (*sum4Int64)(nil)
 	_ AggFunc = (*sum4Float64)(nil)
+
+	// All the AggFunc implementations for "GROUP_CONCAT" are listed here.
+	_ AggFunc = (*groupConcat)(nil)
+	_ AggFunc = (*groupConcatOrder)(nil)
 )
 
 // PartialResult represents data structure to store the partial result for the
@@ -14,11 +14,18 @@
 package aggfuncs
 
 import (
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
 )
 
 // Build is used to build a specific AggFunc implementation according to the
@@ -37,6 +44,8 @@ func Build(ctx sessionctx.Context, aggFuncDesc *aggregation.AggFuncDesc, ordinal
 		return buildMaxMin(aggFuncDesc, ordinal, true)
 	case ast.AggFuncMin:
 		return buildMaxMin(aggFuncDesc, ordinal, false)
+	case ast.AggFuncGroupConcat:
+		return buildGroupConcat(ctx, aggFuncDesc, ordinal)
 	}
 	return nil
 }
@@ -170,3 +179,37 @@ func buildMaxMin(aggFuncDesc *aggregation.AggFuncDesc, ordinal int, isMax bool)
 	}
 	return nil
 }
+
+// buildGroupConcat builds the AggFunc implementation for function "GROUP_CONCAT".
+func buildGroupConcat(ctx sessionctx.Context, aggFuncDesc *aggregation.AggFuncDesc, ordinal int) AggFunc {
+	// The last arg is promised to be a not-null string constant, so the error can be ignored.
+	c, _ := aggFuncDesc.Args[len(aggFuncDesc.Args)-1].(*expression.Constant)
+	sep, _, err := c.EvalString(nil, chunk.Row{})
+	// This err should never happen.
+	if err != nil {
+		panic(fmt.Sprintf("Error happened when buildGroupConcat: %s", err.Error()))
+	}
+	var s string
+	s, err = variable.GetSessionSystemVar(ctx.GetSessionVars(), variable.GroupConcatMaxLen)
+	if err != nil {
+		panic(fmt.Sprintf("Error happened when buildGroupConcat: no system variable named '%s'", variable.GroupConcatMaxLen))
+	}
+	maxLen, err := strconv.ParseUint(s, 10, 64)
+	// Should never happen
+	if err != nil {
+		panic(fmt.Sprintf("Error happened when buildGroupConcat: %s", errors.Trace(err).Error()))
+	}
+	base := baseGroupConcat4String{
+		baseAggFunc: baseAggFunc{
+			args:    aggFuncDesc.Args[:len(aggFuncDesc.Args)-1],
+			ordinal: ordinal,
+		},
+		sep:       sep,
+		maxLen:    maxLen,
+		truncated: new(int32),
+	}
+	if len(aggFuncDesc.OrderByItems) > 0 {
+		return &groupConcatOrder{base, aggFuncDesc.OrderByItems}
+	}
+	return &groupConcat{base}
+}
@@ -0,0 +1,249 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggfuncs
+
+import (
+	"bytes"
+	"sort"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+type baseGroupConcat4String struct {
+	baseAggFunc
+
+	sep    string
+	maxLen uint64
+	// According to MySQL, a 'group_concat' function generates exactly one 'truncated' warning during its life time, no matter
+	// how many group actually truncated. 'truncated' acts as a sentinel to indicate whether this warning has already been
+	// generated.
+	truncated *int32
+}
+
+// concatArgs concatenates the values of the args in the row, it returns false if any of them is null.
+func (e *baseGroupConcat4String) concatArgs(row chunk.Row, buffer *bytes.Buffer) (bool, error) {
+	for _, arg := range e.args {
+		d, err := arg.Eval(row)
+		if err != nil {
+			return false, err
+		}
+		if d.IsNull() {
+			return false, nil
+		}
+		s, err := d.ToString()
+		if err != nil {
+			return false, err
+		}
+		buffer.WriteString(s)
+	}
+	return true, nil
+}
+
+// appendFinalResult truncates the result to `group_concat_max_len` and appends it to the chunk.
+func (e *baseGroupConcat4String) appendFinalResult(sctx sessionctx.Context, buffer *bytes.Buffer, chk *chunk.Chunk) {
+	if buffer == nil {
+		chk.AppendNull(e.ordinal)
+		return
+	}
+	if e.maxLen > 0 && uint64(buffer.Len()) > e.maxLen {
+		buffer.Truncate(int(e.maxLen))
+		if atomic.CompareAndSwapInt32(e.truncated, 0, 1) {
+			sctx.GetSessionVars().StmtCtx.AppendWarning(expression.ErrCutValueGroupConcat.GenWithStackByArgs(e.args[0].String()))
+		}
+	}
+	chk.AppendString(e.ordinal, buffer.String())
+}
+
+type partialResult4GroupConcat struct {
+	buffer *bytes.Buffer
+}
+
+// groupConcat concatenates the values in the order they are read. It's used to concatenate both the
+// original rows and the partial results concatenated by the coprocessor, in which case the only arg
+// is the partial result.
+type groupConcat struct {
+	baseGroupConcat4String
+}
+
+func (e *groupConcat) AllocPartialResult() PartialResult {
+	return PartialResult(new(partialResult4GroupConcat))
+}
+
+func (e *groupConcat) ResetPartialResult(pr PartialResult) {
+	p := (*partialResult4GroupConcat)(pr)
+	p.buffer = nil
+}
+
+func (e *groupConcat) UpdatePartialResult(sctx sessionctx.Context, rowsInGroup []chunk.Row, pr PartialResult) error {
+	p := (*partialResult4GroupConcat)(pr)
+	var valBuf bytes.Buffer
+	for _, row := range rowsInGroup {
+		valBuf.Reset()
+		ok, err := e.concatArgs(row, &valBuf)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if p.buffer == nil {
+			p.buffer = &bytes.Buffer{}
+		} else {
+			p.buffer.WriteString(e.sep)
+		}
+		p.buffer.Write(valBuf.Bytes())
+	}
+	return nil
+}
+
+func (e *groupConcat) MergePartialResult(sctx sessionctx.Context, src, dst PartialResult) error {
+	p1, p2 := (*partialResult4GroupConcat)(src), (*partialResult4GroupConcat)(dst)
+	if p1.buffer == nil {
+		return nil
+	}
+	if p2.buffer == nil {
+		p2.buffer = p1.buffer
+		return nil
+	}
+	p2.buffer.WriteString(e.sep)
+	p2.buffer.Write(p1.buffer.Bytes())
+	return nil
+}
+
+func (e *groupConcat) AppendFinalResult2Chunk(sctx sessionctx.Context, pr PartialResult, chk *chunk.Chunk) error {
+	p := (*partialResult4GroupConcat)(pr)
+	e.appendFinalResult(sctx, p.buffer, chk)
+	return nil
+}
+
+type groupConcatRow struct {
+	keys []types.Datum
+	val  string
+}
+
+type partialResult4GroupConcatOrder struct {
+	rows []groupConcatRow
+}
+
+// groupConcatOrder keeps the concatenated values of every row with the order by keys, and sorts
+// them when the final result is appended. It's only executed in TiDB, since the values concatenated
+// by different regions can't be merged in order.
+type groupConcatOrder struct {
+	baseGroupConcat4String
+
+	byItems []*aggregation.ByItem
+}
+
+func (e *groupConcatOrder) AllocPartialResult() PartialResult {
+	return PartialResult(new(partialResult4GroupConcatOrder))
+}
+
+func (e *groupConcatOrder) ResetPartialResult(pr PartialResult) {
+	p := (*partialResult4GroupConcatOrder)(pr)
+	p.rows = nil
+}
+
+func (e *groupConcatOrder) UpdatePartialResult(sctx sessionctx.Context, rowsInGroup []chunk.Row, pr PartialResult) error {
+	p := (*partialResult4GroupConcatOrder)(pr)
+	var valBuf bytes.Buffer
+	for _, row := range rowsInGroup {
+		valBuf.Reset()
+		ok, err := e.concatArgs(row, &valBuf)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		keys := make([]types.Datum, 0, len(e.byItems))
+		for _, item := range e.byItems {
+			d, err := item.Expr.Eval(row)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, types.CloneDatum(d))
+		}
+		p.rows = append(p.rows, groupConcatRow{keys: keys, val: valBuf.String()})
+	}
+	return nil
+}
+
+func (e *groupConcatOrder) MergePartialResult(sctx sessionctx.Context, src, dst PartialResult) error {
+	p1, p2 := (*partialResult4GroupConcatOrder)(src), (*partialResult4GroupConcatOrder)(dst)
+	p2.rows = append(p2.rows, p1.rows...)
+	return nil
+}
+
+func (e *groupConcatOrder) AppendFinalResult2Chunk(sctx sessionctx.Context, pr PartialResult, chk *chunk.Chunk) error {
+	p := (*partialResult4GroupConcatOrder)(pr)
+	if len(p.rows) == 0 {
+		e.appendFinalResult(sctx, nil, chk)
+		return nil
+	}
+	sc := sctx.GetSessionVars().StmtCtx
+	var sortErr error
+	sort.SliceStable(p.rows, func(i, j int) bool {
+		for k, item := range e.byItems {
+			cmp, err := compareByItem(sc, item, &p.rows[i].keys[k], &p.rows[j].keys[k])
+			if err != nil && sortErr == nil {
+				sortErr = err
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	buffer := &bytes.Buffer{}
+	for i, row := range p.rows {
+		if i > 0 {
+			buffer.WriteString(e.sep)
+		}
+		buffer.WriteString(row.val)
+	}
+	e.appendFinalResult(sctx, buffer, chk)
+	return nil
+}
+
+// compareByItem compares the keys of two rows in the order of the by item.
+func compareByItem(sc *stmtctx.StatementContext, item *aggregation.ByItem, a, b *types.Datum) (int, error) {
+	if item.NullOrder != ast.NullOrderDefault && (a.IsNull() || b.IsNull()) {
+		if a.IsNull() == b.IsNull() {
+			return 0, nil
+		}
+		// The explicit NULL order doesn't depend on the direction of the order.
+		if a.IsNull() == (item.NullOrder == ast.NullsFirst) {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	cmp, err := a.CompareDatum(sc, b)
+	if err != nil {
+		return 0, err
+	}
+	if item.Desc {
+		return -cmp, nil
+	}
+	return cmp, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggfuncs_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor/aggfuncs"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+func (s *testSuite) testMergePartialResult4GroupConcat(c *C, byItems []*aggregation.ByItem, results ...string) {
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	srcChk := chunk.NewChunkWithCapacity([]*types.FieldType{ft}, 5)
+	for i := 0; i < 5; i++ {
+		srcChk.AppendInt64(0, int64(i))
+	}
+	srcChk.AppendNull(0)
+
+	args := []expression.Expression{&expression.Column{RetType: ft, Index: 0}, &expression.Constant{Value: types.NewStringDatum(","), RetType: types.NewFieldType(mysql.TypeString)}}
+	desc, err := aggregation.NewAggFuncDesc(s.ctx, ast.AggFuncGroupConcat, args)
+	c.Assert(err, IsNil)
+	desc.OrderByItems = byItems
+	partialDesc, finalDesc := desc.Split([]int{0})
+	partialFunc := aggfuncs.Build(s.ctx, partialDesc, 0)
+	partialResult := partialFunc.AllocPartialResult()
+	finalFunc := aggfuncs.Build(s.ctx, finalDesc, 0)
+	finalPr := finalFunc.AllocPartialResult()
+	resultChk := chunk.NewChunkWithCapacity([]*types.FieldType{desc.RetTp}, 1)
+
+	check := func(f aggfuncs.AggFunc, pr aggfuncs.PartialResult, expected string) {
+		resultChk.Reset()
+		c.Assert(f.AppendFinalResult2Chunk(s.ctx, pr, resultChk), IsNil)
+		c.Assert(resultChk.GetRow(0).GetString(0), Equals, expected)
+	}
+
+	// The null value is skipped.
+	for i := 0; i < srcChk.NumRows(); i++ {
+		c.Assert(partialFunc.UpdatePartialResult(s.ctx, []chunk.Row{srcChk.GetRow(i)}, partialResult), IsNil)
+	}
+	check(partialFunc, partialResult, results[0])
+	c.Assert(finalFunc.MergePartialResult(s.ctx, partialResult, finalPr), IsNil)
+
+	partialResult = partialFunc.AllocPartialResult()
+	for i := 2; i < srcChk.NumRows(); i++ {
+		c.Assert(partialFunc.UpdatePartialResult(s.ctx, []chunk.Row{srcChk.GetRow(i)}, partialResult), IsNil)
+	}
+	check(partialFunc, partialResult, results[1])
+	c.Assert(finalFunc.MergePartialResult(s.ctx, partialResult, finalPr), IsNil)
+	check(finalFunc, finalPr, results[2])
+}
+
+func (s *testSuite) TestMergePartialResult4GroupConcat(c *C) {
+	s.testMergePartialResult4GroupConcat(c, nil, "0,1,2,3,4", "2,3,4", "0,1,2,3,4,2,3,4")
+	byItems := []*aggregation.ByItem{{Expr: &expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong), Index: 0}, Desc: true}}
+	s.testMergePartialResult4GroupConcat(c, byItems, "4,3,2,1,0", "4,3,2", "4,4,3,3,2,2,1,0")
+}
+
+func (s *testSuite) TestGroupConcat(c *C) {
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	args := []expression.Expression{&expression.Column{RetType: ft, Index: 0}, &expression.Constant{Value: types.NewStringDatum(","), RetType: types.NewFieldType(mysql.TypeString)}}
+	desc, err := aggregation.NewAggFuncDesc(s.ctx, ast.AggFuncGroupConcat, args)
+	c.Assert(err, IsNil)
+	finalFunc := aggfuncs.Build(s.ctx, desc, 0)
+	resultChk := chunk.NewChunkWithCapacity([]*types.FieldType{desc.RetTp}, 1)
+
+	// The result is null if there is no row.
+	pr := finalFunc.AllocPartialResult()
+	c.Assert(finalFunc.AppendFinalResult2Chunk(s.ctx, pr, resultChk), IsNil)
+	c.Assert(resultChk.GetRow(0).IsNull(0), IsTrue)
+
+	// The result is truncated to group_concat_max_len.
+	s.ctx.GetSessionVars().SetSystemVar("group_concat_max_len", "3")
+	defer s.ctx.GetSessionVars().SetSystemVar("group_concat_max_len", "1024")
+	finalFunc = aggfuncs.Build(s.ctx, desc, 0)
+	srcChk := chunk.NewChunkWithCapacity([]*types.FieldType{ft}, 5)
+	for i := 0; i < 5; i++ {
+		srcChk.AppendInt64(0, int64(i))
+	}
+	iter := chunk.NewIterator4Chunk(srcChk)
+	for row := iter.Begin(); row != iter.End(); row = iter.Next() {
+		c.Assert(finalFunc.UpdatePartialResult(s.ctx, []chunk.Row{row}, pr), IsNil)
+	}
+	resultChk.Reset()
+	c.Assert(finalFunc.AppendFinalResult2Chunk(s.ctx, pr, resultChk), IsNil)
+	c.Assert(resultChk.GetRow(0).GetString(0), Equals, "0,1")
+	c.Assert(s.ctx.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+}
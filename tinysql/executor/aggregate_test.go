@@ -14,6 +14,8 @@
 package executor_test
 
 import (
+	"fmt"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testutil"
@@ -68,6 +70,75 @@ func (s *testSuiteAgg) TestAggPushDown(c *C) {
 	tk.MustQuery("select count(a) from t where b>0 group by a, b order by a limit 1;").Check(testkit.Rows("3"))
 }
 
+func (s *testSuiteAgg) TestCountWithoutPredicate(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, NULL), (5, NULL)")
+	tk.MustExec("analyze table t")
+	// The rows are counted by the coprocessor, only the partial counts are returned.
+	c.Assert(fmt.Sprintf("%v", tk.MustQuery("explain select count(*) from t").Rows()), Matches, `.*Agg.*cop.*`)
+	tk.MustQuery("select count(*), count(1), count(b) from t").Check(testkit.Rows("5 5 3"))
+
+	// The count isn't read from the statistics, so it's exact after the table is modified.
+	tk.MustExec("insert into t values (6, 6)")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("6"))
+	tk.MustExec("begin")
+	tk.MustExec("delete from t where a = 1")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("5"))
+	tk.MustExec("commit")
+
+	// The count is the one of the snapshot the transaction reads.
+	tk.MustExec("begin")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("5"))
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	tk2.MustExec("insert into t values (7, 7)")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("5"))
+	tk.MustExec("commit")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("6"))
+}
+
+func (s *testSuiteAgg) TestAggOverEmptyInput(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int)")
+	// test for empty table
+	tk.MustQuery("select count(*), count(a), sum(a), avg(a), max(a), min(a) from t").Check(testkit.Rows("0 0 <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("select count(*) from t group by a").Check(testkit.Rows())
+
+	tk.MustExec("insert into t values(1, 1), (2, 2), (3, NULL)")
+	// test for constant false conditions
+	tk.MustQuery("select count(*) from t where false").Check(testkit.Rows("0"))
+	tk.MustQuery("select count(*), count(a), sum(a), avg(a), max(a), min(a) from t where 1 = 0").Check(testkit.Rows("0 0 <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("select count(b), sum(b) from t where a > 1 and false").Check(testkit.Rows("0 <nil>"))
+	// test for rows filtered out
+	tk.MustQuery("select count(*), count(a), sum(a), avg(a), max(a), min(a) from t where a > 10").Check(testkit.Rows("0 0 <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("select count(b), max(b) from t where a = 3").Check(testkit.Rows("0 <nil>"))
+	// test that group by and having still return no rows
+	tk.MustQuery("select count(*) from t where false group by a").Check(testkit.Rows())
+	tk.MustQuery("select count(*) from t having false").Check(testkit.Rows())
+}
+
+func (s *testSuiteAgg) TestHavingAlias(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (2, 3), (3, 4), (3, 5), (3, 6)")
+	// HAVING on the alias of a non-aggregate select field.
+	tk.MustQuery("select a as x from t group by a having x > 1").Sort().Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select a + 1 as x from t group by a having x > 3").Check(testkit.Rows("4"))
+	tk.MustQuery("select a as x, count(*) from t group by x having x < 3").Sort().Check(testkit.Rows("1 1", "2 2"))
+	// HAVING on the alias of an aggregate select field.
+	tk.MustQuery("select a, sum(b) as s from t group by a having s > 3").Sort().Check(testkit.Rows("2 5", "3 15"))
+	tk.MustQuery("select a, count(*) cnt from t group by a having cnt >= 2 and cnt < 3").Check(testkit.Rows("2 2"))
+	// Both kinds of aliases in one HAVING clause.
+	tk.MustQuery("select a as x, max(b) as m from t group by a having x > 1 and m < 5").Check(testkit.Rows("2 3"))
+	// The alias is resolved inside the aggregate function of HAVING too.
+	tk.MustQuery("select a as x from t group by a having sum(x) > 4").Check(testkit.Rows("3"))
+}
+
 func (s *testSuiteAgg) TestAggEliminator(c *C) {
 	tk := testkit.NewTestKitWithInit(c, s.store)
 
@@ -78,6 +149,9 @@ func (s *testSuiteAgg) TestAggEliminator(c *C) {
 	tk.MustQuery("select min(b) from t").Check(testkit.Rows("-2"))
 	tk.MustQuery("select max(b*b) from t").Check(testkit.Rows("4"))
 	tk.MustQuery("select min(b*b) from t").Check(testkit.Rows("1"))
+	// The aggregation grouped by the primary key is computed over single rows.
+	tk.MustQuery("select a, count(b), sum(b), max(b) from t group by a").Sort().Check(testkit.Rows("1 1 -1 -1", "2 1 -2 -2", "3 1 1 1", "4 0 <nil> <nil>"))
+	tk.MustQuery("select count(*) from t group by a, b").Check(testkit.Rows("1", "1", "1", "1"))
 }
 
 func (s *testSuiteAgg) TestInjectProjBelowTopN(c *C) {
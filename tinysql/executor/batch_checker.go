@@ -120,6 +120,14 @@ func getKeysNeedCheckOneRow(ctx sessionctx.Context, t table.Table, row []types.D
 		if !v.Meta().Unique {
 			continue
 		}
+		// A row out of a partial unique index doesn't conflict with the rows in it.
+		covered, err1 := v.Covers(ctx, row)
+		if err1 != nil {
+			return nil, err1
+		}
+		if !covered {
+			continue
+		}
 		colVals, err1 := v.FetchValues(row, nil)
 		if err1 != nil {
 			return nil, err1
@@ -108,6 +108,10 @@ func (b *executorBuilder) build(p plannercore.Plan) Executor {
 		return b.buildMemTable(v)
 	case *plannercore.PhysicalTableDual:
 		return b.buildTableDual(v)
+	case *plannercore.PhysicalValues:
+		return b.buildValues(v)
+	case *plannercore.PhysicalUnionAll:
+		return b.buildUnionAll(v)
 	case *plannercore.Analyze:
 		return b.buildAnalyze(v)
 	case *plannercore.PhysicalTableReader:
@@ -261,6 +265,7 @@ func (b *executorBuilder) buildInsert(v *plannercore.Insert) Executor {
 	}
 	insert := &InsertExec{
 		InsertValues: ivs,
+		OnDuplicate:  v.OnDuplicate,
 	}
 	return insert
 }
@@ -404,6 +409,7 @@ func (b *executorBuilder) buildMergeJoin(v *plannercore.PhysicalMergeJoin) Execu
 	e.innerTable = &mergeJoinInnerTable{
 		reader:   rightExec,
 		joinKeys: rightKeys,
+		memQuota: b.ctx.GetSessionVars().MemQuotaMergeJoin,
 	}
 
 	e.outerTable = &mergeJoinOuterTable{
@@ -595,6 +601,28 @@ func (b *executorBuilder) buildTableDual(v *plannercore.PhysicalTableDual) Execu
 	return e
 }
 
+func (b *executorBuilder) buildValues(v *plannercore.PhysicalValues) Executor {
+	e := &ValuesExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ExplainID()),
+		lists:        v.Lists,
+	}
+	return e
+}
+
+func (b *executorBuilder) buildUnionAll(v *plannercore.PhysicalUnionAll) Executor {
+	childExecs := make([]Executor, len(v.Children()))
+	for i, child := range v.Children() {
+		childExecs[i] = b.build(child)
+		if b.err != nil {
+			return nil
+		}
+	}
+	e := &UnionExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ExplainID(), childExecs...),
+	}
+	return e
+}
+
 func (b *executorBuilder) getStartTS() (uint64, error) {
 	if b.startTS != 0 {
 		// Return the cached value.
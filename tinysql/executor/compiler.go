@@ -26,6 +26,8 @@ import (
 // Compiler compiles an ast.StmtNode to a physical plan.
 type Compiler struct {
 	Ctx sessionctx.Context
+	// Sink receives the result of the compiled statement instead of the client if it's set.
+	Sink ResultSink
 }
 
 // Compile compiles an ast.StmtNode to a physical plan.
@@ -46,5 +48,6 @@ func (c *Compiler) Compile(ctx context.Context, stmtNode ast.StmtNode) (*ExecStm
 		StmtNode:    stmtNode,
 		Ctx:         c.Ctx,
 		OutputNames: names,
+		Sink:        c.Sink,
 	}, nil
 }
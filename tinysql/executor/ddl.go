@@ -138,7 +138,7 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := domain.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.KeyType, model.NewCIStr(s.IndexName),
-		s.IndexPartSpecifications, s.IndexOption, s.IfNotExists)
+		s.IndexPartSpecifications, s.IndexOption, s.Where, s.IfNotExists)
 	return err
 }
 
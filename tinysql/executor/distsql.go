@@ -51,7 +51,7 @@ var LookupTableTaskChannelSize int32 = 50
 // lookupTableTask is created from a partial result of an index request which
 // contains the handles in those index keys.
 type lookupTableTask struct {
-	handles []int64
+	handles lookupHandles
 	rowIdx  []int // rowIdx represents the handle index for every row. Only used when keep order.
 	rows    []chunk.Row
 	idxRows *chunk.Chunk
@@ -181,6 +181,8 @@ type IndexReaderExecutor struct {
 
 	// result returns one or more distsql.PartialResult and each PartialResult is returned by one region.
 	result distsql.SelectResult
+	// cancel cancels the context of the cop requests, so the kv layer stops working on them once the executor is closed.
+	cancel context.CancelFunc
 	// columns are only required by union scan.
 	columns []*model.ColumnInfo
 	// outputColumns are only required by union scan.
@@ -193,6 +195,10 @@ type IndexReaderExecutor struct {
 
 // Close clears all resources hold by current object.
 func (e *IndexReaderExecutor) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
 	err := e.result.Close()
 	e.result = nil
 	return err
@@ -229,7 +235,12 @@ func (e *IndexReaderExecutor) open(ctx context.Context, kvRanges []kv.KeyRange)
 	if err != nil {
 		return err
 	}
+	ctx, e.cancel = context.WithCancel(ctx)
 	e.result, err = distsql.Select(ctx, e.ctx, kvReq, retTypes(e))
+	if err != nil {
+		e.cancel()
+		e.cancel = nil
+	}
 	return err
 }
 
@@ -255,6 +266,8 @@ type IndexLookUpExecutor struct {
 	idxWorkerWg sync.WaitGroup
 	tblWorkerWg sync.WaitGroup
 	finished    chan struct{}
+	// cancel cancels the context of the workers and their cop requests.
+	cancel context.CancelFunc
 
 	kvRanges      []kv.KeyRange
 	workerStarted bool
@@ -289,7 +302,10 @@ func (e *IndexLookUpExecutor) startWorkers(ctx context.Context, initBatchSize in
 	// indexWorker will write to workCh and tableWorker will read from workCh,
 	// so fetching index and getting table data can run concurrently.
 	workCh := make(chan *lookupTableTask, 1)
+	ctx, e.cancel = context.WithCancel(ctx)
 	if err := e.startIndexWorker(ctx, e.kvRanges, workCh, initBatchSize); err != nil {
+		e.cancel()
+		e.cancel = nil
 		return err
 	}
 	e.startTableWorker(ctx, workCh)
@@ -315,19 +331,7 @@ func (e *IndexLookUpExecutor) startIndexWorker(ctx context.Context, kvRanges []k
 	if err != nil {
 		return err
 	}
-	worker := &indexWorker{
-		idxLookup:    e,
-		workCh:       workCh,
-		finished:     e.finished,
-		resultCh:     e.resultCh,
-		keepOrder:    e.keepOrder,
-		batchSize:    initBatchSize,
-		maxBatchSize: e.ctx.GetSessionVars().IndexLookupSize,
-		maxChunkSize: e.maxChunkSize,
-	}
-	if worker.batchSize > worker.maxBatchSize {
-		worker.batchSize = worker.maxBatchSize
-	}
+	worker := e.newIndexWorker(workCh, initBatchSize)
 	e.idxWorkerWg.Add(1)
 	go func() {
 		ctx1, cancel := context.WithCancel(ctx)
@@ -346,9 +350,37 @@ func (e *IndexLookUpExecutor) startIndexWorker(ctx context.Context, kvRanges []k
 	return nil
 }
 
+// newIndexWorker creates the indexWorker whose first batch has initBatchSize handles, the size is raised to
+// the min batch size of the session and capped by the max batch size.
+func (e *IndexLookUpExecutor) newIndexWorker(workCh chan<- *lookupTableTask, initBatchSize int) *indexWorker {
+	sessVars := e.ctx.GetSessionVars()
+	worker := &indexWorker{
+		idxLookup:    e,
+		workCh:       workCh,
+		finished:     e.finished,
+		resultCh:     e.resultCh,
+		keepOrder:    e.keepOrder,
+		batchSize:    initBatchSize,
+		maxBatchSize: sessVars.IndexLookupSize,
+		maxChunkSize: e.maxChunkSize,
+	}
+	if worker.batchSize < sessVars.IndexLookupMinBatchSize {
+		worker.batchSize = sessVars.IndexLookupMinBatchSize
+	}
+	if worker.batchSize > worker.maxBatchSize {
+		worker.batchSize = worker.maxBatchSize
+	}
+	return worker
+}
+
 // startTableWorker launchs some background goroutines which pick tasks from workCh and execute the task.
 func (e *IndexLookUpExecutor) startTableWorker(ctx context.Context, workCh <-chan *lookupTableTask) {
 	lookupConcurrencyLimit := e.ctx.GetSessionVars().IndexLookupConcurrency
+	var cache *LookupRowCache
+	cacheLayout, cacheHandleIdx, ok := e.lookupCacheLayout()
+	if ok {
+		cache = getLookupRowCache(e.ctx, e.startTS)
+	}
 	e.tblWorkerWg.Add(lookupConcurrencyLimit)
 	for i := 0; i < lookupConcurrencyLimit; i++ {
 		worker := &tableWorker{
@@ -358,6 +390,9 @@ func (e *IndexLookUpExecutor) startTableWorker(ctx context.Context, workCh <-cha
 			buildTblReader: e.buildTableReader,
 			keepOrder:      e.keepOrder,
 			handleIdx:      e.handleIdx,
+			cache:          cache,
+			cacheLayout:    cacheLayout,
+			cacheHandleIdx: cacheHandleIdx,
 		}
 		ctx1, cancel := context.WithCancel(ctx)
 		go func() {
@@ -392,6 +427,9 @@ func (e *IndexLookUpExecutor) Close() error {
 	}
 
 	close(e.finished)
+	// Cancel the outstanding cop requests, the workers blocked on them exit promptly.
+	e.cancel()
+	e.cancel = nil
 	// Drain the resultCh and discard the result, in case that Next() doesn't fully
 	// consume the data, background worker still writing to resultCh and block forever.
 	for range e.resultCh {
@@ -546,7 +584,7 @@ func (w *indexWorker) buildTableTask(handles []int64, retChk *chunk.Chunk) *look
 	}
 
 	task := &lookupTableTask{
-		handles:              handles,
+		handles:              newLookupHandles(handles),
 		indexOrder:           indexOrder,
 		duplicatedIndexOrder: duplicatedIndexOrder,
 		idxRows:              retChk,
@@ -564,6 +602,11 @@ type tableWorker struct {
 	buildTblReader func(ctx context.Context, handles []int64) (Executor, error)
 	keepOrder      bool
 	handleIdx      int
+
+	// cache is the cache of the table rows in the transaction, it's nil if the rows can't be cached.
+	cache          *LookupRowCache
+	cacheLayout    string
+	cacheHandleIdx int
 }
 
 // pickAndExecTask picks tasks from workCh, and execute them.
@@ -599,15 +642,42 @@ func (w *tableWorker) pickAndExecTask(ctx context.Context) {
 // executeTask executes the table look up tasks. We will construct a table reader and send request by handles.
 // Then we hold the returning rows and finish this task.
 func (w *tableWorker) executeTask(ctx context.Context, task *lookupTableTask) error {
-	tableReader, err := w.buildTblReader(ctx, task.handles)
+	handleCnt := task.handles.Len()
+	task.rows = make([]chunk.Row, 0, handleCnt)
+	handles := task.handles.expand()
+	if w.cache != nil {
+		// Only the handles missed in the cache are read from the table.
+		var cachedRows []chunk.Row
+		cachedRows, handles = w.cache.get(w.cacheLayout, handles)
+		task.rows = append(task.rows, cachedRows...)
+	}
+	if len(handles) > 0 {
+		if err := w.readTableRows(ctx, task, handles); err != nil {
+			return err
+		}
+	}
+
+	if w.keepOrder {
+		task.rowIdx = make([]int, 0, len(task.rows))
+		for i := range task.rows {
+			handle := task.rows[i].GetInt64(w.handleIdx)
+			task.rowIdx = append(task.rowIdx, task.indexOrder[handle])
+		}
+		sort.Sort(task)
+	}
+
+	return nil
+}
+
+// readTableRows reads the table rows of the handles and appends them to the task.
+func (w *tableWorker) readTableRows(ctx context.Context, task *lookupTableTask, handles []int64) error {
+	tableReader, err := w.buildTblReader(ctx, handles)
 	if err != nil {
 		logutil.Logger(ctx).Error("build table reader failed", zap.Error(err))
 		return err
 	}
 	defer terror.Call(tableReader.Close)
 
-	handleCnt := len(task.handles)
-	task.rows = make([]chunk.Row, 0, handleCnt)
 	for {
 		chk := newFirstChunk(tableReader)
 		err = Next(ctx, tableReader, chk)
@@ -616,22 +686,14 @@ func (w *tableWorker) executeTask(ctx context.Context, task *lookupTableTask) er
 			return err
 		}
 		if chk.NumRows() == 0 {
-			break
+			return nil
 		}
 		iter := chunk.NewIterator4Chunk(chk)
 		for row := iter.Begin(); row != iter.End(); row = iter.Next() {
 			task.rows = append(task.rows, row)
+			if w.cache != nil {
+				w.cache.put(w.cacheLayout, row.GetInt64(w.cacheHandleIdx), row, retTypes(tableReader))
+			}
 		}
 	}
-
-	if w.keepOrder {
-		task.rowIdx = make([]int, 0, len(task.rows))
-		for i := range task.rows {
-			handle := task.rows[i].GetInt64(w.handleIdx)
-			task.rowIdx = append(task.rowIdx, task.indexOrder[handle])
-		}
-		sort.Sort(task)
-	}
-
-	return nil
 }
@@ -18,10 +18,12 @@ import (
 	"context"
 	"fmt"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/store/tikv"
 	"github.com/pingcap/tidb/tablecodec"
@@ -158,3 +160,211 @@ func (s *testSuite3) TestPushLimitDownIndexLookUpReader(c *C) {
 	tk.MustQuery("select * from tbl use index(idx_b_c) where b > 1 order by b desc limit 2,1").Check(testkit.Rows("3 3 3"))
 	tk.MustQuery("select * from tbl use index(idx_b_c) where b > 1 and c > 1 limit 2,1").Check(testkit.Rows("4 4 4"))
 }
+
+func (s *testSuite3) TestTopNPushDownMultiRegions(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int)")
+	// a is a permutation of [0, 100) which is not in the order of id.
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i, i*37%100))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+
+	dom := domain.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 10)
+
+	// Every region returns only the first offset+count rows, the root TopN picks the result from them.
+	rows := tk.MustQuery("explain select * from t order by a limit 5, 3").Rows()
+	var pushedDown bool
+	for _, row := range rows {
+		info := fmt.Sprintf("%v", row)
+		if strings.Contains(info, "cop") && strings.Contains(info, "offset:0, count:8") {
+			pushedDown = true
+		}
+	}
+	c.Assert(pushedDown, IsTrue, Commentf("%v", rows))
+
+	tk.MustQuery("select a from t order by a limit 5, 3").Check(testkit.Rows("5", "6", "7"))
+	tk.MustQuery("select a from t order by a desc limit 3").Check(testkit.Rows("99", "98", "97"))
+	tk.MustQuery("select id, a from t order by a limit 2").Check(testkit.Rows("0 0", "73 1"))
+	tk.MustQuery("select id, a from t where id > 50 order by a desc limit 2").Check(testkit.Rows("54 98", "81 97"))
+	tk.MustQuery("select a from t order by a limit 98, 5").Check(testkit.Rows("98", "99"))
+}
+
+func (s *testSuite3) TestDistinctPushDown(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int, index idx_a(a))")
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d)", i, i%5, i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+
+	dom := domain.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 10)
+
+	// Every region only returns the distinct values of its index range, the root agg merges them.
+	rows := tk.MustQuery("explain select distinct a from t").Rows()
+	var pushedDown bool
+	for _, row := range rows {
+		info := fmt.Sprintf("%v", row)
+		if strings.Contains(info, "HashAgg") && strings.Contains(info, "cop") {
+			pushedDown = true
+		}
+	}
+	c.Assert(pushedDown, IsTrue, Commentf("%v", rows))
+
+	tk.MustQuery("select distinct a from t order by a").Check(testkit.Rows("0", "1", "2", "3", "4"))
+	tk.MustQuery("select distinct a from t where a > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select count(*) from (select distinct a from t) t1").Check(testkit.Rows("5"))
+}
+
+func (s *testSuite3) TestGroupConcatPushDown(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b varchar(10))")
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, 'x%d')", i, i%5, i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+	tk.MustExec("insert into t values (100, 0, null), (101, null, 'y')")
+	tk.MustExec("analyze table t")
+
+	dom := domain.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 10)
+
+	// findCopAgg returns the estimated rows of the cop aggregation and the table scan.
+	findCopAgg := func(sql string) (aggRows, scanRows float64, found bool) {
+		for _, row := range tk.MustQuery("explain " + sql).Rows() {
+			id, task := fmt.Sprintf("%v", row[0]), fmt.Sprintf("%v", row[2])
+			cnt, err := strconv.ParseFloat(fmt.Sprintf("%v", row[1]), 64)
+			c.Assert(err, IsNil)
+			if strings.Contains(id, "HashAgg") && task == "cop" {
+				aggRows, found = cnt, true
+			}
+			if strings.Contains(id, "TableScan") {
+				scanRows = cnt
+			}
+		}
+		return
+	}
+	// Every region concatenates the values of its groups, so only one partial row per group is sent to the root.
+	aggRows, scanRows, found := findCopAgg("select a, group_concat(id) from t group by a")
+	c.Assert(found, IsTrue)
+	c.Assert(aggRows < scanRows, IsTrue, Commentf("agg rows %v, scan rows %v", aggRows, scanRows))
+	// The partial results concatenated by different regions can't be merged in order.
+	_, _, found = findCopAgg("select a, group_concat(id order by id) from t group by a")
+	c.Assert(found, IsFalse)
+
+	tk.MustQuery("select a, length(group_concat(id)) from t group by a order by a").Check(testkit.Rows(
+		"<nil> 3", "0 61", "1 57", "2 57", "3 57", "4 57"))
+	tk.MustQuery("select group_concat(id order by id) from t where a = 1").Check(testkit.Rows(
+		"1,6,11,16,21,26,31,36,41,46,51,56,61,66,71,76,81,86,91,96"))
+	tk.MustQuery("select group_concat(id, b order by id desc separator ';') from t where id < 10 and a < 2").Check(testkit.Rows(
+		"6x6;5x5;1x1;0x0"))
+	// The rows with any null arg are skipped, the result is null if no row is left.
+	tk.MustQuery("select a, group_concat(b order by id separator '') from t where id >= 95 group by a order by a").Check(testkit.Rows(
+		"<nil> y", "0 x95", "1 x96", "2 x97", "3 x98", "4 x99"))
+	tk.MustQuery("select group_concat(b) from t where id = 100").Check(testkit.Rows("<nil>"))
+	tk.MustQuery("select group_concat(id) from t where id > 200").Check(testkit.Rows("<nil>"))
+
+	// The result is truncated to group_concat_max_len.
+	tk.MustExec("set @@group_concat_max_len = 7")
+	tk.MustQuery("select group_concat(id order by id) from t where a = 1").Check(testkit.Rows("1,6,11,"))
+	warnings := tk.MustQuery("show warnings").Rows()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(fmt.Sprintf("%v", warnings[0][1]), Equals, "1260")
+}
+
+func (s *testSuite3) TestStableTopN(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (id int primary key, a int)")
+	tk.MustExec("create table t1 (a int, b int)")
+	// Every value of a is shared by many rows, so only the handle decides which of them are returned.
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i, i%3))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+	tk.MustExec("insert into t1 values (1, 3), (0, 2), (1, 1), (0, 0)")
+
+	dom := domain.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 10)
+
+	tk.MustExec("set @@tidb_opt_stable_topn = 1")
+	tk.MustQuery("select id, a from t order by a limit 4").Check(testkit.Rows("0 0", "3 0", "6 0", "9 0"))
+	tk.MustQuery("select id from t order by a desc limit 2, 2").Check(testkit.Rows("8", "11"))
+	tk.MustQuery("select a from t where id > 50 order by a limit 2").Check(testkit.Rows("0", "0"))
+	tk.MustQuery("select * from t1 order by a limit 3").Check(testkit.Rows("0 2", "0 0", "1 3"))
+	// The ties are left to the executor when the flag is off, only the order by values are checked.
+	tk.MustExec("set @@tidb_opt_stable_topn = 0")
+	tk.MustQuery("select a from t order by a limit 4").Check(testkit.Rows("0", "0", "0", "0"))
+}
+
+func (s *testSuite3) TestIndexLookUpRowCache(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c int, d int, index idx(b))")
+	var values []string
+	for i := 1; i <= 20; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d)", i, i, i*10))
+	}
+	tk.MustExec("insert into t(a, b, c) values " + strings.Join(values, ","))
+	tk.MustExec("set @@tidb_index_lookup_cache_size = 8")
+
+	tk.MustExec("begin")
+	tk.MustQuery("select * from t use index(idx) where b between 1 and 4 order by a").Check(testkit.Rows(
+		"1 1 10 <nil>", "2 2 20 <nil>", "3 3 30 <nil>", "4 4 40 <nil>"))
+	cache, ok := tk.Se.GetSessionVars().TxnCtx.HandleCache.(*executor.LookupRowCache)
+	c.Assert(ok, IsTrue)
+	hits, misses := cache.Stats()
+	c.Assert(hits, Equals, int64(0))
+	c.Assert(misses, Equals, int64(4))
+
+	// The overlapping handles are read from the cache.
+	tk.MustQuery("select * from t use index(idx) where b between 3 and 6 order by a").Check(testkit.Rows(
+		"3 3 30 <nil>", "4 4 40 <nil>", "5 5 50 <nil>", "6 6 60 <nil>"))
+	hits, misses = cache.Stats()
+	c.Assert(hits, Equals, int64(2))
+	c.Assert(misses, Equals, int64(6))
+
+	// The rows read by a different layout are not shared.
+	tk.MustQuery("select a, c from t use index(idx) where b between 3 and 6 order by a").Check(testkit.Rows(
+		"3 30", "4 40", "5 50", "6 60"))
+	hits, misses = cache.Stats()
+	c.Assert(hits, Equals, int64(2))
+	c.Assert(misses, Equals, int64(10))
+
+	// The least recently used rows are evicted.
+	tk.MustQuery("select * from t use index(idx) where b between 1 and 2 order by a").Check(testkit.Rows(
+		"1 1 10 <nil>", "2 2 20 <nil>"))
+	hits, misses = cache.Stats()
+	c.Assert(hits, Equals, int64(2))
+	c.Assert(misses, Equals, int64(12))
+	tk.MustExec("commit")
+
+	// The cache is invalidated when the transaction ends.
+	tk.MustExec("delete from t where a = 3")
+	tk.MustExec("insert into t(a, b, c) values (3, 3, 0)")
+	tk.MustQuery("select * from t use index(idx) where b between 3 and 4 order by a").Check(testkit.Rows(
+		"3 3 0 <nil>", "4 4 40 <nil>"))
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.HandleCache, Not(Equals), cache)
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/stringutil"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+// blockingCopClient sends the cop requests whose responses block until their contexts are canceled.
+type blockingCopClient struct {
+	sent chan context.Context
+}
+
+func (c *blockingCopClient) Send(ctx context.Context, req *kv.Request, vars *kv.Variables) kv.Response {
+	c.sent <- ctx
+	return &blockingCopResponse{ctx: ctx}
+}
+
+func (c *blockingCopClient) IsRequestTypeSupported(reqType, subType int64) bool {
+	return true
+}
+
+type blockingCopResponse struct {
+	ctx context.Context
+}
+
+func (r *blockingCopResponse) Next(ctx context.Context) (kv.ResultSubset, error) {
+	<-r.ctx.Done()
+	return nil, r.ctx.Err()
+}
+
+func (r *blockingCopResponse) Close() error {
+	return nil
+}
+
+func (s *pkgTestSuite) TestCloseCancelsCopRequests(c *C) {
+	client := &blockingCopClient{sent: make(chan context.Context, 1)}
+	sctx := mock.NewContext()
+	sctx.Store = &mock.Store{Client: client}
+
+	tblInfo := &model.TableInfo{ID: 1, Name: model.NewCIStr("t")}
+	idxInfo := &model.IndexInfo{ID: 1, Name: model.NewCIStr("idx")}
+	col := &expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong)}
+	newBase := func(id string) baseExecutor {
+		return newBaseExecutor(sctx, expression.NewSchema(col), stringutil.StringerStr(id))
+	}
+	waitCancel := func(copCtx context.Context) {
+		select {
+		case <-copCtx.Done():
+		case <-time.After(5 * time.Second):
+			c.Fatal("the cop request isn't canceled after closing the executor")
+		}
+	}
+
+	reader := &IndexReaderExecutor{
+		baseExecutor:    newBase("IndexReader"),
+		table:           tables.MockTableFromMeta(tblInfo),
+		index:           idxInfo,
+		physicalTableID: tblInfo.ID,
+		dagPB:           &tipb.DAGRequest{},
+	}
+	c.Assert(reader.Open(context.Background()), IsNil)
+	copCtx := <-client.sent
+	c.Assert(copCtx.Err(), IsNil)
+	c.Assert(reader.Close(), IsNil)
+	waitCancel(copCtx)
+
+	lookup := &IndexLookUpExecutor{
+		baseExecutor: newBase("IndexLookUp"),
+		table:        tables.MockTableFromMeta(tblInfo),
+		index:        idxInfo,
+		dagPB:        &tipb.DAGRequest{},
+	}
+	c.Assert(lookup.Open(context.Background()), IsNil)
+	c.Assert(lookup.startWorkers(context.Background(), 1), IsNil)
+	copCtx = <-client.sent
+	c.Assert(copCtx.Err(), IsNil)
+	// The index worker is blocked on reading the cop response, Close returns only if the request is canceled.
+	closed := make(chan error, 1)
+	go func() {
+		closed <- lookup.Close()
+	}()
+	waitCancel(copCtx)
+	select {
+	case err := <-closed:
+		c.Assert(err, IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatal("closing the executor is blocked by the outstanding cop request")
+	}
+}
+
+// handleSelectResult returns the handles 0, 1, ..., total-1 by the required rows of the chunk.
+type handleSelectResult struct {
+	next  int64
+	total int64
+}
+
+func (r *handleSelectResult) NextRaw(context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (r *handleSelectResult) Next(ctx context.Context, chk *chunk.Chunk) error {
+	chk.Reset()
+	for !chk.IsFull() && r.next < r.total {
+		chk.AppendInt64(0, r.next)
+		r.next++
+	}
+	return nil
+}
+
+func (r *handleSelectResult) Close() error {
+	return nil
+}
+
+func (s *pkgTestSuite) TestIndexWorkerMinBatchSize(c *C) {
+	sctx := mock.NewContext()
+	sessVars := sctx.GetSessionVars()
+	sessVars.IndexLookupSize = 100
+	lookup := &IndexLookUpExecutor{
+		baseExecutor: newBaseExecutor(sctx, expression.NewSchema(), stringutil.StringerStr("IndexLookUp")),
+	}
+	firstBatch := func(initBatchSize int) int {
+		worker := lookup.newIndexWorker(nil, initBatchSize)
+		chk := chunk.NewChunkWithCapacity([]*types.FieldType{types.NewFieldType(mysql.TypeLonglong)}, lookup.maxChunkSize)
+		handles, _, _, err := worker.extractTaskHandles(context.Background(), chk, &handleSelectResult{total: 1000}, 0)
+		c.Assert(err, IsNil)
+		return len(handles)
+	}
+
+	tests := []struct {
+		minBatchSize  int
+		initBatchSize int
+		firstBatch    int
+	}{
+		// The floor is disabled.
+		{0, 1, 1},
+		{0, 10, 10},
+		// The first batch is raised to the floor.
+		{32, 1, 32},
+		{32, 10, 32},
+		{32, 64, 64},
+		// The floor still respects the max batch size.
+		{200, 1, 100},
+		{32, 500, 100},
+	}
+	for _, tt := range tests {
+		sessVars.IndexLookupMinBatchSize = tt.minBatchSize
+		c.Assert(firstBatch(tt.initBatchSize), Equals, tt.firstBatch, Commentf("%+v", tt))
+	}
+}
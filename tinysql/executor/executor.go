@@ -25,6 +25,8 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/terror"
+	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/table"
@@ -50,8 +52,41 @@ var (
 	_ Executor = &TableReaderExecutor{}
 	_ Executor = &TableScanExec{}
 	_ Executor = &TopNExec{}
+	_ Executor = &ValuesExec{}
 )
 
+func init() {
+	// The planner evaluates the uncorrelated subqueries while building the plan, but it
+	// can't import the executor package because of the dependency cycle, so the function
+	// is assigned here.
+	plannercore.EvalSubquery = func(ctx context.Context, p plannercore.PhysicalPlan, is infoschema.InfoSchema, sctx sessionctx.Context) ([][]types.Datum, error) {
+		b := newExecutorBuilder(sctx, is)
+		exec := b.build(p)
+		if b.err != nil {
+			return nil, b.err
+		}
+		err := exec.Open(ctx)
+		defer terror.Call(exec.Close)
+		if err != nil {
+			return nil, err
+		}
+		var rows [][]types.Datum
+		chk := newFirstChunk(exec)
+		for {
+			err = Next(ctx, exec, chk)
+			if err != nil {
+				return nil, err
+			}
+			if chk.NumRows() == 0 {
+				return rows, nil
+			}
+			for i := 0; i < chk.NumRows(); i++ {
+				rows = append(rows, chk.GetRow(i).GetDatumRow(retTypes(exec)))
+			}
+		}
+	}
+}
+
 type baseExecutor struct {
 	ctx           sessionctx.Context
 	id            fmt.Stringer
@@ -342,6 +377,116 @@ func (e *TableDualExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	return nil
 }
 
+// ValuesExec represents an executor that outputs the rows of a VALUES list.
+type ValuesExec struct {
+	baseExecutor
+
+	lists  [][]expression.Expression
+	cursor int
+}
+
+// Open implements the Executor Open interface.
+func (e *ValuesExec) Open(ctx context.Context) error {
+	e.cursor = 0
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *ValuesExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.Schema().Len() == 0 {
+		numRows := mathutil.Min(len(e.lists)-e.cursor, req.RequiredRows())
+		req.SetNumVirtualRows(numRows)
+		e.cursor += numRows
+		return nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for ; e.cursor < len(e.lists) && !req.IsFull(); e.cursor++ {
+		for i, expr := range e.lists[e.cursor] {
+			val, err := expr.Eval(chunk.Row{})
+			if err != nil {
+				return err
+			}
+			// The values of a column may have different types, they are converted to the type of the column.
+			val, err = val.ConvertTo(sc, e.retFieldTypes[i])
+			if err != nil {
+				return err
+			}
+			req.AppendDatum(i, &val)
+		}
+	}
+	return nil
+}
+
+// UnionExec represents an executor that outputs the rows of its children one after another.
+// The rows of a child whose types differ from the union's are converted to the union's types.
+type UnionExec struct {
+	baseExecutor
+
+	childIdx     int
+	childResults []*chunk.Chunk
+}
+
+// Open implements the Executor Open interface.
+func (e *UnionExec) Open(ctx context.Context) error {
+	if err := e.baseExecutor.Open(ctx); err != nil {
+		return err
+	}
+	e.childIdx = 0
+	e.childResults = make([]*chunk.Chunk, len(e.children))
+	for i, child := range e.children {
+		for j, tp := range retTypes(child) {
+			if !tp.Equal(e.retFieldTypes[j]) {
+				e.childResults[i] = newFirstChunk(child)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *UnionExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	for ; e.childIdx < len(e.children); e.childIdx++ {
+		child, childResult := e.children[e.childIdx], e.childResults[e.childIdx]
+		if childResult == nil {
+			if err := Next(ctx, child, req); err != nil {
+				return err
+			}
+			if req.NumRows() > 0 {
+				return nil
+			}
+			continue
+		}
+		childResult.SetRequiredRows(req.RequiredRows(), e.maxChunkSize)
+		if err := Next(ctx, child, childResult); err != nil {
+			return err
+		}
+		if childResult.NumRows() > 0 {
+			return e.convertRows(childResult, retTypes(child), req)
+		}
+	}
+	return nil
+}
+
+// convertRows converts the rows of a child to the types of the union and appends them to req.
+func (e *UnionExec) convertRows(childResult *chunk.Chunk, childTypes []*types.FieldType, req *chunk.Chunk) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for i := 0; i < childResult.NumRows(); i++ {
+		row := childResult.GetRow(i)
+		for j, tp := range childTypes {
+			val := row.GetDatum(j, tp)
+			val, err := val.ConvertTo(sc, e.retFieldTypes[j])
+			if err != nil {
+				return err
+			}
+			req.AppendDatum(j, &val)
+		}
+	}
+	return nil
+}
+
 // SelectionExec represents a filter executor.
 type SelectionExec struct {
 	baseExecutor
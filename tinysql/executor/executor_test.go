@@ -14,6 +14,7 @@
 package executor_test
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/store/mockstore/mocktikv"
 	"github.com/pingcap/tidb/util/logutil"
@@ -158,6 +160,12 @@ func (s *testSuiteP1) TestSelectWithoutFrom(c *C) {
 
 	r = tk.MustQuery("select 1 order by 1;")
 	r.Check(testkit.Rows("1"))
+
+	r = tk.MustQuery("select 1+1, 2*3, 'a', 1 > 2, null;")
+	r.Check(testkit.Rows("2 6 a 0 <nil>"))
+
+	r = tk.MustQuery("select ifnull(null, 5), if(1 < 2, 'x', 'y'), -(1+2)*3;")
+	r.Check(testkit.Rows("5 x -9"))
 }
 
 // TestSelectBackslashN Issue 3685.
@@ -581,6 +589,29 @@ func (s *testSuiteP1) TestOrderBy(c *C) {
 	tk.MustQuery("select c1 as c2 from t order by c2 + 1").Check(testkit.Rows("2", "1"))
 }
 
+func (s *testSuiteP1) TestOrderByNullOrder(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, index idx_b(b))")
+	tk.MustExec("insert into t values (1, 2), (2, null), (3, 1), (4, null)")
+
+	// NULLs are the smallest values by default.
+	tk.MustQuery("select b from t order by b").Check(testkit.Rows("<nil>", "<nil>", "1", "2"))
+	tk.MustQuery("select b from t order by b desc").Check(testkit.Rows("2", "1", "<nil>", "<nil>"))
+	tk.MustQuery("select b from t order by b nulls first").Check(testkit.Rows("<nil>", "<nil>", "1", "2"))
+	tk.MustQuery("select b from t order by b desc nulls last").Check(testkit.Rows("2", "1", "<nil>", "<nil>"))
+
+	tk.MustQuery("select b from t order by b nulls last").Check(testkit.Rows("1", "2", "<nil>", "<nil>"))
+	tk.MustQuery("select b from t order by b asc nulls last").Check(testkit.Rows("1", "2", "<nil>", "<nil>"))
+	tk.MustQuery("select b from t order by b desc nulls first").Check(testkit.Rows("<nil>", "<nil>", "2", "1"))
+	tk.MustQuery("select a, b from t order by b nulls last, a desc").Check(testkit.Rows("3 1", "1 2", "4 <nil>", "2 <nil>"))
+
+	// The same for TopN, it can't be pushed down to the coprocessor.
+	tk.MustQuery("select b from t order by b nulls last limit 3").Check(testkit.Rows("1", "2", "<nil>"))
+	tk.MustQuery("select b from t order by b desc nulls first limit 3").Check(testkit.Rows("<nil>", "<nil>", "2"))
+	tk.MustQuery("select b from t use index(idx_b) order by b nulls last limit 1").Check(testkit.Rows("1"))
+}
+
 func (s *testSuiteP1) TestSelectErrorRow(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -777,6 +808,22 @@ func (s *testSuite8) TestIndexScan(c *C) {
 	result.Check(testkit.Rows())
 }
 
+func (s *testSuite8) TestPrefixIndexScan(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, name varchar(20), index idx_name(name(5)))")
+	tk.MustExec("insert t values (1, 'abcdefgh'), (2, 'abcdexyz'), (3, 'abcde'), (4, 'abc'), (5, 'bcdefgh')")
+	// The range is built on the first 5 chars, and the rows sharing the prefix are filtered by the full value.
+	tk.MustQuery("select id from t use index(idx_name) where name = 'abcdefgh'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t use index(idx_name) where name = 'abcde'").Check(testkit.Rows("3"))
+	tk.MustQuery("select id from t use index(idx_name) where name in ('abcdexyz', 'abc')").Sort().Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select id from t use index(idx_name) where name > 'abcdefgh'").Sort().Check(testkit.Rows("2", "5"))
+	plan := fmt.Sprintf("%v", tk.MustQuery("explain select id from t use index(idx_name) where name = 'abcdefgh'").Rows())
+	c.Assert(plan, Matches, `.*range:\["abcde","abcde"\].*`)
+	c.Assert(plan, Matches, `.*Selection.*eq\(test.t.name, .*abcdefgh.*`)
+}
+
 func (s *testSuiteP1) TestIndexReverseOrder(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -834,6 +881,63 @@ func (s *testSuiteP2) TestTableDual(c *C) {
 	tk.MustQuery("select t1.* from t t1, t t2 where t1.a=t2.a and 1=0").Check(testkit.Rows())
 }
 
+func (s *testSuiteP2) TestValuesTable(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustQuery("select * from (values row(1, 2), row(3, 4)) t").Check(testkit.Rows("1 2", "3 4"))
+	tk.MustQuery("select column_1, column_0 + 1 from (values row(1, 'a'), row(2, 'b')) as t where column_0 > 1").Check(testkit.Rows("b 3"))
+	tk.MustQuery("select count(*) from (values row(1), row(2), row(3)) t").Check(testkit.Rows("3"))
+	// The values of a column are converted to the type which can hold all of them.
+	tk.MustQuery("select * from (values row(1), row(2.5), row(null)) t").Check(testkit.Rows("1", "2.5", "<nil>"))
+	tk.MustQuery("select * from (values row(1), row('x')) t").Check(testkit.Rows("1", "x"))
+	_, err := tk.Exec("select * from (values row(1, 2), row(3)) t")
+	c.Assert(err, NotNil)
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int primary key, b varchar(10))")
+	tk.MustExec("insert into t values (1, 'x'), (2, 'y'), (3, 'z')")
+	tk.MustQuery("select t.b, v.column_1 from t join (values row(1, 'a'), row(3, 'c'), row(4, 'd')) v on t.a = v.column_0 order by t.a").Check(testkit.Rows("x a", "z c"))
+	tk.MustQuery("select t.a, v.column_0 from t left join (values row(2)) v on t.a = v.column_0 order by t.a").Check(testkit.Rows("1 <nil>", "2 2", "3 <nil>"))
+}
+
+func (s *testSuiteP2) TestUnion(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(a int, b varchar(10))")
+	tk.MustExec("create table t2(a int, b double)")
+	tk.MustExec("insert into t1 values (1, 'x'), (2, 'y'), (2, 'y')")
+	tk.MustExec("insert into t2 values (2, 1.5), (3, 2), (null, null)")
+
+	tk.MustQuery("select a from t1 union select a from t2 order by a").Check(testkit.Rows("<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t1 union all select a from t2 order by a").Check(testkit.Rows("<nil>", "1", "2", "2", "2", "3"))
+	tk.MustQuery("select a, b from t1 union select a, b from t1").Sort().Check(testkit.Rows("1 x", "2 y"))
+	// A DISTINCT union overrides the ALL unions to its left.
+	tk.MustQuery("select a from t1 union all select a from t1 union select a from t2 order by a").Check(testkit.Rows("<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t1 union select a from t2 union all select a from t1 order by a").Check(testkit.Rows("<nil>", "1", "1", "2", "2", "2", "3"))
+	tk.MustQuery("select a from t1 union all select a from t2 order by a desc limit 2").Check(testkit.Rows("3", "2"))
+	tk.MustQuery("(select a from t1 order by a limit 1) union all (select a from t2 order by a desc limit 1)").Check(testkit.Rows("1", "3"))
+
+	// The branches are converted to the type which can hold all of them.
+	tk.MustQuery("select b from t1 union all select b from t2 order by b").Check(testkit.Rows("<nil>", "1.5", "2", "x", "y", "y"))
+	tk.MustQuery("select a from t1 union select b from t2 order by a").Check(testkit.Rows("<nil>", "1", "1.5", "2"))
+	tk.MustQuery("select 1 union select 'a'").Sort().Check(testkit.Rows("1", "a"))
+	tk.MustQuery("select 1 union all select 2.5 union all select null").Sort().Check(testkit.Rows("1", "2.5", "<nil>"))
+	// The filter on the union is evaluated on the converted values.
+	tk.MustQuery("select * from (select a from t1 union all select b from t2) t where t.a < 1.6 order by t.a").Check(testkit.Rows("1", "1.5"))
+	tk.MustQuery("select * from (select b from t1 union all select a from t2) t where t.b = '2'").Check(testkit.Rows("2"))
+
+	tk.MustQuery("select count(*) from (select a from t1 union all select a from t2) t").Check(testkit.Rows("6"))
+	tk.MustQuery("select a from t1 where a >= (select a from t2 where a < 3 union select 1 from dual where 1 = 0)").Check(testkit.Rows("2", "2"))
+	tk.MustQuery("select a from t2 where a <= (select max(a) from t1 union select 2)").Check(testkit.Rows("2"))
+	_, err := tk.Exec("select a from t2 where a <= (select max(a) from t1 union all select 2)")
+	c.Assert(err, NotNil)
+
+	_, err = tk.Exec("select a from t1 union select a, b from t2")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[planner:1222]The used SELECT statements have a different number of columns")
+}
+
 func (s *testSuiteP2) TestAdapterStatement(c *C) {
 	se, err := session.CreateSession4Test(s.store)
 	c.Check(err, IsNil)
@@ -852,6 +956,41 @@ func (s *testSuiteP2) TestAdapterStatement(c *C) {
 	c.Check(stmt.OriginText(), Equals, "create table test.t (a int)")
 }
 
+func (s *testSuiteP2) TestExportResultToSink(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c double)")
+	tk.MustExec(`insert into t values (1, 'x', 1.5), (2, 'a,b', null), (3, 'say "hi"', -2)`)
+
+	tk.Se.GetSessionVars().TxnCtx.InfoSchema = domain.GetDomain(tk.Se).InfoSchema()
+	tk.Se.GetSessionVars().StmtCtx = &stmtctx.StatementContext{}
+	buf := new(bytes.Buffer)
+	compiler := &executor.Compiler{Ctx: tk.Se, Sink: executor.NewCSVSink(buf, true)}
+	stmtNode, err := s.ParseOneStmt("select a, b as name, c from t order by a", "", "")
+	c.Assert(err, IsNil)
+	stmt, err := compiler.Compile(context.TODO(), stmtNode)
+	c.Assert(err, IsNil)
+	rs, err := stmt.Exec(context.TODO())
+	c.Assert(err, IsNil)
+	// The result is written into the sink instead of being returned.
+	c.Assert(rs, IsNil)
+	c.Assert(buf.String(), Equals, "a,name,c\n1,x,1.5\n2,\"a,b\",\\N\n3,\"say \"\"hi\"\"\",-2\n")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.AffectedRows(), Equals, uint64(3))
+
+	// Nothing is written for an empty result.
+	buf.Reset()
+	compiler.Sink = executor.NewCSVSink(buf, true)
+	stmtNode, err = s.ParseOneStmt("select a from t where a > 3", "", "")
+	c.Assert(err, IsNil)
+	stmt, err = compiler.Compile(context.TODO(), stmtNode)
+	c.Assert(err, IsNil)
+	rs, err = stmt.Exec(context.TODO())
+	c.Assert(err, IsNil)
+	c.Assert(rs, IsNil)
+	c.Assert(buf.String(), Equals, "")
+}
+
 func (s *testSuiteP2) TestRow(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -1150,6 +1289,52 @@ func (s *testSuite) TestLimit(c *C) {
 	))
 }
 
+func (s *testSuite) TestCaseInsensitiveCollation(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, a varchar(20) collate utf8mb4_general_ci, b varchar(20), index idx_a(a))")
+	tk.MustExec("insert into t values(1, 'a', 'a'), (2, 'B', 'B'), (3, 'A', 'A'), (4, 'b ', 'b'), (5, 'c', 'c')")
+
+	tk.MustQuery("select id from t where a = 'A' order by id").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select id from t where a = 'b' order by id").Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select id from t where a in ('C', 'b') order by id").Check(testkit.Rows("2", "4", "5"))
+	tk.MustQuery("select id from t where a > 'a' order by id").Check(testkit.Rows("2", "4", "5"))
+	tk.MustQuery("select id from t where a < 'B' order by id").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select id from t use index(idx_a) where a = 'a' order by id").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select id from t use index(idx_a) where a >= 'b' order by id").Check(testkit.Rows("2", "4", "5"))
+	// The redundant conditions are removed by the collation too.
+	tk.MustQuery("select id from t where a = 'a' and a = 'A' order by id").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select id from t where a = 'B' and a <= 'b' order by id").Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select id from t where a > 'a' and a < 'C' order by id").Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select id from t where a >= 'b' and a <= 'B' order by id").Check(testkit.Rows("2", "4"))
+	// The binary collation is still case-sensitive.
+	tk.MustQuery("select id from t where b = 'A' order by id").Check(testkit.Rows("3"))
+	tk.MustQuery("select id from t where b = 'a' and b = 'A'").Check(testkit.Rows())
+	tk.MustQuery("select id from t where b > 'a' order by id").Check(testkit.Rows("4", "5"))
+
+	tk.MustQuery("select id from t order by a, id").Check(testkit.Rows("1", "3", "2", "4", "5"))
+	tk.MustQuery("select id from t order by a desc, id limit 3").Check(testkit.Rows("5", "2", "4"))
+	tk.MustQuery("select id from t use index(idx_a) order by a, id").Check(testkit.Rows("1", "3", "2", "4", "5"))
+	tk.MustQuery("select id from t order by b").Check(testkit.Rows("3", "2", "1", "4", "5"))
+
+	// Hash aggregation and hash join group and match the keys by the collation too.
+	tk.MustQuery("select count(*) from t group by a order by count(*)").Check(testkit.Rows("1", "2", "2"))
+	tk.MustQuery("select count(*) from t group by b order by count(*)").Check(testkit.Rows("1", "1", "1", "1", "1"))
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1(a varchar(20) collate utf8mb4_general_ci, b varchar(20))")
+	tk.MustExec("insert into t1 values('A', 'A'), ('b', 'b')")
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t.a = t1.a order by t.id").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t.b = t1.b order by t.id").Check(testkit.Rows("3", "4"))
+	// The case-sensitive collation is used when comparing it with a case-insensitive one, whichever side it's on.
+	tk.MustQuery("select id from t where a = b order by id").Check(testkit.Rows("1", "2", "3", "5"))
+	tk.MustQuery("select id from t where b = a order by id").Check(testkit.Rows("1", "2", "3", "5"))
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t.b = t1.a order by t.id").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t1.a = t.b order by t.id").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t.a = t1.b order by t.id").Check(testkit.Rows("3"))
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.id from t, t1 where t1.b = t.a order by t.id").Check(testkit.Rows("3"))
+}
+
 type testSuite2 struct {
 	*baseTestSuite
 }
@@ -0,0 +1,115 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// ResultSink receives the result of a query instead of the client, e.g. to export it into a file.
+type ResultSink interface {
+	// WriteChunk writes the rows in the chunk. The chunk is reused after it returns.
+	WriteChunk(fields []*ast.ResultField, chk *chunk.Chunk) error
+	// Close flushes the written rows, it's called after all the rows are written.
+	Close() error
+}
+
+// csvNull is how a NULL value is written, the same as SELECT INTO OUTFILE in MySQL.
+const csvNull = `\N`
+
+// CSVSink writes the result of a query into the writer in CSV format.
+type CSVSink struct {
+	w *csv.Writer
+	// header is set to write the column names before the first row.
+	header bool
+	record []string
+}
+
+// NewCSVSink creates a CSVSink writing into w. If header is set, the column names
+// are written as the first line.
+func NewCSVSink(w io.Writer, header bool) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w), header: header}
+}
+
+// WriteChunk implements the ResultSink interface.
+func (s *CSVSink) WriteChunk(fields []*ast.ResultField, chk *chunk.Chunk) error {
+	if s.header {
+		s.header = false
+		s.record = s.record[:0]
+		for _, f := range fields {
+			s.record = append(s.record, f.ColumnAsName.O)
+		}
+		if err := s.w.Write(s.record); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	it := chunk.NewIterator4Chunk(chk)
+	for row := it.Begin(); row != it.End(); row = it.Next() {
+		s.record = s.record[:0]
+		for i, f := range fields {
+			if row.IsNull(i) {
+				s.record = append(s.record, csvNull)
+				continue
+			}
+			d := row.GetDatum(i, &f.Column.FieldType)
+			str, err := d.ToString()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			s.record = append(s.record, str)
+		}
+		if err := s.w.Write(s.record); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// Close implements the ResultSink interface.
+func (s *CSVSink) Close() error {
+	s.w.Flush()
+	return errors.Trace(s.w.Error())
+}
+
+// handleSink writes the result of the executor into the sink of the statement instead of
+// returning it to the client, the number of written rows is reported as affected rows.
+func (a *ExecStmt) handleSink(ctx context.Context, e Executor) error {
+	defer func() {
+		terror.Log(e.Close())
+	}()
+
+	sc := a.Ctx.GetSessionVars().StmtCtx
+	fields := colNames2ResultFields(e.Schema(), a.OutputNames, a.Ctx.GetSessionVars().CurrentDB)
+	chk := newFirstChunk(e)
+	for {
+		if err := Next(ctx, e, chk); err != nil {
+			return err
+		}
+		if chk.NumRows() == 0 {
+			break
+		}
+		if err := a.Sink.WriteChunk(fields, chk); err != nil {
+			return err
+		}
+		sc.AddAffectedRows(uint64(chk.NumRows()))
+	}
+	return a.Sink.Close()
+}
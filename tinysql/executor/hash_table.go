@@ -16,11 +16,13 @@ package executor
 import (
 	"hash"
 	"hash/fnv"
+	"sync"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
 )
@@ -74,8 +76,11 @@ func (hc *hashContext) initHash(rows int) {
 // hashRowContainer handles the rows and the hash map of a table.
 // TODO: support spilling out to disk when memory is limited.
 type hashRowContainer struct {
-	records   *chunk.List
-	hashTable *rowHashMap
+	records *chunk.List
+	// hashTables are the shards of the hash map, a row whose hash key is `key`
+	// is stored in hashTables[key%len(hashTables)].
+	hashTables []*rowHashMap
+	estCount   int
 
 	sc   *stmtctx.StatementContext
 	hCtx *hashContext
@@ -95,8 +100,9 @@ func newHashRowContainer(sctx sessionctx.Context, estCount int, hCtx *hashContex
 		estCount = 0
 	}
 	c := &hashRowContainer{
-		records:   initList,
-		hashTable: newRowHashMap(estCount),
+		records:    initList,
+		hashTables: []*rowHashMap{newRowHashMap(estCount)},
+		estCount:   estCount,
 
 		sc:   sctx.GetSessionVars().StmtCtx,
 		hCtx: hCtx,
@@ -108,7 +114,7 @@ func newHashRowContainer(sctx sessionctx.Context, estCount int, hCtx *hashContex
 // in multiple goroutines while each goroutine should keep its own
 // h and buf.
 func (c *hashRowContainer) GetMatchedRows(probeKey uint64, probeRow chunk.Row, hCtx *hashContext) (matched []chunk.Row, err error) {
-	innerPtrs := c.hashTable.Get(probeKey)
+	innerPtrs := c.hashTableOf(probeKey).Get(probeKey)
 	if len(innerPtrs) == 0 {
 		return
 	}
@@ -140,6 +146,11 @@ func (c *hashRowContainer) matchJoinKey(buildRow, probeRow chunk.Row, probeHCtx
 		probeRow, probeHCtx.allTypes, probeHCtx.keyColIdx)
 }
 
+// hashTableOf returns the shard of the hash map which stores the rows of the hash key.
+func (c *hashRowContainer) hashTableOf(hashKey uint64) *rowHashMap {
+	return c.hashTables[hashKey%uint64(len(c.hashTables))]
+}
+
 // PutChunk puts a chunk into hashRowContainer and build hash map. It's not thread-safe.
 // key of hash table: hash value of key columns
 // value of hash table: RowPtr of the corresponded row
@@ -163,14 +174,109 @@ func (c *hashRowContainer) PutChunk(chk *chunk.Chunk) error {
 		}
 		key := c.hCtx.hashVals[i].Sum64()
 		rowPtr := chunk.RowPtr{ChkIdx: chkIdx, RowIdx: uint32(i)}
-		c.hashTable.Put(key, rowPtr)
+		c.hashTableOf(key).Put(key, rowPtr)
 	}
 	return nil
 }
 
+// AddChunk adds a chunk into hashRowContainer without building the hash map,
+// BuildHashTable should be called after all the chunks are added. It's not thread-safe.
+func (c *hashRowContainer) AddChunk(chk *chunk.Chunk) {
+	c.records.Add(chk)
+}
+
+// hashedRow is a row of the records with its hash key.
+type hashedRow struct {
+	key uint64
+	ptr chunk.RowPtr
+}
+
+// BuildHashTable builds the hash map of the added chunks with `concurrency` goroutines.
+// The chunks are hashed in parallel first, the rows of every chunk are bucketed by
+// the shard of their hash keys. Then the hash map is partitioned into `concurrency`
+// shards and every goroutine populates its own shard from its buckets only, so no
+// lock is needed and GetMatchedRows is still lock free.
+func (c *hashRowContainer) BuildHashTable(concurrency int) error {
+	numChks := c.records.NumChunks()
+	// buckets[chkIdx][shard] are the rows of the chunk which belong to the shard,
+	// the rows with NULL keys are never matched and are left out.
+	buckets := make([][][]hashedRow, numChks)
+	errs := make([]error, concurrency)
+	c.runBuildWorkers(concurrency, errs, func(workerID int) error {
+		hCtx := &hashContext{allTypes: c.hCtx.allTypes, keyColIdx: c.hCtx.keyColIdx}
+		for chkIdx := workerID; chkIdx < numChks; chkIdx += concurrency {
+			chk := c.records.GetChunk(chkIdx)
+			hCtx.initHash(chk.NumRows())
+			for _, colIdx := range hCtx.keyColIdx {
+				err := codec.HashChunkColumns(c.sc, hCtx.hashVals, chk, hCtx.allTypes[colIdx], colIdx, hCtx.buf, hCtx.hasNull)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+			shards := make([][]hashedRow, concurrency)
+			for i := 0; i < chk.NumRows(); i++ {
+				if hCtx.hasNull[i] {
+					continue
+				}
+				key := hCtx.hashVals[i].Sum64()
+				shard := key % uint64(concurrency)
+				shards[shard] = append(shards[shard], hashedRow{key: key, ptr: chunk.RowPtr{ChkIdx: uint32(chkIdx), RowIdx: uint32(i)}})
+			}
+			buckets[chkIdx] = shards
+		}
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	c.hashTables = make([]*rowHashMap, concurrency)
+	c.runBuildWorkers(concurrency, errs, func(workerID int) error {
+		m := newRowHashMap(c.estCount / concurrency)
+		for chkIdx := range buckets {
+			for _, row := range buckets[chkIdx][workerID] {
+				m.Put(row.key, row.ptr)
+			}
+		}
+		c.hashTables[workerID] = m
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runBuildWorkers runs `concurrency` build workers and waits for all of them, the
+// error or the panic of worker i is stored in errs[i].
+func (c *hashRowContainer) runBuildWorkers(concurrency int, errs []error, work func(workerID int) error) {
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		workerID := i
+		go util.WithRecovery(func() {
+			errs[workerID] = work(workerID)
+		}, func(r interface{}) {
+			if r != nil {
+				errs[workerID] = errors.Errorf("%v", r)
+			}
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
+
 // Len returns the length of the records in hashRowContainer.
 func (c hashRowContainer) Len() int {
-	return c.hashTable.Len()
+	length := 0
+	for _, m := range c.hashTables {
+		length += m.Len()
+	}
+	return length
 }
 
 const (
@@ -15,7 +15,10 @@ package executor
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/mock"
 )
 
 func (s *pkgTestSuite) TestRowHashMap(c *C) {
@@ -48,3 +51,49 @@ func (s *pkgTestSuite) TestRowHashMap(c *C) {
 	}
 	c.Check(m.Len(), Equals, totalCount)
 }
+
+func (s *pkgTestSuite) TestHashRowContainerParallelBuild(c *C) {
+	ctx := mock.NewContext()
+	allTypes := []*types.FieldType{types.NewFieldType(mysql.TypeLonglong)}
+	newContainer := func() *hashRowContainer {
+		hCtx := &hashContext{allTypes: allTypes, keyColIdx: []int{0}}
+		return newHashRowContainer(ctx, 0, hCtx, chunk.NewList(allTypes, 32, 32))
+	}
+	genChunks := func() []*chunk.Chunk {
+		chks := make([]*chunk.Chunk, 0, 10)
+		for i := 0; i < 10; i++ {
+			chk := chunk.NewChunkWithCapacity(allTypes, 32)
+			for j := 0; j < 32; j++ {
+				if j%7 == 0 {
+					chk.AppendNull(0)
+				} else {
+					chk.AppendInt64(0, int64((i*32+j)%50))
+				}
+			}
+			chks = append(chks, chk)
+		}
+		return chks
+	}
+
+	serial := newContainer()
+	for _, chk := range genChunks() {
+		c.Assert(serial.PutChunk(chk), IsNil)
+	}
+	parallel := newContainer()
+	for _, chk := range genChunks() {
+		parallel.AddChunk(chk)
+	}
+	c.Assert(parallel.BuildHashTable(4), IsNil)
+
+	// Every build worker populates its own shard.
+	c.Assert(parallel.hashTables, HasLen, 4)
+	for _, m := range parallel.hashTables {
+		c.Assert(m.Len(), Greater, 0)
+	}
+	// Rows with NULL keys are not put into the hash map.
+	c.Assert(serial.Len(), Equals, 10*32-10*5)
+	c.Assert(parallel.Len(), Equals, serial.Len())
+	for key := range serial.hashTables[0].hashTable {
+		c.Assert(parallel.hashTableOf(key).Get(key), DeepEquals, serial.hashTableOf(key).Get(key))
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// LookupRowCache is a LRU cache of the table rows read by the IndexLookUpExecutors in a transaction,
+// so the lookups on the overlapping handles can skip reading the table again. It's stored in the
+// transaction context, so it's invalidated when the transaction ends.
+type LookupRowCache struct {
+	mu       sync.Mutex
+	startTS  uint64
+	capacity int
+	elements map[lookupRowKey]*list.Element
+	lru      *list.List
+
+	hits   int64
+	misses int64
+}
+
+// lookupRowKey identifies a cached row by the layout of the table read and the handle.
+type lookupRowKey struct {
+	layout string
+	handle int64
+}
+
+type lookupRowEntry struct {
+	key lookupRowKey
+	row chunk.Row
+}
+
+// getLookupRowCache gets the LookupRowCache of the current transaction, it returns nil if the cache is disabled.
+func getLookupRowCache(ctx sessionctx.Context, startTS uint64) *LookupRowCache {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.IndexLookupCacheSize <= 0 {
+		return nil
+	}
+	txnCtx := sessVars.TxnCtx
+	if cache, ok := txnCtx.HandleCache.(*LookupRowCache); ok && cache.startTS == startTS {
+		return cache
+	}
+	cache := &LookupRowCache{
+		startTS:  startTS,
+		capacity: sessVars.IndexLookupCacheSize,
+		elements: make(map[lookupRowKey]*list.Element),
+		lru:      list.New(),
+	}
+	txnCtx.HandleCache = cache
+	return cache
+}
+
+// Stats returns the number of handles hit and missed in the cache.
+func (c *LookupRowCache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// get returns the cached rows of the handles, and the handles missed in the cache.
+func (c *LookupRowCache) get(layout string, handles []int64) (rows []chunk.Row, missed []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, h := range handles {
+		if elem, ok := c.elements[lookupRowKey{layout: layout, handle: h}]; ok {
+			c.lru.MoveToFront(elem)
+			rows = append(rows, elem.Value.(*lookupRowEntry).row)
+			continue
+		}
+		missed = append(missed, h)
+	}
+	c.hits += int64(len(rows))
+	c.misses += int64(len(missed))
+	return rows, missed
+}
+
+// put caches a copy of the row, the least recently used row is evicted if the cache is full.
+func (c *LookupRowCache) put(layout string, handle int64, row chunk.Row, fieldTypes []*types.FieldType) {
+	chk := chunk.New(fieldTypes, 1, 1)
+	chk.AppendRow(row)
+	key := lookupRowKey{layout: layout, handle: handle}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.elements[key]; ok {
+		elem.Value.(*lookupRowEntry).row = chk.GetRow(0)
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.elements[key] = c.lru.PushFront(&lookupRowEntry{key: key, row: chk.GetRow(0)})
+	for c.lru.Len() > c.capacity {
+		back := c.lru.Back()
+		c.lru.Remove(back)
+		delete(c.elements, back.Value.(*lookupRowEntry).key)
+	}
+}
+
+// lookupCacheLayout returns the layout of the table rows read by the executor and the offset of the handle
+// in the rows. The rows can't be cached if the handle is not read or the table plan has other operators
+// than the table scan, e.g. the selection filters the rows.
+func (e *IndexLookUpExecutor) lookupCacheLayout() (string, int, bool) {
+	if len(e.tblPlans) != 1 {
+		return "", 0, false
+	}
+	handleOffset := -1
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatInt(getPhysicalTableID(e.table), 10))
+	for i, col := range e.columns {
+		if col.ID == model.ExtraHandleID || (e.table.Meta().PKIsHandle && mysql.HasPriKeyFlag(col.Flag)) {
+			handleOffset = i
+		}
+		sb.WriteByte(',')
+		sb.WriteString(strconv.FormatInt(col.ID, 10))
+	}
+	if handleOffset < 0 {
+		return "", 0, false
+	}
+	return sb.String(), handleOffset, true
+}
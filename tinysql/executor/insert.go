@@ -16,8 +16,13 @@ package executor
 import (
 	"context"
 
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/logutil"
@@ -28,6 +33,10 @@ import (
 type InsertExec struct {
 	*InsertValues
 
+	OnDuplicate    []*expression.Assignment
+	evalBuffer4Dup chunk.MutRow
+	curInsertVals  chunk.MutRow
+
 	Priority mysql.PriorityEnum
 }
 
@@ -40,6 +49,9 @@ func (e *InsertExec) exec(ctx context.Context, rows [][]types.Datum) error {
 	}
 	sessVars.GetWriteStmtBufs().BufStore = kv.NewBufferStore(txn, kv.TempTxnMemBufCap)
 	sessVars.StmtCtx.AddRecordRows(uint64(len(rows)))
+	if len(e.OnDuplicate) > 0 {
+		return e.batchUpdateDupRows(ctx, txn, rows)
+	}
 	for _, row := range rows {
 		logutil.BgLogger().Debug("row", zap.Int("col", len(row)))
 		var err error
@@ -54,6 +66,136 @@ func (e *InsertExec) exec(ctx context.Context, rows [][]types.Datum) error {
 	return nil
 }
 
+// batchUpdateDupRows inserts the rows, and updates the row in the table
+// instead when a row conflicts with it on the primary key or a unique index.
+func (e *InsertExec) batchUpdateDupRows(ctx context.Context, txn kv.Transaction, newRows [][]types.Datum) error {
+	toBeCheckedRows, err := getKeysNeedCheck(ctx, e.ctx, e.Table, newRows)
+	if err != nil {
+		return err
+	}
+	for _, r := range toBeCheckedRows {
+		handle, found, err := e.lookupDupHandle(ctx, txn, r)
+		if err != nil {
+			return err
+		}
+		if found {
+			err = e.updateDupRow(ctx, txn, r, handle)
+		} else {
+			_, err = e.addRecord(ctx, r.row)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupDupHandle returns the handle of the row in the table which has the
+// same primary key or unique index key as the to-be-inserted row.
+func (e *InsertExec) lookupDupHandle(ctx context.Context, txn kv.Transaction, r toBeCheckedRow) (int64, bool, error) {
+	if r.handleKey != nil {
+		_, err := txn.Get(ctx, r.handleKey.newKV.key)
+		if err == nil {
+			handle, err := tablecodec.DecodeRowKey(r.handleKey.newKV.key)
+			return handle, true, err
+		}
+		if !kv.IsErrNotFound(err) {
+			return 0, false, err
+		}
+	}
+	for _, uk := range r.uniqueKeys {
+		val, err := txn.Get(ctx, uk.newKV.key)
+		if err != nil {
+			if kv.IsErrNotFound(err) {
+				continue
+			}
+			return 0, false, err
+		}
+		handle, err := tables.DecodeHandle(val)
+		return handle, true, err
+	}
+	return 0, false, nil
+}
+
+// updateDupRow applies the `ON DUPLICATE KEY UPDATE` assignments on the row
+// identified by handle. `VALUES(col)` in the assignments refers to the
+// to-be-inserted row. See https://dev.mysql.com/doc/refman/5.7/en/insert-on-duplicate.html
+func (e *InsertExec) updateDupRow(ctx context.Context, txn kv.Transaction, r toBeCheckedRow, handle int64) error {
+	oldRow, err := getOldRow(ctx, e.ctx, txn, r.t, handle)
+	if err != nil {
+		if kv.IsErrNotFound(err) {
+			err = errors.NotFoundf("can not be duplicated row, due to old row not found. handle %d", handle)
+		}
+		return err
+	}
+	e.curInsertVals.SetDatums(r.row...)
+	e.ctx.GetSessionVars().CurrInsertValues = e.curInsertVals.ToRow()
+
+	newData := make([]types.Datum, len(oldRow))
+	copy(newData, oldRow)
+	e.evalBuffer4Dup.SetDatums(newData...)
+	cols := r.t.WritableCols()
+	touched := make([]bool, len(newData))
+	for _, assign := range e.OnDuplicate {
+		idx := assign.Col.Index
+		val, err := assign.Expr.Eval(e.evalBuffer4Dup.ToRow())
+		if err != nil {
+			return err
+		}
+		newData[idx], err = table.CastValue(e.ctx, val, cols[idx].ToInfo())
+		if err != nil {
+			return err
+		}
+		// The later assignments see the value assigned by the former ones, as MySQL does.
+		e.evalBuffer4Dup.SetDatum(idx, newData[idx])
+		touched[idx] = true
+	}
+	return e.updateRecord(r.t, handle, oldRow, newData, touched)
+}
+
+// updateRecord writes newData over the row identified by handle. The affected
+// rows is 2 when the row is changed and 0 when it is not, as MySQL does.
+func (e *InsertExec) updateRecord(t table.Table, handle int64, oldData, newData []types.Datum, touched []bool) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	changed, handleChanged := false, false
+	for i, col := range t.WritableCols() {
+		if !touched[i] {
+			continue
+		}
+		cmp, err := newData[i].CompareDatum(sc, &oldData[i])
+		if err != nil {
+			return err
+		}
+		if cmp == 0 {
+			touched[i] = false
+			continue
+		}
+		changed = true
+		if col.IsPKHandleColumn(t.Meta()) {
+			handleChanged = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if handleChanged {
+		// The record key is changed, so the old row is removed and the new one is added.
+		if err := t.RemoveRecord(e.ctx, handle, oldData); err != nil {
+			return err
+		}
+		if _, err := t.AddRecord(e.ctx, newData, table.IsUpdate); err != nil {
+			return err
+		}
+		sc.AddAffectedRows(1)
+		return nil
+	}
+	if err := t.UpdateRecord(e.ctx, handle, oldData, newData, touched); err != nil {
+		return err
+	}
+	sc.AddAffectedRows(2)
+	return nil
+}
+
 // Next implements the Executor Next interface.
 func (e *InsertExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	req.Reset()
@@ -83,6 +225,9 @@ func (e *InsertExec) Close() error {
 
 // Open implements the Executor Open interface.
 func (e *InsertExec) Open(ctx context.Context) error {
+	if len(e.OnDuplicate) > 0 {
+		e.initEvalBuffer4Dup()
+	}
 	if e.SelectExec != nil {
 		var err error
 		// Hint: step II.2
@@ -96,3 +241,12 @@ func (e *InsertExec) Open(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (e *InsertExec) initEvalBuffer4Dup() {
+	colTps := make([]*types.FieldType, 0, len(e.Table.WritableCols()))
+	for _, col := range e.Table.WritableCols() {
+		colTps = append(colTps, &col.FieldType)
+	}
+	e.evalBuffer4Dup = chunk.MutRowFromTypes(colTps)
+	e.curInsertVals = chunk.MutRowFromTypes(colTps[:len(e.Table.Cols())])
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/expression"
 	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
@@ -145,6 +146,27 @@ func (e *HashJoinExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 	return nil
 }
 
+// keyCollationTypes returns the field types used to hash and compare the join keys of one side. The
+// string keys of both sides take the collation derived from the key pair, so the strings equal in a
+// case-insensitive collation fall into the same bucket no matter which side they come from.
+func (e *HashJoinExec) keyCollationTypes(allTypes []*types.FieldType, keys []*expression.Column) []*types.FieldType {
+	keyTypes := make([]*types.FieldType, len(allTypes))
+	copy(keyTypes, allTypes)
+	for i := range keys {
+		ft := keyTypes[keys[i].Index]
+		if ft.EvalType() != types.ETString {
+			continue
+		}
+		collation := expression.DeriveCollationFromExprs(e.outerKeys[i], e.innerKeys[i])
+		if ft.Collate != collation {
+			ft = ft.Clone()
+			ft.Collate = collation
+			keyTypes[keys[i].Index] = ft
+		}
+	}
+	return keyTypes
+}
+
 func (e *HashJoinExec) fetchAndBuildHashTable(ctx context.Context) error {
 	buildKeyColIdx := make([]int, len(e.innerKeys))
 	for i := range e.innerKeys {
@@ -152,12 +174,15 @@ func (e *HashJoinExec) fetchAndBuildHashTable(ctx context.Context) error {
 	}
 	allTypes := e.innerSideExec.base().retFieldTypes
 	hCtx := &hashContext{
-		allTypes:  allTypes,
+		allTypes:  e.keyCollationTypes(allTypes, e.innerKeys),
 		keyColIdx: buildKeyColIdx,
 	}
 	initList := chunk.NewList(allTypes, e.initCap, e.maxChunkSize)
 	e.rowContainer = newHashRowContainer(e.ctx, int(e.innerSideEstCount), hCtx, initList)
 
+	// With more than one worker, the build side is fetched first and then the
+	// hash table is built by multiple goroutines.
+	parallelBuild := e.concurrency > 1
 	for {
 		chk := chunk.NewChunkWithCapacity(e.innerSideExec.base().retFieldTypes, e.ctx.GetSessionVars().MaxChunkSize)
 		err := Next(ctx, e.innerSideExec, chk)
@@ -165,13 +190,21 @@ func (e *HashJoinExec) fetchAndBuildHashTable(ctx context.Context) error {
 			return err
 		}
 		if chk.NumRows() == 0 {
-			return nil
+			break
+		}
+		if parallelBuild {
+			e.rowContainer.AddChunk(chk)
+			continue
 		}
 		err = e.rowContainer.PutChunk(chk)
 		if err != nil {
 			return err
 		}
 	}
+	if parallelBuild {
+		return e.rowContainer.BuildHashTable(int(e.concurrency))
+	}
+	return nil
 }
 
 func (e *HashJoinExec) initializeForOuter() {
@@ -246,18 +279,19 @@ func (e *HashJoinExec) fetchAndProbeHashTable(ctx context.Context) {
 	for i := range e.outerKeys {
 		outerKeyColIdx[i] = e.outerKeys[i].Index
 	}
+	outerTypes := e.keyCollationTypes(retTypes(e.outerSideExec), e.outerKeys)
 
 	// Start e.concurrency join workers to outer hash table and join build side and
 	// outer side rows.
 	for i := uint(0); i < e.concurrency; i++ {
 		e.joinWorkerWaitGroup.Add(1)
 		workID := i
-		go util.WithRecovery(func() { e.runJoinWorker(workID, outerKeyColIdx) }, e.handleJoinWorkerPanic)
+		go util.WithRecovery(func() { e.runJoinWorker(workID, outerTypes, outerKeyColIdx) }, e.handleJoinWorkerPanic)
 	}
 	go util.WithRecovery(e.waitJoinWorkersAndCloseResultChan, nil)
 }
 
-func (e *HashJoinExec) runJoinWorker(workerID uint, outerKeyColIdx []int) {
+func (e *HashJoinExec) runJoinWorker(workerID uint, outerTypes []*types.FieldType, outerKeyColIdx []int) {
 	var (
 		outerSideResult *chunk.Chunk
 		selected        = make([]bool, 0, chunk.InitialCapacity)
@@ -272,7 +306,7 @@ func (e *HashJoinExec) runJoinWorker(workerID uint, outerKeyColIdx []int) {
 		dest: e.outerResultChs[workerID],
 	}
 	hCtx := &hashContext{
-		allTypes:  retTypes(e.outerSideExec),
+		allTypes:  outerTypes,
 		keyColIdx: outerKeyColIdx,
 	}
 	for ok := true; ok; {
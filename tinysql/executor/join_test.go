@@ -19,6 +19,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testutil"
 )
 
 type testSuiteJoin1 struct {
@@ -316,6 +317,51 @@ func (s *testSuiteJoin1) TestJoinLeak(c *C) {
 	tk.MustExec("set @@tidb_hash_join_concurrency=5")
 }
 
+func (s *testSuiteJoin1) TestHashJoinParallelBuild(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b int)")
+	tk.MustExec("create table t2 (a int, b int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3), (null, 4), (5, null)")
+	tk.MustExec("insert into t2 values (1, 10), (1, 11), (2, 20), (null, 40), (4, 50), (5, null)")
+
+	sqls := []string{
+		"select /*+ HASH_JOIN(t1, t2) */ * from t1 join t2 on t1.a = t2.a",
+		"select /*+ HASH_JOIN(t1, t2) */ * from t1 join t2 on t1.a = t2.a and t1.b = t2.b - 9",
+		"select /*+ HASH_JOIN(t1, t2) */ * from t1 left join t2 on t1.a = t2.a",
+		"select /*+ HASH_JOIN(t1, t2) */ * from t1 right join t2 on t1.b = t2.b",
+		"select /*+ HASH_JOIN(t1, t2) */ t1.a, count(*) from t1 join t2 on t1.a = t2.a group by t1.a",
+		"select /*+ HASH_JOIN(t1, t2) */ * from t1 left join t2 on t1.a = t2.a and t2.b > 10",
+	}
+	for _, sql := range sqls {
+		tk.MustExec("set @@tidb_hash_join_concurrency=1")
+		expected := tk.MustQuery(sql).Sort().Rows()
+		tk.MustExec("set @@tidb_hash_join_concurrency=4")
+		tk.MustQuery(sql).Sort().Check(expected)
+	}
+	tk.MustExec("set @@tidb_hash_join_concurrency=5")
+}
+
+func (s *testSuiteJoin1) TestJoinMismatchedKeyTypes(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b int)")
+	tk.MustExec("create table t2 (a varchar(20), b int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3), (null, 4)")
+	tk.MustExec("insert into t2 values ('1', 10), ('01', 10), ('1.0', 20), ('2.5', 20), ('4', 40), (null, 40)")
+
+	// The int and varchar keys are both compared as double, so '01' and '1.0' equal 1 and '2.5' matches nothing.
+	tk.MustQuery("select /*+ HASH_JOIN(t1, t2) */ t1.a, t2.a from t1 join t2 on t1.a = t2.a").Sort().Check(testutil.RowsWithSep(",",
+		"1,01", "1,1", "1,1.0"))
+	tk.MustQuery("select /*+ HASH_JOIN(t1, t2) */ t1.a, t2.b from t1 left join t2 on t1.a = t2.a").Sort().Check(testutil.RowsWithSep(",",
+		"1,10", "1,10", "1,20", "2,<nil>", "3,<nil>", "<nil>,<nil>"))
+	tk.MustQuery("select /*+ HASH_JOIN(t1, t2) */ t1.a, t2.a from t1 right join t2 on t1.a = t2.a and t1.b * 10 = t2.b").Sort().Check(testutil.RowsWithSep(",",
+		"1,01", "1,1", "<nil>,1.0", "<nil>,2.5", "<nil>,4", "<nil>,<nil>"))
+	tk.MustQuery("select /*+ HASH_JOIN(t1, t2) */ count(*) from t1, t2 where t1.a = t2.a and t2.a = '01'").Check(testkit.Rows("1"))
+}
+
 func (s *testSuiteJoin1) TestHashJoinExecEncodeDecodeRow(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -412,3 +458,20 @@ func (s *testSuiteJoin1) TestInjectProjOnTopN(c *C) {
 		"2",
 	))
 }
+
+func (s *testSuiteJoin1) TestJoinConstantOnCondition(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(a int, b int)")
+	tk.MustExec("create table t2(a int, b int)")
+	tk.MustExec("insert into t1 values(1, 1), (2, 2)")
+	tk.MustExec("insert into t2 values(1, 3)")
+	tk.MustQuery("select * from t1 join t2 on 1 = 1 order by t1.a").Check(testkit.Rows("1 1 1 3", "2 2 1 3"))
+	tk.MustQuery("select * from t1 join t2 on true and t1.a = t2.a").Check(testkit.Rows("1 1 1 3"))
+	tk.MustQuery("select * from t1 join t2 on 0").Check(testkit.Rows())
+	tk.MustQuery("select * from t1 join t2 on t1.a = t2.a and null").Check(testkit.Rows())
+	tk.MustQuery("select count(*) from t1 join t2 on 0 where t1.a > 0").Check(testkit.Rows("0"))
+	tk.MustQuery("select * from t1 left join t2 on 0 order by t1.a").Check(testkit.Rows("1 1 <nil> <nil>", "2 2 <nil> <nil>"))
+	tk.MustQuery("select * from t1 right join t2 on 0").Check(testkit.Rows("<nil> <nil> 1 3"))
+}
@@ -25,6 +25,7 @@ var (
 	_ joiner = &leftOuterJoiner{}
 	_ joiner = &rightOuterJoiner{}
 	_ joiner = &innerJoiner{}
+	_ joiner = &semiJoiner{}
 )
 
 // joiner is used to generate join results according to the join type.
@@ -75,6 +76,7 @@ type joiner interface {
 	//   2. 'RightOuterJoin': concats the unmatched outer row with a row of NULLs
 	//      and appends it to the result buffer.
 	//   3. 'InnerJoin': ignores the unmatched outer row.
+	//   4. 'SemiJoin': ignores the unmatched outer row.
 	onMissMatch(outer chunk.Row, chk *chunk.Chunk)
 
 	// Clone deep copies a joiner.
@@ -112,6 +114,9 @@ func newJoiner(ctx sessionctx.Context, joinType plannercore.JoinType,
 	case plannercore.InnerJoin:
 		base.chk = chunk.NewChunkWithCapacity(colTypes, ctx.GetSessionVars().MaxChunkSize)
 		return &innerJoiner{base}
+	case plannercore.SemiJoin:
+		base.shallowRow = chunk.MutRowFromTypes(colTypes)
+		return &semiJoiner{base}
 	}
 	panic("unsupported join type in func newJoiner()")
 }
@@ -149,6 +154,15 @@ func (j *baseJoiner) makeJoinRowToChunk(chk *chunk.Chunk, lhs, rhs chunk.Row) {
 	chk.AppendPartialRow(lhs.Len(), rhs)
 }
 
+// makeShallowJoinRow shallow copies `inner` and `outer` into `shallowRow`.
+func (j *baseJoiner) makeShallowJoinRow(isRightJoin bool, inner, outer chunk.Row) {
+	if !isRightJoin {
+		inner, outer = outer, inner
+	}
+	j.shallowRow.ShallowCopyPartialRow(0, inner)
+	j.shallowRow.ShallowCopyPartialRow(inner.Len(), outer)
+}
+
 // filter is used to filter the result constructed by tryToMatchInners, the result is
 // built by one outer row and multiple inner rows. The returned bool value
 // indicates whether the outer row matches any inner rows.
@@ -408,3 +422,67 @@ func (j *innerJoiner) onMissMatch(outer chunk.Row, chk *chunk.Chunk) {
 func (j *innerJoiner) Clone() joiner {
 	return &innerJoiner{baseJoiner: j.baseJoiner.Clone()}
 }
+
+type semiJoiner struct {
+	baseJoiner
+}
+
+// tryToMatchInners implements joiner interface.
+func (j *semiJoiner) tryToMatchInners(outer chunk.Row, inners chunk.Iterator, chk *chunk.Chunk) (matched bool, hasNull bool, err error) {
+	if inners.Len() == 0 {
+		return false, false, nil
+	}
+
+	if len(j.conditions) == 0 {
+		chk.AppendPartialRow(0, outer)
+		inners.ReachEnd()
+		return true, false, nil
+	}
+
+	for inner := inners.Current(); inner != inners.End(); inner = inners.Next() {
+		j.makeShallowJoinRow(j.outerIsRight, inner, outer)
+
+		// For SemiJoin, we can safely treat null result of join conditions as false,
+		// so we ignore the nullness returned by EvalBool here.
+		matched, _, err = expression.EvalBool(j.ctx, j.conditions, j.shallowRow.ToRow())
+		if err != nil {
+			return false, false, err
+		}
+		if matched {
+			chk.AppendPartialRow(0, outer)
+			inners.ReachEnd()
+			return true, false, nil
+		}
+	}
+	return false, false, nil
+}
+
+func (j *semiJoiner) tryToMatchOuters(outers chunk.Iterator, inner chunk.Row, chk *chunk.Chunk, outerRowStatus []outerRowStatusFlag) (_ []outerRowStatusFlag, err error) {
+	outerRowStatus = outerRowStatus[:0]
+	outer, numToAppend := outers.Current(), chk.RequiredRows()-chk.NumRows()
+	for ; outer != outers.End() && numToAppend > 0; outer, numToAppend = outers.Next(), numToAppend-1 {
+		matched := true
+		if len(j.conditions) > 0 {
+			j.makeShallowJoinRow(j.outerIsRight, inner, outer)
+			matched, _, err = expression.EvalBool(j.ctx, j.conditions, j.shallowRow.ToRow())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if matched {
+			outerRowStatus = append(outerRowStatus, outerRowMatched)
+			chk.AppendPartialRow(0, outer)
+		} else {
+			outerRowStatus = append(outerRowStatus, outerRowUnmatched)
+		}
+	}
+	return outerRowStatus, nil
+}
+
+func (j *semiJoiner) onMissMatch(outer chunk.Row, chk *chunk.Chunk) {
+}
+
+// Clone implements joiner interface.
+func (j *semiJoiner) Clone() joiner {
+	return &semiJoiner{baseJoiner: j.baseJoiner.Clone()}
+}
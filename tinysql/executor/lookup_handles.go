@@ -0,0 +1,80 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import "math"
+
+// handleRange is a run of consecutive handles in [start, end].
+type handleRange struct {
+	start int64
+	end   int64
+}
+
+// lookupHandles stores the handles of a lookupTableTask. When the handles are dense, e.g. the
+// index is clustered with the handle, the runs of consecutive handles are compacted into ranges
+// so they don't take an int64 for every handle while the task is queued. They are expanded when a
+// table worker picks the task to read the table.
+type lookupHandles struct {
+	flat   []int64
+	ranges []handleRange
+	count  int
+}
+
+func newLookupHandles(handles []int64) lookupHandles {
+	runs := 0
+	for i, h := range handles {
+		if i == 0 || !isNextHandle(handles[i-1], h) {
+			runs++
+		}
+	}
+	// A range takes as much memory as two handles.
+	if runs*2 >= len(handles) {
+		return lookupHandles{flat: handles, count: len(handles)}
+	}
+	ranges := make([]handleRange, 0, runs)
+	for i, h := range handles {
+		if i > 0 && isNextHandle(handles[i-1], h) {
+			ranges[len(ranges)-1].end = h
+			continue
+		}
+		ranges = append(ranges, handleRange{start: h, end: h})
+	}
+	return lookupHandles{ranges: ranges, count: len(handles)}
+}
+
+func isNextHandle(prev, h int64) bool {
+	return prev != math.MaxInt64 && h == prev+1
+}
+
+// Len returns the number of the handles.
+func (h *lookupHandles) Len() int {
+	return h.count
+}
+
+// expand returns the handles in their original order.
+func (h *lookupHandles) expand() []int64 {
+	if h.ranges == nil {
+		return h.flat
+	}
+	handles := make([]int64, 0, h.count)
+	for _, r := range h.ranges {
+		for handle := r.start; ; handle++ {
+			handles = append(handles, handle)
+			if handle == r.end {
+				break
+			}
+		}
+	}
+	return handles
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"math"
+
+	. "github.com/pingcap/check"
+)
+
+func (s *pkgTestSuite) TestLookupHandles(c *C) {
+	// Dense handles are compacted into ranges.
+	dense := make([]int64, 0, 3000)
+	for i := int64(0); i < 1000; i++ {
+		dense = append(dense, i)
+	}
+	for i := int64(5000); i < 7000; i++ {
+		dense = append(dense, i)
+	}
+	handles := newLookupHandles(append([]int64(nil), dense...))
+	c.Assert(handles.Len(), Equals, len(dense))
+	// Two ranges are stored instead of 3000 handles.
+	c.Assert(handles.flat, IsNil)
+	c.Assert(handles.ranges, HasLen, 2)
+	c.Assert(handles.expand(), DeepEquals, dense)
+
+	// Sparse handles are kept as they are, and the index order of them is kept.
+	sparse := []int64{9, 3, 4, 7, 1, math.MaxInt64, math.MinInt64}
+	handles = newLookupHandles(sparse)
+	c.Assert(handles.ranges, IsNil)
+	c.Assert(handles.Len(), Equals, len(sparse))
+	c.Assert(handles.expand(), DeepEquals, sparse)
+
+	// The handles out of order are compacted into several ranges.
+	mixed := []int64{10, 11, 12, 13, 1, 2, 3, 4, math.MaxInt64 - 1, math.MaxInt64}
+	handles = newLookupHandles(append([]int64(nil), mixed...))
+	c.Assert(handles.ranges, DeepEquals, []handleRange{{10, 13}, {1, 4}, {math.MaxInt64 - 1, math.MaxInt64}})
+	c.Assert(handles.expand(), DeepEquals, mixed)
+
+	handles = newLookupHandles(nil)
+	c.Assert(handles.Len(), Equals, 0)
+	c.Assert(handles.expand(), HasLen, 0)
+}
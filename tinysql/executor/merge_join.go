@@ -79,6 +79,12 @@ type mergeJoinInnerTable struct {
 	curResultInUse bool
 	resultQueue    []*chunk.Chunk
 	resourceQueue  []*chunk.Chunk
+
+	// memQuota is the memory quota of the rows with the same key. When the chunks holding them
+	// exceed it, the rows are spilled to "rowsInDisk" instead. Zero or negative means no quota.
+	memQuota   int64
+	rowsInDisk *chunk.ListInDisk
+	spillChk   *chunk.Chunk
 }
 
 func (t *mergeJoinInnerTable) init(ctx context.Context, chk4Reader *chunk.Chunk) (err error) {
@@ -99,10 +105,13 @@ func (t *mergeJoinInnerTable) init(ctx context.Context, chk4Reader *chunk.Chunk)
 	return err
 }
 
+// rowsWithSameKey returns the next group of rows with the same join key. If the group is spilled
+// to disk, the returned rows are empty and the group is held by "t.rowsInDisk" instead.
 func (t *mergeJoinInnerTable) rowsWithSameKey() ([]chunk.Row, error) {
-	lastResultIdx := len(t.resultQueue) - 1
-	t.resourceQueue = append(t.resourceQueue, t.resultQueue[0:lastResultIdx]...)
-	t.resultQueue = t.resultQueue[lastResultIdx:]
+	t.recycleResults()
+	if err := t.closeRowsInDisk(); err != nil {
+		return nil, err
+	}
 	// no more data.
 	if t.firstRow4Key == t.curIter.End() {
 		return nil, nil
@@ -110,22 +119,103 @@ func (t *mergeJoinInnerTable) rowsWithSameKey() ([]chunk.Row, error) {
 	t.sameKeyRows = t.sameKeyRows[:0]
 	t.sameKeyRows = append(t.sameKeyRows, t.firstRow4Key)
 	for {
+		numResults := len(t.resultQueue)
 		selectedRow, err := t.nextRow()
 		// error happens or no more data.
 		if err != nil || selectedRow == t.curIter.End() {
 			t.firstRow4Key = t.curIter.End()
-			return t.sameKeyRows, err
+			if err != nil {
+				return nil, err
+			}
+			return t.sameKeyRows, t.finishSpill()
 		}
 		compareResult := compareChunkRow(t.keyCmpFuncs, selectedRow, t.firstRow4Key, t.joinKeys, t.joinKeys)
-		if compareResult == 0 {
-			t.sameKeyRows = append(t.sameKeyRows, selectedRow)
-		} else {
+		if compareResult != 0 {
 			t.firstRow4Key = selectedRow
-			return t.sameKeyRows, nil
+			return t.sameKeyRows, t.finishSpill()
+		}
+		if t.rowsInDisk != nil {
+			err = t.spillRow(selectedRow)
+		} else {
+			t.sameKeyRows = append(t.sameKeyRows, selectedRow)
+			// A new chunk is read to hold the rows, check whether the quota is exceeded.
+			if len(t.resultQueue) > numResults && t.memQuota > 0 && t.memoryUsage() > t.memQuota {
+				err = t.spill()
+			}
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
 }
 
+// recycleResults moves the chunks before "t.curResult" to "t.resourceQueue", the rows in them
+// must not be referenced anymore.
+func (t *mergeJoinInnerTable) recycleResults() {
+	lastResultIdx := len(t.resultQueue) - 1
+	t.resourceQueue = append(t.resourceQueue, t.resultQueue[0:lastResultIdx]...)
+	t.resultQueue = t.resultQueue[lastResultIdx:]
+}
+
+func (t *mergeJoinInnerTable) memoryUsage() (sum int64) {
+	for _, chk := range t.resultQueue {
+		sum += chk.MemoryUsage()
+	}
+	return sum
+}
+
+// spill writes the rows with the current key to disk, and the following rows with the same key
+// are written to disk as soon as they are read.
+func (t *mergeJoinInnerTable) spill() error {
+	// The first row is still used to compare the keys after its chunk is recycled.
+	t.firstRow4Key = t.firstRow4Key.CopyConstruct()
+	t.rowsInDisk = chunk.NewListInDisk(retTypes(t.reader))
+	if t.spillChk == nil {
+		maxChunkSize := t.reader.base().maxChunkSize
+		t.spillChk = chunk.New(retTypes(t.reader), maxChunkSize, maxChunkSize)
+	}
+	for _, row := range t.sameKeyRows {
+		if err := t.spillRow(row); err != nil {
+			return err
+		}
+	}
+	t.sameKeyRows = t.sameKeyRows[:0]
+	t.recycleResults()
+	return nil
+}
+
+func (t *mergeJoinInnerTable) spillRow(row chunk.Row) error {
+	t.spillChk.AppendRow(row)
+	if !t.spillChk.IsFull() {
+		return nil
+	}
+	err := t.rowsInDisk.Add(t.spillChk)
+	t.spillChk.Reset()
+	if len(t.sameKeyRows) == 0 {
+		t.recycleResults()
+	}
+	return err
+}
+
+// finishSpill writes the rows left in "t.spillChk" to disk.
+func (t *mergeJoinInnerTable) finishSpill() error {
+	if t.rowsInDisk == nil || t.spillChk.NumRows() == 0 {
+		return nil
+	}
+	err := t.rowsInDisk.Add(t.spillChk)
+	t.spillChk.Reset()
+	return err
+}
+
+func (t *mergeJoinInnerTable) closeRowsInDisk() error {
+	if t.rowsInDisk == nil {
+		return nil
+	}
+	err := t.rowsInDisk.Close()
+	t.rowsInDisk = nil
+	return err
+}
+
 func (t *mergeJoinInnerTable) nextRow() (chunk.Row, error) {
 	for {
 		if t.curRow == t.curIter.End() {
@@ -187,6 +277,9 @@ func (t *mergeJoinInnerTable) reallocReaderResult() {
 // Close implements the Executor Close interface.
 func (e *MergeJoinExec) Close() error {
 	e.childrenResults = nil
+	if err := e.innerTable.closeRowsInDisk(); err != nil {
+		return err
+	}
 
 	return e.baseExecutor.Close()
 }
@@ -270,7 +363,7 @@ func (e *MergeJoinExec) joinToChunk(ctx context.Context, chk *chunk.Chunk) (hasM
 		}
 
 		cmpResult := -1
-		if e.outerTable.selected[e.outerTable.row.Idx()] && len(e.innerRows) > 0 {
+		if e.outerTable.selected[e.outerTable.row.Idx()] && e.innerIter4Row.Len() > 0 {
 			cmpResult, err = e.compare(e.outerTable.row, e.innerIter4Row.Current())
 			if err != nil {
 				return false, err
@@ -300,6 +393,9 @@ func (e *MergeJoinExec) joinToChunk(ctx context.Context, chk *chunk.Chunk) (hasM
 		}
 
 		matched, _, err := e.joiner.tryToMatchInners(e.outerTable.row, e.innerIter4Row, chk)
+		if err == nil {
+			err = e.innerIterErr()
+		}
 		if err != nil {
 			return false, err
 		}
@@ -312,6 +408,9 @@ func (e *MergeJoinExec) joinToChunk(ctx context.Context, chk *chunk.Chunk) (hasM
 			e.outerTable.row = e.outerTable.iter.Next()
 			e.outerTable.hasMatch = false
 			e.innerIter4Row.Begin()
+			if err = e.innerIterErr(); err != nil {
+				return false, err
+			}
 		}
 
 		if chk.IsFull() {
@@ -343,8 +442,21 @@ func (e *MergeJoinExec) fetchNextInnerRows() (err error) {
 	if err != nil {
 		return err
 	}
-	e.innerIter4Row = chunk.NewIterator4Slice(e.innerRows)
+	if e.innerTable.rowsInDisk != nil {
+		// The join group is too large to be kept in memory, it's read from disk for every outer row.
+		e.innerIter4Row = chunk.NewIterator4ListInDisk(e.innerTable.rowsInDisk)
+	} else {
+		e.innerIter4Row = chunk.NewIterator4Slice(e.innerRows)
+	}
 	e.innerIter4Row.Begin()
+	return e.innerIterErr()
+}
+
+// innerIterErr returns the error happened when reading the join group from disk.
+func (e *MergeJoinExec) innerIterErr() error {
+	if it, ok := e.innerIter4Row.(*chunk.Iterator4ListInDisk); ok {
+		return it.Error()
+	}
 	return nil
 }
 
@@ -340,6 +340,38 @@ func (s *testSuite2) TestMergeJoin(c *C) {
 	))
 }
 
+func (s *testSuite2) TestMergeJoinSpillInnerGroup(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("drop table if exists t2")
+	tk.MustExec("create table t1(a int, b int)")
+	tk.MustExec("create table t2(a int, b int)")
+	tk.MustExec("insert into t1 values(1, 1), (1, 2), (2, 3), (3, 4)")
+	tk.MustExec("insert into t2 values(1, 1), (1, 2), (1, 3), (1, 4)")
+	for i := 0; i < 10; i++ {
+		tk.MustExec("insert into t2 select * from t2 where a = 1")
+	}
+	tk.MustExec("insert into t2 values(3, 1)")
+	tk.MustExec("set @@tidb_max_chunk_size = 32")
+	tk.MustExec("set @@tidb_mem_quota_mergejoin = 1")
+
+	tk.MustQuery("select /*+ TIDB_SMJ(t1, t2) */ count(*), sum(t1.b), sum(t2.b) from t1 join t2 on t1.a = t2.a").Check(testkit.Rows(
+		"8193 12292 20481",
+	))
+	tk.MustQuery("select /*+ TIDB_SMJ(t1, t2) */ t1.a, count(t2.a) from t1 left join t2 on t1.a = t2.a group by t1.a order by t1.a").Check(testkit.Rows(
+		"1 8192",
+		"2 0",
+		"3 1",
+	))
+	tk.MustQuery("select /*+ TIDB_SMJ(t1, t2) */ count(*) from t1 join t2 on t1.a = t2.a and t1.b < t2.b").Check(testkit.Rows("5120"))
+
+	tk.MustExec("set @@tidb_mem_quota_mergejoin = 0")
+	tk.MustQuery("select /*+ TIDB_SMJ(t1, t2) */ count(*), sum(t1.b), sum(t2.b) from t1 join t2 on t1.a = t2.a").Check(testkit.Rows(
+		"8193 12292 20481",
+	))
+}
+
 func (s *testSuite2) Test3WaysMergeJoin(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -23,6 +23,8 @@ import (
 
 	"github.com/cznic/mathutil"
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/parser/ast"
@@ -101,12 +103,24 @@ func (e *ShowExec) fetchAll(ctx context.Context) error {
 		return e.fetchShowDatabases()
 	case ast.ShowTables:
 		return e.fetchShowTables()
+	case ast.ShowTableStatus:
+		return e.fetchShowTableStatus()
 	case ast.ShowVariables:
 		return e.fetchShowVariables()
 	case ast.ShowWarnings:
 		return e.fetchShowWarnings(false)
 	case ast.ShowErrors:
 		return e.fetchShowWarnings(true)
+	case ast.ShowProcessList:
+		return e.fetchShowProcessList()
+	}
+	return nil
+}
+
+func (e *ShowExec) fetchShowProcessList() error {
+	pl := domain.GetDomain(e.ctx).ShowProcessList()
+	for _, pi := range pl {
+		e.appendRow(pi.ToRow(e.Full))
 	}
 	return nil
 }
@@ -159,6 +173,49 @@ func (e *ShowExec) fetchShowTables() error {
 	return nil
 }
 
+// fetchShowTableStatus composes show table status result. The row count and
+// sizes are estimated from the statistics, so they stay zero until the table
+// has been analyzed.
+func (e *ShowExec) fetchShowTableStatus() error {
+	if !e.is.SchemaExists(e.DBName) {
+		return ErrBadDB.GenWithStackByArgs(e.DBName)
+	}
+	tables := append([]table.Table(nil), e.is.SchemaTables(e.DBName)...)
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Meta().Name.O < tables[j].Meta().Name.O
+	})
+	statsHandle := domain.GetDomain(e.ctx).StatsHandle()
+	for _, tbl := range tables {
+		tblInfo := tbl.Meta()
+		var rowCount, avgRowLength uint64
+		if statsHandle != nil {
+			statsTbl := statsHandle.GetTableStats(tblInfo)
+			if !statsTbl.Pseudo && statsTbl.Count > 0 {
+				rowCount = uint64(statsTbl.Count)
+				cols := make([]*expression.Column, 0, len(tblInfo.Columns))
+				for _, col := range tblInfo.Columns {
+					cols = append(cols, &expression.Column{UniqueID: col.ID, RetType: &col.FieldType})
+				}
+				avgRowLength = uint64(statsTbl.GetTableAvgRowSize(cols))
+			}
+		}
+		collation := tblInfo.Collate
+		if collation == "" {
+			collation = mysql.DefaultCollationName
+		}
+		e.appendRow([]interface{}{
+			tblInfo.Name.O,
+			"InnoDB",
+			rowCount,
+			avgRowLength,
+			rowCount * avgRowLength,
+			collation,
+			tblInfo.Comment,
+		})
+	}
+	return nil
+}
+
 func (e *ShowExec) fetchShowVariables() (err error) {
 	var (
 		value         string
@@ -14,7 +14,10 @@
 package executor_test
 
 import (
+	"fmt"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testutil"
 )
@@ -79,3 +82,48 @@ func (s *testSuite5) TestShowEscape(c *C) {
 	tk.MustExec("drop table \"t`abl\"\"e\"")
 	tk.MustExec("set sql_mode=@old_sql_mode")
 }
+
+func (s *testSuite5) TestShowProcessList(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1), (2), (3)")
+
+	// The query is running until its result set is closed.
+	rs, err := tk.Exec("select a from t")
+	c.Assert(err, IsNil)
+	connID := tk.Se.GetSessionVars().ConnectionID
+
+	tk2 := testkit.NewTestKit(c, s.store)
+	result := tk2.MustQuery(fmt.Sprintf("show full processlist where Id = %d", connID))
+	result.Check(testutil.RowsWithSep("|", fmt.Sprintf("%d|test|Query|0|sending data|select a from t", connID)))
+	// The show processlist statement itself is listed too.
+	sql := fmt.Sprintf("show processlist where Id = %d", tk2.Se.GetSessionVars().ConnectionID)
+	tk2.MustQuery(sql).CheckAt([]int{4, 5}, testutil.RowsWithSep("|", "sending data|"+sql))
+
+	c.Assert(rs.Close(), IsNil)
+	tk2.MustQuery(fmt.Sprintf("show processlist where Id = %d", connID)).Check(testkit.Rows())
+}
+
+func (s *testSuite5) TestShowTableStatus(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b varchar(20))")
+	tk.MustExec("create table t2 (a int)")
+	tk.MustExec("insert into t1 values (1, 'a'), (2, 'bb'), (3, 'ccc'), (4, NULL)")
+
+	// The estimates are zero before the table is analyzed.
+	tk.MustQuery("show table status").CheckAt([]int{0, 2, 3, 4}, testkit.Rows("t1 0 0 0", "t2 0 0 0"))
+
+	tk.MustExec("analyze table t1")
+	rows := tk.MustQuery("show table status where Name = 't1'").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][2], Equals, "4")
+	c.Assert(rows[0][3], Not(Equals), "0")
+	c.Assert(rows[0][4], Not(Equals), "0")
+	tk.MustQuery("show table status from test where Name = 't2'").CheckAt([]int{2}, testkit.Rows("0"))
+	err := tk.QueryToErr("show table status from not_exist")
+	c.Assert(executor.ErrBadDB.Equal(err), IsTrue, Commentf("err %v", err))
+}
@@ -16,6 +16,7 @@ package executor
 import (
 	"context"
 
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/ast"
@@ -30,7 +31,7 @@ import (
 
 // SimpleExec represents simple statement executor.
 // For statements do simple execution.
-// includes `UseStmt`,`BeginStmt`, `CommitStmt` and `RollbackStmt`.
+// includes `UseStmt`,`BeginStmt`, `CommitStmt`, `RollbackStmt` and `KillStmt`.
 type SimpleExec struct {
 	baseExecutor
 
@@ -54,6 +55,8 @@ func (e *SimpleExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		e.executeCommit(x)
 	case *ast.RollbackStmt:
 		err = e.executeRollback(x)
+	case *ast.KillStmt:
+		e.executeKill(x)
 	}
 	e.done = true
 	return err
@@ -138,3 +141,11 @@ func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 	}
 	return nil
 }
+
+func (e *SimpleExec) executeKill(s *ast.KillStmt) {
+	sm := domain.GetDomain(e.ctx).SessionManager()
+	if sm == nil {
+		return
+	}
+	sm.Kill(s.ConnectionID, s.Query)
+}
@@ -19,6 +19,7 @@ import (
 	"sort"
 
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
 	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/util/chunk"
 )
@@ -120,13 +121,33 @@ func (e *SortExec) buildKeyColumns() {
 	}
 }
 
-func (e *SortExec) lessRow(rowI, rowJ chunk.Row) bool {
-	for i, colIdx := range e.keyColumns {
-		cmpFunc := e.keyCmpFuncs[i]
-		cmp := cmpFunc(rowI, colIdx, rowJ, colIdx)
-		if e.ByItems[i].Desc {
-			cmp = -cmp
+// compareKey compares the i-th key column of the rows in the order of the i-th ByItem.
+func (e *SortExec) compareKey(i int, rowI, rowJ chunk.Row) int {
+	colIdx := e.keyColumns[i]
+	by := e.ByItems[i]
+	if by.NullOrder != ast.NullOrderDefault {
+		lNull, rNull := rowI.IsNull(colIdx), rowJ.IsNull(colIdx)
+		if lNull || rNull {
+			if lNull == rNull {
+				return 0
+			}
+			// The explicit NULL order doesn't depend on the direction of the sort.
+			if lNull == (by.NullOrder == ast.NullsFirst) {
+				return -1
+			}
+			return 1
 		}
+	}
+	cmp := e.keyCmpFuncs[i](rowI, colIdx, rowJ, colIdx)
+	if by.Desc {
+		cmp = -cmp
+	}
+	return cmp
+}
+
+func (e *SortExec) lessRow(rowI, rowJ chunk.Row) bool {
+	for i := range e.keyColumns {
+		cmp := e.compareKey(i, rowI, rowJ)
 		if cmp < 0 {
 			return true
 		} else if cmp > 0 {
@@ -167,12 +188,8 @@ func (h *topNChunkHeap) Less(i, j int) bool {
 }
 
 func (h *topNChunkHeap) greaterRow(rowI, rowJ chunk.Row) bool {
-	for i, colIdx := range h.keyColumns {
-		cmpFunc := h.keyCmpFuncs[i]
-		cmp := cmpFunc(rowI, colIdx, rowJ, colIdx)
-		if h.ByItems[i].Desc {
-			cmp = -cmp
-		}
+	for i := range h.keyColumns {
+		cmp := h.compareKey(i, rowI, rowJ)
 		if cmp > 0 {
 			return true
 		} else if cmp < 0 {
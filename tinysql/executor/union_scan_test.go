@@ -14,6 +14,9 @@
 package executor_test
 
 import (
+	"fmt"
+	"strings"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testkit"
 )
@@ -98,3 +101,37 @@ func (s *testSuite7) TestUnionScanForMemBufferReader(c *C) {
 	tk.MustQuery("select a,b from t1 use index(idx) where b>1 and c is not null;").Check(testkit.Rows("3 3"))
 	tk.MustExec("commit")
 }
+
+func (s *testSuite7) TestUnionScanOnlyForDirtyTables(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int primary key, b int)")
+	tk.MustExec("create table t2 (a int primary key, b int)")
+	tk.MustExec("insert t1 values (1, 1), (2, 2)")
+	tk.MustExec("insert t2 values (1, 1), (2, 2)")
+
+	hasUnionScan := func(sql string) bool {
+		return strings.Contains(fmt.Sprintf("%v", tk.MustQuery("explain "+sql).Rows()), "UnionScan")
+	}
+	// Neither the autocommit read nor the read before any write in the transaction needs a union scan.
+	c.Assert(hasUnionScan("select * from t1"), IsFalse)
+	tk.MustExec("begin")
+	c.Assert(hasUnionScan("select * from t1"), IsFalse)
+	tk.MustExec("insert t1 values (3, 3)")
+	// Only the written table needs to merge the uncommitted rows.
+	c.Assert(hasUnionScan("select * from t1"), IsTrue)
+	c.Assert(hasUnionScan("select * from t2"), IsFalse)
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 1", "2 2"))
+	tk.MustQuery("select t1.a, t2.b from t1 left join t2 on t1.a = t2.a").Sort().Check(testkit.Rows("1 1", "2 2", "3 <nil>"))
+	tk.MustExec("delete from t2 where a = 1")
+	c.Assert(hasUnionScan("select * from t2"), IsTrue)
+	tk.MustQuery("select * from t2").Check(testkit.Rows("2 2"))
+	tk.MustExec("commit")
+	// A new transaction starts clean.
+	tk.MustExec("begin")
+	c.Assert(hasUnionScan("select * from t1"), IsFalse)
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk.MustExec("rollback")
+}
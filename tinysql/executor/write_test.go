@@ -459,6 +459,60 @@ func (s *testSuite4) TestReplace(c *C) {
 	tk.MustQuery("select * from t1;").Check(testkit.Rows("1 20 30", "2 30 20", "50 20 30"))
 }
 
+func (s *testSuite4) TestInsertOnDuplicateKeyUpdate(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t;")
+	tk.MustExec("create table t (a int primary key, b int, c int, unique index idx_b (b));")
+
+	// No conflict, the row is inserted.
+	tk.MustExec("insert into t values (1, 10, 100) on duplicate key update c = c + 1;")
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(1))
+	tk.MustQuery("select * from t;").Check(testkit.Rows("1 10 100"))
+
+	// Conflict on the primary key, the row is updated.
+	tk.MustExec("insert into t values (1, 20, 200) on duplicate key update c = c + 1;")
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(2))
+	tk.MustQuery("select * from t;").Check(testkit.Rows("1 10 101"))
+
+	// Conflict on the unique index, VALUES() refers to the to-be-inserted row.
+	tk.MustExec("insert into t values (2, 10, 300) on duplicate key update c = values(c), b = values(b) + 1;")
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(2))
+	tk.MustQuery("select * from t;").Check(testkit.Rows("1 11 300"))
+	tk.MustQuery("select * from t use index(idx_b) where b = 11;").Check(testkit.Rows("1 11 300"))
+	tk.MustQuery("select * from t use index(idx_b) where b = 10;").Check(testkit.Rows())
+
+	// The later assignment sees the value assigned by the former one.
+	tk.MustExec("insert into t values (1, 0, 0) on duplicate key update b = 12, c = b;")
+	tk.MustQuery("select * from t;").Check(testkit.Rows("1 12 12"))
+
+	// The row is not changed, so no row is affected.
+	tk.MustExec("insert into t values (1, 0, 0) on duplicate key update c = 12;")
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(0))
+	tk.MustQuery("select * from t;").Check(testkit.Rows("1 12 12"))
+
+	// Update the primary key.
+	tk.MustExec("insert into t values (1, 0, 0) on duplicate key update a = 5;")
+	tk.MustQuery("select * from t;").Check(testkit.Rows("5 12 12"))
+
+	// Multiple rows, the later row conflicts with the former one in the same statement.
+	tk.MustExec("insert into t values (3, 30, 3), (4, 40, 4), (3, 31, 5) on duplicate key update c = c + values(c);")
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(4))
+	tk.MustQuery("select * from t;").Check(testkit.Rows("3 30 8", "4 40 4", "5 12 12"))
+
+	// Insert ... select.
+	tk.MustExec("insert into t select a, b, c from t where a = 4 on duplicate key update c = 0;")
+	tk.MustQuery("select * from t;").Check(testkit.Rows("3 30 8", "4 40 0", "5 12 12"))
+
+	// The update conflicts with another row.
+	_, err := tk.Exec("insert into t values (3, 0, 0) on duplicate key update b = 40;")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select * from t;").Check(testkit.Rows("3 30 8", "4 40 0", "5 12 12"))
+
+	_, err = tk.Exec("insert into t values (3, 0, 0) on duplicate key update d = 1;")
+	c.Assert(err.Error(), Equals, "[planner:1054]Unknown column 'd' in 'field list'")
+}
+
 func (s *testSuite) TestDelete(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	s.fillData(tk, "delete_test")
@@ -481,6 +535,29 @@ func (s *testSuite) TestDelete(c *C) {
 	tk.MustExec("commit")
 }
 
+func (s *testSuite) TestDeleteByIndexRange(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int, index idx_a(a), index idx_b(b))")
+	tk.MustExec("insert into t values (1, 1, 10), (2, 2, 20), (3, 3, 30), (4, 4, 40), (5, 5, 50)")
+
+	tk.MustExec("delete from t where a >= 2 and a < 4")
+	tk.CheckExecResult(2, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1 10", "4 4 40", "5 5 50"))
+	// The index entries of the deleted rows are removed as well.
+	tk.MustQuery("select a from t use index(idx_a)").Check(testkit.Rows("1", "4", "5"))
+	tk.MustQuery("select b from t use index(idx_b)").Check(testkit.Rows("10", "40", "50"))
+
+	tk.MustExec("delete from t where a = 5")
+	tk.CheckExecResult(1, 0)
+	tk.MustExec("delete from t where a = 6")
+	tk.CheckExecResult(0, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1 10", "4 4 40"))
+	tk.MustQuery("select a from t use index(idx_a)").Check(testkit.Rows("1", "4"))
+	tk.MustQuery("select b from t use index(idx_b)").Check(testkit.Rows("10", "40"))
+}
+
 func (s *testSuite4) TestNotNullDefault(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test; drop table if exists t1,t2;")
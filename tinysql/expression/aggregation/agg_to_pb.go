@@ -40,6 +40,12 @@ func AggFuncToPBExpr(sc *stmtctx.StatementContext, client kv.Client, aggFunc *Ag
 		tp = tipb.ExprType_Sum
 	case ast.AggFuncAvg:
 		tp = tipb.ExprType_Avg
+	case ast.AggFuncGroupConcat:
+		// The partial results concatenated by different regions can't be merged in order.
+		if len(aggFunc.OrderByItems) > 0 {
+			return nil
+		}
+		tp = tipb.ExprType_GroupConcat
 	}
 	if !client.IsRequestTypeSupported(kv.ReqTypeSelect, int64(tp)) {
 		return nil
@@ -72,6 +78,8 @@ func PBExprToAggFuncDesc(sc *stmtctx.StatementContext, aggFunc *tipb.Expr, field
 		name = ast.AggFuncSum
 	case tipb.ExprType_Avg:
 		name = ast.AggFuncAvg
+	case tipb.ExprType_GroupConcat:
+		name = ast.AggFuncGroupConcat
 	default:
 		return nil, errors.Errorf("unknown aggregation function type: %v", aggFunc.Tp)
 	}
@@ -257,3 +257,42 @@ func (s *testAggFuncSuit) TestMaxMin(c *C) {
 	partialResult := minFunc.GetPartialResult(minEvalCtx)
 	c.Assert(partialResult[0].GetInt64(), Equals, int64(1))
 }
+
+func (s *testAggFuncSuit) TestGroupConcat(c *C) {
+	col := &expression.Column{
+		Index:   0,
+		RetType: types.NewFieldType(mysql.TypeLonglong),
+	}
+	sep := &expression.Constant{
+		Value:   types.NewStringDatum(","),
+		RetType: types.NewFieldType(mysql.TypeString),
+	}
+
+	ctx := mock.NewContext()
+	desc, err := NewAggFuncDesc(s.ctx, ast.AggFuncGroupConcat, []expression.Expression{col, sep})
+	c.Assert(err, IsNil)
+	concatFunc := desc.GetAggFunc(ctx)
+	evalCtx := concatFunc.CreateContext(s.ctx.GetSessionVars().StmtCtx)
+
+	result := concatFunc.GetResult(evalCtx)
+	c.Assert(result.IsNull(), IsTrue)
+
+	err = concatFunc.Update(evalCtx, s.ctx.GetSessionVars().StmtCtx, s.nullRow)
+	c.Assert(err, IsNil)
+	result = concatFunc.GetResult(evalCtx)
+	c.Assert(result.IsNull(), IsTrue)
+
+	for i := 1; i <= 3; i++ {
+		row := chunk.MutRowFromDatums(types.MakeDatums(i)).ToRow()
+		err = concatFunc.Update(evalCtx, s.ctx.GetSessionVars().StmtCtx, row)
+		c.Assert(err, IsNil)
+	}
+	result = concatFunc.GetResult(evalCtx)
+	c.Assert(result.GetString(), Equals, "1,2,3")
+	partialResult := concatFunc.GetPartialResult(evalCtx)
+	c.Assert(partialResult[0].GetString(), Equals, "1,2,3")
+
+	concatFunc.ResetContext(s.ctx.GetSessionVars().StmtCtx, evalCtx)
+	result = concatFunc.GetResult(evalCtx)
+	c.Assert(result.IsNull(), IsTrue)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregation
+
+import (
+	"bytes"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// concatFunction concatenates the values of GROUP_CONCAT in the order they are read, it's used by the
+// coprocessor to compute the partial results, so the order by clause is not supported.
+// The last arg is the separator. In FinalMode or Partial2Mode, the first arg is the partial result.
+type concatFunction struct {
+	aggFunction
+}
+
+func (cf *concatFunction) separator() (string, error) {
+	sep, err := cf.Args[len(cf.Args)-1].Eval(chunk.Row{})
+	if err != nil {
+		return "", err
+	}
+	return sep.GetString(), nil
+}
+
+// Update implements Aggregation interface.
+func (cf *concatFunction) Update(evalCtx *AggEvaluateContext, sc *stmtctx.StatementContext, row chunk.Row) error {
+	if len(cf.Args) < 2 {
+		return errors.New("Wrong number of args for AggFuncGroupConcat")
+	}
+	datumBuf := make([]types.Datum, 0, len(cf.Args)-1)
+	for _, a := range cf.Args[:len(cf.Args)-1] {
+		value, err := a.Eval(row)
+		if err != nil {
+			return err
+		}
+		if value.IsNull() {
+			return nil
+		}
+		datumBuf = append(datumBuf, value)
+	}
+	sep, err := cf.separator()
+	if err != nil {
+		return err
+	}
+	if evalCtx.Buffer == nil {
+		evalCtx.Buffer = &bytes.Buffer{}
+	} else {
+		evalCtx.Buffer.WriteString(sep)
+	}
+	for _, d := range datumBuf {
+		s, err := d.ToString()
+		if err != nil {
+			return err
+		}
+		evalCtx.Buffer.WriteString(s)
+	}
+	return nil
+}
+
+// ResetContext implements Aggregation interface.
+func (cf *concatFunction) ResetContext(sc *stmtctx.StatementContext, evalCtx *AggEvaluateContext) {
+	evalCtx.Buffer = nil
+}
+
+// GetResult implements Aggregation interface.
+func (cf *concatFunction) GetResult(evalCtx *AggEvaluateContext) (d types.Datum) {
+	if evalCtx.Buffer != nil {
+		d.SetString(evalCtx.Buffer.String())
+	} else {
+		d.SetNull()
+	}
+	return d
+}
+
+// GetPartialResult implements Aggregation interface.
+func (cf *concatFunction) GetPartialResult(evalCtx *AggEvaluateContext) []types.Datum {
+	return []types.Datum{cf.GetResult(evalCtx)}
+}
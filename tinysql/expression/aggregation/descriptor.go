@@ -26,6 +26,37 @@ type AggFuncDesc struct {
 	baseFuncDesc
 	// Mode represents the execution mode of the aggregation function.
 	Mode AggFunctionMode
+	// OrderByItems represents the order by clause used in GROUP_CONCAT.
+	OrderByItems []*ByItem
+}
+
+// ByItem is an item of the order by clause within the aggregation function.
+type ByItem struct {
+	Expr expression.Expression
+	Desc bool
+	// NullOrder is only set if the NULLs are not placed as the default order,
+	// which treats NULLs as the smallest values.
+	NullOrder ast.NullOrder
+}
+
+// String implements fmt.Stringer interface.
+func (by *ByItem) String() string {
+	str := by.Expr.String()
+	if by.Desc {
+		str += " desc"
+	}
+	switch by.NullOrder {
+	case ast.NullsFirst:
+		str += " nulls first"
+	case ast.NullsLast:
+		str += " nulls last"
+	}
+	return str
+}
+
+// Clone makes a copy of ByItem.
+func (by *ByItem) Clone() *ByItem {
+	return &ByItem{Expr: by.Expr.Clone(), Desc: by.Desc, NullOrder: by.NullOrder}
 }
 
 // NewAggFuncDesc creates an aggregation function signature descriptor.
@@ -39,6 +70,16 @@ func NewAggFuncDesc(ctx sessionctx.Context, name string, args []expression.Expre
 
 // Equal checks whether two aggregation function signatures are equal.
 func (a *AggFuncDesc) Equal(ctx sessionctx.Context, other *AggFuncDesc) bool {
+	if len(a.OrderByItems) != len(other.OrderByItems) {
+		return false
+	}
+	for i := range a.OrderByItems {
+		if a.OrderByItems[i].Desc != other.OrderByItems[i].Desc ||
+			a.OrderByItems[i].NullOrder != other.OrderByItems[i].NullOrder ||
+			!a.OrderByItems[i].Expr.Equal(ctx, other.OrderByItems[i].Expr) {
+			return false
+		}
+	}
 	return a.baseFuncDesc.equal(ctx, &other.baseFuncDesc)
 }
 
@@ -46,6 +87,12 @@ func (a *AggFuncDesc) Equal(ctx sessionctx.Context, other *AggFuncDesc) bool {
 func (a *AggFuncDesc) Clone() *AggFuncDesc {
 	clone := *a
 	clone.baseFuncDesc = *a.baseFuncDesc.clone()
+	if len(a.OrderByItems) > 0 {
+		clone.OrderByItems = make([]*ByItem, len(a.OrderByItems))
+		for i, by := range a.OrderByItems {
+			clone.OrderByItems[i] = by.Clone()
+		}
+	}
 	return &clone
 }
 
@@ -79,6 +126,17 @@ func (a *AggFuncDesc) Split(ordinal []int) (partialAggDesc, finalAggDesc *AggFun
 			RetType: a.RetTp,
 		})
 		finalAggDesc.Args = args
+	case ast.AggFuncGroupConcat:
+		// The final phase concatenates the partial results with the same separator, and it
+		// sorts the rows merged from the partial results if the order is required.
+		args := make([]expression.Expression, 0, 2)
+		args = append(args, &expression.Column{
+			Index:   ordinal[0],
+			RetType: a.RetTp,
+		})
+		args = append(args, a.Args[len(a.Args)-1])
+		finalAggDesc.Args = args
+		finalAggDesc.OrderByItems = a.OrderByItems
 	default:
 		args := make([]expression.Expression, 0, 1)
 		args = append(args, &expression.Column{
@@ -130,7 +188,7 @@ func (a *AggFuncDesc) EvalNullValueInOuterJoin(ctx sessionctx.Context, schema *e
 	case ast.AggFuncSum, ast.AggFuncMax, ast.AggFuncMin,
 		ast.AggFuncFirstRow:
 		return a.evalNullValueInOuterJoin4Sum(ctx, schema)
-	case ast.AggFuncAvg:
+	case ast.AggFuncAvg, ast.AggFuncGroupConcat:
 		return types.Datum{}, false
 	default:
 		panic("unsupported agg function")
@@ -153,6 +211,8 @@ func (a *AggFuncDesc) GetAggFunc(ctx sessionctx.Context) Aggregation {
 		return &maxMinFunction{aggFunction: aggFunc, isMax: false}
 	case ast.AggFuncFirstRow:
 		return &firstRowFunction{aggFunction: aggFunc}
+	case ast.AggFuncGroupConcat:
+		return &concatFunction{aggFunction: aggFunc}
 	default:
 		panic("unsupported agg function")
 	}
@@ -28,6 +28,18 @@ func ExplainAggFunc(agg *AggFuncDesc) string {
 			buffer.WriteString(", ")
 		}
 	}
+	if len(agg.OrderByItems) > 0 {
+		buffer.WriteString(" order by ")
+		for i, item := range agg.OrderByItems {
+			buffer.WriteString(item.Expr.ExplainInfo())
+			if item.Desc {
+				buffer.WriteString(" desc")
+			}
+			if i+1 < len(agg.OrderByItems) {
+				buffer.WriteString(", ")
+			}
+		}
+	}
 	buffer.WriteString(")")
 	return buffer.String()
 }
@@ -37,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -47,6 +48,9 @@ type baseBuiltinFunc struct {
 	ctx          sessionctx.Context
 	tp           *types.FieldType
 	pbCode       tipb.ScalarFuncSig
+	// collator is used to compare the string arguments, it is derived from the args once
+	// when the function is built instead of for every row.
+	collator collate.Collator
 
 	childrenVectorizedOnce *sync.Once
 	childrenVectorized     bool
@@ -77,9 +81,10 @@ func newBaseBuiltinFunc(ctx sessionctx.Context, args []Expression) baseBuiltinFu
 		bufAllocator:           newLocalSliceBuffer(len(args)),
 		childrenVectorizedOnce: new(sync.Once),
 
-		args: args,
-		ctx:  ctx,
-		tp:   types.NewFieldType(mysql.TypeUnspecified),
+		args:     args,
+		ctx:      ctx,
+		tp:       types.NewFieldType(mysql.TypeUnspecified),
+		collator: collate.GetCollator(DeriveCollationFromExprs(args...)),
 	}
 }
 
@@ -130,9 +135,10 @@ func newBaseBuiltinFuncWithTp(ctx sessionctx.Context, args []Expression, retType
 		bufAllocator:           newLocalSliceBuffer(len(args)),
 		childrenVectorizedOnce: new(sync.Once),
 
-		args: args,
-		ctx:  ctx,
-		tp:   fieldType,
+		args:     args,
+		ctx:      ctx,
+		tp:       fieldType,
+		collator: collate.GetCollator(DeriveCollationFromExprs(args...)),
 	}
 }
 
@@ -237,6 +243,7 @@ func (b *baseBuiltinFunc) cloneFrom(from *baseBuiltinFunc) {
 	b.ctx = from.ctx
 	b.tp = from.tp
 	b.pbCode = from.pbCode
+	b.collator = from.collator
 	b.bufAllocator = newLocalSliceBuffer(len(b.args))
 	b.childrenVectorizedOnce = new(sync.Once)
 }
@@ -332,6 +339,7 @@ type functionClass interface {
 var funcs = map[string]functionClass{
 	// common functions
 	ast.IsNull: &isNullFunctionClass{baseFunctionClass{ast.IsNull, 1, 1}},
+	ast.Cast:   &castAsRealFunctionClass{baseFunctionClass{ast.Cast, 1, 1}},
 
 	// string functions
 	ast.Length:      &lengthFunctionClass{baseFunctionClass{ast.Length, 1, 1}},
@@ -339,6 +347,7 @@ var funcs = map[string]functionClass{
 	ast.Strcmp:      &strcmpFunctionClass{baseFunctionClass{ast.Strcmp, 2, 2}},
 
 	// control functions
+	ast.Case:   &caseWhenFunctionClass{baseFunctionClass{ast.Case, 1, -1}},
 	ast.If:     &ifFunctionClass{baseFunctionClass{ast.If, 3, 3}},
 	ast.Ifnull: &ifNullFunctionClass{baseFunctionClass{ast.Ifnull, 2, 2}},
 
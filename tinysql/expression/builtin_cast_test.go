@@ -0,0 +1,49 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+func (s *testEvaluatorSuite) TestCastAsReal(c *C) {
+	fc := funcs[ast.Cast]
+	testCases := []struct {
+		arg interface{}
+		res interface{}
+	}{
+		{1, float64(1)},
+		{-3, float64(-3)},
+		{1.5, float64(1.5)},
+		{"01", float64(1)},
+		{"1.0", float64(1)},
+		{" 2.5 ", float64(2.5)},
+		{nil, nil},
+	}
+	for _, tc := range testCases {
+		args := types.MakeDatums(tc.arg)
+		fn, err := fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := evalBuiltinFunc(fn, chunk.MutRowFromDatums(args).ToRow())
+		c.Assert(err, IsNil)
+		c.Assert(d.GetValue(), Equals, tc.res, Commentf("%v", tc.arg))
+	}
+
+	col := &Column{Index: 0, RetType: types.NewFieldType(mysql.TypeDouble)}
+	c.Assert(WrapWithCastAsReal(s.ctx, col), Equals, col)
+}
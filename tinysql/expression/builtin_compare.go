@@ -16,11 +16,13 @@ package expression
 import (
 	"math"
 
+	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -93,7 +95,10 @@ func GetCmpFunction(lhs, rhs Expression) CompareFunc {
 	case types.ETReal:
 		return CompareReal
 	case types.ETString:
-		return CompareString
+		collator := collate.GetCollator(DeriveCollationFromExprs(lhs, rhs))
+		return func(sctx sessionctx.Context, lhsArg, rhsArg Expression, lhsRow, rhsRow chunk.Row) (int64, bool, error) {
+			return compareStringWithCollator(sctx, collator, lhsArg, rhsArg, lhsRow, rhsRow)
+		}
 	}
 	return nil
 }
@@ -219,7 +224,7 @@ func (b *builtinLTStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinLTStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfLT(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfLT(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 type builtinLEIntSig struct {
@@ -261,7 +266,7 @@ func (b *builtinLEStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinLEStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfLE(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfLE(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 type builtinGTIntSig struct {
@@ -303,7 +308,7 @@ func (b *builtinGTStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinGTStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfGT(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfGT(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 type builtinGEIntSig struct {
@@ -345,7 +350,7 @@ func (b *builtinGEStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinGEStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfGE(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfGE(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 type builtinEQIntSig struct {
@@ -387,7 +392,7 @@ func (b *builtinEQStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinEQStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfEQ(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfEQ(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 type builtinNEIntSig struct {
@@ -429,7 +434,7 @@ func (b *builtinNEStringSig) Clone() builtinFunc {
 }
 
 func (b *builtinNEStringSig) evalInt(row chunk.Row) (val int64, isNull bool, err error) {
-	return resOfNE(CompareString(b.ctx, b.args[0], b.args[1], row, row))
+	return resOfNE(compareStringWithCollator(b.ctx, b.collator, b.args[0], b.args[1], row, row))
 }
 
 func resOfLT(val int64, isNull bool, err error) (int64, bool, error) {
@@ -563,6 +568,10 @@ func CompareInt(sctx sessionctx.Context, lhsArg, rhsArg Expression, lhsRow, rhsR
 
 // CompareString compares two strings.
 func CompareString(sctx sessionctx.Context, lhsArg, rhsArg Expression, lhsRow, rhsRow chunk.Row) (int64, bool, error) {
+	return compareStringWithCollator(sctx, collate.GetCollator(DeriveCollationFromExprs(lhsArg, rhsArg)), lhsArg, rhsArg, lhsRow, rhsRow)
+}
+
+func compareStringWithCollator(sctx sessionctx.Context, collator collate.Collator, lhsArg, rhsArg Expression, lhsRow, rhsRow chunk.Row) (int64, bool, error) {
 	arg0, isNull0, err := lhsArg.EvalString(sctx, lhsRow)
 	if err != nil {
 		return 0, true, err
@@ -576,7 +585,40 @@ func CompareString(sctx sessionctx.Context, lhsArg, rhsArg Expression, lhsRow, r
 	if isNull0 || isNull1 {
 		return compareNull(isNull0, isNull1), true, nil
 	}
-	return int64(types.CompareString(arg0, arg1)), false, nil
+	return int64(collator.Compare(arg0, arg1)), false, nil
+}
+
+// DeriveCollationFromExprs derives the collation used to compare the string
+// expressions. Binary strings are always compared byte by byte, otherwise the
+// collation of a column takes precedence over the ones of other expressions,
+// e.g. `c = 'a'` is compared by the collation of column `c`. Among the ones of
+// the same precedence, a case-sensitive collation wins over a case-insensitive
+// one, so the result doesn't depend on the order of the expressions.
+func DeriveCollationFromExprs(exprs ...Expression) string {
+	var (
+		collation  string
+		fromColumn bool
+	)
+	for _, expr := range exprs {
+		ft := expr.GetType()
+		if ft.EvalType() != types.ETString {
+			continue
+		}
+		if ft.Collate == charset.CollationBin {
+			return charset.CollationBin
+		}
+		_, isColumn := expr.(*Column)
+		switch {
+		case collation == "" || (isColumn && !fromColumn):
+			collation, fromColumn = ft.Collate, isColumn
+		case isColumn == fromColumn && collate.IsCICollation(collation) && !collate.IsCICollation(ft.Collate):
+			collation = ft.Collate
+		}
+	}
+	if collation == "" {
+		return charset.CollationBin
+	}
+	return collation
 }
 
 // CompareReal compares two float-point values.
@@ -81,6 +81,7 @@ func (b *builtinLTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -88,7 +89,7 @@ func (b *builtinLTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val < 0 {
 			i64s[i] = 1
 		} else {
@@ -163,6 +164,7 @@ func (b *builtinLEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -170,7 +172,7 @@ func (b *builtinLEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val <= 0 {
 			i64s[i] = 1
 		} else {
@@ -245,6 +247,7 @@ func (b *builtinGTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -252,7 +255,7 @@ func (b *builtinGTStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val > 0 {
 			i64s[i] = 1
 		} else {
@@ -327,6 +330,7 @@ func (b *builtinGEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -334,7 +338,7 @@ func (b *builtinGEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val >= 0 {
 			i64s[i] = 1
 		} else {
@@ -409,6 +413,7 @@ func (b *builtinEQStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -416,7 +421,7 @@ func (b *builtinEQStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val == 0 {
 			i64s[i] = 1
 		} else {
@@ -491,6 +496,7 @@ func (b *builtinNEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		return err
 	}
 
+	collator := b.collator
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
 	i64s := result.Int64s()
@@ -498,7 +504,7 @@ func (b *builtinNEStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		if result.IsNull(i) {
 			continue
 		}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 		if val != 0 {
 			i64s[i] = 1
 		} else {
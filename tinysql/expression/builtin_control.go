@@ -24,11 +24,15 @@ import (
 )
 
 var (
+	_ functionClass = &caseWhenFunctionClass{}
 	_ functionClass = &ifFunctionClass{}
 	_ functionClass = &ifNullFunctionClass{}
 )
 
 var (
+	_ builtinFunc = &builtinCaseWhenIntSig{}
+	_ builtinFunc = &builtinCaseWhenRealSig{}
+	_ builtinFunc = &builtinCaseWhenStringSig{}
 	_ builtinFunc = &builtinIfNullIntSig{}
 	_ builtinFunc = &builtinIfNullRealSig{}
 	_ builtinFunc = &builtinIfNullStringSig{}
@@ -111,6 +115,124 @@ func InferType4ControlFuncs(lhs, rhs *types.FieldType) *types.FieldType {
 	return resultFieldType
 }
 
+type caseWhenFunctionClass struct {
+	baseFunctionClass
+}
+
+// getFunction see https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#operator_case
+// The args are the "when" conditions and the "then" results in pairs, followed by the "else"
+// result if there is one.
+func (c *caseWhenFunctionClass) getFunction(ctx sessionctx.Context, args []Expression) (sig builtinFunc, err error) {
+	if err = c.verifyArgs(args); err != nil {
+		return nil, err
+	}
+	l := len(args)
+	// The result type is inferred from all the "then" results and the "else" result.
+	retTp := &types.FieldType{Tp: mysql.TypeNull}
+	for i := 1; i < l; i += 2 {
+		retTp = InferType4ControlFuncs(retTp, args[i].GetType())
+	}
+	if l%2 == 1 {
+		retTp = InferType4ControlFuncs(retTp, args[l-1].GetType())
+	}
+	evalTps := retTp.EvalType()
+	argTps := make([]types.EvalType, 0, l)
+	for i := 0; i < l-1; i += 2 {
+		argTps = append(argTps, types.ETInt, evalTps)
+	}
+	if l%2 == 1 {
+		argTps = append(argTps, evalTps)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, evalTps, argTps...)
+	retTp.Flag |= bf.tp.Flag
+	bf.tp = retTp
+	switch evalTps {
+	case types.ETInt:
+		sig = &builtinCaseWhenIntSig{bf}
+		sig.setPbCode(tipb.ScalarFuncSig_CaseWhenInt)
+	case types.ETReal:
+		sig = &builtinCaseWhenRealSig{bf}
+		sig.setPbCode(tipb.ScalarFuncSig_CaseWhenReal)
+	case types.ETString:
+		sig = &builtinCaseWhenStringSig{bf}
+		sig.setPbCode(tipb.ScalarFuncSig_CaseWhenString)
+	}
+	return sig, nil
+}
+
+// caseWhenMatch returns the position of the result to evaluate for the row, or -1 if no
+// condition is true and there is no "else" result.
+func caseWhenMatch(ctx sessionctx.Context, args []Expression, row chunk.Row) (int, error) {
+	l := len(args)
+	for i := 0; i < l-1; i += 2 {
+		cond, isNull, err := args[i].EvalInt(ctx, row)
+		if err != nil {
+			return -1, err
+		}
+		if !isNull && cond != 0 {
+			return i + 1, nil
+		}
+	}
+	if l%2 == 1 {
+		return l - 1, nil
+	}
+	return -1, nil
+}
+
+type builtinCaseWhenIntSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinCaseWhenIntSig) Clone() builtinFunc {
+	newSig := &builtinCaseWhenIntSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+func (b *builtinCaseWhenIntSig) evalInt(row chunk.Row) (ret int64, isNull bool, err error) {
+	idx, err := caseWhenMatch(b.ctx, b.args, row)
+	if err != nil || idx < 0 {
+		return 0, true, err
+	}
+	return b.args[idx].EvalInt(b.ctx, row)
+}
+
+type builtinCaseWhenRealSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinCaseWhenRealSig) Clone() builtinFunc {
+	newSig := &builtinCaseWhenRealSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+func (b *builtinCaseWhenRealSig) evalReal(row chunk.Row) (ret float64, isNull bool, err error) {
+	idx, err := caseWhenMatch(b.ctx, b.args, row)
+	if err != nil || idx < 0 {
+		return 0, true, err
+	}
+	return b.args[idx].EvalReal(b.ctx, row)
+}
+
+type builtinCaseWhenStringSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinCaseWhenStringSig) Clone() builtinFunc {
+	newSig := &builtinCaseWhenStringSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+func (b *builtinCaseWhenStringSig) evalString(row chunk.Row) (ret string, isNull bool, err error) {
+	idx, err := caseWhenMatch(b.ctx, b.args, row)
+	if err != nil || idx < 0 {
+		return "", true, err
+	}
+	return b.args[idx].EvalString(b.ctx, row)
+}
+
 type ifFunctionClass struct {
 	baseFunctionClass
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -61,26 +62,126 @@ func (c *inFunctionClass) getFunction(ctx sessionctx.Context, args []Expression)
 	bf.tp.Flen = 1
 	switch args[0].GetType().EvalType() {
 	case types.ETInt:
-		sig = &builtinInIntSig{baseBuiltinFunc: bf}
+		sig = newBuiltinInIntSig(bf)
 		sig.setPbCode(tipb.ScalarFuncSig_InInt)
 	case types.ETString:
-		sig = &builtinInStringSig{baseBuiltinFunc: bf}
+		sig = newBuiltinInStringSig(bf)
 		sig.setPbCode(tipb.ScalarFuncSig_InString)
 	case types.ETReal:
-		sig = &builtinInRealSig{baseBuiltinFunc: bf}
+		sig = newBuiltinInRealSig(bf)
 		sig.setPbCode(tipb.ScalarFuncSig_InReal)
 	}
 	return sig, nil
 }
 
+// inHashSetThreshold is the least number of constant items in an IN list to
+// look them up in a hash set, shorter lists are compared one by one.
+const inHashSetThreshold = 16
+
+// baseInSig is the base of the IN signatures. When the list has enough
+// constant items, they are evaluated once into a hash set of the concrete
+// signature and only the other items are compared row by row.
+type baseInSig struct {
+	baseBuiltinFunc
+	// nonConstArgs is args[0] followed by the items not in the hash set, it is
+	// nil if the hash set is not built.
+	nonConstArgs []Expression
+	// hasNull is true if any item in the hash set is NULL.
+	hasNull bool
+}
+
+func (b *baseInSig) cloneFrom(from *baseInSig) {
+	b.baseBuiltinFunc.cloneFrom(&from.baseBuiltinFunc)
+	b.hasNull = from.hasNull
+	if from.nonConstArgs != nil {
+		b.nonConstArgs, _ = splitInConstArgs(b.args)
+	}
+}
+
+// compareArgs returns the items which have to be compared one by one and
+// whether there is a NULL among the others.
+func (b *baseInSig) compareArgs(useHashSet bool) ([]Expression, bool) {
+	if useHashSet {
+		return b.nonConstArgs[1:], b.hasNull
+	}
+	return b.args[1:], false
+}
+
+// collectConstArgs returns the constant items of the list if there are
+// enough of them for a hash set, and remembers the others in nonConstArgs.
+func (b *baseInSig) collectConstArgs() []Expression {
+	nonConstArgs, constArgs := splitInConstArgs(b.args)
+	if len(constArgs) < inHashSetThreshold {
+		return nil
+	}
+	b.nonConstArgs = nonConstArgs
+	return constArgs
+}
+
+// resetHashSet falls back to comparing all the items one by one.
+func (b *baseInSig) resetHashSet() {
+	b.nonConstArgs = nil
+	b.hasNull = false
+}
+
+func splitInConstArgs(args []Expression) (nonConstArgs, constArgs []Expression) {
+	nonConstArgs = []Expression{args[0]}
+	for _, arg := range args[1:] {
+		if arg.ConstItem() {
+			constArgs = append(constArgs, arg)
+		} else {
+			nonConstArgs = append(nonConstArgs, arg)
+		}
+	}
+	return nonConstArgs, constArgs
+}
+
 // builtinInIntSig see https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_in
 type builtinInIntSig struct {
-	baseBuiltinFunc
+	baseInSig
+	// hashSet maps the constant items to whether one of them has the same
+	// signedness as args[0], which means it matches regardless of the sign.
+	hashSet map[int64]bool
+}
+
+func newBuiltinInIntSig(bf baseBuiltinFunc) *builtinInIntSig {
+	sig := &builtinInIntSig{baseInSig: baseInSig{baseBuiltinFunc: bf}}
+	sig.buildHashSet()
+	return sig
+}
+
+func (b *builtinInIntSig) buildHashSet() {
+	constArgs := b.collectConstArgs()
+	if constArgs == nil {
+		return
+	}
+	isUnsigned0 := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
+	b.hashSet = make(map[int64]bool, len(constArgs))
+	for _, arg := range constArgs {
+		val, isNull, err := arg.EvalInt(b.ctx, chunk.Row{})
+		if err != nil {
+			// Leave the error to be reported when the rows are evaluated.
+			b.hashSet = nil
+			b.resetHashSet()
+			return
+		}
+		if isNull {
+			b.hasNull = true
+			continue
+		}
+		isUnsigned := mysql.HasUnsignedFlag(arg.GetType().Flag)
+		b.hashSet[val] = b.hashSet[val] || isUnsigned == isUnsigned0
+	}
+}
+
+func (b *builtinInIntSig) hashSetContains(arg0 int64) bool {
+	sameSign, ok := b.hashSet[arg0]
+	return ok && (sameSign || arg0 >= 0)
 }
 
 func (b *builtinInIntSig) Clone() builtinFunc {
-	newSig := &builtinInIntSig{}
-	newSig.cloneFrom(&b.baseBuiltinFunc)
+	newSig := &builtinInIntSig{hashSet: b.hashSet}
+	newSig.cloneFrom(&b.baseInSig)
 	return newSig
 }
 
@@ -89,9 +190,12 @@ func (b *builtinInIntSig) evalInt(row chunk.Row) (int64, bool, error) {
 	if isNull0 || err != nil {
 		return 0, isNull0, err
 	}
+	if b.hashSet != nil && b.hashSetContains(arg0) {
+		return 1, false, nil
+	}
 	isUnsigned0 := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
-	var hasNull bool
-	for _, arg := range b.args[1:] {
+	args, hasNull := b.compareArgs(b.hashSet != nil)
+	for _, arg := range args {
 		evaledArg, isNull, err := arg.EvalInt(b.ctx, row)
 		if err != nil {
 			return 0, true, err
@@ -124,12 +228,48 @@ func (b *builtinInIntSig) evalInt(row chunk.Row) (int64, bool, error) {
 
 // builtinInStringSig see https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_in
 type builtinInStringSig struct {
-	baseBuiltinFunc
+	baseInSig
+	// hashSet holds the collation keys of the constant items.
+	hashSet map[string]struct{}
+}
+
+func newBuiltinInStringSig(bf baseBuiltinFunc) *builtinInStringSig {
+	sig := &builtinInStringSig{baseInSig: baseInSig{baseBuiltinFunc: bf}}
+	sig.buildHashSet()
+	return sig
+}
+
+func (b *builtinInStringSig) buildHashSet() {
+	constArgs := b.collectConstArgs()
+	if constArgs == nil {
+		return
+	}
+	collator := b.collator
+	b.hashSet = make(map[string]struct{}, len(constArgs))
+	for _, arg := range constArgs {
+		val, isNull, err := arg.EvalString(b.ctx, chunk.Row{})
+		if err != nil {
+			// Leave the error to be reported when the rows are evaluated.
+			b.hashSet = nil
+			b.resetHashSet()
+			return
+		}
+		if isNull {
+			b.hasNull = true
+			continue
+		}
+		b.hashSet[collator.Key(val)] = struct{}{}
+	}
+}
+
+func (b *builtinInStringSig) hashSetContains(collator collate.Collator, arg0 string) bool {
+	_, ok := b.hashSet[collator.Key(arg0)]
+	return ok
 }
 
 func (b *builtinInStringSig) Clone() builtinFunc {
-	newSig := &builtinInStringSig{}
-	newSig.cloneFrom(&b.baseBuiltinFunc)
+	newSig := &builtinInStringSig{hashSet: b.hashSet}
+	newSig.cloneFrom(&b.baseInSig)
 	return newSig
 }
 
@@ -138,8 +278,12 @@ func (b *builtinInStringSig) evalInt(row chunk.Row) (int64, bool, error) {
 	if isNull0 || err != nil {
 		return 0, isNull0, err
 	}
-	var hasNull bool
-	for _, arg := range b.args[1:] {
+	collator := b.collator
+	if b.hashSet != nil && b.hashSetContains(collator, arg0) {
+		return 1, false, nil
+	}
+	args, hasNull := b.compareArgs(b.hashSet != nil)
+	for _, arg := range args {
 		evaledArg, isNull, err := arg.EvalString(b.ctx, row)
 		if err != nil {
 			return 0, true, err
@@ -148,7 +292,7 @@ func (b *builtinInStringSig) evalInt(row chunk.Row) (int64, bool, error) {
 			hasNull = true
 			continue
 		}
-		if arg0 == evaledArg {
+		if collator.Compare(arg0, evaledArg) == 0 {
 			return 1, false, nil
 		}
 	}
@@ -157,12 +301,46 @@ func (b *builtinInStringSig) evalInt(row chunk.Row) (int64, bool, error) {
 
 // builtinInRealSig see https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_in
 type builtinInRealSig struct {
-	baseBuiltinFunc
+	baseInSig
+	hashSet map[float64]struct{}
+}
+
+func newBuiltinInRealSig(bf baseBuiltinFunc) *builtinInRealSig {
+	sig := &builtinInRealSig{baseInSig: baseInSig{baseBuiltinFunc: bf}}
+	sig.buildHashSet()
+	return sig
+}
+
+func (b *builtinInRealSig) buildHashSet() {
+	constArgs := b.collectConstArgs()
+	if constArgs == nil {
+		return
+	}
+	b.hashSet = make(map[float64]struct{}, len(constArgs))
+	for _, arg := range constArgs {
+		val, isNull, err := arg.EvalReal(b.ctx, chunk.Row{})
+		if err != nil {
+			// Leave the error to be reported when the rows are evaluated.
+			b.hashSet = nil
+			b.resetHashSet()
+			return
+		}
+		if isNull {
+			b.hasNull = true
+			continue
+		}
+		b.hashSet[val] = struct{}{}
+	}
+}
+
+func (b *builtinInRealSig) hashSetContains(arg0 float64) bool {
+	_, ok := b.hashSet[arg0]
+	return ok
 }
 
 func (b *builtinInRealSig) Clone() builtinFunc {
-	newSig := &builtinInRealSig{}
-	newSig.cloneFrom(&b.baseBuiltinFunc)
+	newSig := &builtinInRealSig{hashSet: b.hashSet}
+	newSig.cloneFrom(&b.baseInSig)
 	return newSig
 }
 
@@ -171,8 +349,11 @@ func (b *builtinInRealSig) evalInt(row chunk.Row) (int64, bool, error) {
 	if isNull0 || err != nil {
 		return 0, isNull0, err
 	}
-	var hasNull bool
-	for _, arg := range b.args[1:] {
+	if b.hashSet != nil && b.hashSetContains(arg0) {
+		return 1, false, nil
+	}
+	args, hasNull := b.compareArgs(b.hashSet != nil)
+	for _, arg := range args {
 		evaledArg, isNull, err := arg.EvalReal(b.ctx, row)
 		if err != nil {
 			return 0, true, err
@@ -14,13 +14,15 @@
 package expression
 
 import (
+	"fmt"
+	"math"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/hack"
-	"math"
 )
 
 func (s *testEvaluatorSuite) TestInFunc(c *C) {
@@ -53,6 +55,75 @@ func (s *testEvaluatorSuite) TestInFunc(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestInHashSet(c *C) {
+	fc := funcs[ast.In]
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	buildIn := func(items ...interface{}) builtinFunc {
+		args := append([]Expression{&Column{Index: 0, RetType: ft}}, s.datumsToConstants(types.MakeDatums(items...))...)
+		fn, err := fc.getFunction(s.ctx, args)
+		c.Assert(err, IsNil)
+		return fn
+	}
+	longList := make([]interface{}, 0, inHashSetThreshold)
+	for i := 0; i < inHashSetThreshold; i++ {
+		longList = append(longList, i*2)
+	}
+
+	// Only the long list of constants is turned into a hash set.
+	c.Assert(buildIn(longList[:inHashSetThreshold-1]...).(*builtinInIntSig).hashSet, IsNil)
+	c.Assert(buildIn(longList...).(*builtinInIntSig).hashSet, HasLen, inHashSetThreshold)
+	withNull := buildIn(append(longList, nil)...).(*builtinInIntSig)
+	c.Assert(withNull.hashSet, HasLen, inHashSetThreshold)
+	c.Assert(withNull.hasNull, IsTrue)
+	c.Assert(withNull.Clone().(*builtinInIntSig).hashSet, HasLen, inHashSetThreshold)
+
+	input := chunk.NewChunkWithCapacity([]*types.FieldType{ft}, 4)
+	input.AppendInt64(0, 4)
+	input.AppendInt64(0, 5)
+	input.AppendInt64(0, -2)
+	input.AppendNull(0)
+	testCases := []struct {
+		fn  builtinFunc
+		res []interface{}
+	}{
+		{buildIn(longList[:inHashSetThreshold-1]...), []interface{}{int64(1), int64(0), int64(0), nil}},
+		{buildIn(longList...), []interface{}{int64(1), int64(0), int64(0), nil}},
+		{withNull, []interface{}{int64(1), nil, nil, nil}},
+		{buildIn(append(longList, -2)...), []interface{}{int64(1), int64(0), int64(1), nil}},
+	}
+	for i, tc := range testCases {
+		result := chunk.NewColumn(types.NewFieldType(mysql.TypeLonglong), input.NumRows())
+		c.Assert(tc.fn.vecEvalInt(input, result), IsNil)
+		for j := 0; j < input.NumRows(); j++ {
+			comment := Commentf("case %d row %d", i, j)
+			d, err := evalBuiltinFunc(tc.fn, input.GetRow(j))
+			c.Assert(err, IsNil)
+			c.Assert(d.GetValue(), Equals, tc.res[j], comment)
+			if tc.res[j] == nil {
+				c.Assert(result.IsNull(j), IsTrue, comment)
+			} else {
+				c.Assert(result.IsNull(j), IsFalse, comment)
+				c.Assert(result.GetInt64(j), Equals, tc.res[j], comment)
+			}
+		}
+	}
+
+	// The strings are looked up by their collation keys.
+	strList := make([]interface{}, 0, inHashSetThreshold)
+	for i := 0; i < inHashSetThreshold; i++ {
+		strList = append(strList, fmt.Sprintf("v%d", i))
+	}
+	strFt := types.NewFieldType(mysql.TypeVarchar)
+	strFt.Charset, strFt.Collate = "utf8mb4", "utf8mb4_general_ci"
+	args := append([]Expression{&Column{Index: 0, RetType: strFt}}, s.datumsToConstants(types.MakeDatums(strList...))...)
+	fn, err := fc.getFunction(s.ctx, args)
+	c.Assert(err, IsNil)
+	c.Assert(fn.(*builtinInStringSig).hashSet, HasLen, inHashSetThreshold)
+	d, err := evalBuiltinFunc(fn, chunk.MutRowFromDatums(types.MakeDatums("V3 ")).ToRow())
+	c.Assert(err, IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(1))
+}
+
 func (s *testEvaluatorSuite) TestRowFunc(c *C) {
 	fc := funcs[ast.RowFunc]
 	_, err := fc.getFunction(s.ctx, s.datumsToConstants(types.MakeDatums([]interface{}{"1", 1.2, true, 120}...)))
@@ -46,12 +46,29 @@ func (b *builtinInIntSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column) e
 	hasNull := make([]bool, n)
 	isUnsigned0 := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
 	var compareResult int
+	args := b.args
+	if b.hashSet != nil {
+		args = b.nonConstArgs
+		for i := 0; i < n; i++ {
+			if buf0.IsNull(i) {
+				hasNull[i] = true
+				continue
+			}
+			arg0 := args0[i]
+			if b.hashSetContains(arg0) {
+				result.SetNull(i, false)
+				r64s[i] = 1
+			} else if b.hasNull {
+				hasNull[i] = true
+			}
+		}
+	}
 
-	for j := 1; j < len(b.args); j++ {
-		if err := b.args[j].VecEvalInt(b.ctx, input, buf1); err != nil {
+	for j := 1; j < len(args); j++ {
+		if err := args[j].VecEvalInt(b.ctx, input, buf1); err != nil {
 			return err
 		}
-		isUnsigned := mysql.HasUnsignedFlag(b.args[j].GetType().Flag)
+		isUnsigned := mysql.HasUnsignedFlag(args[j].GetType().Flag)
 		args1 := buf1.Int64s()
 		buf1.MergeNulls(buf0)
 		for i := 0; i < n; i++ {
@@ -116,10 +133,28 @@ func (b *builtinInStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 		r64s[i] = 0
 	}
 	hasNull := make([]bool, n)
+	collator := b.collator
 	var compareResult int
+	args := b.args
+	if b.hashSet != nil {
+		args = b.nonConstArgs
+		for i := 0; i < n; i++ {
+			if buf0.IsNull(i) {
+				hasNull[i] = true
+				continue
+			}
+			arg0 := buf0.GetString(i)
+			if b.hashSetContains(collator, arg0) {
+				result.SetNull(i, false)
+				r64s[i] = 1
+			} else if b.hasNull {
+				hasNull[i] = true
+			}
+		}
+	}
 
-	for j := 1; j < len(b.args); j++ {
-		if err := b.args[j].VecEvalString(b.ctx, input, buf1); err != nil {
+	for j := 1; j < len(args); j++ {
+		if err := args[j].VecEvalString(b.ctx, input, buf1); err != nil {
 			return err
 		}
 		for i := 0; i < n; i++ {
@@ -129,7 +164,7 @@ func (b *builtinInStringSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column
 			}
 			arg0 := buf0.GetString(i)
 			arg1 := buf1.GetString(i)
-			compareResult = types.CompareString(arg0, arg1)
+			compareResult = collator.Compare(arg0, arg1)
 			if compareResult == 0 {
 				result.SetNull(i, false)
 				r64s[i] = 1
@@ -172,9 +207,26 @@ func (b *builtinInRealSig) vecEvalInt(input *chunk.Chunk, result *chunk.Column)
 	}
 	hasNull := make([]bool, n)
 	var compareResult int
+	args := b.args
+	if b.hashSet != nil {
+		args = b.nonConstArgs
+		for i := 0; i < n; i++ {
+			if buf0.IsNull(i) {
+				hasNull[i] = true
+				continue
+			}
+			arg0 := args0[i]
+			if b.hashSetContains(arg0) {
+				result.SetNull(i, false)
+				r64s[i] = 1
+			} else if b.hasNull {
+				hasNull[i] = true
+			}
+		}
+	}
 
-	for j := 1; j < len(b.args); j++ {
-		if err := b.args[j].VecEvalReal(b.ctx, input, buf1); err != nil {
+	for j := 1; j < len(args); j++ {
+		if err := args[j].VecEvalReal(b.ctx, input, buf1); err != nil {
 			return err
 		}
 		args1 := buf1.Float64s()
@@ -83,6 +83,18 @@ func validEqualCond(cond Expression) (*Column, *Constant) {
 	return nil, nil
 }
 
+// mismatchedColumnEQ checks if the cond is an expression like [column eq column] with the columns of
+// different eval types. Such columns are compared after casting them to a common type, so neither of
+// them can be substituted by a constant or the other column without changing the result.
+func mismatchedColumnEQ(cond Expression) bool {
+	if fun, ok := cond.(*ScalarFunction); ok && fun.FuncName.L == ast.EQ {
+		lCol, lOk := fun.GetArgs()[0].(*Column)
+		rCol, rOk := fun.GetArgs()[1].(*Column)
+		return lOk && rOk && lCol.GetType().EvalType() != rCol.GetType().EvalType()
+	}
+	return false
+}
+
 // tryToReplaceCond aims to replace all occurrences of column 'src' and try to replace it with 'tgt' in 'cond'
 // It returns
 //  bool: if a replacement happened
@@ -159,7 +171,7 @@ func (s *propConstSolver) propagateConstantEQ() {
 			cons = append(cons, con)
 		}
 		for i, cond := range s.conditions {
-			if !visited[i] {
+			if !visited[i] && !mismatchedColumnEQ(cond) {
 				s.conditions[i] = ColumnSubstitute(cond, NewSchema(cols...), cons)
 			}
 		}
@@ -191,7 +203,7 @@ func (s *propConstSolver) propagateColumnEQ() {
 		if fun, ok := s.conditions[i].(*ScalarFunction); ok && fun.FuncName.L == ast.EQ {
 			lCol, lOk := fun.GetArgs()[0].(*Column)
 			rCol, rOk := fun.GetArgs()[1].(*Column)
-			if lOk && rOk {
+			if lOk && rOk && !mismatchedColumnEQ(fun) {
 				lID := s.getColID(lCol)
 				rID := s.getColID(rCol)
 				s.unionSet.Union(lID, rID)
@@ -397,7 +409,7 @@ func (s *propOuterJoinConstSolver) propagateConstantEQ() {
 			cons = append(cons, con)
 		}
 		for i, cond := range s.joinConds {
-			if !visited[i+lenFilters] {
+			if !visited[i+lenFilters] && !mismatchedColumnEQ(cond) {
 				s.joinConds[i] = ColumnSubstitute(cond, NewSchema(cols...), cons)
 			}
 		}
@@ -422,7 +434,7 @@ func (s *propOuterJoinConstSolver) validColEqualCond(cond Expression) (*Column,
 	if fun, ok := cond.(*ScalarFunction); ok && fun.FuncName.L == ast.EQ {
 		lCol, lOk := fun.GetArgs()[0].(*Column)
 		rCol, rOk := fun.GetArgs()[1].(*Column)
-		if lOk && rOk {
+		if lOk && rOk && !mismatchedColumnEQ(fun) {
 			return s.colsFromOuterAndInner(lCol, rCol)
 		}
 	}
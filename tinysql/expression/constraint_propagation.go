@@ -16,11 +16,13 @@ package expression
 import (
 	"bytes"
 
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
 	"go.uber.org/zap"
 )
@@ -172,3 +174,163 @@ func ruleColumnEQConst(ctx sessionctx.Context, i, j int, exprs *exprSet) {
 		}
 	}
 }
+
+// RemoveRedundantConds removes the duplicate CNF conditions and the "column op const"
+// conditions implied by the others on the same column, so the overlapping ranges are
+// merged into the tightest one.
+// "a > 5, a > 3, a <= 10, a < 20" => "a > 5, a <= 10"
+// "a = 1, a > 5" => false
+// Note that the conditions which are mutable or have side effects are kept as they are.
+func RemoveRedundantConds(ctx sessionctx.Context, conditions []Expression) []Expression {
+	stable := make([]Expression, 0, len(conditions))
+	var mutable []Expression
+	for _, cond := range conditions {
+		if IsMutableEffectsExpr(cond) {
+			mutable = append(mutable, cond)
+		} else {
+			stable = append(stable, cond)
+		}
+	}
+	solver := newConstraintSolver(ruleConstantFalse, ruleColumnOPConst)
+	return append(solver.Solve(ctx, stable), mutable...)
+}
+
+// symmetricOp is the operator after swapping the arguments of the comparison.
+var symmetricOp = map[string]string{
+	ast.LT: ast.GT,
+	ast.GE: ast.LE,
+	ast.GT: ast.LT,
+	ast.LE: ast.GE,
+	ast.EQ: ast.EQ,
+}
+
+// validCompareCond extracts the column, the operator and the constant from the "column op const"
+// or the "const op column" condition, the operator is normalized to the form of "column op const".
+// The constant must be not null and have the same eval type with the column.
+func validCompareCond(cond Expression) (*Column, string, *Constant) {
+	sf, ok := cond.(*ScalarFunction)
+	if !ok {
+		return nil, "", nil
+	}
+	op, ok := symmetricOp[sf.FuncName.L]
+	if !ok {
+		return nil, "", nil
+	}
+	args := sf.GetArgs()
+	col, colOk := args[0].(*Column)
+	con, conOk := args[1].(*Constant)
+	if colOk && conOk {
+		op = sf.FuncName.L
+	} else {
+		col, colOk = args[1].(*Column)
+		con, conOk = args[0].(*Constant)
+		if !colOk || !conOk {
+			return nil, "", nil
+		}
+	}
+	if con.Value.IsNull() || col.GetType().EvalType() != con.GetType().EvalType() {
+		return nil, "", nil
+	}
+	return col, op, con
+}
+
+// ruleColumnOPConst removes the "column op const" condition implied by another one on the same column,
+// and propagates false if the two conditions can't be satisfied at the same time.
+// "a > 5, a >= 3" => "a > 5"
+// "a = 3, a < 5" => "a = 3"
+// "a > 5, a < 3" => false
+func ruleColumnOPConst(ctx sessionctx.Context, i, j int, exprs *exprSet) {
+	col1, op1, con1 := validCompareCond(exprs.data[i])
+	if col1 == nil {
+		return
+	}
+	col2, op2, con2 := validCompareCond(exprs.data[j])
+	if col2 == nil || !col1.Equal(ctx, col2) {
+		return
+	}
+	cmp, err := compareConstants(ctx, col1, con1, con2)
+	if err != nil {
+		logutil.BgLogger().Warn("compare constant", zap.Error(err))
+		return
+	}
+	switch {
+	case columnOPConstImplies(op1, op2, cmp):
+		exprs.tombstone[j] = true
+	case op1 == ast.EQ:
+		// The value of exprs[i] doesn't satisfy exprs[j].
+		exprs.SetConstFalse()
+	case isLowerOp(op1) && isUpperOp(op2):
+		if cmp > 0 || (cmp == 0 && (op1 == ast.GT || op2 == ast.LT)) {
+			exprs.SetConstFalse()
+		}
+	}
+}
+
+// compareConstants compares the constants of two "column op const" conditions on the same column.
+// The strings are compared by the collation of the comparisons, so 'abc' equals 'ABC' on a column
+// with a case-insensitive collation.
+func compareConstants(ctx sessionctx.Context, col *Column, con1, con2 *Constant) (int, error) {
+	if col.GetType().EvalType() == types.ETString {
+		collator := collate.GetCollator(DeriveCollationFromExprs(col, con1, con2))
+		return collator.Compare(con1.Value.GetString(), con2.Value.GetString()), nil
+	}
+	return con1.Value.CompareDatum(ctx.GetSessionVars().StmtCtx, &con2.Value)
+}
+
+func isLowerOp(op string) bool { return op == ast.GT || op == ast.GE }
+
+func isUpperOp(op string) bool { return op == ast.LT || op == ast.LE }
+
+// columnOPConstImplies returns true if "column op1 con1" implies "column op2 con2", cmp is
+// the result of comparing con1 with con2.
+func columnOPConstImplies(op1, op2 string, cmp int) bool {
+	switch {
+	case op1 == ast.EQ:
+		// Check if the value of the first condition satisfies the second one.
+		switch op2 {
+		case ast.EQ:
+			return cmp == 0
+		case ast.GT:
+			return cmp > 0
+		case ast.GE:
+			return cmp >= 0
+		case ast.LT:
+			return cmp < 0
+		case ast.LE:
+			return cmp <= 0
+		}
+	case isLowerOp(op1) && isLowerOp(op2):
+		return cmp > 0 || (cmp == 0 && (op1 == ast.GT || op2 == ast.GE))
+	case isUpperOp(op1) && isUpperOp(op2):
+		return cmp < 0 || (cmp == 0 && (op1 == ast.LT || op2 == ast.LE))
+	}
+	return false
+}
+
+// ImpliedBy returns true if all the rows satisfying the CNF conditions satisfy the condition too.
+// It's a conservative check, only the duplicate condition and the "column op const" condition
+// on the same column are recognized.
+// "a > 5" is implied by "a > 10, b = 1"
+func ImpliedBy(ctx sessionctx.Context, cond Expression, conditions []Expression) bool {
+	if IsMutableEffectsExpr(cond) {
+		return false
+	}
+	col, op, con := validCompareCond(cond)
+	for _, c := range conditions {
+		if c.Equal(ctx, cond) {
+			return true
+		}
+		if col == nil {
+			continue
+		}
+		col1, op1, con1 := validCompareCond(c)
+		if col1 == nil || !col1.Equal(ctx, col) {
+			continue
+		}
+		cmp, err := con1.Value.CompareDatum(ctx.GetSessionVars().StmtCtx, &con.Value)
+		if err == nil && columnOPConstImplies(op1, op, cmp) {
+			return true
+		}
+	}
+	return false
+}
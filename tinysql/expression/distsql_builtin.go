@@ -118,11 +118,17 @@ func getSignatureByPB(ctx sessionctx.Context, sigCode tipb.ScalarFuncSig, tp *ti
 	case tipb.ScalarFuncSig_SetVar:
 		f = &builtinSetVarSig{base}
 	case tipb.ScalarFuncSig_InInt:
-		f = &builtinInIntSig{base}
+		f = newBuiltinInIntSig(base)
 	case tipb.ScalarFuncSig_InReal:
-		f = &builtinInRealSig{base}
+		f = newBuiltinInRealSig(base)
 	case tipb.ScalarFuncSig_InString:
-		f = &builtinInStringSig{base}
+		f = newBuiltinInStringSig(base)
+	case tipb.ScalarFuncSig_CaseWhenInt:
+		f = &builtinCaseWhenIntSig{base}
+	case tipb.ScalarFuncSig_CaseWhenReal:
+		f = &builtinCaseWhenRealSig{base}
+	case tipb.ScalarFuncSig_CaseWhenString:
+		f = &builtinCaseWhenStringSig{base}
 	case tipb.ScalarFuncSig_IfNullInt:
 		f = &builtinIfNullIntSig{base}
 	case tipb.ScalarFuncSig_IfNullReal:
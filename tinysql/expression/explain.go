@@ -32,7 +32,11 @@ func (expr *ScalarFunction) explainInfo(normalized bool) string {
 	var buffer bytes.Buffer
 	fmt.Fprintf(&buffer, "%s(", expr.FuncName.L)
 	for i, arg := range expr.GetArgs() {
-		buffer.WriteString(arg.ExplainInfo())
+		if normalized {
+			buffer.WriteString(arg.ExplainNormalizedInfo())
+		} else {
+			buffer.WriteString(arg.ExplainInfo())
+		}
 		if i+1 < len(expr.GetArgs()) {
 			buffer.WriteString(", ")
 		}
@@ -108,7 +112,11 @@ func sortedExplainExpressionList(exprs []Expression, normalized bool) []byte {
 	buffer := bytes.NewBufferString("")
 	exprInfos := make([]string, 0, len(exprs))
 	for _, expr := range exprs {
-		exprInfos = append(exprInfos, expr.ExplainInfo())
+		if normalized {
+			exprInfos = append(exprInfos, expr.ExplainNormalizedInfo())
+		} else {
+			exprInfos = append(exprInfos, expr.ExplainInfo())
+		}
 	}
 	sort.Strings(exprInfos)
 	for i, info := range exprInfos {
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tipb/go-tipb"
 	"go.uber.org/zap"
@@ -278,6 +279,14 @@ func SortByItemToPB(sc *stmtctx.StatementContext, client kv.Client, expr Express
 }
 
 func (pc PbConverter) canFuncBePushed(sf *ScalarFunction) bool {
+	switch sf.FuncName.L {
+	case ast.LT, ast.LE, ast.EQ, ast.NE, ast.GE, ast.GT, ast.In:
+		// The coprocessor compares strings byte by byte, so the comparisons under
+		// a case-insensitive collation must be evaluated in TiDB.
+		if collate.IsCICollation(DeriveCollationFromExprs(sf.GetArgs()...)) {
+			return false
+		}
+	}
 	switch sf.FuncName.L {
 	case
 		// op functions.
@@ -302,6 +311,7 @@ func (pc PbConverter) canFuncBePushed(sf *ScalarFunction) bool {
 		ast.Div,
 
 		// control flow functions.
+		ast.Case,
 		ast.If,
 		ast.Ifnull,
 
@@ -105,6 +105,9 @@ type Expression interface {
 	// ExplainInfo returns operator information to be explained.
 	ExplainInfo() string
 
+	// ExplainNormalizedInfo returns operator normalized information for generating digest.
+	ExplainNormalizedInfo() string
+
 	// HashCode creates the hashcode for expression which can be used to identify itself from other expression.
 	// It generated as the following:
 	// Constant: ConstantFlag+encoded value
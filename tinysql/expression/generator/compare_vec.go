@@ -76,6 +76,8 @@ func (b *builtin{{ .compare.CompareName }}{{ .type.TypeName }}Sig) vecEvalInt(in
 {{ if .type.Fixed }}
 	arg0 := buf0.{{ .type.TypeNameInColumn }}s()
 	arg1 := buf1.{{ .type.TypeNameInColumn }}s()
+{{- else }}
+	collator := b.collator
 {{- end }}
 	result.ResizeInt64(n, false)
 	result.MergeNulls(buf0, buf1)
@@ -87,7 +89,7 @@ func (b *builtin{{ .compare.CompareName }}{{ .type.TypeName }}Sig) vecEvalInt(in
 {{- if eq .type.ETName "Real" }}
 		val := types.CompareFloat64(arg0[i], arg1[i])
 {{- else }}
-		val := types.CompareString(buf0.GetString(i), buf1.GetString(i))
+		val := collator.Compare(buf0.GetString(i), buf1.GetString(i))
 {{- end }}
 		if val {{ .compare.Operator }} 0 {
 			i64s[i] = 1
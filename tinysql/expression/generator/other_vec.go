@@ -94,6 +94,8 @@ var builtinInTmpl = template.Must(template.New("builtinInTmpl").Parse(`
 					compareResult = 0
 				}
 		}
+	{{- else if eq .Input.TypeName "String" -}}
+		compareResult = collator.Compare(arg0, arg1)
 	{{- else -}}
 		compareResult = types.Compare{{ .Input.TypeNameInColumn }}(arg0, arg1)
 	{{- end -}}
@@ -118,14 +120,38 @@ func (b *{{.SigName}}) vecEvalInt(input *chunk.Chunk, result *chunk.Column) erro
 	{{- if $InputInt }}
 		isUnsigned0 := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
 	{{- end }}
+	{{- if $InputString }}
+		collator := b.collator
+	{{- end }}
 	var compareResult int
+	args := b.args
+	if b.hashSet != nil {
+		args = b.nonConstArgs
+		for i := 0; i < n; i++ {
+			if buf0.IsNull(i) {
+				hasNull[i] = true
+				continue
+			}
+			{{- if $InputFixed }}
+				arg0 := args0[i]
+			{{- else }}
+				arg0 := buf0.Get{{ .Input.TypeName }}(i)
+			{{- end }}
+			if b.hashSetContains({{ if $InputString }}collator, {{ end }}arg0) {
+				result.SetNull(i, false)
+				r64s[i] = 1
+			} else if b.hasNull {
+				hasNull[i] = true
+			}
+		}
+	}
 
-	for j := 1; j < len(b.args); j++ {
-		if err := b.args[j].VecEval{{ .Input.TypeName }}(b.ctx, input, buf1); err != nil {
+	for j := 1; j < len(args); j++ {
+		if err := args[j].VecEval{{ .Input.TypeName }}(b.ctx, input, buf1); err != nil {
 			return err
 		}
 		{{- if $InputInt }}
-			isUnsigned := mysql.HasUnsignedFlag(b.args[j].GetType().Flag)
+			isUnsigned := mysql.HasUnsignedFlag(args[j].GetType().Flag)
 		{{- end }}
 		{{- if $InputFixed }}
 			args1 := buf1.{{.Input.TypeNameInColumn}}s()
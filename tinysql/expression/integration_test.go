@@ -90,6 +90,24 @@ func (s *testIntegrationSuite) TestInPredicate4UnsignedInt(c *C) {
 	r.Check(testkit.Rows("1"))
 }
 
+func (s *testIntegrationSuite) TestNotInPredicateWithNull(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer s.cleanEnv(c)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (null, 3)")
+	// A NULL in the list makes every unmatched row evaluate to NULL, so no row is returned.
+	tk.MustQuery("select a from t where a not in (1, null)").Check(testkit.Rows())
+	tk.MustQuery("select a from t where a not in (null)").Check(testkit.Rows())
+	tk.MustQuery("select a from t where a in (1, null)").Check(testkit.Rows("1"))
+	// A NULL on the left side never matches and never passes the filter.
+	tk.MustQuery("select b from t where a not in (1, 3)").Check(testkit.Rows("2"))
+	tk.MustQuery("select b, a not in (1, null) from t order by b").Check(testkit.Rows("1 0", "2 <nil>", "3 <nil>"))
+	tk.MustQuery("select b from t where (a, b) not in ((null, 2)) order by b").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select b from t where (a, b) not in ((1, 1), (3, 3))").Check(testkit.Rows("2"))
+}
+
 func (s *testIntegrationSuite) TestFilterExtractFromDNF(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	defer s.cleanEnv(c)
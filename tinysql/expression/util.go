@@ -376,6 +376,101 @@ func DeriveRelaxedFiltersFromDNF(expr Expression, schema *Schema) Expression {
 	return ComposeDNFCondition(ctx, newDNFItems...)
 }
 
+// DNFEqualsToIn collapses the equalities between the same column and constants in a DNF condition into
+// an in function, e.g. `a = 1 or b = 1 or a = 2` is rewritten as `a in (1, 2) or b = 1`, because the in
+// list builds cleaner point ranges than the disjunction. The condition is returned as is if there is
+// nothing to collapse.
+func DNFEqualsToIn(ctx sessionctx.Context, cond Expression) Expression {
+	sf, ok := cond.(*ScalarFunction)
+	if !ok || sf.FuncName.L != ast.LogicOr {
+		return cond
+	}
+	type inList struct {
+		args []Expression
+		// pos is the position of the first equality of the column in the new DNF items.
+		pos   int
+		items int
+	}
+	dnfItems := FlattenDNFConditions(sf)
+	newDNFItems := make([]Expression, 0, len(dnfItems))
+	var lists []*inList
+	collapsed := false
+	for _, item := range dnfItems {
+		col, values := equalConstants(item)
+		if col == nil {
+			newDNFItems = append(newDNFItems, item)
+			continue
+		}
+		var list *inList
+		for _, l := range lists {
+			if l.args[0].Equal(ctx, col) {
+				list = l
+				break
+			}
+		}
+		if list == nil {
+			list = &inList{args: []Expression{col}, pos: len(newDNFItems)}
+			lists = append(lists, list)
+			newDNFItems = append(newDNFItems, item)
+		} else {
+			collapsed = true
+		}
+		list.args = append(list.args, values...)
+		list.items++
+	}
+	if !collapsed {
+		return cond
+	}
+	for _, l := range lists {
+		if l.items < 2 {
+			continue
+		}
+		in, err := NewFunction(ctx, ast.In, types.NewFieldType(mysql.TypeTiny), l.args...)
+		if err != nil {
+			return cond
+		}
+		newDNFItems[l.pos] = in
+	}
+	return ComposeDNFCondition(ctx, newDNFItems...)
+}
+
+// equalConstants returns the column and the constants if the expression is an equality between them,
+// or an in function of the column and the constants. The constants must be compared with the column in
+// the type of the column, so they can be put into one in list.
+func equalConstants(expr Expression) (*Column, []Expression) {
+	sf, ok := expr.(*ScalarFunction)
+	if !ok {
+		return nil, nil
+	}
+	args := sf.GetArgs()
+	var col *Column
+	var values []Expression
+	switch sf.FuncName.L {
+	case ast.EQ:
+		if c, ok := args[0].(*Column); ok {
+			col, values = c, args[1:]
+		} else if c, ok := args[1].(*Column); ok {
+			col, values = c, args[:1]
+		}
+	case ast.In:
+		if c, ok := args[0].(*Column); ok {
+			col, values = c, args[1:]
+		}
+	}
+	if col == nil {
+		return nil, nil
+	}
+	for _, value := range values {
+		if _, ok := value.(*Constant); !ok {
+			return nil, nil
+		}
+		if value.GetType().Tp != mysql.TypeNull && GetAccurateCmpType(col, value) != col.GetType().EvalType() {
+			return nil, nil
+		}
+	}
+	return col, values
+}
+
 // GetRowLen gets the length if the func is row, returns 1 if not row.
 func GetRowLen(e Expression) int {
 	if f, ok := e.(*ScalarFunction); ok && f.FuncName.L == ast.RowFunc {
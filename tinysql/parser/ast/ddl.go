@@ -443,6 +443,8 @@ type CreateIndexStmt struct {
 	IndexPartSpecifications []*IndexPartSpecification
 	IndexOption             *IndexOption
 	KeyType                 IndexKeyType
+	// Where is the filter of a partial index, only the rows satisfying it are indexed.
+	Where ExprNode
 }
 
 // Accept implements Node Accept interface.
@@ -471,6 +473,13 @@ func (n *CreateIndexStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.IndexOption = node.(*IndexOption)
 	}
+	if n.Where != nil {
+		node, ok := n.Where.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Where = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
 
@@ -196,6 +196,34 @@ func (n *TableSource) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ValuesTable is the VALUES table value constructor used as a table source, like
+// "(VALUES ROW(1, 2), ROW(3, 4)) AS t".
+type ValuesTable struct {
+	node
+
+	// Lists is the rows of the table, every row has the same number of values.
+	Lists [][]ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *ValuesTable) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ValuesTable)
+	for i, list := range n.Lists {
+		for j, val := range list {
+			node, ok := val.Accept(v)
+			if !ok {
+				return n, false
+			}
+			n.Lists[i][j] = node.(ExprNode)
+		}
+	}
+	return v.Leave(n)
+}
+
 // WildCardField is a special type of select field content.
 type WildCardField struct {
 	node
@@ -297,12 +325,26 @@ func (n *TableRefsClause) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// NullOrder is the order of the NULLs specified by NULLS FIRST or NULLS LAST.
+type NullOrder int
+
+const (
+	// NullOrderDefault follows MySQL, NULLs are smaller than any value, so they come
+	// first in ascending order and last in descending order.
+	NullOrderDefault NullOrder = iota
+	// NullsFirst puts the NULLs before any value.
+	NullsFirst
+	// NullsLast puts the NULLs after any value.
+	NullsLast
+)
+
 // ByItem represents an item in order by or group by.
 type ByItem struct {
 	node
 
-	Expr ExprNode
-	Desc bool
+	Expr      ExprNode
+	Desc      bool
+	NullOrder NullOrder
 }
 
 // Accept implements Node Accept interface.
@@ -415,6 +457,8 @@ type SelectStmt struct {
 	TableHints []*TableOptimizerHint
 	// IsInBraces indicates whether it's a stmt in brace.
 	IsInBraces bool
+	// IsAfterUnionDistinct indicates whether it's a stmt after "union distinct".
+	IsAfterUnionDistinct bool
 }
 
 // Accept implements Node Accept interface.
@@ -496,6 +540,71 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// UnionSelectList represents the select list in a union statement.
+type UnionSelectList struct {
+	node
+
+	Selects []*SelectStmt
+}
+
+// Accept implements Node Accept interface.
+func (n *UnionSelectList) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*UnionSelectList)
+	for i, sel := range n.Selects {
+		node, ok := sel.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Selects[i] = node.(*SelectStmt)
+	}
+	return v.Leave(n)
+}
+
+// UnionStmt represents "union statement"
+// See https://dev.mysql.com/doc/refman/5.7/en/union.html
+type UnionStmt struct {
+	dmlNode
+
+	SelectList *UnionSelectList
+	OrderBy    *OrderByClause
+	Limit      *Limit
+}
+
+// Accept implements Node Accept interface.
+func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*UnionStmt)
+	if n.SelectList != nil {
+		node, ok := n.SelectList.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.SelectList = node.(*UnionSelectList)
+	}
+	if n.OrderBy != nil {
+		node, ok := n.OrderBy.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OrderBy = node.(*OrderByClause)
+	}
+	if n.Limit != nil {
+		node, ok := n.Limit.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Limit = node.(*Limit)
+	}
+	return v.Leave(n)
+}
+
 // Assignment is the expression for assignment, like a = 1.
 type Assignment struct {
 	node
@@ -535,13 +644,14 @@ type ColumnNameOrUserVar struct {
 type InsertStmt struct {
 	dmlNode
 
-	IsReplace bool
-	Table     *TableRefsClause
-	Columns   []*ColumnName
-	Lists     [][]ExprNode
-	Setlist   []*Assignment
-	Priority  mysql.PriorityEnum
-	Select    ResultSetNode
+	IsReplace   bool
+	Table       *TableRefsClause
+	Columns     []*ColumnName
+	Lists       [][]ExprNode
+	Setlist     []*Assignment
+	Priority    mysql.PriorityEnum
+	OnDuplicate []*Assignment
+	Select      ResultSetNode
 }
 
 // Accept implements Node Accept interface.
@@ -589,6 +699,13 @@ func (n *InsertStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Setlist[i] = node.(*Assignment)
 	}
+	for i, val := range n.OnDuplicate {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OnDuplicate[i] = node.(*Assignment)
+	}
 	return v.Leave(n)
 }
 
@@ -691,6 +808,7 @@ const (
 	ShowProcessList
 	ShowCreateDatabase
 	ShowErrors
+	ShowTableStatus
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
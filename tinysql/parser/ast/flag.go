@@ -39,6 +39,8 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 		x.SetFlag(x.Expr.GetFlag() | x.Left.GetFlag() | x.Right.GetFlag())
 	case *BinaryOperationExpr:
 		x.SetFlag(x.L.GetFlag() | x.R.GetFlag())
+	case *CaseExpr:
+		f.caseExpr(x)
 	case *ColumnNameExpr:
 		x.SetFlag(FlagHasReference)
 	case *DefaultExpr:
@@ -53,6 +55,8 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 		f.patternIn(x)
 	case *RowExpr:
 		f.row(x)
+	case *SubqueryExpr:
+		x.SetFlag(FlagHasSubquery)
 	case *UnaryOperationExpr:
 		x.SetFlag(x.V.GetFlag())
 	case *ValuesExpr:
@@ -68,6 +72,21 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 	return in, true
 }
 
+func (f *flagSetter) caseExpr(x *CaseExpr) {
+	var flag uint64
+	if x.Value != nil {
+		flag |= x.Value.GetFlag()
+	}
+	for _, val := range x.WhenClauses {
+		flag |= val.Expr.GetFlag()
+		flag |= val.Result.GetFlag()
+	}
+	if x.ElseClause != nil {
+		flag |= x.ElseClause.GetFlag()
+	}
+	x.SetFlag(flag)
+}
+
 func (f *flagSetter) patternIn(x *PatternInExpr) {
 	flag := x.Expr.GetFlag()
 	for _, val := range x.List {
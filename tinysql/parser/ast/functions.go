@@ -28,11 +28,13 @@ var (
 // List scalar function names.
 const (
 	IsNull      = "isnull"
+	Cast        = "cast"
 	Length      = "length"
 	Strcmp      = "strcmp"
 	OctetLength = "octet_length"
 	If          = "if"
 	Ifnull      = "ifnull"
+	Case        = "case"
 	LogicAnd    = "and"
 	LogicOr     = "or"
 	GE          = "ge"
@@ -105,6 +107,8 @@ const (
 	AggFuncMax = "max"
 	// AggFuncMin is the name of min function.
 	AggFuncMin = "min"
+	// AggFuncGroupConcat is the name of group_concat function.
+	AggFuncGroupConcat = "group_concat"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
@@ -113,7 +117,10 @@ type AggregateFuncExpr struct {
 	// F is the function name.
 	F string
 	// Args is the function args.
+	// For GROUP_CONCAT, the separator is appended as the last arg.
 	Args []ExprNode
+	// Order is only used in GROUP_CONCAT.
+	Order *OrderByClause
 }
 
 // Format the ExprNode into a Writer.
@@ -135,5 +142,12 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 		}
 		n.Args[i] = node.(ExprNode)
 	}
+	if n.Order != nil {
+		node, ok := n.Order.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Order = node.(*OrderByClause)
+	}
 	return v.Leave(n)
 }
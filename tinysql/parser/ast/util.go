@@ -16,7 +16,7 @@ package ast
 // IsReadOnly checks whether the input ast is readOnly.
 func IsReadOnly(node Node) bool {
 	switch st := node.(type) {
-	case *SelectStmt:
+	case *SelectStmt, *UnionStmt:
 		checker := readOnlyChecker{
 			readOnly: true,
 		}
@@ -514,6 +514,9 @@ type IndexInfo struct {
 	State   SchemaState    `json:"state"`
 	Comment string         `json:"comment"`    // Comment
 	Tp      IndexType      `json:"index_type"` // Index type: Btree, Hash or Rtree
+	// Condition is the filter of a partial index, only the rows satisfying it are indexed.
+	// It's empty if all the rows are indexed.
+	Condition string `json:"condition,omitempty"`
 }
 
 // Clone clones IndexInfo.
@@ -693,8 +693,10 @@ import (
 	PredicateExpr			"Predicate expression factor"
 	SetExpr				"Set variable statement value's expression"
 	BitExpr				"bit expression"
+	CaseExpr			"CASE expression"
 	SimpleExpr			"simple expression"
 	SimpleIdent			"Simple Identifier expression"
+	SubSelect			"Sub Select"
 	SumExpr				"aggregate functions"
 	FunctionCallGeneric		"Function call with Identifier"
 	FunctionCallKeyword		"Function call with keyword as function name"
@@ -705,6 +707,7 @@ import (
 	UserVariable			"User defined variable name"
 	StringLiteral			"text literal"
 	ExpressionOpt			"Optional expression"
+	ElseOpt				"Optional else clause"
 	SignedLiteral			"Literal or NumLiteral with sign"
 	DefaultValueExpr		"DefaultValueExpr(Now or Signed Literal)"
 	NowSymOptionFraction		"NowSym with optional fraction part"
@@ -728,6 +731,7 @@ import (
 	ExplainStmt			"EXPLAIN statement"
 	ExplainableStmt			"explainable statement"
 	InsertIntoStmt			"INSERT INTO statement"
+	KillStmt			"Kill statement"
 	SelectStmt			"SELECT statement"
 	ReplaceIntoStmt			"REPLACE INTO statement"
 	RollbackStmt			"ROLLBACK statement"
@@ -735,6 +739,7 @@ import (
 	ShowStmt			"Show engines/databases/tables/user/columns/warnings/status statement"
 	Statement			"statement"
 	TruncateTableStmt		"TRUNCATE TABLE statement"
+	UnionStmt			"Union select state ment"
 	UseStmt				"USE statement"
 
 %type   <item>
@@ -805,10 +810,12 @@ import (
 	IndexType			"index type"
 	IndexTypeName			"index type name"
 	IndexTypeOpt			"optional index type"
+	IndexWhereOpt			"optional filter of a partial index"
 	IndexPartSpecification		"Index column name or expression"
 	IndexPartSpecificationList	"List of index column name or expression"
 	IndexPartSpecificationListOpt   "Optional list of index column name or expression"
 	InsertValues			"Rest part of INSERT/REPLACE INTO statement"
+	OnDuplicateKeyUpdate		"ON DUPLICATE KEY UPDATE value list"
 	JoinTable 			"join table"
 	JoinType			"join type"
 	LocationLabelList		"location label name list"
@@ -817,6 +824,7 @@ import (
 	LimitClause			"LIMIT clause"
 	LimitOption			"Limit option could be integer or parameter marker."
 	NumLiteral			"Num/Int/Float/Decimal Literal"
+	NullOrderOpt			"optional NULLS FIRST or NULLS LAST"
 	OptFull				"Full or empty"
 	OptTemporary			"TEMPORARY or empty"
 	Order				"ORDER BY clause optional collation specification"
@@ -829,6 +837,8 @@ import (
 	PriorityOpt			"Statement priority option"
 	OptGConcatSeparator		"optional GROUP_CONCAT SEPARATOR"
 	RowValue			"Row value"
+	RowConstructor			"Row constructor of the VALUES table source"
+	RowConstructorList		"Row constructor list"
 	SelectStmtCalcFoundRows		"SELECT statement optional SQL_CALC_FOUND_ROWS"
 	SelectStmtSQLBigResult		"SELECT statement optional SQL_BIG_RESULT"
 	SelectStmtSQLBufferResult	"SELECT statement optional SQL_BUFFER_RESULT"
@@ -863,12 +873,17 @@ import (
 	TableNameListOpt		"Table name list opt"
 	TableRef 			"table reference"
 	TableRefs 			"table references"
+	UnionClauseList		"Union select clause list"
+	UnionOpt		"Union Option(empty/ALL/DISTINCT)"
+	UnionSelect		"Union (select) item"
 
 	Values			"values"
 	ValuesList		"values list"
 	ValuesOpt		"values optional"
 	VariableAssignment	"set variable value"
 	VariableAssignmentList	"set variable value list"
+	WhenClause		"When clause"
+	WhenClauseList		"When clause list"
 	WhereClause		"WHERE clause"
 	WhereClauseOptional	"Optional WHERE clause"
 	WithValidation		"with validation"
@@ -1847,7 +1862,7 @@ NumLiteral:
  *     LOCK [=] {DEFAULT | NONE | SHARED | EXCLUSIVE}
  *******************************************************************************************/
 CreateIndexStmt:
-	"CREATE" IndexKeyTypeOpt "INDEX" IfNotExists Identifier IndexTypeOpt "ON" TableName '(' IndexPartSpecificationList ')' IndexOptionList
+	"CREATE" IndexKeyTypeOpt "INDEX" IfNotExists Identifier IndexTypeOpt "ON" TableName '(' IndexPartSpecificationList ')' IndexOptionList IndexWhereOpt
 	{
 		var indexOption *ast.IndexOption
 		if $12 != nil {
@@ -1863,7 +1878,7 @@ CreateIndexStmt:
 				indexOption.Tp = $6.(model.IndexType)
 			}
 		}
-		$$ = &ast.CreateIndexStmt{
+		stmt := &ast.CreateIndexStmt{
 			IfNotExists:   $4.(bool),
 			IndexName:     $5,
 			Table:         $8.(*ast.TableName),
@@ -1871,6 +1886,25 @@ CreateIndexStmt:
 			IndexOption:   indexOption,
 			KeyType:       $2.(ast.IndexKeyType),
 		}
+		if $13 != nil {
+			stmt.Where = $13.(ast.ExprNode)
+		}
+		$$ = stmt
+	}
+
+IndexWhereOpt:
+	{
+		$$ = nil
+	}
+|	"WHERE" Expression
+	{
+		// The filter is kept as text in the index info, the parser has read the token after
+		// the expression here, so the text ends before it.
+		startOffset := parser.startOffset(&yyS[yypt])
+		endOffset := parser.endOffset(&parser.yylval)
+		expr := $2
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = expr
 	}
 
 IndexPartSpecificationListOpt:
@@ -2253,6 +2287,12 @@ BoolPri:
 	{
 		$$ = &ast.BinaryOperationExpr{Op: $2.(opcode.Op), L: $1, R: $3}
 	}
+|	BoolPri CompareOp AnyOrAll SubSelect %prec eq
+	{
+		sq := $4.(*ast.SubqueryExpr)
+		sq.MultiRows = true
+		$$ = &ast.CompareSubqueryExpr{Op: $2.(opcode.Op), L: $1, R: sq, All: $3.(bool)}
+	}
 |	BoolPri CompareOp singleAtIdentifier assignmentEq PredicateExpr %prec assignmentEq
 	{
 		v := $3
@@ -2350,6 +2390,12 @@ PredicateExpr:
 	{
 		$$ = &ast.PatternInExpr{Expr: $1, Not: !$2.(bool), List: $4.([]ast.ExprNode)}
 	}
+|	BitExpr InOrNotOp SubSelect
+	{
+		sq := $3.(*ast.SubqueryExpr)
+		sq.MultiRows = true
+		$$ = &ast.PatternInExpr{Expr: $1, Not: !$2.(bool), List: []ast.ExprNode{sq}}
+	}
 |	BitExpr BetweenOrNotOp BitExpr "AND" PredicateExpr
 	{
 		$$ = &ast.BetweenExpr{
@@ -2675,13 +2721,16 @@ NotKeywordToken:
  *
  **********************************************************************************/
 InsertIntoStmt:
-	"INSERT" PriorityOpt IntoOpt TableName InsertValues
+	"INSERT" PriorityOpt IntoOpt TableName InsertValues OnDuplicateKeyUpdate
 	{
 		x := $5.(*ast.InsertStmt)
 		x.Priority = $2.(mysql.PriorityEnum)
 		// Wraps many layers here so that it can be processed the same way as select statement.
 		ts := &ast.TableSource{Source: $4.(*ast.TableName)}
 		x.Table = &ast.TableRefsClause{TableRefs: &ast.Join{Left: ts}}
+		if $6 != nil {
+			x.OnDuplicate = $6.([]*ast.Assignment)
+		}
 		$$ = x
 	}
 
@@ -2786,6 +2835,15 @@ ColumnSetValueList:
 		$$ = append($1.([]*ast.Assignment), $3.(*ast.Assignment))
 	}
 
+OnDuplicateKeyUpdate:
+	{
+		$$ = nil
+	}
+|	"ON" "DUPLICATE" "KEY" "UPDATE" AssignmentList
+	{
+		$$ = $5
+	}
+
 /***********************************Insert Statements END************************************/
 
 /************************************************************************************
@@ -2899,9 +2957,23 @@ ByList:
 	}
 
 ByItem:
-	Expression Order
+	Expression Order NullOrderOpt
+	{
+		$$ = &ast.ByItem{Expr: $1, Desc: $2.(bool), NullOrder: $3.(ast.NullOrder)}
+	}
+
+NullOrderOpt:
+	/* EMPTY */
+	{
+		$$ = ast.NullOrderDefault
+	}
+|	"NULLS" "FIRST"
 	{
-		$$ = &ast.ByItem{Expr: $1, Desc: $2.(bool)}
+		$$ = ast.NullsFirst
+	}
+|	"NULLS" "LAST"
+	{
+		$$ = ast.NullsLast
 	}
 
 Order:
@@ -3021,6 +3093,7 @@ SimpleExpr:
 |	Literal
 |	Variable
 |	SumExpr
+|	CaseExpr
 |	'!' SimpleExpr %prec neg
 	{
 		$$ = &ast.UnaryOperationExpr{Op: opcode.Not, V: $2}
@@ -3075,6 +3148,7 @@ SimpleExpr:
 	{
 		$$ = &ast.ValuesExpr{Column: $3.(*ast.ColumnNameExpr)}
 	}
+|	SubSelect
 
 DistinctKwd:
 	"DISTINCT"
@@ -3272,6 +3346,16 @@ SumExpr:
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3}}
 	}
+|	builtinGroupConcat '(' ExpressionList OrderByOptional OptGConcatSeparator ')'
+	{
+		args := $3.([]ast.ExprNode)
+		args = append(args, $5.(ast.ExprNode))
+		agg := &ast.AggregateFuncExpr{F: $1, Args: args}
+		if $4 != nil {
+			agg.Order = $4.(*ast.OrderByClause)
+		}
+		$$ = agg
+	}
 
 OptGConcatSeparator:
         {
@@ -3312,6 +3396,48 @@ ExpressionOpt:
 		$$ = $1
 	}
 
+CaseExpr:
+	"CASE" ExpressionOpt WhenClauseList ElseOpt "END"
+	{
+		x := &ast.CaseExpr{WhenClauses: $3.([]*ast.WhenClause)}
+		if $2 != nil {
+			x.Value = $2
+		}
+		if $4 != nil {
+			x.ElseClause = $4
+		}
+		$$ = x
+	}
+
+WhenClauseList:
+	WhenClause
+	{
+		$$ = []*ast.WhenClause{$1.(*ast.WhenClause)}
+	}
+|	WhenClauseList WhenClause
+	{
+		$$ = append($1.([]*ast.WhenClause), $2.(*ast.WhenClause))
+	}
+
+WhenClause:
+	"WHEN" Expression "THEN" Expression
+	{
+		$$ = &ast.WhenClause{
+			Expr: $2,
+			Result: $4,
+		}
+	}
+
+ElseOpt:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	"ELSE" Expression
+	{
+		$$ = $2
+	}
+
 CastType:
 	"BINARY" OptFieldLen
 	{
@@ -3632,6 +3758,128 @@ SelectStmt:
 		$$ = st
 	}
 
+// See https://dev.mysql.com/doc/refman/5.7/en/union.html
+UnionStmt:
+	UnionClauseList "UNION" UnionOpt SelectStmtBasic OrderByOptional SelectStmtLimit
+	{
+		st := $4.(*ast.SelectStmt)
+		union := $1.(*ast.UnionStmt)
+		st.IsAfterUnionDistinct = $3.(bool)
+		lastSelect := union.SelectList.Selects[len(union.SelectList.Selects)-1]
+		endOffset := parser.endOffset(&yyS[yypt-4])
+		parser.setLastSelectFieldText(lastSelect, endOffset)
+		union.SelectList.Selects = append(union.SelectList.Selects, st)
+		if $5 != nil {
+			union.OrderBy = $5.(*ast.OrderByClause)
+		}
+		if $6 != nil {
+			union.Limit = $6.(*ast.Limit)
+		}
+		if $5 == nil && $6 == nil {
+			lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
+			if lastField.Expr != nil && lastField.AsName.O == "" {
+				src := parser.src
+				lastEnd := len(src)
+				if src[lastEnd-1] == ';' {
+					lastEnd--
+				}
+				parser.setLastSelectFieldText(st, lastEnd)
+			}
+		}
+		$$ = union
+	}
+|	UnionClauseList "UNION" UnionOpt SelectStmtFromDualTable OrderByOptional SelectStmtLimit
+	{
+		st := $4.(*ast.SelectStmt)
+		union := $1.(*ast.UnionStmt)
+		st.IsAfterUnionDistinct = $3.(bool)
+		lastSelect := union.SelectList.Selects[len(union.SelectList.Selects)-1]
+		endOffset := parser.endOffset(&yyS[yypt-4])
+		parser.setLastSelectFieldText(lastSelect, endOffset)
+		union.SelectList.Selects = append(union.SelectList.Selects, st)
+		if $5 != nil {
+			union.OrderBy = $5.(*ast.OrderByClause)
+		}
+		if $6 != nil {
+			union.Limit = $6.(*ast.Limit)
+		}
+		$$ = union
+	}
+|	UnionClauseList "UNION" UnionOpt SelectStmtFromTable OrderByOptional SelectStmtLimit
+	{
+		st := $4.(*ast.SelectStmt)
+		union := $1.(*ast.UnionStmt)
+		st.IsAfterUnionDistinct = $3.(bool)
+		lastSelect := union.SelectList.Selects[len(union.SelectList.Selects)-1]
+		endOffset := parser.endOffset(&yyS[yypt-4])
+		parser.setLastSelectFieldText(lastSelect, endOffset)
+		union.SelectList.Selects = append(union.SelectList.Selects, st)
+		if $5 != nil {
+			union.OrderBy = $5.(*ast.OrderByClause)
+		}
+		if $6 != nil {
+			union.Limit = $6.(*ast.Limit)
+		}
+		$$ = union
+	}
+|	UnionClauseList "UNION" UnionOpt '(' SelectStmt ')' OrderByOptional SelectStmtLimit
+	{
+		union := $1.(*ast.UnionStmt)
+		lastSelect := union.SelectList.Selects[len(union.SelectList.Selects)-1]
+		endOffset := parser.endOffset(&yyS[yypt-6])
+		parser.setLastSelectFieldText(lastSelect, endOffset)
+		st := $5.(*ast.SelectStmt)
+		st.IsInBraces = true
+		st.IsAfterUnionDistinct = $3.(bool)
+		endOffset = parser.endOffset(&yyS[yypt-2])
+		parser.setLastSelectFieldText(st, endOffset)
+		union.SelectList.Selects = append(union.SelectList.Selects, st)
+		if $7 != nil {
+			union.OrderBy = $7.(*ast.OrderByClause)
+		}
+		if $8 != nil {
+			union.Limit = $8.(*ast.Limit)
+		}
+		$$ = union
+	}
+
+UnionClauseList:
+	UnionSelect
+	{
+		selectList := &ast.UnionSelectList{Selects: []*ast.SelectStmt{$1.(*ast.SelectStmt)}}
+		$$ = &ast.UnionStmt{
+			SelectList: selectList,
+		}
+	}
+|	UnionClauseList "UNION" UnionOpt UnionSelect
+	{
+		union := $1.(*ast.UnionStmt)
+		st := $4.(*ast.SelectStmt)
+		st.IsAfterUnionDistinct = $3.(bool)
+		lastSelect := union.SelectList.Selects[len(union.SelectList.Selects)-1]
+		endOffset := parser.endOffset(&yyS[yypt-2])
+		parser.setLastSelectFieldText(lastSelect, endOffset)
+		union.SelectList.Selects = append(union.SelectList.Selects, st)
+		$$ = union
+	}
+
+UnionSelect:
+	SelectStmt
+	{
+		$$ = $1.(interface{})
+	}
+|	'(' SelectStmt ')'
+	{
+		st := $2.(*ast.SelectStmt)
+		st.IsInBraces = true
+		endOffset := parser.endOffset(&yyS[yypt])
+		parser.setLastSelectFieldText(st, endOffset)
+		$$ = $2
+	}
+
+UnionOpt:
+	DefaultTrueDistinctOpt
+
 FromDual:
 	"FROM" "DUAL"
 
@@ -3695,11 +3943,55 @@ TableFactor:
 		parser.setLastSelectFieldText(st, endOffset)
 		$$ = &ast.TableSource{Source: $2.(*ast.SelectStmt), AsName: $4.(model.CIStr)}
 	}
+|	'(' UnionStmt ')' TableAsName
+	{
+		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
+	}
+|	'(' "VALUES" RowConstructorList ')' TableAsName
+	{
+		$$ = &ast.TableSource{Source: &ast.ValuesTable{Lists: $3.([][]ast.ExprNode)}, AsName: $5.(model.CIStr)}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
 	}
 
+RowConstructorList:
+	RowConstructor
+	{
+		$$ = [][]ast.ExprNode{$1.([]ast.ExprNode)}
+	}
+|	RowConstructorList ',' RowConstructor
+	{
+		$$ = append($1.([][]ast.ExprNode), $3.([]ast.ExprNode))
+	}
+
+RowConstructor:
+	"ROW" '(' ExpressionList ')'
+	{
+		$$ = $3
+	}
+
+SubSelect:
+	'(' SelectStmt ')'
+	{
+		s := $2.(*ast.SelectStmt)
+		endOffset := parser.endOffset(&yyS[yypt])
+		parser.setLastSelectFieldText(s, endOffset)
+		src := parser.src
+		// See the implementation of yyParse function
+		s.SetText(src[yyS[yypt-1].offset:yyS[yypt].offset])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
+|	'(' UnionStmt ')'
+	{
+		s := $2.(*ast.UnionStmt)
+		src := parser.src
+		// See the implementation of yyParse function
+		s.SetText(src[yyS[yypt-1].offset:yyS[yypt].offset])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
+
 TableAsNameOpt:
 	{
 		$$ = model.CIStr{}
@@ -4425,13 +4717,6 @@ ShowStmt:
 			DBName:	$5.(string),
 		}
 	}
-|	"SHOW" OptFull "PROCESSLIST"
-	{
-		$$ = &ast.ShowStmt{
-			Tp: ast.ShowProcessList,
-			Full:	$2.(bool),
-		}
-	}
 
 ShowIndexKwd:
 	"INDEX"
@@ -4454,6 +4739,20 @@ ShowTargetFilterable:
 			Full:	$1.(bool),
 		}
 	}
+|	OptFull "PROCESSLIST"
+	{
+		$$ = &ast.ShowStmt{
+			Tp: ast.ShowProcessList,
+			Full:	$1.(bool),
+		}
+	}
+|	"TABLE" "STATUS" ShowDatabaseNameOpt
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowTableStatus,
+			DBName:	$3.(string),
+		}
+	}
 |	"WARNINGS"
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowWarnings}
@@ -4543,9 +4842,11 @@ Statement:
 |	DropIndexStmt
 |	DropTableStmt
 |	InsertIntoStmt
+|	KillStmt
 |	RollbackStmt
 |	ReplaceIntoStmt
 |	SelectStmt
+|	UnionStmt
 |	SetStmt
 |	ShowStmt
 |	TruncateTableStmt
@@ -4553,6 +4854,7 @@ Statement:
 
 ExplainableStmt:
 	SelectStmt
+|	UnionStmt
 |	DeleteFromStmt
 |	InsertIntoStmt
 |	ReplaceIntoStmt
@@ -5281,6 +5583,27 @@ StringName:
 		$$ = $1
 	}
 
+KillStmt:
+	"KILL" NUM
+	{
+		$$ = &ast.KillStmt{
+			ConnectionID: getUint64FromNUM($2),
+		}
+	}
+|	"KILL" "CONNECTION" NUM
+	{
+		$$ = &ast.KillStmt{
+			ConnectionID: getUint64FromNUM($3),
+		}
+	}
+|	"KILL" "QUERY" NUM
+	{
+		$$ = &ast.KillStmt{
+			ConnectionID: getUint64FromNUM($3),
+			Query:        true,
+		}
+	}
+
 UseStmt:
 	"USE" DBName
 	{
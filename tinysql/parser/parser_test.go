@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/types"
 	driver "github.com/pingcap/tidb/types/parser_driver"
@@ -339,6 +340,14 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"INSERT INTO tt VALUES (01000001783);", true, "INSERT INTO `tt` VALUES (1000001783)"},
 		{"INSERT INTO tt VALUES (default);", true, "INSERT INTO `tt` VALUES (DEFAULT)"},
 
+		{"INSERT INTO foo VALUES (1, 2) ON DUPLICATE KEY UPDATE b = 3", true, ""},
+		{"INSERT INTO foo (a, b) VALUES (1, 2), (3, 4) ON DUPLICATE KEY UPDATE b = values(b) + 1, c = a", true, ""},
+		{"INSERT INTO foo SET a = 1 ON DUPLICATE KEY UPDATE b = 2", true, ""},
+		{"INSERT INTO foo SELECT * FROM bar ON DUPLICATE KEY UPDATE b = 2", true, ""},
+		{"INSERT INTO foo SELECT * FROM bar JOIN baz ON bar.a = baz.a ON DUPLICATE KEY UPDATE b = 2", true, ""},
+		{"INSERT INTO foo VALUES (1, 2) ON DUPLICATE KEY UPDATE", false, ""},
+		{"REPLACE INTO foo VALUES (1, 2) ON DUPLICATE KEY UPDATE b = 3", false, ""},
+
 		{"REPLACE INTO foo VALUES (1 || 2)", true, "REPLACE INTO `foo` VALUES (1 OR 2)"},
 		{"REPLACE INTO foo VALUES (1 | 2)", true, "REPLACE INTO `foo` VALUES (1|2)"},
 		{"REPLACE INTO foo VALUES (false || true)", true, "REPLACE INTO `foo` VALUES (FALSE OR TRUE)"},
@@ -411,6 +420,9 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"select 1 from dual limit 1", true, "SELECT 1 LIMIT 1"},
 		{"select 1 as a from dual order by a", true, "SELECT 1 AS `a` ORDER BY `a`"},
 		{"select 1 order by 1", true, "SELECT 1 ORDER BY 1"},
+		{"select a from t order by a nulls first", true, "SELECT `a` FROM `t` ORDER BY `a` NULLS FIRST"},
+		{"select a from t order by a desc nulls last, b", true, "SELECT `a` FROM `t` ORDER BY `a` DESC NULLS LAST,`b`"},
+		{"select a from t order by a nulls", false, ""},
 
 		// for https://github.com/pingcap/tidb/issues/1050
 		{`SELECT /*!40001 SQL_NO_CACHE */ * FROM test WHERE 1 limit 0, 2000;`, true, "SELECT SQL_NO_CACHE * FROM `test` WHERE 1 LIMIT 0,2000"},
@@ -490,6 +502,11 @@ func (s *testParserSuite) TestExpression(c *C) {
 		{"select n'string'", true, "SELECT _UTF8'string'"},
 		// for comparison
 		{"select 1 <=> 0, 1 <=> null, 1 = null", true, "SELECT 1<=>0,1<=>NULL,1=NULL"},
+		// for case expression
+		{"select case when a > 1 then b else c end from t", true, "SELECT CASE WHEN `a`>1 THEN `b` ELSE `c` END FROM `t`"},
+		{"select case a when 1 then b when 2 then c end from t", true, "SELECT CASE `a` WHEN 1 THEN `b` WHEN 2 THEN `c` END FROM `t`"},
+		{"select case a else b end from t", false, ""},
+		{"select case when a then b", false, ""},
 		// for date literal
 		{"select date'1989-09-10'", true, "SELECT DATE '1989-09-10'"},
 		{"select date 19890910", false, ""},
@@ -863,6 +880,9 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select count(c1) from t;`, true, "SELECT COUNT(`c1`) FROM `t`"},
 		{`select count(*) from t;`, true, "SELECT COUNT(1) FROM `t`"},
 		{`select count(c1, c2) from t;`, false, ""},
+		{`select group_concat(c2) from t;`, true, "SELECT GROUP_CONCAT(`c2` SEPARATOR ',') FROM `t`"},
+		{`select group_concat(c1, c2 order by c1 desc, c2 separator ';') from t;`, true, "SELECT GROUP_CONCAT(`c1`, `c2` ORDER BY `c1` DESC,`c2` SEPARATOR ';') FROM `t`"},
+		{`select group_concat() from t;`, false, ""},
 	}
 	s.RunTest(c, table)
 }
@@ -1390,6 +1410,175 @@ func (s *testParserSuite) TestAnalyze(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestKill(c *C) {
+	table := []testCase{
+		{"kill 23123", true, ""},
+		{"kill connection 23123", true, ""},
+		{"kill query 23123", true, ""},
+		{"kill", false, ""},
+		{"kill query", false, ""},
+		{"kill 'abc'", false, ""},
+	}
+	s.RunTest(c, table)
+
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("kill query 3", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.KillStmt).Query, IsTrue)
+	c.Assert(stmt.(*ast.KillStmt).ConnectionID, Equals, uint64(3))
+	stmt, err = parser.ParseOneStmt("kill connection 4", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.KillStmt).Query, IsFalse)
+	c.Assert(stmt.(*ast.KillStmt).ConnectionID, Equals, uint64(4))
+}
+
+func (s *testParserSuite) TestOnDuplicateKeyUpdate(c *C) {
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("insert into t values (1, 2) on duplicate key update b = values(b) + 1, c = 3", "", "")
+	c.Assert(err, IsNil)
+	ins := stmt.(*ast.InsertStmt)
+	c.Assert(ins.OnDuplicate, HasLen, 2)
+	c.Assert(ins.OnDuplicate[0].Column.Name.L, Equals, "b")
+	c.Assert(ins.OnDuplicate[1].Column.Name.L, Equals, "c")
+
+	stmt, err = parser.ParseOneStmt("insert into t values (1, 2)", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.InsertStmt).OnDuplicate, IsNil)
+}
+
+func (s *testParserSuite) TestSubquery(c *C) {
+	table := []testCase{
+		{"select (select 1)", true, ""},
+		{"select * from t where a > (select max(b) from u)", true, ""},
+		{"select (select a, b from u limit 1) = (1, 2) from t", true, ""},
+		{"select * from t where a > (select b from u where c = 1 order by b limit 1) + 1", true, ""},
+		{"select * from t where a > (select b from u", false, ""},
+		{"select * from t where a > (select)", false, ""},
+		{"select * from t where a in (select b from u where u.c = t.c)", true, ""},
+		{"select * from t where (a, c) not in (select b, c from u)", true, ""},
+		{"select a in (select b from u union select c from v) from t", true, ""},
+		{"select * from t where a in (select b from u", false, ""},
+		{"select * from t where a > all (select b from u)", true, ""},
+		{"select * from t where a = any (select b from u)", true, ""},
+		{"select * from t where a <> some (select b from u)", true, ""},
+		{"select a <= all (select b from u) from t", true, ""},
+		{"select * from t where (a, c) = any (select b, c from u)", true, ""},
+		{"select * from t where a > all (1, 2)", false, ""},
+		{"select * from t where a > any (select b from u", false, ""},
+		{"select * from t where a > any", true, ""},
+	}
+	s.RunTest(c, table)
+
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("select * from t where a > (select max(b) from u)", "", "")
+	c.Assert(err, IsNil)
+	cmp := stmt.(*ast.SelectStmt).Where.(*ast.BinaryOperationExpr)
+	subq, ok := cmp.R.(*ast.SubqueryExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(subq.Query.Text(), Equals, "select max(b) from u")
+	c.Assert(subq.Query.(*ast.SelectStmt).Fields.Fields[0].Text(), Equals, "max(b)")
+
+	stmt, err = parser.ParseOneStmt("select * from t where a not in (select b from u)", "", "")
+	c.Assert(err, IsNil)
+	in := stmt.(*ast.SelectStmt).Where.(*ast.PatternInExpr)
+	c.Assert(in.Not, IsTrue)
+	c.Assert(in.List, HasLen, 1)
+	subq, ok = in.List[0].(*ast.SubqueryExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(subq.MultiRows, IsTrue)
+	c.Assert(subq.Query.Text(), Equals, "select b from u")
+
+	stmt, err = parser.ParseOneStmt("select * from t where a > all (select b from u)", "", "")
+	c.Assert(err, IsNil)
+	quantified := stmt.(*ast.SelectStmt).Where.(*ast.CompareSubqueryExpr)
+	c.Assert(quantified.Op, Equals, opcode.GT)
+	c.Assert(quantified.All, IsTrue)
+	subq, ok = quantified.R.(*ast.SubqueryExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(subq.MultiRows, IsTrue)
+
+	stmt, err = parser.ParseOneStmt("select * from t where a = some (select b from u)", "", "")
+	c.Assert(err, IsNil)
+	quantified = stmt.(*ast.SelectStmt).Where.(*ast.CompareSubqueryExpr)
+	c.Assert(quantified.Op, Equals, opcode.EQ)
+	c.Assert(quantified.All, IsFalse)
+}
+
+func (s *testParserSuite) TestValuesTable(c *C) {
+	table := []testCase{
+		{"select * from (values row(1, 2), row(3, 4)) t", true, ""},
+		{"select * from (values row(1)) as t", true, ""},
+		{"select * from t1 join (values row(1, 'a'), row(2, 'b')) t2 on t1.a = t2.column_0", true, ""},
+		{"select * from (values row(1, 2))", false, ""},
+		{"select * from (values (1, 2)) t", false, ""},
+		{"select * from (values row()) t", false, ""},
+	}
+	s.RunTest(c, table)
+
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("select * from (values row(1, 2), row(3, 4)) t", "", "")
+	c.Assert(err, IsNil)
+	ts := stmt.(*ast.SelectStmt).From.TableRefs.Left.(*ast.TableSource)
+	c.Assert(ts.AsName.L, Equals, "t")
+	values, ok := ts.Source.(*ast.ValuesTable)
+	c.Assert(ok, IsTrue)
+	c.Assert(values.Lists, HasLen, 2)
+	c.Assert(values.Lists[1], HasLen, 2)
+}
+
+func (s *testParserSuite) TestUnion(c *C) {
+	table := []testCase{
+		{"select c1 from t1 union select c2 from t2", true, ""},
+		{"select c1 from t1 union all select c2 from t2", true, ""},
+		{"select c1 from t1 union distinct select c2 from t2 union all select c3 from t3", true, ""},
+		{"select c1 from t1 union (select c2 from t2) order by c1 limit 1", true, ""},
+		{"(select c1 from t1) union all (select c2 from t2 limit 1) limit 2", true, ""},
+		{"select 1 union select 'a' from dual", true, ""},
+		{"select * from (select a from t1 union all select b from t2) t", true, ""},
+		{"select a from t1 where a > (select max(b) from t2 union select 1)", true, ""},
+		{"explain select a from t1 union select b from t2", true, ""},
+		{"select c1 from t1 union", false, ""},
+		{"select * from (select a from t1 union select b from t2)", false, ""},
+	}
+	s.RunTest(c, table)
+
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("select a from t1 union all select b from t2 union select c from t3 order by a limit 1", "", "")
+	c.Assert(err, IsNil)
+	union, ok := stmt.(*ast.UnionStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(union.SelectList.Selects, HasLen, 3)
+	c.Assert(union.SelectList.Selects[0].IsAfterUnionDistinct, IsFalse)
+	c.Assert(union.SelectList.Selects[1].IsAfterUnionDistinct, IsFalse)
+	c.Assert(union.SelectList.Selects[2].IsAfterUnionDistinct, IsTrue)
+	c.Assert(union.SelectList.Selects[2].OrderBy, IsNil)
+	c.Assert(union.OrderBy, NotNil)
+	c.Assert(union.Limit, NotNil)
+}
+
+func (s *testParserSuite) TestPartialIndex(c *C) {
+	table := []testCase{
+		{"create index idx on t (a) where b > 10", true, ""},
+		{"create unique index idx on t (a) comment 'foo' where b > 10 and c is not null", true, ""},
+		{"create index idx on t (a) where", false, ""},
+		{"create index idx on t (a) where b > 10 comment 'foo'", false, ""},
+	}
+	s.RunTest(c, table)
+
+	parser := parser.New()
+	stmt, err := parser.ParseOneStmt("create index idx on t (a) where b > 10 and  c < 'x' ;", "", "")
+	c.Assert(err, IsNil)
+	where := stmt.(*ast.CreateIndexStmt).Where
+	c.Assert(where, NotNil)
+	c.Assert(where.Text(), Equals, "b > 10 and  c < 'x'")
+	stmt, err = parser.ParseOneStmt("create index idx on t (a) where (b in (1, 2))", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateIndexStmt).Where.Text(), Equals, "(b in (1, 2))")
+	stmt, err = parser.ParseOneStmt("create index idx on t (a)", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateIndexStmt).Where, IsNil)
+}
+
 func (s *testParserSuite) TestSideEffect(c *C) {
 	// This test cover a bug that parse an error SQL doesn't leave the parser in a
 	// clean state, cause the following SQL parse fail.
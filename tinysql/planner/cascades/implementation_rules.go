@@ -48,6 +48,9 @@ var defaultImplementationMap = map[memo.Operand][]ImplementationRule{
 	memo.OperandTiKVSingleGather: {
 		&ImplTiKVSingleReadGather{},
 	},
+	memo.OperandTiKVDoubleGather: {
+		&ImplTiKVDoubleReadGather{},
+	},
 	memo.OperandShow: {
 		&ImplShow{},
 	},
@@ -140,6 +143,27 @@ func (r *ImplTiKVSingleReadGather) OnImplement(expr *memo.GroupExpr, reqProp *pr
 	return impl.NewTableReaderImpl(reader, sg.Source.TblColHists), nil
 }
 
+// ImplTiKVDoubleReadGather implements TiKVDoubleGather as PhysicalIndexLookUpReader.
+type ImplTiKVDoubleReadGather struct {
+}
+
+// Match implements ImplementationRule Match interface.
+func (r *ImplTiKVDoubleReadGather) Match(expr *memo.GroupExpr, prop *property.PhysicalProperty) (matched bool) {
+	return true
+}
+
+// OnImplement implements ImplementationRule OnImplement interface.
+//
+// The required property is passed down to the index side, the handles are read in the order of
+// the index if it's matched by the IndexScan, so the IndexLookUp keeps the order and no Sort is needed.
+func (r *ImplTiKVDoubleReadGather) OnImplement(expr *memo.GroupExpr, reqProp *property.PhysicalProperty) (memo.Implementation, error) {
+	logicProp := expr.Group.Prop
+	dg := expr.ExprNode.(*plannercore.TiKVDoubleGather)
+	stats := logicProp.Stats.ScaleByExpectCnt(reqProp.ExpectedCnt)
+	reader := dg.GetPhysicalIndexLookUpReader(logicProp.Schema, stats, !reqProp.IsEmpty(), reqProp)
+	return impl.NewIndexLookUpReaderImpl(reader, logicProp.Schema, dg.Source.TblColHists, dg.Source.TblCols), nil
+}
+
 // ImplTableScan implements TableScan as PhysicalTableScan.
 type ImplTableScan struct {
 }
@@ -422,7 +446,7 @@ func (r *ImplHashJoinBuildRight) Match(expr *memo.GroupExpr, prop *property.Phys
 func (r *ImplHashJoinBuildRight) OnImplement(expr *memo.GroupExpr, reqProp *property.PhysicalProperty) (memo.Implementation, error) {
 	join := expr.ExprNode.(*plannercore.LogicalJoin)
 	switch join.JoinType {
-	case plannercore.InnerJoin:
+	case plannercore.InnerJoin, plannercore.SemiJoin:
 		return getImplForHashJoin(expr, reqProp, 1), nil
 	default:
 		// TODO: deal with other join type.
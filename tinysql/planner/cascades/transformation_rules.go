@@ -46,6 +46,7 @@ var defaultTransformationMap = map[memo.Operand][]Transformation{
 	memo.OperandSelection: {
 		NewRulePushSelDownTableScan(),
 		NewRulePushSelDownTiKVSingleGather(),
+		NewRulePushSelDownTiKVDoubleGather(),
 		NewRulePushSelDownSort(),
 		NewRulePushSelDownProjection(),
 		NewRulePushSelDownAggregation(),
@@ -274,6 +275,68 @@ func (r *PushSelDownTiKVSingleGather) OnTransform(old *memo.ExprIter) (newExprs
 	return []*memo.GroupExpr{remainedSelExpr}, true, false, nil
 }
 
+// PushSelDownTiKVDoubleGather pushes the selection down to the index side of TiKVDoubleGather.
+type PushSelDownTiKVDoubleGather struct {
+	baseRule
+}
+
+// NewRulePushSelDownTiKVDoubleGather creates a new Transformation PushSelDownTiKVDoubleGather.
+// The pattern of this rule is `Selection -> TiKVDoubleGather -> Any`.
+func NewRulePushSelDownTiKVDoubleGather() Transformation {
+	any := memo.NewPattern(memo.OperandAny, memo.EngineTiKVOnly)
+	dg := memo.BuildPattern(memo.OperandTiKVDoubleGather, memo.EngineTiDBOnly, any)
+	p := memo.BuildPattern(memo.OperandSelection, memo.EngineTiDBOnly, dg)
+
+	rule := &PushSelDownTiKVDoubleGather{}
+	rule.pattern = p
+	return rule
+}
+
+// OnTransform implements Transformation interface.
+//
+// It transforms `oldSel -> oldDg -> any` to one of the following new exprs:
+// 1. `newDg -> pushedSel -> any`
+// 2. `remainedSel -> newDg -> pushedSel -> any`
+//
+// Only the filters covered by the index columns can be pushed down, since the child of
+// TiKVDoubleGather is the index side of the IndexLookUp.
+// TODO: push the other filters down to the table side of the IndexLookUp.
+func (r *PushSelDownTiKVDoubleGather) OnTransform(old *memo.ExprIter) (newExprs []*memo.GroupExpr, eraseOld bool, eraseAll bool, err error) {
+	sel := old.GetExpr().ExprNode.(*plannercore.LogicalSelection)
+	dg := old.Children[0].GetExpr().ExprNode.(*plannercore.TiKVDoubleGather)
+	childGroup := old.Children[0].Children[0].Group
+	var covered, pushed, remained []expression.Expression
+	for _, cond := range sel.Conditions {
+		if dg.CoveredByIndex(cond) {
+			covered = append(covered, cond)
+		} else {
+			remained = append(remained, cond)
+		}
+	}
+	sctx := dg.SCtx()
+	_, pushed, notPushed := expression.ExpressionsToPB(sctx.GetSessionVars().StmtCtx, covered, sctx.GetClient())
+	if len(pushed) == 0 {
+		return nil, false, false, nil
+	}
+	remained = append(remained, notPushed...)
+	pushedSel := plannercore.LogicalSelection{Conditions: pushed}.Init(sctx)
+	pushedSelExpr := memo.NewGroupExpr(pushedSel)
+	pushedSelExpr.Children = append(pushedSelExpr.Children, childGroup)
+	pushedSelGroup := memo.NewGroupWithSchema(pushedSelExpr, childGroup.Prop.Schema).SetEngineType(childGroup.EngineType)
+	dgExpr := memo.NewGroupExpr(dg)
+	dgExpr.Children = append(dgExpr.Children, pushedSelGroup)
+	if len(remained) == 0 {
+		// `oldSel -> oldDg -> any` is transformed to `newDg -> pushedSel -> any`.
+		return []*memo.GroupExpr{dgExpr}, true, false, nil
+	}
+	dgGroup := memo.NewGroupWithSchema(dgExpr, pushedSelGroup.Prop.Schema)
+	remainedSel := plannercore.LogicalSelection{Conditions: remained}.Init(sel.SCtx())
+	remainedSelExpr := memo.NewGroupExpr(remainedSel)
+	remainedSelExpr.Children = append(remainedSelExpr.Children, dgGroup)
+	// `oldSel -> oldDg -> any` is transformed to `remainedSel -> newDg -> pushedSel -> any`.
+	return []*memo.GroupExpr{remainedSelExpr}, true, false, nil
+}
+
 // EnumeratePaths converts DataSource to table scan and index scans.
 type EnumeratePaths struct {
 	baseRule
@@ -805,8 +868,9 @@ func (r *PushTopNDownProjection) OnTransform(old *memo.ExprIter) (newExprs []*me
 	newTopN.ByItems = make([]*plannercore.ByItems, 0, len(topN.ByItems))
 	for _, by := range topN.ByItems {
 		newTopN.ByItems = append(newTopN.ByItems, &plannercore.ByItems{
-			Expr: expression.ColumnSubstitute(by.Expr, old.Children[0].Group.Prop.Schema, proj.Exprs),
-			Desc: by.Desc,
+			Expr:      expression.ColumnSubstitute(by.Expr, old.Children[0].Group.Prop.Schema, proj.Exprs),
+			Desc:      by.Desc,
+			NullOrder: by.NullOrder,
 		})
 	}
 
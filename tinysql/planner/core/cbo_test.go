@@ -16,6 +16,9 @@ package core_test
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -152,6 +155,164 @@ func (s *testAnalyzeSuite) TestNullCount(c *C) {
 	}
 }
 
+func (s *testAnalyzeSuite) TestAggGroupCountEstimation(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t (a int, b int, c int)")
+	values := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d)", i%4, i%5, i))
+	}
+	testKit.MustExec("insert into t values " + strings.Join(values, ", "))
+	testKit.MustExec("analyze table t")
+
+	tests := []struct {
+		sql    string
+		groups int
+	}{
+		{sql: "select count(*) from t group by a", groups: 4},
+		{sql: "select count(*) from t group by b", groups: 5},
+		// The group count is the product of the NDVs of the columns.
+		{sql: "select count(*) from t group by a, b", groups: 20},
+		{sql: "select count(*) from t group by a, b, a", groups: 20},
+		// It's capped by the input row count.
+		{sql: "select count(*) from t group by a, c", groups: 100},
+		{sql: "select count(*) from t", groups: 1},
+	}
+	for _, tt := range tests {
+		c.Assert(testKit.MustQuery(tt.sql).Rows(), HasLen, tt.groups, Commentf("for %s", tt.sql))
+		estimated := -1.0
+		for _, row := range testKit.MustQuery("explain " + tt.sql).Rows() {
+			if strings.Contains(row[0].(string), "Agg") && row[2].(string) == "root" {
+				estimated, err = strconv.ParseFloat(row[1].(string), 64)
+				c.Assert(err, IsNil)
+				break
+			}
+		}
+		c.Assert(math.Abs(estimated-float64(tt.groups)) <= float64(tt.groups)*0.1, IsTrue,
+			Commentf("for %s, estimated %v, actual %v", tt.sql, estimated, tt.groups))
+	}
+}
+
+func (s *testAnalyzeSuite) TestSkewedJoinEstimation(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t1, t2")
+	testKit.MustExec("create table t1 (a int, b int)")
+	testKit.MustExec("create table t2 (a int, b int)")
+	// The value 1 takes most of the rows of both tables, and each of the other values has only one row.
+	// Keep the tables smaller than the sample size of a region, so the histograms are exact.
+	values := make([]string, 0, 600)
+	for i := 0; i < 500; i++ {
+		values = append(values, fmt.Sprintf("(1, %d)", i))
+	}
+	for i := 2; i < 102; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i, i))
+	}
+	testKit.MustExec("insert into t1 values " + strings.Join(values, ", "))
+	testKit.MustExec("insert into t2 values " + strings.Join(values, ", "))
+	testKit.MustExec("analyze table t1, t2")
+
+	sql := "select * from t1 join t2 on t1.a = t2.a"
+	actual := 500*500 + 100.0
+	testKit.MustQuery("select count(*) from t1 join t2 on t1.a = t2.a").Check(testkit.Rows("250100"))
+	// The estimation assuming the values are uniformly distributed is N(t1) * N(t2) / NDV(a).
+	ndvEstimated := 600 * 600 / 101.0
+	estimated := -1.0
+	for _, row := range testKit.MustQuery("explain " + sql).Rows() {
+		if strings.Contains(row[0].(string), "Join") {
+			estimated, err = strconv.ParseFloat(row[1].(string), 64)
+			c.Assert(err, IsNil)
+			break
+		}
+	}
+	c.Assert(math.Abs(estimated-actual) <= actual*0.1, IsTrue,
+		Commentf("estimated %v, ndv estimated %v, actual %v", estimated, ndvEstimated, actual))
+}
+
+func (s *testAnalyzeSuite) TestIndexLookUpSelectivity(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t (a int primary key, b int, c varchar(200), e int, index b(b))")
+	for i := 0; i < 100; i++ {
+		testKit.MustExec(constructInsertSQL(i, 100))
+	}
+	testKit.MustExec("analyze table t")
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  "select * from t where t.b = 1",
+			best: "IndexLookUp(Index(t.b)[[1,1]], Table(t))",
+		},
+		{
+			sql:  "select * from t where t.b <= 90",
+			best: "TableReader(Table(t)->Sel([le(test.t.b, 90)]))",
+		},
+		{
+			sql:  "select * from t where t.b >= 10",
+			best: "TableReader(Table(t)->Sel([ge(test.t.b, 10)]))",
+		},
+	}
+	for _, tt := range tests {
+		ctx := testKit.Se.(sessionctx.Context)
+		stmts, err := session.Parse(ctx, tt.sql)
+		c.Assert(err, IsNil)
+		c.Assert(stmts, HasLen, 1)
+		stmt := stmts[0]
+		is := domain.GetDomain(ctx).InfoSchema()
+		err = core.Preprocess(ctx, stmt, is)
+		c.Assert(err, IsNil)
+		p, _, err := planner.Optimize(context.TODO(), ctx, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(core.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
+	}
+}
+
+func (s *testAnalyzeSuite) TestDoubleReadSeekCount(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		indexRows float64
+		tableRows float64
+		batchSize int
+		seeks     float64
+	}{
+		{indexRows: 10, tableRows: 100, batchSize: 20000, seeks: 9},
+		// A full range lookup still seeks once per batch.
+		{indexRows: 100, tableRows: 100, batchSize: 20000, seeks: 1},
+		{indexRows: 100000, tableRows: 100000, batchSize: 20000, seeks: 5},
+		{indexRows: 100, tableRows: 0, batchSize: 20000, seeks: 1},
+		{indexRows: 0, tableRows: 100, batchSize: 20000, seeks: 0},
+	}
+	for _, tt := range tests {
+		c.Assert(core.DoubleReadSeekCount(tt.indexRows, tt.tableRows, tt.batchSize), Equals, tt.seeks, Commentf("%+v", tt))
+	}
+}
+
 func newStoreWithBootstrap() (kv.Storage, *domain.Domain, error) {
 	store, err := mockstore.NewMockTikvStore()
 	if err != nil {
@@ -58,6 +58,10 @@ type Insert struct {
 	Lists         [][]expression.Expression
 	SetList       []*expression.Assignment
 
+	// OnDuplicate is the assignment list of `ON DUPLICATE KEY UPDATE`, it is
+	// evaluated on the conflicting row in the table.
+	OnDuplicate []*expression.Assignment
+
 	IsReplace bool
 
 	// NeedFillDefaultValue is true when expr in value list reference other column.
@@ -61,6 +61,7 @@ var (
 	ErrCartesianProductUnsupported     = terror.ClassOptimizer.New(mysql.ErrCartesianProductUnsupported, mysql.MySQLErrName[mysql.ErrCartesianProductUnsupported])
 	ErrStmtNotFound                    = terror.ClassOptimizer.New(mysql.ErrPreparedStmtNotFound, mysql.MySQLErrName[mysql.ErrPreparedStmtNotFound])
 	ErrAmbiguous                       = terror.ClassOptimizer.New(mysql.ErrNonUniq, mysql.MySQLErrName[mysql.ErrNonUniq])
+	ErrSubqueryNo1Row                  = terror.ClassOptimizer.New(mysql.ErrSubqueryNo1Row, mysql.MySQLErrName[mysql.ErrSubqueryNo1Row])
 	// Since we cannot know if user loggined with a password, use message of ErrAccessDeniedNoPassword instead
 	ErrAccessDenied = terror.ClassOptimizer.New(mysql.ErrAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDeniedNoPassword])
 )
@@ -110,6 +111,7 @@ func init() {
 		mysql.ErrNonuniqTable:                        mysql.ErrNonuniqTable,
 		mysql.ErrTooBigPrecision:                     mysql.ErrTooBigPrecision,
 		mysql.ErrInvalidWildCard:                     mysql.ErrInvalidWildCard,
+		mysql.ErrSubqueryNo1Row:                      mysql.ErrSubqueryNo1Row,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mysqlErrCodeMap
 }
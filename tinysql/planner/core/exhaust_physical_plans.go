@@ -17,6 +17,7 @@ import (
 	"math"
 
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/util/set"
 )
@@ -95,7 +96,7 @@ func (p *PhysicalMergeJoin) tryToGetChildReqProp(prop *property.PhysicalProperty
 		if !prop.IsPrefix(lProp) && !prop.IsPrefix(rProp) {
 			return nil, false
 		}
-		if prop.IsPrefix(rProp) && p.JoinType == LeftOuterJoin {
+		if prop.IsPrefix(rProp) && (p.JoinType == LeftOuterJoin || p.JoinType == SemiJoin) {
 			return nil, false
 		}
 		if prop.IsPrefix(lProp) && p.JoinType == RightOuterJoin {
@@ -250,7 +251,7 @@ func (p *LogicalJoin) getHashJoins(prop *property.PhysicalProperty) []PhysicalPl
 	}
 	joins := make([]PhysicalPlan, 0, 2)
 	switch p.JoinType {
-	case LeftOuterJoin:
+	case LeftOuterJoin, SemiJoin:
 		joins = append(joins, p.getHashJoin(prop, 1))
 	case RightOuterJoin:
 		joins = append(joins, p.getHashJoin(prop, 0))
@@ -363,7 +364,7 @@ func MatchItems(p *property.PhysicalProperty, items []*ByItems) bool {
 	}
 	for i, col := range p.Items {
 		sortItem := items[i]
-		if sortItem.Desc != col.Desc || !sortItem.Expr.Equal(nil, col.Col) {
+		if sortItem.Desc != col.Desc || sortItem.NullOrder != ast.NullOrderDefault || !sortItem.Expr.Equal(nil, col.Col) {
 			return false
 		}
 	}
@@ -387,7 +388,10 @@ func (la *LogicalAggregation) getHashAggs(prop *property.PhysicalProperty) []Phy
 		return nil
 	}
 	hashAggs := make([]PhysicalPlan, 0, len(wholeTaskTypes))
-	taskTypes := []property.TaskType{property.CopSingleReadTaskType, property.CopDoubleReadTaskType, property.RootTaskType}
+	taskTypes := []property.TaskType{property.CopSingleReadTaskType, property.CopDoubleReadTaskType}
+	if !la.preferAggToCop || !la.canPushToCop() {
+		taskTypes = append(taskTypes, property.RootTaskType)
+	}
 	for _, taskTp := range taskTypes {
 		agg := NewPhysicalHashAgg(la, la.stats.ScaleByExpectCnt(prop.ExpectedCnt), &property.PhysicalProperty{ExpectedCnt: math.MaxFloat64, TaskTp: taskTp})
 		agg.SetSchema(la.schema.Clone())
@@ -424,6 +428,20 @@ func (p *LogicalLimit) exhaustPhysicalPlans(prop *property.PhysicalProperty) []P
 	return ret
 }
 
+func (p *LogicalUnionAll) exhaustPhysicalPlans(prop *property.PhysicalProperty) []PhysicalPlan {
+	// UnionAll can not pass any order.
+	if !prop.IsEmpty() {
+		return nil
+	}
+	chReqProps := make([]*property.PhysicalProperty, 0, len(p.children))
+	for range p.children {
+		chReqProps = append(chReqProps, &property.PhysicalProperty{ExpectedCnt: prop.ExpectedCnt})
+	}
+	ua := PhysicalUnionAll{}.Init(p.ctx, p.stats.ScaleByExpectCnt(prop.ExpectedCnt), chReqProps...)
+	ua.SetSchema(p.Schema())
+	return []PhysicalPlan{ua}
+}
+
 func (ls *LogicalSort) getPhysicalSort(prop *property.PhysicalProperty) *PhysicalSort {
 	ps := PhysicalSort{ByItems: ls.ByItems}.Init(ls.ctx, ls.stats.ScaleByExpectCnt(prop.ExpectedCnt), &property.PhysicalProperty{ExpectedCnt: math.MaxFloat64})
 	return ps
@@ -122,7 +122,7 @@ func (p *PhysicalTableReader) ExplainInfo() string {
 
 // ExplainNormalizedInfo implements Plan interface.
 func (p *PhysicalTableReader) ExplainNormalizedInfo() string {
-	return p.ExplainInfo()
+	return "data:" + p.tablePlan.TP()
 }
 
 // ExplainInfo implements Plan interface.
@@ -132,7 +132,7 @@ func (p *PhysicalIndexReader) ExplainInfo() string {
 
 // ExplainNormalizedInfo implements Plan interface.
 func (p *PhysicalIndexReader) ExplainNormalizedInfo() string {
-	return p.ExplainInfo()
+	return "index:" + p.indexPlan.TP()
 }
 
 // ExplainInfo implements Plan interface.
@@ -170,12 +170,23 @@ func (p *PhysicalTableDual) ExplainInfo() string {
 	return fmt.Sprintf("rows:%v", p.RowCount)
 }
 
+// ExplainInfo implements Plan interface.
+func (p *PhysicalValues) ExplainInfo() string {
+	return fmt.Sprintf("rows:%v", len(p.Lists))
+}
+
 // ExplainInfo implements Plan interface.
 func (p *PhysicalSort) ExplainInfo() string {
 	buffer := bytes.NewBufferString("")
 	return explainByItems(buffer, p.ByItems).String()
 }
 
+// ExplainNormalizedInfo implements Plan interface.
+func (p *PhysicalSort) ExplainNormalizedInfo() string {
+	buffer := bytes.NewBufferString("")
+	return explainNormalizedByItems(buffer, p.ByItems).String()
+}
+
 // ExplainInfo implements Plan interface.
 func (p *PhysicalLimit) ExplainInfo() string {
 	return fmt.Sprintf("offset:%v, count:%v", p.Offset, p.Count)
@@ -311,6 +322,12 @@ func (p *PhysicalTopN) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainNormalizedInfo implements Plan interface.
+func (p *PhysicalTopN) ExplainNormalizedInfo() string {
+	buffer := bytes.NewBufferString("")
+	return explainNormalizedByItems(buffer, p.ByItems).String()
+}
+
 // ExplainInfo implements Plan interface.
 func (p *LogicalJoin) ExplainInfo() string {
 	buffer := bytes.NewBufferString(p.JoinType.String())
@@ -366,6 +383,11 @@ func (p *LogicalTableDual) ExplainInfo() string {
 	return fmt.Sprintf("rowcount:%d", p.RowCount)
 }
 
+// ExplainInfo implements Plan interface.
+func (p *LogicalValues) ExplainInfo() string {
+	return fmt.Sprintf("rowcount:%d", len(p.Lists))
+}
+
 // ExplainInfo implements Plan interface.
 func (p *DataSource) ExplainInfo() string {
 	buffer := bytes.NewBufferString("")
@@ -400,6 +422,20 @@ func explainByItems(buffer *bytes.Buffer, byItems []*ByItems) *bytes.Buffer {
 	return buffer
 }
 
+func explainNormalizedByItems(buffer *bytes.Buffer, byItems []*ByItems) *bytes.Buffer {
+	for i, item := range byItems {
+		order := "asc"
+		if item.Desc {
+			order = "desc"
+		}
+		fmt.Fprintf(buffer, "%s:%s", expression.SortedExplainNormalizedExpressionList([]expression.Expression{item.Expr}), order)
+		if i+1 < len(byItems) {
+			buffer.WriteString(", ")
+		}
+	}
+	return buffer
+}
+
 // ExplainInfo implements Plan interface.
 func (p *LogicalSort) ExplainInfo() string {
 	buffer := bytes.NewBufferString("")
@@ -458,3 +494,8 @@ func (p *TiKVSingleGather) ExplainInfo() string {
 	}
 	return buffer.String()
 }
+
+// ExplainInfo implements Plan interface.
+func (p *TiKVDoubleGather) ExplainInfo() string {
+	return p.Source.ExplainInfo() + ", index:" + p.Index.Name.String()
+}
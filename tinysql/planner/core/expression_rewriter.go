@@ -19,6 +19,7 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
@@ -50,6 +51,9 @@ func evalAstExpr(sctx sessionctx.Context, expr ast.ExprNode) (types.Datum, error
 	return newExpr.Eval(chunk.Row{})
 }
 
+// EvalSubquery evaluates an uncorrelated subquery once and returns all the rows of its result.
+var EvalSubquery func(ctx context.Context, p PhysicalPlan, is infoschema.InfoSchema, sctx sessionctx.Context) ([][]types.Datum, error)
+
 // rewrite function rewrites ast expr to expression.Expression.
 // aggMapper maps ast.AggregateFuncExpr to the columns offset in p's output schema.
 // asScalar means whether this expression must be treated as a scalar expression.
@@ -89,6 +93,24 @@ func (b *PlanBuilder) rewriteWithPreprocess(
 	return expr, resultPlan, err
 }
 
+// rewriteInsertOnDuplicateUpdate rewrites the expression of an assignment in
+// `ON DUPLICATE KEY UPDATE`, where `VALUES(col)` refers to the row being inserted.
+func (b *PlanBuilder) rewriteInsertOnDuplicateUpdate(ctx context.Context, exprNode ast.ExprNode, mockPlan LogicalPlan, insertPlan *Insert) (expression.Expression, error) {
+	b.rewriterCounter++
+	defer func() { b.rewriterCounter-- }()
+
+	rewriter := b.getExpressionRewriter(ctx, mockPlan)
+	if rewriter.err != nil {
+		return nil, rewriter.err
+	}
+
+	rewriter.insertPlan = insertPlan
+	rewriter.asScalar = true
+
+	expr, _, err := b.rewriteExprNode(rewriter, exprNode, true)
+	return expr, err
+}
+
 func (b *PlanBuilder) getExpressionRewriter(ctx context.Context, p LogicalPlan) (rewriter *expressionRewriter) {
 	defer func() {
 		if p != nil {
@@ -247,21 +269,14 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 			return inNode, true
 		}
 	case *ast.PatternInExpr:
-		if len(v.List) != 1 {
-			break
-		}
-		// For 10 in ((select * from t)), the parser won't set v.Sel.
-		// So we must process this case here.
-		x := v.List[0]
-		for {
-			switch y := x.(type) {
-			case *ast.ParenthesesExpr:
-				x = y.Expr
-			default:
-				return inNode, false
-			}
+		if subq := inSubquery(v); subq != nil {
+			return er.handleInSubquery(v, subq)
 		}
+	case *ast.CompareSubqueryExpr:
+		return er.handleCompareSubquery(v)
 	case *ast.ParenthesesExpr:
+	case *ast.SubqueryExpr:
+		return er.handleScalarSubquery(v)
 	case *ast.ValuesExpr:
 		schema, names := er.schema, er.names
 		// NOTE: "er.insertPlan != nil" means that we are rewriting the
@@ -290,6 +305,445 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 	return inNode, false
 }
 
+// buildSubquery builds the plan of the subquery, the columns of the outer query referenced by the
+// subquery are returned as well.
+func (er *expressionRewriter) buildSubquery(subq *ast.SubqueryExpr) (LogicalPlan, []*expression.Column, error) {
+	b := er.b
+	curClause := b.curClause
+	outerSchema, outerNames, outerColRefs := b.outerSchema, b.outerNames, b.outerColRefs
+	b.outerSchema, b.outerNames, b.outerColRefs = er.schema, er.names, nil
+	defer func() {
+		b.curClause = curClause
+		b.outerSchema, b.outerNames, b.outerColRefs = outerSchema, outerNames, outerColRefs
+	}()
+	np, err := b.buildResultSetNode(er.ctx, subq.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Pop the handle map generated by the subquery.
+	b.handleHelper.popMap()
+	subq.Correlated = len(b.outerColRefs) > 0
+	return np, b.outerColRefs, nil
+}
+
+// evalSubquery optimizes the plan of an uncorrelated subquery and executes it.
+func (er *expressionRewriter) evalSubquery(np LogicalPlan) ([][]types.Datum, error) {
+	physicalPlan, err := DoOptimize(er.ctx, er.b.optFlag, np)
+	if err != nil {
+		return nil, err
+	}
+	return EvalSubquery(er.ctx, physicalPlan, er.b.is, er.sctx)
+}
+
+// handleScalarSubquery evaluates the scalar subquery and replaces it with its result, so
+// the predicates on it can be used to build the ranges like the ones on constants.
+func (er *expressionRewriter) handleScalarSubquery(v *ast.SubqueryExpr) (ast.Node, bool) {
+	np, _, err := er.buildSubquery(v)
+	if err != nil {
+		er.err = err
+		return v, true
+	}
+	if v.Correlated {
+		er.err = ErrNotSupportedYet.GenWithStackByArgs("correlated scalar subquery")
+		return v, true
+	}
+	// Two rows are enough to tell whether the subquery returns more than one row.
+	limit := LogicalLimit{Count: 2}.Init(er.sctx)
+	limit.SetChildren(np)
+	rows, err := er.evalSubquery(limit)
+	if err != nil {
+		er.err = err
+		return v, true
+	}
+	if len(rows) > 1 {
+		er.err = ErrSubqueryNo1Row
+		return v, true
+	}
+	v.Evaluated = true
+	cols := np.Schema().Columns
+	consts := make([]expression.Expression, 0, len(cols))
+	for i, col := range cols {
+		con := &expression.Constant{RetType: col.RetType}
+		if len(rows) == 0 {
+			// An empty subquery is evaluated to NULL.
+			tp := *col.RetType
+			tp.Flag &= ^mysql.NotNullFlag
+			con.RetType = &tp
+		} else {
+			con.Value = rows[0][i]
+		}
+		consts = append(consts, con)
+	}
+	if len(consts) == 1 {
+		er.ctxStackAppend(consts[0], types.EmptyName)
+		return v, true
+	}
+	expr, err := er.newFunction(ast.RowFunc, consts[0].GetType(), consts...)
+	if err != nil {
+		er.err = err
+		return v, true
+	}
+	er.ctxStackAppend(expr, types.EmptyName)
+	return v, true
+}
+
+// inSubquery returns the subquery of an "IN" expression like "a in (select b from t)", or nil if
+// the expression has a list of values. For 10 in ((select * from t)), the parser puts the subquery
+// in the list, so the parentheses are stripped here.
+func inSubquery(v *ast.PatternInExpr) *ast.SubqueryExpr {
+	if len(v.List) != 1 {
+		return nil
+	}
+	x := v.List[0]
+	for {
+		switch y := x.(type) {
+		case *ast.ParenthesesExpr:
+			x = y.Expr
+		case *ast.SubqueryExpr:
+			return y
+		default:
+			return nil
+		}
+	}
+}
+
+// handleInSubquery rewrites "a in (select b from t)". In the WHERE clause, the outer plan is joined
+// with the subquery by a semi join on "a = b", and the conditions of the subquery which refer to
+// the outer query become the join conditions as well. Otherwise the subquery must be uncorrelated,
+// it's evaluated and the expression is rewritten to an "IN" list of its rows.
+func (er *expressionRewriter) handleInSubquery(v *ast.PatternInExpr, subq *ast.SubqueryExpr) (ast.Node, bool) {
+	er.rewriteInSubquery(v.Expr, subq, v.Not, &v.Type)
+	return v, true
+}
+
+// rewriteInSubquery rewrites "l in (subq)", or "l not in (subq)" if not is true.
+func (er *expressionRewriter) rewriteInSubquery(l ast.ExprNode, subq *ast.SubqueryExpr, not bool, tp *types.FieldType) {
+	asScalar := er.asScalar
+	er.asScalar = true
+	l.Accept(er)
+	if er.err != nil {
+		return
+	}
+	lexpr := er.ctxStack[len(er.ctxStack)-1]
+	np, outerColRefs, err := er.buildSubquery(subq)
+	if err != nil {
+		er.err = err
+		return
+	}
+	subq.MultiRows = true
+	if asScalar || not {
+		if subq.Correlated {
+			er.err = ErrNotSupportedYet.GenWithStackByArgs("correlated subquery outside the conditions of the WHERE clause")
+			return
+		}
+		er.evalInSubquery(np, not, tp)
+		return
+	}
+	cols := np.Schema().Columns
+	var rexpr expression.Expression = cols[0]
+	if len(cols) > 1 {
+		rexpr, err = er.newFunction(ast.RowFunc, cols[0].GetType(), expression.Column2Exprs(cols)...)
+		if err != nil {
+			er.err = err
+			return
+		}
+	}
+	eq, err := er.constructBinaryOpFunction(lexpr, rexpr, ast.EQ)
+	if err != nil {
+		er.err = err
+		return
+	}
+	conds := expression.SplitCNFItems(eq)
+	corConds := er.pullUpCorrelatedConds(np)
+	if !refsCovered(outerColRefs, corConds) {
+		er.err = ErrNotSupportedYet.GenWithStackByArgs("correlated column outside the WHERE clause of subquery")
+		return
+	}
+	conds = append(conds, corConds...)
+	join := LogicalJoin{JoinType: SemiJoin}.Init(er.sctx)
+	join.SetChildren(er.p, np)
+	join.SetSchema(buildLogicalJoinSchema(SemiJoin, join))
+	join.names = er.p.OutputNames()
+	join.attachOnConds(conds)
+	er.p = join
+	er.ctxStackPop(1)
+}
+
+// pullUpCorrelatedConds moves the conditions which refer to the outer query out of the selection
+// of the subquery, the columns of the subquery used by them are appended to the projection, so they
+// can be evaluated by the join.
+func (er *expressionRewriter) pullUpCorrelatedConds(np LogicalPlan) []expression.Expression {
+	proj, ok := np.(*LogicalProjection)
+	if !ok {
+		return nil
+	}
+	sel, ok := proj.children[0].(*LogicalSelection)
+	if !ok {
+		return nil
+	}
+	var corConds, conds []expression.Expression
+	for _, cond := range sel.Conditions {
+		if len(expression.ExtractColumnsFromExpressions(nil, []expression.Expression{cond}, er.schema.Contains)) > 0 {
+			corConds = append(corConds, cond)
+		} else {
+			conds = append(conds, cond)
+		}
+	}
+	if len(corConds) == 0 {
+		return nil
+	}
+	child := sel.children[0]
+	if len(conds) == 0 {
+		proj.SetChildren(child)
+	} else {
+		sel.Conditions = conds
+	}
+	for _, col := range expression.ExtractColumnsFromExpressions(nil, corConds, nil) {
+		idx := child.Schema().ColumnIndex(col)
+		if idx < 0 || proj.schema.Contains(col) {
+			continue
+		}
+		proj.Exprs = append(proj.Exprs, col)
+		proj.schema.Append(col)
+		proj.names = append(proj.names, child.OutputNames()[idx])
+	}
+	return corConds
+}
+
+// refsCovered checks whether all the references to the outer columns are in the conditions.
+func refsCovered(refs []*expression.Column, conds []expression.Expression) bool {
+	cols := expression.ExtractColumnsFromExpressions(nil, conds, nil)
+	for _, ref := range refs {
+		found := false
+		for _, col := range cols {
+			if col == ref {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// evalInSubquery evaluates the uncorrelated subquery of "IN" and rewrites the expression to an "IN"
+// list of its rows.
+func (er *expressionRewriter) evalInSubquery(np LogicalPlan, not bool, tp *types.FieldType) {
+	rows, err := er.evalSubquery(np)
+	if err != nil {
+		er.err = err
+		return
+	}
+	if len(rows) == 0 {
+		// "a in (empty set)" is always false and "a not in (empty set)" is always true, even if a is NULL.
+		er.ctxStackPop(1)
+		result := expression.Zero
+		if not {
+			result = expression.One
+		}
+		er.ctxStackAppend(result.Clone(), types.EmptyName)
+		return
+	}
+	cols := np.Schema().Columns
+	for _, row := range rows {
+		consts := make([]expression.Expression, 0, len(cols))
+		for i, col := range cols {
+			consts = append(consts, &expression.Constant{Value: row[i], RetType: col.RetType})
+		}
+		if len(consts) == 1 {
+			er.ctxStackAppend(consts[0], types.EmptyName)
+			continue
+		}
+		expr, err := er.newFunction(ast.RowFunc, consts[0].GetType(), consts...)
+		if err != nil {
+			er.err = err
+			return
+		}
+		er.ctxStackAppend(expr, types.EmptyName)
+	}
+	er.inToExpression(len(rows), not, tp)
+}
+
+// handleCompareSubquery rewrites the quantified comparisons like "a > all (select b from t)". "= any" and
+// "!= all" are the same as "in" and "not in". The others compare with the max or min of the uncorrelated
+// subquery, e.g. "a > all (select b from t)" becomes "a > (select max(b) from t)".
+func (er *expressionRewriter) handleCompareSubquery(v *ast.CompareSubqueryExpr) (ast.Node, bool) {
+	subq, ok := v.R.(*ast.SubqueryExpr)
+	if !ok {
+		er.err = errors.Errorf("Unknown compare type %T.", v.R)
+		return v, true
+	}
+	switch {
+	case v.Op == opcode.EQ && !v.All, v.Op == opcode.NE && v.All:
+		er.rewriteInSubquery(v.L, subq, v.All, &v.Type)
+		return v, true
+	case v.Op == opcode.NullEQ:
+		er.err = ErrNotSupportedYet.GenWithStackByArgs("<=> any or <=> all")
+		return v, true
+	}
+	asScalar := er.asScalar
+	er.asScalar = true
+	v.L.Accept(er)
+	if er.err != nil {
+		return v, true
+	}
+	lexpr := er.ctxStack[len(er.ctxStack)-1]
+	np, _, err := er.buildSubquery(subq)
+	if err != nil {
+		er.err = err
+		return v, true
+	}
+	subq.MultiRows = true
+	if subq.Correlated {
+		er.err = ErrNotSupportedYet.GenWithStackByArgs("correlated subquery with any or all")
+		return v, true
+	}
+	if expression.GetRowLen(lexpr) != 1 || np.Schema().Len() != 1 {
+		er.err = expression.ErrOperandColumns.GenWithStackByArgs(1)
+		return v, true
+	}
+	cond, err := er.buildQuantifiedCmp(lexpr, np, v.Op, v.All, asScalar)
+	if err != nil {
+		er.err = err
+		return v, true
+	}
+	er.ctxStackPop(1)
+	er.ctxStackAppend(cond, types.EmptyName)
+	return v, true
+}
+
+// buildQuantifiedCmp evaluates the max or min of the subquery, with the count of its non-null values and
+// the count of its rows, and builds the comparison on them. The result follows MySQL: "any" on an empty
+// subquery is false and "all" is true, otherwise the result is NULL instead of false for "any" and instead
+// of true for "all" if the subquery has NULL values, and NULL if lexpr is NULL.
+func (er *expressionRewriter) buildQuantifiedCmp(lexpr expression.Expression, np LogicalPlan, op opcode.Op, all bool, asScalar bool) (expression.Expression, error) {
+	col := np.Schema().Columns[0]
+	// "> all" and "< any" compare with the max, "< all" and "> any" compare with the min,
+	// "= all" and "!= any" need both of them.
+	var funcNames []string
+	switch op {
+	case opcode.EQ, opcode.NE:
+		funcNames = []string{ast.AggFuncMax, ast.AggFuncMin}
+	case opcode.GT, opcode.GE:
+		funcNames = []string{ast.AggFuncMax}
+		if !all {
+			funcNames[0] = ast.AggFuncMin
+		}
+	default:
+		funcNames = []string{ast.AggFuncMin}
+		if !all {
+			funcNames[0] = ast.AggFuncMax
+		}
+	}
+	aggFuncs := make([]*aggregation.AggFuncDesc, 0, len(funcNames)+2)
+	for _, name := range funcNames {
+		desc, err := aggregation.NewAggFuncDesc(er.sctx, name, []expression.Expression{col})
+		if err != nil {
+			return nil, err
+		}
+		aggFuncs = append(aggFuncs, desc)
+	}
+	for _, arg := range []expression.Expression{col, expression.One} {
+		desc, err := aggregation.NewAggFuncDesc(er.sctx, ast.AggFuncCount, []expression.Expression{arg})
+		if err != nil {
+			return nil, err
+		}
+		aggFuncs = append(aggFuncs, desc)
+	}
+	plan4Agg := LogicalAggregation{AggFuncs: aggFuncs}.Init(er.sctx)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(aggFuncs))...)
+	names := make(types.NameSlice, 0, len(aggFuncs))
+	for _, desc := range aggFuncs {
+		schema.Append(&expression.Column{
+			UniqueID: er.sctx.GetSessionVars().AllocPlanColumnID(),
+			RetType:  desc.RetTp,
+		})
+		names = append(names, types.EmptyName)
+	}
+	plan4Agg.SetChildren(np)
+	plan4Agg.SetSchema(schema)
+	plan4Agg.names = names
+	rows, err := er.evalSubquery(plan4Agg)
+	if err != nil {
+		return nil, err
+	}
+	row := rows[0]
+	notNullCnt, cnt := row[len(row)-2].GetInt64(), row[len(row)-1].GetInt64()
+	if cnt == 0 {
+		if all {
+			return expression.One.Clone(), nil
+		}
+		return expression.Zero.Clone(), nil
+	}
+
+	extremes := make([]expression.Expression, 0, len(funcNames))
+	for i := range funcNames {
+		extremes = append(extremes, &expression.Constant{Value: row[i], RetType: aggFuncs[i].RetTp})
+	}
+	var cond expression.Expression
+	switch op {
+	case opcode.EQ, opcode.NE:
+		// "a = all (subq)" is true only if a equals the only distinct value of the subquery,
+		// and "a != any (subq)" is the negation of it.
+		var eqs [2]expression.Expression
+		eqs[0], err = er.newFunction(ast.EQ, types.NewFieldType(mysql.TypeTiny), lexpr, extremes[0])
+		if err != nil {
+			return nil, err
+		}
+		eqs[1], err = er.newFunction(ast.EQ, types.NewFieldType(mysql.TypeTiny), extremes[0], extremes[1])
+		if err != nil {
+			return nil, err
+		}
+		cond, err = er.newFunction(ast.LogicAnd, types.NewFieldType(mysql.TypeTiny), eqs[:]...)
+		if err == nil && op == opcode.NE {
+			cond, err = er.newFunction(ast.UnaryNot, types.NewFieldType(mysql.TypeTiny), cond)
+		}
+	default:
+		cond, err = er.newFunction(op.String(), types.NewFieldType(mysql.TypeTiny), lexpr, extremes[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The NULL values of the subquery turn the result from true to NULL for "all",
+	// and from false to NULL for "any".
+	logicOp := ast.LogicOr
+	if all {
+		logicOp = ast.LogicAnd
+	}
+	if notNullCnt < cnt {
+		cond, err = er.newFunction(logicOp, types.NewFieldType(mysql.TypeTiny), cond, expression.Null.Clone())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !asScalar {
+		// In the conditions, NULL is the same as false.
+		return cond, nil
+	}
+	// If lexpr is NULL, the result is NULL even if "a = all (1, 2)" would be false and "a != any (1)" would be true.
+	outerIsNull, err := er.newFunction(ast.IsNull, types.NewFieldType(mysql.TypeTiny), lexpr)
+	if err != nil {
+		return nil, err
+	}
+	notNullResult := expression.One
+	if all {
+		notNullResult = expression.Zero
+	}
+	outerNullChecker, err := er.newFunction(ast.If, types.NewFieldType(mysql.TypeTiny), outerIsNull, expression.Null.Clone(), notNullResult.Clone())
+	if err != nil {
+		return nil, err
+	}
+	// "all" is (cond or if(isnull(a), null, false)), "any" is (cond and if(isnull(a), null, true)).
+	logicOp = ast.LogicAnd
+	if all {
+		logicOp = ast.LogicOr
+	}
+	return er.newFunction(logicOp, types.NewFieldType(mysql.TypeTiny), cond, outerNullChecker)
+}
+
 // Leave implements Visitor interface.
 func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok bool) {
 	if er.err != nil {
@@ -300,7 +754,8 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 		inNode = er.preprocess(inNode)
 	}
 	switch v := inNode.(type) {
-	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.ValuesExpr:
+	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
+		*ast.SubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
 	case *driver.ValueExpr:
 		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
 		er.ctxStackAppend(value, types.EmptyName)
@@ -318,8 +773,12 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 		er.betweenToExpression(v)
 	case *ast.RowExpr:
 		er.rowToScalarFunc(v)
+	case *ast.CaseExpr:
+		er.caseWhenToExpression(v)
 	case *ast.PatternInExpr:
-		er.inToExpression(len(v.List), v.Not, &v.Type)
+		if inSubquery(v) == nil {
+			er.inToExpression(len(v.List), v.Not, &v.Type)
+		}
 	case *ast.IsNullExpr:
 		er.isNullToExpression(v)
 	case *ast.DefaultExpr:
@@ -539,6 +998,51 @@ func (er *expressionRewriter) rowToScalarFunc(v *ast.RowExpr) {
 	er.ctxStackAppend(function, types.EmptyName)
 }
 
+func (er *expressionRewriter) caseWhenToExpression(v *ast.CaseExpr) {
+	stkLen := len(er.ctxStack)
+	argsLen := 2 * len(v.WhenClauses)
+	if v.ElseClause != nil {
+		argsLen++
+	}
+	er.err = expression.CheckArgsNotMultiColumnRow(er.ctxStack[stkLen-argsLen:]...)
+	if er.err != nil {
+		return
+	}
+
+	// value                          -> ctxStack[stkLen-argsLen-1]
+	// when clause(condition, result) -> ctxStack[stkLen-argsLen:stkLen-1]
+	// else clause                    -> ctxStack[stkLen-1]
+	var args []expression.Expression
+	if v.Value != nil {
+		// The simple case is rewritten to the searched case, where each condition is
+		// an equality between the value and the "when" expression.
+		value := er.ctxStack[stkLen-argsLen-1]
+		args = make([]expression.Expression, 0, argsLen)
+		for i := stkLen - argsLen; i < stkLen-1; i += 2 {
+			arg, err := er.newFunction(ast.EQ, types.NewFieldType(mysql.TypeTiny), value, er.ctxStack[i])
+			if err != nil {
+				er.err = err
+				return
+			}
+			args = append(args, arg, er.ctxStack[i+1])
+		}
+		if v.ElseClause != nil {
+			args = append(args, er.ctxStack[stkLen-1])
+		}
+		// The value is popped together with the clauses.
+		argsLen++
+	} else {
+		args = er.ctxStack[stkLen-argsLen:]
+	}
+	function, err := er.newFunction(ast.Case, &v.Type, args...)
+	if err != nil {
+		er.err = err
+		return
+	}
+	er.ctxStackPop(argsLen)
+	er.ctxStackAppend(function, types.EmptyName)
+}
+
 func (er *expressionRewriter) betweenToExpression(v *ast.BetweenExpr) {
 	stkLen := len(er.ctxStack)
 	er.err = expression.CheckArgsNotMultiColumnRow(er.ctxStack[stkLen-3:]...)
@@ -633,6 +1137,21 @@ func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 		er.ctxStackAppend(column, er.names[idx])
 		return
 	}
+	if er.b.outerSchema != nil {
+		idx, err = expression.FindFieldName(er.b.outerNames, v)
+		if err != nil {
+			er.err = ErrAmbiguous.GenWithStackByArgs(v.Name, clauseMsg[fieldList])
+			return
+		}
+		if idx >= 0 {
+			// Each reference is a distinct column, so that it can be told whether it's pulled up
+			// out of the subquery.
+			column := er.b.outerSchema.Columns[idx].Clone().(*expression.Column)
+			er.b.outerColRefs = append(er.b.outerColRefs, column)
+			er.ctxStackAppend(column, er.b.outerNames[idx])
+			return
+		}
+	}
 	if er.b.curClause == globalOrderByClause {
 		er.b.curClause = orderByClause
 	}
@@ -15,6 +15,7 @@ package core_test
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -58,3 +59,157 @@ func (s *testExpressionRewriterSuite) TestBinaryOpFunction(c *C) {
 	tk.MustQuery("SELECT * FROM t WHERE (a,b,c) <= (1,2,3) order by b").Check(testkit.Rows("1 1 <nil>", "1 2 3"))
 	tk.MustQuery("SELECT * FROM t WHERE (a,b,c) > (1,2,3) order by b").Check(testkit.Rows("1 3 <nil>"))
 }
+
+func (s *testExpressionRewriterSuite) TestScalarSubquery(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, u")
+	tk.MustExec("create table t(a int, b int, index idx_a(a))")
+	tk.MustExec("create table u(b int, c int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, 4), (5, 5)")
+	tk.MustExec("insert into u values (1, 1), (3, 2), (NULL, 3)")
+	// The uncorrelated subquery is evaluated to a constant, which forms the range of the index.
+	tk.MustQuery("explain select a from t where a > (select max(b) from u)").Check(testkit.Rows(
+		"IndexReader_36 3333.33 root index:IndexScan_35",
+		"└─IndexScan_35 3333.33 cop table:t, index:a, range:(3,+inf], keep order:false, stats:pseudo",
+	))
+	tk.MustQuery("select a from t where a > (select max(b) from u)").Check(testkit.Rows("4", "5"))
+	tk.MustQuery("select * from t where a = (select b from u where c = 2) + 1").Check(testkit.Rows("4 4"))
+	tk.MustQuery("select (select b from u where c = 2), (select b from u where c = 4)").Check(testkit.Rows("3 <nil>"))
+	tk.MustQuery("select * from t where a < (select b from u where c = 4)").Check(testkit.Rows())
+	tk.MustQuery("select * from t where (a, b) = (select b, c from u where c = 1)").Check(testkit.Rows("1 1"))
+	_, err = tk.Exec("select * from t where a > (select b from u)")
+	c.Assert(core.ErrSubqueryNo1Row.Equal(err), IsTrue, Commentf("err %v", err))
+}
+
+func (s *testExpressionRewriterSuite) TestInSubquery(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, u")
+	tk.MustExec("create table t(a int, c int)")
+	tk.MustExec("create table u(b int, c int)")
+	tk.MustExec("insert into t values (1, 1), (2, 1), (3, 2), (NULL, 2), (4, 3)")
+	tk.MustExec("insert into u values (1, 1), (1, 1), (2, 2), (3, 2), (NULL, 3)")
+	// The correlated condition of the subquery is pulled up into the semi join.
+	tk.MustQuery("explain select * from t where a in (select b from u where u.c = t.c)").Check(testkit.Rows(
+		"HashLeftJoin_9 7984.01 root semi join, equal:[eq(test.t.a, test.u.b) eq(test.t.c, test.u.c)]",
+		"├─TableReader_12 9980.01 root data:Selection_11",
+		"│ └─Selection_11 9980.01 cop not(isnull(test.t.a)), not(isnull(test.t.c))",
+		"│   └─TableScan_10 10000.00 cop table:t, range:[-inf,+inf], keep order:false, stats:pseudo",
+		"└─TableReader_15 9980.01 root data:Selection_14",
+		"  └─Selection_14 9980.01 cop not(isnull(test.u.b)), not(isnull(test.u.c))",
+		"    └─TableScan_13 10000.00 cop table:u, range:[-inf,+inf], keep order:false, stats:pseudo",
+	))
+	tk.MustQuery("select * from t where a in (select b from u where u.c = t.c)").Sort().Check(testkit.Rows("1 1", "3 2"))
+	tk.MustQuery("select * from t where a in (select b from u where u.c > t.c)").Check(testkit.Rows("2 1"))
+	tk.MustQuery("select * from t where a in (select b from u where u.c = t.c and u.b > 1) and c > 1").Check(testkit.Rows("3 2"))
+	tk.MustQuery("select * from t where a in (select b from u)").Sort().Check(testkit.Rows("1 1", "2 1", "3 2"))
+	tk.MustQuery("select * from t where (a, c) in (select b, c from u)").Sort().Check(testkit.Rows("1 1", "3 2"))
+	tk.MustQuery("select * from t where a in (select b from u where b > 10)").Check(testkit.Rows())
+	// The subqueries outside the conditions of the WHERE clause are evaluated to the lists of values.
+	tk.MustQuery("select * from t where a not in (select b from u)").Check(testkit.Rows())
+	tk.MustQuery("select * from t where a not in (select b from u where b is not null)").Check(testkit.Rows("4 3"))
+	tk.MustQuery("select * from t where a not in (select b from u where b > 10)").Sort().Check(testkit.Rows("1 1", "2 1", "3 2", "4 3", "<nil> 2"))
+	tk.MustQuery("select a, a in (select b from u), a not in (select b from u where b > 10) from t").Sort().Check(testkit.Rows(
+		"1 1 1", "2 1 1", "3 1 1", "4 <nil> 1", "<nil> <nil> 1",
+	))
+
+	_, err = tk.Exec("select * from t where a not in (select b from u where u.c = t.c)")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where a in (select b from u where u.c = t.c) or c = 1")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where a in (select t.c from u)")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where a = (select b from u where u.c = t.c)")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where a in (select b, c from u)")
+	c.Assert(err, NotNil)
+}
+
+func (s *testExpressionRewriterSuite) TestCompareSubquery(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, u")
+	tk.MustExec("create table t(a int, c int)")
+	tk.MustExec("create table u(b int, c int)")
+	tk.MustExec("insert into t values (1, 1), (2, 1), (3, 2), (NULL, 2)")
+	tk.MustExec("insert into u values (1, 1), (2, 1), (NULL, 2)")
+	// "> all" is rewritten to "> max" of the subquery, which is evaluated to a constant.
+	tk.MustQuery("explain select * from t where a > all (select b from u where b is not null)").Check(testkit.Rows(
+		"TableReader_21 3333.33 root data:Selection_20",
+		"└─Selection_20 3333.33 cop gt(test.t.a, 2)",
+		"  └─TableScan_19 10000.00 cop table:t, range:[-inf,+inf], keep order:false, stats:pseudo",
+	))
+	// "= any" is rewritten to a semi join like "in".
+	tk.MustQuery("explain select * from t where a = any (select b from u)").Check(testkit.Rows(
+		"HashLeftJoin_8 7992.00 root semi join, equal:[eq(test.t.a, test.u.b)]",
+		"├─TableReader_11 9990.00 root data:Selection_10",
+		"│ └─Selection_10 9990.00 cop not(isnull(test.t.a))",
+		"│   └─TableScan_9 10000.00 cop table:t, range:[-inf,+inf], keep order:false, stats:pseudo",
+		"└─TableReader_14 9990.00 root data:Selection_13",
+		"  └─Selection_13 9990.00 cop not(isnull(test.u.b))",
+		"    └─TableScan_12 10000.00 cop table:u, range:[-inf,+inf], keep order:false, stats:pseudo",
+	))
+
+	// Empty subquery.
+	tk.MustQuery("select a, a > all (select b from u where b > 10), a = all (select b from u where b > 10), " +
+		"a > any (select b from u where b > 10), a != any (select b from u where b > 10) from t").Sort().Check(testkit.Rows(
+		"1 1 1 0 0", "2 1 1 0 0", "3 1 1 0 0", "<nil> 1 1 0 0",
+	))
+	tk.MustQuery("select a from t where a < all (select b from u where b > 10)").Sort().Check(testkit.Rows("1", "2", "3", "<nil>"))
+	tk.MustQuery("select a from t where a = any (select b from u where b > 10)").Check(testkit.Rows())
+	tk.MustQuery("select a from t where a != all (select b from u where b > 10)").Sort().Check(testkit.Rows("1", "2", "3", "<nil>"))
+
+	// Subquery without NULL values.
+	tk.MustQuery("select a, a > all (select b from u where b is not null), a <= all (select b from u where b is not null), " +
+		"a > any (select b from u where b is not null), a = all (select b from u where b is not null), " +
+		"a != any (select b from u where b is not null) from t").Sort().Check(testkit.Rows(
+		"1 0 1 0 0 1", "2 0 0 1 0 1", "3 1 0 1 0 1", "<nil> <nil> <nil> <nil> <nil> <nil>",
+	))
+	tk.MustQuery("select a, a = all (select b from u where b = 2), a != any (select b from u where b = 2) from t").Sort().Check(testkit.Rows(
+		"1 0 1", "2 1 0", "3 0 1", "<nil> <nil> <nil>",
+	))
+	tk.MustQuery("select a from t where a > all (select b from u where b is not null)").Check(testkit.Rows("3"))
+	tk.MustQuery("select a from t where a >= any (select b from u where b is not null)").Sort().Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("select a from t where a != all (select b from u where b is not null)").Check(testkit.Rows("3"))
+	tk.MustQuery("select a from t where a = some (select b from u where b is not null)").Sort().Check(testkit.Rows("1", "2"))
+
+	// Subquery with NULL values.
+	tk.MustQuery("select a, a > all (select b from u), a > any (select b from u), a < any (select b from u), " +
+		"a = all (select b from u where b = 2 or b is null), a != any (select b from u where b = 2 or b is null) from t").Sort().Check(testkit.Rows(
+		"1 0 <nil> 1 0 1", "2 0 1 <nil> <nil> <nil>", "3 <nil> 1 <nil> 0 1", "<nil> <nil> <nil> <nil> <nil> <nil>",
+	))
+	tk.MustQuery("select a from t where a > all (select b from u)").Check(testkit.Rows())
+	tk.MustQuery("select a from t where a > any (select b from u)").Sort().Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select a from t where not (a > all (select b from u))").Sort().Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select a from t where a != all (select b from u)").Check(testkit.Rows())
+
+	_, err = tk.Exec("select * from t where a > all (select b from u where u.c = t.c)")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where a <=> any (select b from u)")
+	c.Assert(core.ErrNotSupportedYet.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t where (a, c) > all (select b, c from u)")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select * from t where (a, c) = any (select b, c from u)").Sort().Check(testkit.Rows("1 1", "2 1"))
+}
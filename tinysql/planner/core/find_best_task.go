@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/planner/util"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 	"golang.org/x/tools/container/intsets"
 )
 
@@ -49,12 +50,13 @@ var wholeTaskTypes = [...]property.TaskType{property.CopSingleReadTaskType, prop
 var invalidTask = &rootTask{cst: math.MaxFloat64}
 
 // GetPropByOrderByItems will check if this sort property can be pushed or not. In order to simplify the problem, we only
-// consider the case that all expression are columns.
+// consider the case that all expression are columns. The order of the children always treats NULLs as the smallest
+// values, so an item with an explicit NULL order can't be pushed either.
 func GetPropByOrderByItems(items []*ByItems) (*property.PhysicalProperty, bool) {
 	propItems := make([]property.Item, 0, len(items))
 	for _, item := range items {
 		col, ok := item.Expr.(*expression.Column)
-		if !ok {
+		if !ok || item.NullOrder != ast.NullOrderDefault {
 			return nil, false
 		}
 		propItems = append(propItems, property.Item{Col: col, Desc: item.Desc})
@@ -73,6 +75,17 @@ func (p *LogicalTableDual) findBestTask(prop *property.PhysicalProperty) (task,
 	return &rootTask{p: dual}, nil
 }
 
+func (p *LogicalValues) findBestTask(prop *property.PhysicalProperty) (task, error) {
+	if !prop.IsEmpty() {
+		return invalidTask, nil
+	}
+	values := PhysicalValues{
+		Lists: p.Lists,
+	}.Init(p.ctx, p.stats)
+	values.SetSchema(p.schema)
+	return &rootTask{p: values}, nil
+}
+
 func (p *LogicalShow) findBestTask(prop *property.PhysicalProperty) (task, error) {
 	if !prop.IsEmpty() {
 		return invalidTask, nil
@@ -586,6 +599,10 @@ func matchIndicesProp(idxCols []*expression.Column, colLens []int, propItems []p
 		if colLens[i] != types.UnspecifiedLength || !item.Col.Equal(nil, idxCols[i]) {
 			return false
 		}
+		// The index is sorted byte by byte, which isn't the order of a case-insensitive collation.
+		if ft := idxCols[i].GetType(); ft.EvalType() == types.ETString && collate.IsCICollation(ft.Collate) {
+			return false
+		}
 	}
 	return true
 }
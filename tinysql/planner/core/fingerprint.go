@@ -0,0 +1,52 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+)
+
+// Fingerprint implements PhysicalPlan interface.
+// The fingerprint is built from the operator types and their normalized explain
+// information, so constants, plan ids, costs and statistics do not affect it.
+func (p *basePhysicalPlan) Fingerprint() string {
+	h := sha256.New()
+	writeFingerprint(h, p.self, 0)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeFingerprint(h hash.Hash, p PhysicalPlan, depth int) {
+	h.Write([]byte(strconv.Itoa(depth)))
+	h.Write([]byte{'\t'})
+	h.Write([]byte(p.TP()))
+	h.Write([]byte{'\t'})
+	h.Write([]byte(p.ExplainNormalizedInfo()))
+	h.Write([]byte{'\n'})
+
+	switch x := p.(type) {
+	case *PhysicalTableReader:
+		writeFingerprint(h, x.tablePlan, depth+1)
+	case *PhysicalIndexReader:
+		writeFingerprint(h, x.indexPlan, depth+1)
+	case *PhysicalIndexLookUpReader:
+		writeFingerprint(h, x.indexPlan, depth+1)
+		writeFingerprint(h, x.tablePlan, depth+1)
+	}
+	for _, child := range p.Children() {
+		writeFingerprint(h, child, depth+1)
+	}
+}
@@ -57,6 +57,10 @@ const (
 	TypeMaxOneRow = "MaxOneRow"
 	// TypeDual is the type of TableDual.
 	TypeDual = "TableDual"
+	// TypeValues is the type of Values.
+	TypeValues = "Values"
+	// TypeUnion is the type of Union.
+	TypeUnion = "Union"
 	// TypeInsert is the type of Insert
 	TypeInsert = "Insert"
 	// TypeDelete is the type of Delete.
@@ -69,6 +73,8 @@ const (
 	TypeIndexReader = "IndexReader"
 	// TypeTiKVSingleGather is the type of TiKVSingleGather.
 	TypeTiKVSingleGather = "TiKVSingleGather"
+	// TypeTiKVDoubleGather is the type of TiKVDoubleGather.
+	TypeTiKVDoubleGather = "TiKVDoubleGather"
 	// TypeShowDDLJobs is the type of show ddl jobs.
 	TypeShowDDLJobs = "ShowDDLJobs"
 )
@@ -97,6 +103,12 @@ func (sg TiKVSingleGather) Init(ctx sessionctx.Context) *TiKVSingleGather {
 	return &sg
 }
 
+// Init initializes TiKVDoubleGather.
+func (dg TiKVDoubleGather) Init(ctx sessionctx.Context) *TiKVDoubleGather {
+	dg.baseLogicalPlan = newBaseLogicalPlan(ctx, TypeTiKVDoubleGather, &dg)
+	return &dg
+}
+
 // Init initializes LogicalTableScan.
 func (ts LogicalTableScan) Init(ctx sessionctx.Context) *LogicalTableScan {
 	ts.baseLogicalPlan = newBaseLogicalPlan(ctx, TypeTableScan, &ts)
@@ -205,6 +217,33 @@ func (p PhysicalTableDual) Init(ctx sessionctx.Context, stats *property.StatsInf
 	return &p
 }
 
+// Init initializes LogicalValues.
+func (p LogicalValues) Init(ctx sessionctx.Context) *LogicalValues {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, TypeValues, &p)
+	return &p
+}
+
+// Init initializes PhysicalValues.
+func (p PhysicalValues) Init(ctx sessionctx.Context, stats *property.StatsInfo) *PhysicalValues {
+	p.basePhysicalPlan = newBasePhysicalPlan(ctx, TypeValues, &p)
+	p.stats = stats
+	return &p
+}
+
+// Init initializes LogicalUnionAll.
+func (p LogicalUnionAll) Init(ctx sessionctx.Context) *LogicalUnionAll {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, TypeUnion, &p)
+	return &p
+}
+
+// Init initializes PhysicalUnionAll.
+func (p PhysicalUnionAll) Init(ctx sessionctx.Context, stats *property.StatsInfo, props ...*property.PhysicalProperty) *PhysicalUnionAll {
+	p.basePhysicalPlan = newBasePhysicalPlan(ctx, TypeUnion, &p)
+	p.childrenReqProps = props
+	p.stats = stats
+	return &p
+}
+
 // Init initializes Delete.
 func (p Delete) Init(ctx sessionctx.Context) *Delete {
 	p.basePlan = newBasePlan(ctx, TypeDelete)
@@ -316,7 +355,10 @@ func (p PhysicalUnionScan) Init(ctx sessionctx.Context, stats *property.StatsInf
 func (p PhysicalIndexLookUpReader) Init(ctx sessionctx.Context) *PhysicalIndexLookUpReader {
 	p.basePhysicalPlan = newBasePhysicalPlan(ctx, TypeIndexLookUp, &p)
 	p.TablePlans = flattenPushDownPlan(p.tablePlan)
-	p.IndexPlans = flattenPushDownPlan(p.indexPlan)
+	// The indexPlan is set by SetChildren later if the reader is built by the cascades planner.
+	if p.indexPlan != nil {
+		p.IndexPlans = flattenPushDownPlan(p.indexPlan)
+	}
 	p.schema = p.tablePlan.Schema()
 	return &p
 }
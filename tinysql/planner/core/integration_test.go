@@ -88,3 +88,30 @@ func (s *testIntegrationSuite) TestPushLimitDownIndexLookUpReader(c *C) {
 		tk.MustQuery(tt).Check(testkit.Rows(output[i].Plan...))
 	}
 }
+
+func (s *testIntegrationSuite) TestPushDownControlFuncSelection(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, c int)")
+
+	var input []string
+	var output []struct {
+		SQL  string
+		Plan []string
+	}
+	s.testData.GetTestCases(c, &input, &output)
+	for i, tt := range input {
+		s.testData.OnRecord(func() {
+			output[i].SQL = tt
+			output[i].Plan = s.testData.ConvertRowsToStrings(tk.MustQuery(tt).Rows())
+		})
+		tk.MustQuery(tt).Check(testkit.Rows(output[i].Plan...))
+	}
+
+	tk.MustExec("insert into t values(1,1,0),(2,2,0),(3,0,5)")
+	tk.MustQuery("select a from t where case when a > 1 then b else 0 end > 0").Check(testkit.Rows("2"))
+	tk.MustQuery("select a from t where case a when 1 then b when 2 then c end = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where if(a > 1, b, c) > 0").Check(testkit.Rows("2"))
+}
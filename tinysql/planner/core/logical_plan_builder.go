@@ -22,6 +22,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/cznic/mathutil"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
@@ -48,10 +49,16 @@ const (
 	TiDBHashJoin = "tidb_hj"
 	// HintHJ is hint enforce hash join.
 	HintHJ = "hash_join"
+	// TiDBIndexNestedLoopJoin is hint enforce index nested loop join, it's not supported yet.
+	TiDBIndexNestedLoopJoin = "tidb_inlj"
+	// HintINLJ is hint enforce index nested loop join, it's not supported yet.
+	HintINLJ = "inl_join"
 	// HintUseIndex is hint enforce using some indexes.
 	HintUseIndex = "use_index"
 	// HintIgnoreIndex is hint enforce ignoring some indexes.
 	HintIgnoreIndex = "ignore_index"
+	// HintNoIndex is hint enforce ignoring all the indexes, so the table is read by a full table scan.
+	HintNoIndex = "no_index"
 )
 
 func (la *LogicalAggregation) collectGroupByColumns() {
@@ -95,6 +102,17 @@ func (b *PlanBuilder) buildAggregation(ctx context.Context, p LogicalPlan, aggFu
 		if err != nil {
 			return nil, nil, err
 		}
+		if aggFunc.Order != nil {
+			newFunc.OrderByItems = make([]*aggregation.ByItem, 0, len(aggFunc.Order.Items))
+			for _, item := range aggFunc.Order.Items {
+				newExpr, np, err := b.rewrite(ctx, item.Expr, p, nil, true)
+				if err != nil {
+					return nil, nil, err
+				}
+				p = np
+				newFunc.OrderByItems = append(newFunc.OrderByItems, &aggregation.ByItem{Expr: newExpr, Desc: item.Desc, NullOrder: explicitNullOrder(item)})
+			}
+		}
 		combined := false
 		for j, oldFunc := range plan4Agg.AggFuncs {
 			if oldFunc.Equal(b.ctx, newFunc) {
@@ -141,8 +159,12 @@ func (b *PlanBuilder) buildResultSetNode(ctx context.Context, node ast.ResultSet
 		switch v := x.Source.(type) {
 		case *ast.SelectStmt:
 			p, err = b.buildSelect(ctx, v)
+		case *ast.UnionStmt:
+			p, err = b.buildUnion(ctx, v)
 		case *ast.TableName:
 			p, err = b.buildDataSource(ctx, v, &x.AsName)
+		case *ast.ValuesTable:
+			p, err = b.buildValuesTable(ctx, v)
 		default:
 			err = ErrUnsupportedType.GenWithStackByArgs(v)
 		}
@@ -171,6 +193,8 @@ func (b *PlanBuilder) buildResultSetNode(ctx context.Context, node ast.ResultSet
 		return p, nil
 	case *ast.SelectStmt:
 		return b.buildSelect(ctx, x)
+	case *ast.UnionStmt:
+		return b.buildUnion(ctx, x)
 	default:
 		return nil, ErrUnsupportedType.GenWithStack("Unsupported ast.ResultSetNode(%T) for buildResultSetNode()", x)
 	}
@@ -211,6 +235,13 @@ func (p *LogicalJoin) extractOnCondition(conditions []expression.Expression, der
 	return p.ExtractOnCondition(conditions, p.children[0].Schema(), p.children[1].Schema(), deriveLeft, deriveRight)
 }
 
+// isJoinKeyTypeMatched checks whether the two columns are compared as their own eval type. Otherwise
+// the comparison converts them to a common type, and the raw column values can't be used as join keys.
+func isJoinKeyTypeMatched(lCol, rCol *expression.Column) bool {
+	cmpType := expression.GetAccurateCmpType(lCol, rCol)
+	return lCol.GetType().EvalType() == cmpType && rCol.GetType().EvalType() == cmpType
+}
+
 // ExtractOnCondition divide conditions in CNF of join node into 4 groups.
 // These conditions can be where conditions, join conditions, or collection of both.
 // If deriveLeft/deriveRight is set, we would try to derive more conditions for left/right plan.
@@ -249,6 +280,15 @@ func (p *LogicalJoin) ExtractOnCondition(
 						}
 					}
 					if binop.FuncName.L == ast.EQ {
+						// The columns are of different types, e.g. int and varchar, which are compared as double.
+						// Hashing the raw values would never match, so cast them and leave it in other conditions,
+						// `updateEQCond` will turn the casted values into the join keys.
+						if !isJoinKeyTypeMatched(arg0, arg1) {
+							cond := expression.NewFunctionInternal(ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny),
+								expression.WrapWithCastAsReal(ctx, arg0), expression.WrapWithCastAsReal(ctx, arg1))
+							otherCond = append(otherCond, cond)
+							continue
+						}
 						cond := expression.NewFunctionInternal(ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny), arg0, arg1)
 						eqCond = append(eqCond, cond.(*expression.ScalarFunction))
 						continue
@@ -410,8 +450,30 @@ func (b *PlanBuilder) buildJoin(ctx context.Context, joinNode *ast.Join) (Logica
 		if newPlan != joinPlan {
 			return nil, errors.New("ON condition doesn't support subqueries yet")
 		}
-		onCondition := expression.SplitCNFItems(onExpr)
+		onCondition := make([]expression.Expression, 0, 1)
+		for _, item := range expression.SplitCNFItems(onExpr) {
+			if con, ok := item.(*expression.Constant); ok {
+				ret, _, err := expression.EvalBool(b.ctx, expression.CNFExprs{con}, chunk.Row{})
+				if err != nil || ret {
+					continue
+				}
+				// If an inner join has a condition which is always false, return dual plan directly.
+				// The outer joins still output the rows of the outer table, so the condition is kept.
+				if joinPlan.JoinType == InnerJoin {
+					dual := LogicalTableDual{}.Init(b.ctx)
+					dual.names = joinPlan.names
+					dual.SetSchema(joinPlan.schema)
+					return dual, nil
+				}
+			}
+			onCondition = append(onCondition, item)
+		}
 		joinPlan.attachOnConds(onCondition)
+		// An inner join whose ON conditions are all always true, like "ON 1 = 1", is a cartesian
+		// product as well.
+		if len(onCondition) == 0 && joinPlan.JoinType == InnerJoin {
+			joinPlan.cartesianJoin = true
+		}
 	} else if joinPlan.JoinType == InnerJoin {
 		// If a inner join without "ON" or "USING" clause, it's a cartesian
 		// product over the join tables.
@@ -428,7 +490,7 @@ func (b *PlanBuilder) buildSelection(ctx context.Context, p LogicalPlan, where a
 	}
 
 	conditions := splitWhere(where)
-	expressions := make([]expression.Expression, 0, len(conditions))
+	cnfItems := make([]expression.Expression, 0, len(conditions))
 	selection := LogicalSelection{}.Init(b.ctx)
 	for _, cond := range conditions {
 		expr, np, err := b.rewrite(ctx, cond, p, AggMapper, false)
@@ -439,21 +501,24 @@ func (b *PlanBuilder) buildSelection(ctx context.Context, p LogicalPlan, where a
 		if expr == nil {
 			continue
 		}
-		cnfItems := expression.SplitCNFItems(expr)
-		for _, item := range cnfItems {
-			if con, ok := item.(*expression.Constant); ok {
-				ret, _, err := expression.EvalBool(b.ctx, expression.CNFExprs{con}, chunk.Row{})
-				if err != nil || ret {
-					continue
-				}
-				// If there is condition which is always false, return dual plan directly.
-				dual := LogicalTableDual{}.Init(b.ctx)
-				dual.names = p.OutputNames()
-				dual.SetSchema(p.Schema())
-				return dual, nil
+		cnfItems = append(cnfItems, expression.SplitCNFItems(expr)...)
+	}
+	// Remove the duplicate and redundant conditions, so the ranges are built from the tightest bounds.
+	cnfItems = expression.RemoveRedundantConds(b.ctx, cnfItems)
+	expressions := make([]expression.Expression, 0, len(cnfItems))
+	for _, item := range cnfItems {
+		if con, ok := item.(*expression.Constant); ok {
+			ret, _, err := expression.EvalBool(b.ctx, expression.CNFExprs{con}, chunk.Row{})
+			if err != nil || ret {
+				continue
 			}
-			expressions = append(expressions, item)
+			// If there is condition which is always false, return dual plan directly.
+			dual := LogicalTableDual{}.Init(b.ctx)
+			dual.names = p.OutputNames()
+			dual.SetSchema(p.Schema())
+			return dual, nil
 		}
+		expressions = append(expressions, item)
 	}
 	if len(expressions) == 0 {
 		return p, nil
@@ -535,7 +600,8 @@ func (b *PlanBuilder) buildProjectionField(ctx context.Context, p LogicalPlan, f
 	var origTblName, tblName, origColName, colName, dbName model.CIStr
 	innerNode := getInnerFromParenthesesAndUnaryPlus(field.Expr)
 	col, isCol := expr.(*expression.Column)
-	if colNameField, ok := innerNode.(*ast.ColumnNameExpr); ok && isCol {
+	// The column of the outer query referenced by a subquery isn't in the schema of p.
+	if colNameField, ok := innerNode.(*ast.ColumnNameExpr); ok && isCol && p.Schema().Contains(col) {
 		// Field is a column reference.
 		idx := p.Schema().ColumnIndex(col)
 		name := p.OutputNames()[idx]
@@ -605,8 +671,9 @@ func (b *PlanBuilder) buildDistinct(child LogicalPlan, length int) (*LogicalAggr
 	b.optFlag = b.optFlag | flagBuildKeyInfo
 	b.optFlag = b.optFlag | flagPushDownAgg
 	plan4Agg := LogicalAggregation{
-		AggFuncs:     make([]*aggregation.AggFuncDesc, 0, child.Schema().Len()),
-		GroupByItems: expression.Column2Exprs(child.Schema().Clone().Columns[:length]),
+		AggFuncs:       make([]*aggregation.AggFuncDesc, 0, child.Schema().Len()),
+		GroupByItems:   expression.Column2Exprs(child.Schema().Clone().Columns[:length]),
+		preferAggToCop: true,
 	}.Init(b.ctx)
 	plan4Agg.collectGroupByColumns()
 	for _, col := range child.Schema().Columns {
@@ -627,23 +694,183 @@ func (b *PlanBuilder) buildDistinct(child LogicalPlan, length int) (*LogicalAggr
 	return plan4Agg, nil
 }
 
+// buildProjection4Union puts a projection above every child of the union, so the children
+// output the columns of the union. The type of a union column is inferred from all the
+// children, a child keeps its own type and the union converts the rows when executing.
+func (b *PlanBuilder) buildProjection4Union(ctx context.Context, u *LogicalUnionAll) {
+	unionCols := make([]*expression.Column, 0, u.children[0].Schema().Len())
+	names := make([]*types.FieldName, 0, u.children[0].Schema().Len())
+
+	// Infer union result types by its children's schema.
+	for i, col := range u.children[0].Schema().Columns {
+		resultTp := col.RetType
+		for j := 1; j < len(u.children); j++ {
+			childTp := u.children[j].Schema().Columns[i].RetType
+			resultTp = unionJoinFieldType(resultTp, childTp)
+		}
+		names = append(names, &types.FieldName{ColName: u.children[0].OutputNames()[i].ColName})
+		unionCols = append(unionCols, &expression.Column{
+			RetType:  resultTp,
+			UniqueID: b.ctx.GetSessionVars().AllocPlanColumnID(),
+		})
+	}
+	u.schema = expression.NewSchema(unionCols...)
+	u.names = names
+	for childID, child := range u.children {
+		schema := u.schema.Clone()
+		for i, col := range schema.Columns {
+			col.RetType = child.Schema().Columns[i].RetType
+		}
+		proj := LogicalProjection{Exprs: expression.Column2Exprs(child.Schema().Columns)}.Init(b.ctx)
+		proj.SetSchema(schema)
+		proj.names = child.OutputNames()
+		proj.SetChildren(child)
+		u.children[childID] = proj
+	}
+}
+
+func (b *PlanBuilder) buildUnion(ctx context.Context, union *ast.UnionStmt) (LogicalPlan, error) {
+	distinctSelectPlans, allSelectPlans, err := b.divideUnionSelectPlans(ctx, union.SelectList.Selects)
+	if err != nil {
+		return nil, err
+	}
+
+	unionDistinctPlan := b.buildUnionAll(ctx, distinctSelectPlans)
+	if unionDistinctPlan != nil {
+		unionDistinctPlan, err = b.buildDistinct(unionDistinctPlan, unionDistinctPlan.Schema().Len())
+		if err != nil {
+			return nil, err
+		}
+		if len(allSelectPlans) > 0 {
+			// Can't change the statements order in order to get the correct column info.
+			allSelectPlans = append([]LogicalPlan{unionDistinctPlan}, allSelectPlans...)
+		}
+	}
+
+	unionAllPlan := b.buildUnionAll(ctx, allSelectPlans)
+	unionPlan := unionDistinctPlan
+	if unionAllPlan != nil {
+		unionPlan = unionAllPlan
+	}
+
+	oldLen := unionPlan.Schema().Len()
+
+	if union.OrderBy != nil {
+		unionPlan, err = b.buildSort(ctx, unionPlan, union.OrderBy.Items, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if union.Limit != nil {
+		unionPlan, err = b.buildLimit(unionPlan, union.Limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// If there are extra expressions generated from `ORDER BY` clause, generate a `Projection` to remove them.
+	if oldLen != unionPlan.Schema().Len() {
+		proj := LogicalProjection{Exprs: expression.Column2Exprs(unionPlan.Schema().Columns[:oldLen])}.Init(b.ctx)
+		proj.SetChildren(unionPlan)
+		schema := expression.NewSchema(unionPlan.Schema().Clone().Columns[:oldLen]...)
+		for _, col := range schema.Columns {
+			col.UniqueID = b.ctx.GetSessionVars().AllocPlanColumnID()
+		}
+		proj.names = unionPlan.OutputNames()[:oldLen]
+		proj.SetSchema(schema)
+		return proj, nil
+	}
+
+	return unionPlan, nil
+}
+
+// divideUnionSelectPlans resolves union's select stmts to logical plans.
+// and divide result plans into "union-distinct" and "union-all" parts.
+// divide rule ref: https://dev.mysql.com/doc/refman/5.7/en/union.html
+// "Mixed UNION types are treated such that a DISTINCT union overrides any ALL union to its left."
+func (b *PlanBuilder) divideUnionSelectPlans(ctx context.Context, selects []*ast.SelectStmt) (distinctSelects []LogicalPlan, allSelects []LogicalPlan, err error) {
+	firstUnionAllIdx, columnNums := 0, -1
+	// The last slot is reserved for appending distinct union outside this function.
+	children := make([]LogicalPlan, len(selects), len(selects)+1)
+	for i := len(selects) - 1; i >= 0; i-- {
+		stmt := selects[i]
+		if firstUnionAllIdx == 0 && stmt.IsAfterUnionDistinct {
+			firstUnionAllIdx = i + 1
+		}
+
+		selectPlan, err := b.buildSelect(ctx, stmt)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The handle of a child can't be used above the union.
+		b.handleHelper.popMap()
+
+		if columnNums == -1 {
+			columnNums = selectPlan.Schema().Len()
+		}
+		if selectPlan.Schema().Len() != columnNums {
+			return nil, nil, ErrWrongNumberOfColumnsInSelect.GenWithStackByArgs()
+		}
+		children[i] = selectPlan
+	}
+	b.handleHelper.pushMap(nil)
+	return children[:firstUnionAllIdx], children[firstUnionAllIdx:], nil
+}
+
+func (b *PlanBuilder) buildUnionAll(ctx context.Context, subPlan []LogicalPlan) LogicalPlan {
+	if len(subPlan) == 0 {
+		return nil
+	}
+	u := LogicalUnionAll{}.Init(b.ctx)
+	u.children = subPlan
+	b.buildProjection4Union(ctx, u)
+	return u
+}
+
 // ByItems wraps a "by" item.
 type ByItems struct {
 	Expr expression.Expression
 	Desc bool
+	// NullOrder is only set if the NULLs are not placed as the default order,
+	// which treats NULLs as the smallest values.
+	NullOrder ast.NullOrder
 }
 
 // String implements fmt.Stringer interface.
 func (by *ByItems) String() string {
+	str := by.Expr.String()
 	if by.Desc {
-		return fmt.Sprintf("%s true", by.Expr)
+		str = fmt.Sprintf("%s true", str)
 	}
-	return by.Expr.String()
+	switch by.NullOrder {
+	case ast.NullsFirst:
+		str += " nulls first"
+	case ast.NullsLast:
+		str += " nulls last"
+	}
+	return str
 }
 
 // Clone makes a copy of ByItems.
 func (by *ByItems) Clone() *ByItems {
-	return &ByItems{Expr: by.Expr.Clone(), Desc: by.Desc}
+	return &ByItems{Expr: by.Expr.Clone(), Desc: by.Desc, NullOrder: by.NullOrder}
+}
+
+// explicitNullOrder returns the NullOrder of ByItems for the NULL order specified
+// by the ByItem, it's NullOrderDefault if the NULLs are placed as the default order.
+func explicitNullOrder(item *ast.ByItem) ast.NullOrder {
+	switch item.NullOrder {
+	case ast.NullsFirst:
+		if item.Desc {
+			return ast.NullsFirst
+		}
+	case ast.NullsLast:
+		if !item.Desc {
+			return ast.NullsLast
+		}
+	}
+	return ast.NullOrderDefault
 }
 
 func (b *PlanBuilder) buildSort(ctx context.Context, p LogicalPlan, byItems []*ast.ByItem, aggMapper map[*ast.AggregateFuncExpr]int) (*LogicalSort, error) {
@@ -657,7 +884,7 @@ func (b *PlanBuilder) buildSort(ctx context.Context, p LogicalPlan, byItems []*a
 		}
 
 		p = np
-		exprs = append(exprs, &ByItems{Expr: it, Desc: item.Desc})
+		exprs = append(exprs, &ByItems{Expr: it, Desc: item.Desc, NullOrder: explicitNullOrder(item)})
 	}
 	sort.ByItems = exprs
 	sort.SetChildren(p)
@@ -815,6 +1042,9 @@ func (a *havingAndOrderbyExprResolver) Enter(n ast.Node) (node ast.Node, skipChi
 	case *ast.AggregateFuncExpr:
 		a.inAggFunc = true
 	case *ast.ColumnNameExpr, *ast.ColumnName:
+	case *ast.SubqueryExpr:
+		// The subquery is resolved in its own scope.
+		return n, true
 	default:
 		a.inExpr = true
 	}
@@ -986,6 +1216,8 @@ func (g *gbyResolver) Enter(inNode ast.Node) (ast.Node, bool) {
 	g.exprDepth++
 	switch inNode.(type) {
 	case *driver.ValueExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.ColumnName:
+	case *ast.SubqueryExpr:
+		return inNode, true
 	default:
 		g.inExpr = true
 	}
@@ -1103,6 +1335,10 @@ func (b *PlanBuilder) pushTableHints(hints []*ast.TableOptimizerHint) {
 			sortMergeTables = append(sortMergeTables, tableNames2HintTableInfo(b.ctx, hint.Tables)...)
 		case TiDBHashJoin, HintHJ:
 			hashJoinTables = append(hashJoinTables, tableNames2HintTableInfo(b.ctx, hint.Tables)...)
+		case TiDBIndexNestedLoopJoin, HintINLJ:
+			// There is no index join, the hint is ignored.
+			errMsg := fmt.Sprintf("Optimizer Hint %s or %s is not supported, since there is no index join", HintINLJ, TiDBIndexNestedLoopJoin)
+			b.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrInternal.GenWithStack(errMsg))
 		case HintUseIndex:
 			if len(hint.Tables) != 0 {
 				dbName := hint.Tables[0].DBName
@@ -1135,6 +1371,23 @@ func (b *PlanBuilder) pushTableHints(hints []*ast.TableOptimizerHint) {
 					},
 				})
 			}
+		case HintNoIndex:
+			for _, tbl := range hint.Tables {
+				dbName := tbl.DBName
+				if dbName.L == "" {
+					dbName = model.NewCIStr(b.ctx.GetSessionVars().CurrentDB)
+				}
+				// An IGNORE INDEX hint without the index list ignores all the indexes, it can't be
+				// written in SQL, so it's only built from this hint.
+				indexHintList = append(indexHintList, indexHintInfo{
+					dbName:  dbName,
+					tblName: tbl.TableName,
+					indexHint: &ast.IndexHint{
+						HintType:  ast.HintIgnore,
+						HintScope: ast.HintForScan,
+					},
+				})
+			}
 
 		default:
 			// ignore hints that not implemented
@@ -1176,6 +1429,31 @@ func (b *PlanBuilder) TableHints() *tableHintInfo {
 	return &(b.tableHintInfo[len(b.tableHintInfo)-1])
 }
 
+// buildHandleByItem builds an order by item referring to the handle column of p, which is used to break the ties
+// of `order by ... limit n`. It returns nil if p doesn't read exactly one table with a single handle column.
+func (b *PlanBuilder) buildHandleByItem(p LogicalPlan) *ast.ByItem {
+	handleMap := b.handleHelper.tailMap()
+	if len(handleMap) != 1 {
+		return nil
+	}
+	for _, cols := range handleMap {
+		if len(cols) != 1 {
+			return nil
+		}
+		idx := p.Schema().ColumnIndex(cols[0])
+		if idx < 0 {
+			return nil
+		}
+		name := p.OutputNames()[idx]
+		return &ast.ByItem{Expr: &ast.ColumnNameExpr{Name: &ast.ColumnName{
+			Schema: name.DBName,
+			Table:  name.TblName,
+			Name:   name.ColName,
+		}}}
+	}
+	return nil
+}
+
 func (b *PlanBuilder) buildSelect(ctx context.Context, sel *ast.SelectStmt) (p LogicalPlan, err error) {
 	b.pushTableHints(sel.TableHints)
 	defer func() {
@@ -1210,6 +1488,16 @@ func (b *PlanBuilder) buildSelect(ctx context.Context, sel *ast.SelectStmt) (p L
 		return nil, err
 	}
 
+	if b.ctx.GetSessionVars().StableTopN && sel.OrderBy != nil && sel.Limit != nil && sel.GroupBy == nil &&
+		!sel.Distinct && !b.detectSelectAgg(sel) {
+		if item := b.buildHandleByItem(p); item != nil {
+			// Copy the statement so the original order by clause is left untouched.
+			stableSel := *sel
+			stableSel.OrderBy = &ast.OrderByClause{Items: append(append([]*ast.ByItem(nil), sel.OrderBy.Items...), item)}
+			sel = &stableSel
+		}
+	}
+
 	if sel.GroupBy != nil {
 		p, gbyCols, err = b.resolveGbyExprs(ctx, p, sel.GroupBy, sel.Fields.Fields)
 		if err != nil {
@@ -1304,6 +1592,74 @@ func (b *PlanBuilder) buildTableDual() *LogicalTableDual {
 	return LogicalTableDual{RowCount: 1}.Init(b.ctx)
 }
 
+// buildValuesTable builds the plan of the VALUES table value constructor. Its columns are
+// named column_0, column_1, ..., and the type of a column can hold the values of all the rows.
+func (b *PlanBuilder) buildValuesTable(ctx context.Context, v *ast.ValuesTable) (LogicalPlan, error) {
+	b.handleHelper.pushMap(nil)
+	mockTablePlan := LogicalTableDual{}.Init(b.ctx)
+	colLen := len(v.Lists[0])
+	lists := make([][]expression.Expression, 0, len(v.Lists))
+	for i, list := range v.Lists {
+		if len(list) != colLen {
+			return nil, ErrWrongValueCountOnRow.GenWithStackByArgs(i + 1)
+		}
+		exprs := make([]expression.Expression, 0, colLen)
+		for _, item := range list {
+			expr, _, err := b.rewrite(ctx, item, mockTablePlan, nil, true)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, expr)
+		}
+		lists = append(lists, exprs)
+	}
+	schema := expression.NewSchema(make([]*expression.Column, 0, colLen)...)
+	names := make([]*types.FieldName, 0, colLen)
+	for i := 0; i < colLen; i++ {
+		tp := lists[0][i].GetType().Clone()
+		for _, exprs := range lists[1:] {
+			tp = unionJoinFieldType(tp, exprs[i].GetType())
+		}
+		schema.Append(&expression.Column{
+			UniqueID: b.ctx.GetSessionVars().AllocPlanColumnID(),
+			RetType:  tp,
+		})
+		names = append(names, &types.FieldName{ColName: model.NewCIStr(fmt.Sprintf("column_%d", i))})
+	}
+	values := LogicalValues{Lists: lists}.Init(b.ctx)
+	values.SetSchema(schema)
+	values.names = names
+	return values, nil
+}
+
+// unionJoinFieldType finds the type which can carry the values of both the given types.
+func unionJoinFieldType(a, b *types.FieldType) *types.FieldType {
+	resultTp := types.NewFieldType(types.MergeFieldType(a.Tp, b.Tp))
+	if resultTp.Tp == mysql.TypeNewDecimal {
+		// The decimal result type is unsigned only when both the decimals are unsigned.
+		resultTp.Flag |= a.Flag & b.Flag & mysql.UnsignedFlag
+	} else {
+		// The non-decimal result type is unsigned when the first type is unsigned.
+		resultTp.Flag |= a.Flag & mysql.UnsignedFlag
+	}
+	if a.Decimal == types.UnspecifiedLength || b.Decimal == types.UnspecifiedLength {
+		// The values like the floats don't have a fixed number of decimals.
+		resultTp.Decimal = types.UnspecifiedLength
+		resultTp.Flen = mathutil.Max(a.Flen, b.Flen)
+	} else {
+		resultTp.Decimal = mathutil.Max(a.Decimal, b.Decimal)
+		// "Flen - Decimal" is the number of the digits before the decimal point.
+		resultTp.Flen = mathutil.Max(a.Flen-a.Decimal, b.Flen-b.Decimal) + resultTp.Decimal
+	}
+	if resultTp.EvalType() != types.ETInt && (a.EvalType() == types.ETInt || b.EvalType() == types.ETInt) && resultTp.Flen < mysql.MaxIntWidth {
+		resultTp.Flen = mysql.MaxIntWidth
+	}
+	resultTp.Charset = a.Charset
+	resultTp.Collate = a.Collate
+	expression.SetBinFlagOrBinStr(b, resultTp)
+	return resultTp
+}
+
 func (ds *DataSource) newExtraHandleSchemaCol() *expression.Column {
 	return &expression.Column{
 		RetType:  types.NewFieldType(mysql.TypeLonglong),
@@ -1318,19 +1674,24 @@ func (ds *DataSource) newExtraHandleSchemaCol() *expression.Column {
 // 1. tidb-server started and statistics handle has not been initialized.
 // 2. table row count from statistics is zero.
 // 3. statistics is outdated.
-func getStatsTable(ctx sessionctx.Context, tblInfo *model.TableInfo, pid int64) *statistics.Table {
-	statsHandle := domain.GetDomain(ctx).StatsHandle()
+func (b *PlanBuilder) getStatsTable(tblInfo *model.TableInfo, pid int64) *statistics.Table {
+	statsHandle := domain.GetDomain(b.ctx).StatsHandle()
 
 	// 1. tidb-server started and statistics handle has not been initialized.
 	if statsHandle == nil {
 		return statistics.PseudoTable(tblInfo)
 	}
 
+	// All the tables of the statement read the statistics from the same snapshot, so the
+	// estimations of the plan are consistent even if the stats are updated concurrently.
+	if b.statsSnapshot == nil {
+		b.statsSnapshot = statsHandle.Snapshot()
+	}
 	var statsTbl *statistics.Table
 	if pid != tblInfo.ID {
-		statsTbl = statsHandle.GetPartitionStats(tblInfo, pid)
+		statsTbl = b.statsSnapshot.GetPartitionStats(tblInfo, pid)
 	} else {
-		statsTbl = statsHandle.GetTableStats(tblInfo)
+		statsTbl = b.statsSnapshot.GetTableStats(tblInfo)
 	}
 
 	// 2. table row count from statistics is zero.
@@ -1380,7 +1741,7 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		TableAsName:         asName,
 		table:               tbl,
 		tableInfo:           tableInfo,
-		statisticTable:      getStatsTable(b.ctx, tbl.Meta(), tbl.Meta().ID),
+		statisticTable:      b.getStatsTable(tbl.Meta(), tbl.Meta().ID),
 		indexHints:          tn.IndexHints,
 		possibleAccessPaths: possiblePaths,
 		Columns:             make([]*model.ColumnInfo, 0, len(columns)),
@@ -1437,23 +1798,30 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	ds.SetSchema(schema)
 	ds.names = names
 
-	// Init FullIdxCols, FullIdxColLens for accessPaths.
+	// Init FullIdxCols, FullIdxColLens and PartialConds for accessPaths.
 	for _, path := range ds.possibleAccessPaths {
 		if !path.IsTablePath {
 			path.FullIdxCols, path.FullIdxColLens = expression.IndexInfo2Cols(ds.Columns, ds.schema.Columns, path.Index)
+			if path.Index.Condition != "" {
+				path.PartialConds, err = b.buildPartialIndexConds(ctx, ds, path.Index)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
 	var result LogicalPlan = ds
 
-	// If this SQL is executed in a non-readonly transaction, we need a
-	// "UnionScan" operator to read the modifications of former SQLs, which is
-	// buffered in tidb-server memory.
+	// If this SQL is executed in a non-readonly transaction and the table has been
+	// written by former SQLs, we need a "UnionScan" operator to read the modifications,
+	// which is buffered in tidb-server memory. The reads of the other tables can't see
+	// any local modification, so they skip it.
 	txn, err := b.ctx.Txn(false)
 	if err != nil {
 		return nil, err
 	}
-	if txn.Valid() && !txn.IsReadOnly() {
+	if txn.Valid() && !txn.IsReadOnly() && b.ctx.GetSessionVars().TxnCtx.IsTableDirty(tableInfo.ID) {
 		us := LogicalUnionScan{handleCol: handleCol}.Init(b.ctx)
 		us.SetChildren(ds)
 		result = us
@@ -1461,6 +1829,53 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	return result, nil
 }
 
+// buildPartialIndexConds parses the filter of the partial index into the CNF conditions on the columns of ds.
+func (b *PlanBuilder) buildPartialIndexConds(ctx context.Context, ds *DataSource, idx *model.IndexInfo) ([]expression.Expression, error) {
+	expr, err := parsePartialIndexCond(idx)
+	if err != nil {
+		return nil, err
+	}
+	cond, _, err := b.rewrite(ctx, expr, ds, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	return expression.SplitCNFItems(cond), nil
+}
+
+// parsePartialIndexCond parses the filter text of the partial index.
+func parsePartialIndexCond(idx *model.IndexInfo) (ast.ExprNode, error) {
+	stmt, err := parser.New().ParseOneStmt("select "+idx.Condition, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sel, ok := stmt.(*ast.SelectStmt)
+	if !ok || len(sel.Fields.Fields) != 1 {
+		return nil, errors.Errorf("invalid filter %s of partial index %s", idx.Condition, idx.Name)
+	}
+	return sel.Fields.Fields[0].Expr, nil
+}
+
+// buildPartialIndexCond builds the filter of the partial index over the rows written to its table,
+// the columns of the filter are the offsets of the table columns in a row.
+func buildPartialIndexCond(sctx sessionctx.Context, tblInfo *model.TableInfo, idx *model.IndexInfo) (expression.Expression, error) {
+	expr, err := parsePartialIndexCond(idx)
+	if err != nil {
+		return nil, err
+	}
+	colInfos := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StatePublic {
+			colInfos = append(colInfos, col)
+		}
+	}
+	cols, names := expression.ColumnInfos2ColumnsAndNames(sctx, model.CIStr{}, tblInfo.Name, colInfos)
+	fakePlan := LogicalTableDual{}.Init(sctx)
+	fakePlan.SetSchema(expression.NewSchema(cols...))
+	fakePlan.SetOutputNames(names)
+	cond, _, err := NewPlanBuilder(sctx, nil).rewrite(context.TODO(), expr, fakePlan, nil, true)
+	return cond, err
+}
+
 func (b *PlanBuilder) buildMemTable(ctx context.Context, dbName model.CIStr, tableInfo *model.TableInfo) (LogicalPlan, error) {
 	// We can use the `tableInfo.Columns` directly because the memory table has
 	// a stable schema and there is no online DDL on the memory table.
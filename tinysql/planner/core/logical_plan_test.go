@@ -23,10 +23,13 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 )
@@ -81,6 +84,73 @@ func (s *testPlanSuite) TestPredicatePushDown(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestPredicatePushDownProjectionGetVar(c *C) {
+	defer testleak.AfterTest(c)()
+	sql := "select * from (select a, @x as v from t) x where x.v = 'a' and x.a > 1"
+	stmt, err := s.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil)
+	p, _, err := BuildLogicalPlan(context.Background(), s.ctx, stmt, s.is)
+	c.Assert(err, IsNil)
+	p, err = logicalOptimize(context.TODO(), flagPredicatePushDown|flagPrunColumns, p.(LogicalPlan))
+	c.Assert(err, IsNil)
+	// The condition on the user variable stays above the projection, since the variable may be
+	// changed between evaluating the projection and the pushed down condition.
+	c.Assert(strings.HasPrefix(ToString(p), "DataScan(t)->Projection->Sel("), IsTrue, Commentf("plan: %s", ToString(p)))
+	lp := p.(LogicalPlan)
+	for len(lp.Children()) > 0 {
+		lp = lp.Children()[0]
+	}
+	ds := lp.(*DataSource)
+	c.Assert(ds.pushedDownConds, HasLen, 1)
+}
+
+func (s *testPlanSuite) TestUnionPredicatePushDown(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql    string
+		best   string
+		pushed int
+	}{
+		{
+			sql:    "select * from (select a from t union all select b from t) x where x.a > 1",
+			best:   "UnionAll{DataScan(t)->Projection->Projection->DataScan(t)->Projection->Projection}->Projection",
+			pushed: 1,
+		},
+		{
+			sql:    "select * from (select a from t union select b from t) x where x.a > 1",
+			best:   "UnionAll{DataScan(t)->Projection->Projection->DataScan(t)->Projection->Projection}->Aggr(firstrow(Column#25))->Projection",
+			pushed: 1,
+		},
+		{
+			// The union converts the ints to strings, so the condition can't be evaluated on the children.
+			sql:    "select * from (select a from t union all select c_str from t) x where x.a = 'a'",
+			best:   "UnionAll{DataScan(t)->Projection->Projection->DataScan(t)->Projection->Projection}->Sel([eq(Column#25, a)])->Projection",
+			pushed: 0,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		p, err = logicalOptimize(context.TODO(), flagPredicatePushDown|flagPrunColumns, p.(LogicalPlan))
+		c.Assert(err, IsNil, comment)
+		c.Assert(ToString(p), Equals, tt.best, comment)
+		lp := p.(LogicalPlan)
+		for _, isUnion := lp.(*LogicalUnionAll); !isUnion; _, isUnion = lp.(*LogicalUnionAll) {
+			lp = lp.Children()[0]
+		}
+		for _, child := range lp.Children() {
+			for len(child.Children()) > 0 {
+				child = child.Children()[0]
+			}
+			c.Assert(child.(*DataSource).pushedDownConds, HasLen, tt.pushed, comment)
+		}
+	}
+}
+
 func (s *testPlanSuite) TestJoinPredicatePushDown(c *C) {
 	defer testleak.AfterTest(c)()
 	var (
@@ -436,8 +506,9 @@ func (s *testPlanSuite) TestValidate(c *C) {
 			err: ErrAmbiguous,
 		},
 		{
+			// The index e is write only, so it falls back to the table scan.
 			sql: "select * from t t1 use index(e)",
-			err: ErrKeyDoesNotExist,
+			err: nil,
 		},
 		{
 			sql: "select a from t having c2",
@@ -554,6 +625,30 @@ func (s *testPlanSuite) TestAggPrune(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestMergeProjection(c *C) {
+	defer testleak.AfterTest(c)()
+	var input, output []string
+	s.testData.GetTestCases(c, &input, &output)
+
+	ctx := context.Background()
+	for i, tt := range input {
+		comment := Commentf("for %s", tt)
+		stmt, err := s.ParseOneStmt(tt, "", "")
+		c.Assert(err, IsNil, comment)
+
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil)
+
+		p, err = logicalOptimize(context.TODO(), flagPrunColumns|flagEliminateProjection, p.(LogicalPlan))
+		c.Assert(err, IsNil)
+		planString := ToString(p)
+		s.testData.OnRecord(func() {
+			output[i] = planString
+		})
+		c.Assert(planString, Equals, output[i], comment)
+	}
+}
+
 func (s *testPlanSuite) TestTopNPushDown(c *C) {
 	defer func() {
 		testleak.AfterTest(c)()
@@ -578,6 +673,59 @@ func (s *testPlanSuite) TestTopNPushDown(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestMergeNestedLimits(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql    string
+		offset uint64
+		count  uint64
+	}{
+		{
+			sql:    "select * from (select * from t limit 10) t1 limit 3",
+			offset: 0,
+			count:  3,
+		},
+		{
+			sql:    "select * from (select * from t limit 2, 10) t1 limit 3, 4",
+			offset: 5,
+			count:  4,
+		},
+		{
+			// Only 2 rows are left in the inner limit after skipping the outer offset.
+			sql:    "select * from (select * from t limit 1, 5) t1 limit 3, 4",
+			offset: 4,
+			count:  2,
+		},
+		{
+			// The outer offset skips all the rows of the inner limit.
+			sql:    "select * from (select * from (select * from t limit 5) t1 limit 2) t2 limit 3, 1",
+			offset: 3,
+			count:  0,
+		},
+	}
+	ctx := context.Background()
+	for i, tt := range tests {
+		comment := Commentf("case:%v sql:%s", i, tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		Preprocess(s.ctx, stmt, s.is)
+		builder := NewPlanBuilder(MockContext(), s.is)
+		p, err := builder.Build(ctx, stmt)
+		c.Assert(err, IsNil, comment)
+		lp, err := logicalOptimize(ctx, builder.optFlag, p.(LogicalPlan))
+		c.Assert(err, IsNil, comment)
+		// The nested limits are collapsed into a single one.
+		c.Assert(strings.Count(ToString(lp), "Limit"), Equals, 1, comment)
+		var limit *LogicalLimit
+		for cur := lp; limit == nil && len(cur.Children()) > 0; cur = cur.Children()[0] {
+			limit, _ = cur.(*LogicalLimit)
+		}
+		c.Assert(limit, NotNil, comment)
+		c.Assert(limit.Offset, Equals, tt.offset, comment)
+		c.Assert(limit.Count, Equals, tt.count, comment)
+	}
+}
+
 func (s *testPlanSuite) TestNameResolver(c *C) {
 	defer testleak.AfterTest(c)()
 	tests := []struct {
@@ -780,3 +928,176 @@ func (s *testPlanSuite) TestUpdateEQCond(c *C) {
 		c.Assert(ToString(p), Equals, tt.best, comment)
 	}
 }
+
+func (s *testPlanSuite) TestExtractOnConditionMismatchedTypes(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := MockContext()
+	lCol := &expression.Column{UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	rIntCol := &expression.Column{UniqueID: 2, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	rStrCol := &expression.Column{UniqueID: 3, RetType: types.NewFieldType(mysql.TypeVarchar)}
+	join := LogicalJoin{}.Init(ctx)
+
+	sameType := expression.NewFunctionInternal(ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny), lCol, rIntCol)
+	// A bare equal condition on an int and a varchar column, like the ones substituted by column propagation.
+	mismatched := expression.NewFunctionInternal(ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny), lCol, rIntCol).(*expression.ScalarFunction)
+	mismatched.GetArgs()[1] = rStrCol
+
+	eqCond, leftCond, rightCond, otherCond := join.ExtractOnCondition([]expression.Expression{sameType, mismatched},
+		expression.NewSchema(lCol), expression.NewSchema(rIntCol, rStrCol), false, false)
+	c.Assert(eqCond, HasLen, 1)
+	c.Assert(leftCond, HasLen, 0)
+	c.Assert(rightCond, HasLen, 0)
+	c.Assert(otherCond, HasLen, 1)
+	// Both sides are compared as double, which is what MySQL does for int and string.
+	cond, ok := otherCond[0].(*expression.ScalarFunction)
+	c.Assert(ok, IsTrue)
+	c.Assert(cond.FuncName.L, Equals, ast.EQ)
+	for _, arg := range cond.GetArgs() {
+		_, isCol := arg.(*expression.Column)
+		c.Assert(isCol, IsFalse)
+		c.Assert(arg.GetType().EvalType(), Equals, types.ETReal)
+	}
+}
+
+func (s *testPlanSuite) TestRemoveRedundantConds(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql   string
+		conds string
+	}{
+		{sql: "select * from t where a > 5 and a > 3 and 5 < a", conds: "[gt(test.t.a, 5)]"},
+		{sql: "select * from t where a >= 5 and a > 5 and a < 10 and a <= 10", conds: "[gt(test.t.a, 5) lt(test.t.a, 10)]"},
+		{sql: "select * from t where a = 5 and a >= 3 and a < 10 and b = 1", conds: "[eq(test.t.a, 5) eq(test.t.b, 1)]"},
+		{sql: "select * from t where a > 5 and b > 3 and a > b", conds: "[gt(test.t.a, 5) gt(test.t.b, 3) gt(test.t.a, test.t.b)]"},
+		{sql: "select * from t where c_str > '1' and c_str > 'a'", conds: "[gt(test.t.c_str, a)]"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		sel, ok := p.(LogicalPlan).Children()[0].(*LogicalSelection)
+		c.Assert(ok, IsTrue, comment)
+		c.Assert(fmt.Sprintf("%s", sel.Conditions), Equals, tt.conds, comment)
+	}
+
+	// The conditions which can't be satisfied at the same time.
+	stmt, err := s.ParseOneStmt("select * from t where a > 5 and a < 3", "", "")
+	c.Assert(err, IsNil)
+	p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+	c.Assert(err, IsNil)
+	_, ok := p.(LogicalPlan).Children()[0].(*LogicalTableDual)
+	c.Assert(ok, IsTrue)
+}
+
+func (s *testPlanSuite) TestConstantOnCondition(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := context.Background()
+	buildJoin := func(sql string) LogicalPlan {
+		comment := Commentf("for %s", sql)
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		return p.(LogicalPlan).Children()[0]
+	}
+
+	// The always true conditions are removed, and the join without conditions is a cartesian join.
+	for _, sql := range []string{
+		"select * from t t1 join t t2 on 1 = 1",
+		"select * from t t1 join t t2 on true",
+		"select * from t t1 join t t2 on 1 and 2 > 1",
+	} {
+		join, ok := buildJoin(sql).(*LogicalJoin)
+		c.Assert(ok, IsTrue, Commentf("for %s", sql))
+		c.Assert(join.cartesianJoin, IsTrue, Commentf("for %s", sql))
+		c.Assert(join.EqualConditions, HasLen, 0)
+		c.Assert(join.LeftConditions, HasLen, 0)
+		c.Assert(join.RightConditions, HasLen, 0)
+		c.Assert(join.OtherConditions, HasLen, 0)
+	}
+	join, ok := buildJoin("select * from t t1 join t t2 on 1 = 1 and t1.a = t2.a").(*LogicalJoin)
+	c.Assert(ok, IsTrue)
+	c.Assert(join.cartesianJoin, IsFalse)
+	c.Assert(join.EqualConditions, HasLen, 1)
+	c.Assert(join.OtherConditions, HasLen, 0)
+
+	// The inner join with an always false condition has no result.
+	for _, sql := range []string{
+		"select * from t t1 join t t2 on 0",
+		"select * from t t1 join t t2 on null",
+		"select * from t t1 join t t2 on t1.a = t2.a and 1 > 2",
+	} {
+		dual, ok := buildJoin(sql).(*LogicalTableDual)
+		c.Assert(ok, IsTrue, Commentf("for %s", sql))
+		c.Assert(dual.RowCount, Equals, 0)
+		c.Assert(dual.OutputNames(), HasLen, dual.Schema().Len())
+	}
+	// The outer join still outputs the rows of the outer table, the condition is kept.
+	join, ok = buildJoin("select * from t t1 left join t t2 on 0").(*LogicalJoin)
+	c.Assert(ok, IsTrue)
+	c.Assert(join.JoinType, Equals, LeftOuterJoin)
+	c.Assert(join.RightConditions, HasLen, 1)
+}
+
+func (s *testPlanSuite) TestDNFEqualsToIn(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql  string
+		cond string
+	}{
+		{sql: "select * from t where a = 1 or a = 3 or a = 2", cond: "in(test.t.a, 1, 3, 2)"},
+		{sql: "select * from t where a = 1 or 2 = a or a in (3, 4)", cond: "in(test.t.a, 1, 2, 3, 4)"},
+		{sql: "select * from t where c_str = 'a' or c_str = 'b'", cond: "in(test.t.c_str, a, b)"},
+		{sql: "select * from t where a = 1 or b = 2 or a = 3", cond: "or(in(test.t.a, 1, 3), eq(test.t.b, 2))"},
+		{sql: "select * from t where a = 1 or b = 2", cond: "or(eq(test.t.a, 1), eq(test.t.b, 2))"},
+		{sql: "select * from t where a = 1 or a > 2", cond: "or(eq(test.t.a, 1), gt(test.t.a, 2))"},
+		{sql: "select * from t where a = 1 or a = b", cond: "or(eq(test.t.a, 1), eq(test.t.a, test.t.b))"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		sel, ok := p.(LogicalPlan).Children()[0].(*LogicalSelection)
+		c.Assert(ok, IsTrue, comment)
+		c.Assert(sel.Conditions, HasLen, 1, comment)
+		c.Assert(expression.DNFEqualsToIn(s.ctx, sel.Conditions[0]).String(), Equals, tt.cond, comment)
+	}
+}
+
+func (s *testPlanSuite) TestConstantSelectWithoutFrom(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql   string
+		exprs string
+	}{
+		{sql: "select 1+1, 2*3", exprs: "[2 6]"},
+		{sql: "select 1+1, 'a', 1 > 2, null", exprs: "[2 a 0 <nil>]"},
+		{sql: "select -(1+2)*3, ifnull(null, 5), if(1 < 2, 'x', 'y')", exprs: "[-9 5 x]"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := BuildLogicalPlan(ctx, s.ctx, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		proj, ok := p.(*LogicalProjection)
+		c.Assert(ok, IsTrue, comment)
+		// All the expressions are folded into constants when building the projection,
+		// so they are evaluated only once on the single row of the dual table.
+		for _, expr := range proj.Exprs {
+			_, ok = expr.(*expression.Constant)
+			c.Assert(ok, IsTrue, comment)
+		}
+		c.Assert(fmt.Sprintf("%s", proj.Exprs), Equals, tt.exprs, comment)
+		dual, ok := proj.Children()[0].(*LogicalTableDual)
+		c.Assert(ok, IsTrue, comment)
+		c.Assert(dual.RowCount, Equals, 1, comment)
+	}
+}
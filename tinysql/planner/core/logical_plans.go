@@ -39,10 +39,12 @@ var (
 	_ LogicalPlan = &LogicalTableDual{}
 	_ LogicalPlan = &DataSource{}
 	_ LogicalPlan = &TiKVSingleGather{}
+	_ LogicalPlan = &TiKVDoubleGather{}
 	_ LogicalPlan = &LogicalTableScan{}
 	_ LogicalPlan = &LogicalIndexScan{}
 	_ LogicalPlan = &LogicalSort{}
 	_ LogicalPlan = &LogicalLimit{}
+	_ LogicalPlan = &LogicalUnionAll{}
 )
 
 // JoinType contains CrossJoin, InnerJoin, LeftOuterJoin, RightOuterJoin, FullOuterJoin, SemiJoin.
@@ -55,6 +57,8 @@ const (
 	LeftOuterJoin
 	// RightOuterJoin means right join.
 	RightOuterJoin
+	// SemiJoin means if row a in table A matches some rows in B, just output a.
+	SemiJoin
 )
 
 // IsOuterJoin returns if this joiner is a outer joiner
@@ -70,6 +74,8 @@ func (tp JoinType) String() string {
 		return "left outer join"
 	case RightOuterJoin:
 		return "right outer join"
+	case SemiJoin:
+		return "semi join"
 	}
 	return "unsupported join type"
 }
@@ -138,6 +144,9 @@ type LogicalAggregation struct {
 
 	possibleProperties [][]*expression.Column
 	inputCount         float64 // inputCount is the input count of this plan.
+	// preferAggToCop is set for the aggregation rewritten from DISTINCT, whose partial agg deduplicates
+	// the rows in coprocessor, so it's always pushed down if possible.
+	preferAggToCop bool
 }
 
 // IsPartialModeAgg returns if all of the AggFuncs are partialMode.
@@ -146,6 +155,22 @@ func (la *LogicalAggregation) IsPartialModeAgg() bool {
 	return la.AggFuncs[0].Mode == aggregation.Partial1Mode
 }
 
+// canPushToCop checks whether the child of the aggregation is a DataSource, optionally under selections,
+// so the aggregation can be pushed down to the coprocessor above the scan.
+func (la *LogicalAggregation) canPushToCop() bool {
+	p := la.children[0]
+	for {
+		switch x := p.(type) {
+		case *DataSource:
+			return true
+		case *LogicalSelection:
+			p = x.children[0]
+		default:
+			return false
+		}
+	}
+}
+
 // GetGroupByCols returns the groupByCols. If the groupByCols haven't be collected,
 // this method would collect them at first. If the GroupByItems have been changed,
 // we should explicitly collect GroupByColumns before this method.
@@ -173,6 +198,19 @@ type LogicalTableDual struct {
 	RowCount int
 }
 
+// LogicalValues represents the rows of a VALUES table value constructor.
+type LogicalValues struct {
+	logicalSchemaProducer
+
+	// Lists is the rows, the i-th expression of a row is the value of the i-th column.
+	Lists [][]expression.Expression
+}
+
+// LogicalUnionAll represents LogicalUnionAll plan.
+type LogicalUnionAll struct {
+	logicalSchemaProducer
+}
+
 // LogicalMemTable represents a memory table or virtual table
 type LogicalMemTable struct {
 	logicalSchemaProducer
@@ -236,6 +274,24 @@ type TiKVSingleGather struct {
 	Index         *model.IndexInfo
 }
 
+// TiKVDoubleGather is a leaf logical operator of TiDB layer to gather tuples
+// from TiKV regions by the handles read from an IndexScan, i.e. the IndexLookUp.
+// Its child is the IndexScan, the table side is built in implementation phase.
+type TiKVDoubleGather struct {
+	logicalSchemaProducer
+	Source *DataSource
+	Index  *model.IndexInfo
+
+	FullIdxCols    []*expression.Column
+	FullIdxColLens []int
+}
+
+// CoveredByIndex checks whether the columns used by the condition are all covered by the index,
+// so the condition can be evaluated on the index side of the IndexLookUp.
+func (dg *TiKVDoubleGather) CoveredByIndex(cond expression.Expression) bool {
+	return isCoveringIndex(expression.ExtractColumns(cond), dg.FullIdxCols, dg.FullIdxColLens, dg.Source.tableInfo.PKIsHandle)
+}
+
 // LogicalTableScan is the logical table scan operator for TiKV.
 type LogicalTableScan struct {
 	logicalSchemaProducer
@@ -327,7 +383,33 @@ func (ds *DataSource) buildIndexGather(path *util.AccessPath) LogicalPlan {
 	return sg
 }
 
-// Convert2Gathers builds logical TiKVSingleGathers from DataSource.
+func (ds *DataSource) buildIndexLookUpGather(path *util.AccessPath) LogicalPlan {
+	is := LogicalIndexScan{
+		Source:         ds,
+		IsDoubleRead:   true,
+		Index:          path.Index,
+		FullIdxCols:    path.FullIdxCols,
+		FullIdxColLens: path.FullIdxColLens,
+		IdxCols:        path.IdxCols,
+		IdxColLens:     path.IdxColLens,
+	}.Init(ds.ctx)
+
+	is.Columns = make([]*model.ColumnInfo, len(ds.Columns))
+	copy(is.Columns, ds.Columns)
+	is.SetSchema(ds.Schema())
+
+	dg := TiKVDoubleGather{
+		Source:         ds,
+		Index:          path.Index,
+		FullIdxCols:    path.FullIdxCols,
+		FullIdxColLens: path.FullIdxColLens,
+	}.Init(ds.ctx)
+	dg.SetSchema(ds.Schema())
+	dg.SetChildren(is)
+	return dg
+}
+
+// Convert2Gathers builds logical TiKVSingleGathers and TiKVDoubleGathers from DataSource.
 func (ds *DataSource) Convert2Gathers() (gathers []LogicalPlan) {
 	tg := ds.buildTableGather()
 	gathers = append(gathers, tg)
@@ -338,8 +420,10 @@ func (ds *DataSource) Convert2Gathers() (gathers []LogicalPlan) {
 			// If index columns can cover all of the needed columns, we can use a IndexGather + IndexScan.
 			if isCoveringIndex(ds.schema.Columns, path.FullIdxCols, path.FullIdxColLens, ds.tableInfo.PKIsHandle) {
 				gathers = append(gathers, ds.buildIndexGather(path))
+			} else {
+				// If index columns can not cover the schema, use a IndexLookUpGather + IndexScan.
+				gathers = append(gathers, ds.buildIndexLookUpGather(path))
 			}
-			// TODO: If index columns can not cover the schema, use IndexLookUpGather.
 		}
 	}
 	return gathers
@@ -394,7 +478,16 @@ func (ds *DataSource) deriveTablePathStats(path *util.AccessPath, conds []expres
 	return noIntervalRange, err
 }
 
-func (ds *DataSource) fillIndexPath(path *util.AccessPath, conds []expression.Expression) error {
+// fillIndexPath fills the ranges and the conditions of the index path, it returns false if
+// the index can't be used for the conditions.
+func (ds *DataSource) fillIndexPath(path *util.AccessPath, conds []expression.Expression) (bool, error) {
+	// The partial index only contains the rows satisfying its filter, so the rows
+	// the conditions match may be missing from it unless they imply the filter.
+	for _, cond := range path.PartialConds {
+		if !expression.ImpliedBy(ds.ctx, cond, conds) {
+			return false, nil
+		}
+	}
 	sc := ds.ctx.GetSessionVars().StmtCtx
 	path.Ranges = ranger.FullRange()
 	path.CountAfterAccess = float64(ds.statisticTable.Count)
@@ -410,7 +503,7 @@ func (ds *DataSource) fillIndexPath(path *util.AccessPath, conds []expression.Ex
 	if len(path.IdxCols) != 0 {
 		res, err := ranger.DetachCondAndBuildRangeForIndex(ds.ctx, conds, path.IdxCols, path.IdxColLens)
 		if err != nil {
-			return err
+			return false, err
 		}
 		path.Ranges = res.Ranges
 		path.AccessConds = res.AccessConds
@@ -420,12 +513,12 @@ func (ds *DataSource) fillIndexPath(path *util.AccessPath, conds []expression.Ex
 		path.IsDNFCond = res.IsDNFCond
 		path.CountAfterAccess, err = ds.tableStats.HistColl.GetRowCountByIndexRanges(sc, path.Index.ID, path.Ranges)
 		if err != nil {
-			return err
+			return false, err
 		}
 	} else {
 		path.TableFilters = conds
 	}
-	return nil
+	return true, nil
 }
 
 // deriveIndexPathStats will fulfill the information that the AccessPath need.
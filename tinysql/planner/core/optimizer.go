@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/types"
 )
 
@@ -135,4 +136,5 @@ func physicalOptimize(logic LogicalPlan) (PhysicalPlan, error) {
 
 func init() {
 	expression.EvalAstExpr = evalAstExpr
+	tables.BuildPartialIndexCond = buildPartialIndexCond
 }
@@ -15,16 +15,19 @@ package core_test
 
 import (
 	"context"
+	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/planner"
 	"github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 )
@@ -215,6 +218,8 @@ func (s *testPlanSuite) TestDAGPlanBuilderUnionScan(c *C) {
 	c.Assert(err, IsNil)
 	_, err = se.Execute(context.Background(), "use test")
 	c.Assert(err, IsNil)
+	tbl, err := s.is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
 
 	var input []string
 	var output []struct {
@@ -233,6 +238,8 @@ func (s *testPlanSuite) TestDAGPlanBuilderUnionScan(c *C) {
 		c.Assert(err, IsNil)
 		txn.Set(kv.Key("AAA"), []byte("BBB"))
 		c.Assert(se.StmtCommit(), IsNil)
+		// Only the tables written in the txn are read by union scan.
+		se.GetSessionVars().TxnCtx.MarkTableDirty(tbl.Meta().ID)
 		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
 		c.Assert(err, IsNil)
 		s.testData.OnRecord(func() {
@@ -399,6 +406,60 @@ func (s *testPlanSuite) TestHintAlias(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestIndexNestedLoopJoinHint(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	// There is no index join, the hint is ignored with a warning.
+	tests := []struct {
+		sql     string
+		best    string
+		hasWarn bool
+	}{
+		{
+			sql:     "select /*+ INL_JOIN(t1) */ * from t t1 join t t2 on t1.b = t2.b",
+			best:    "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(test.t.b,test.t.b)",
+			hasWarn: true,
+		},
+		{
+			sql:     "select /*+ TIDB_INLJ(t2) */ * from t t1 left join t t2 on t1.b = t2.b",
+			best:    "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(test.t.b,test.t.b)",
+			hasWarn: true,
+		},
+		{
+			sql:  "select * from t t1 join t t2 on t1.b = t2.b",
+			best: "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(test.t.b,test.t.b)",
+		},
+	}
+	ctx := context.Background()
+	for i, tt := range tests {
+		comment := Commentf("case:%v sql:%s", i, tt.sql)
+		se.GetSessionVars().StmtCtx.SetWarnings(nil)
+
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		p, _, err := planner.Optimize(ctx, se, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		c.Assert(core.ToString(p), Equals, tt.best, comment)
+		warnings := se.GetSessionVars().StmtCtx.GetWarnings()
+		if tt.hasWarn {
+			c.Assert(warnings, HasLen, 1, comment)
+		} else {
+			c.Assert(warnings, HasLen, 0, comment)
+		}
+	}
+}
+
 func (s *testPlanSuite) TestIndexHint(c *C) {
 	defer testleak.AfterTest(c)()
 	store, dom, err := newStoreWithBootstrap()
@@ -443,3 +504,290 @@ func (s *testPlanSuite) TestIndexHint(c *C) {
 		}
 	}
 }
+
+func (s *testPlanSuite) TestIndexHintChooseAccessPath(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	// Both index c_d_e and index g can be used to access the rows.
+	tests := []struct {
+		sql  string
+		used string
+		not  []string
+	}{
+		{
+			sql:  "select /*+ USE_INDEX(t, g) */ * from t where c = 1 and g = 1",
+			used: "Index(t.g)",
+			not:  []string{"Index(t.c_d_e)"},
+		},
+		{
+			sql:  "select /*+ USE_INDEX(t, c_d_e) */ * from t where c = 1 and g = 1",
+			used: "Index(t.c_d_e)",
+			not:  []string{"Index(t.g)"},
+		},
+		{
+			sql:  "select /*+ IGNORE_INDEX(t, c_d_e, g) */ * from t where c = 1 and g = 1",
+			used: "Table(t)",
+			not:  []string{"Index(t.c_d_e)", "Index(t.g)"},
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("sql:%s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		plan := core.ToString(p)
+		c.Assert(strings.Contains(plan, tt.used), IsTrue, Commentf("sql:%s plan:%s", tt.sql, plan))
+		for _, idx := range tt.not {
+			c.Assert(strings.Contains(plan, idx), IsFalse, Commentf("sql:%s plan:%s", tt.sql, plan))
+		}
+	}
+}
+
+func (s *testPlanSuite) TestScalarSubqueryIndexRange(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	// The uncorrelated subqueries are evaluated to constants, so they can be used to build the index range.
+	for _, sql := range []string{
+		"select /*+ USE_INDEX(t, c_d_e) */ * from t where c > (select 3)",
+		"select /*+ USE_INDEX(t, c_d_e) */ * from t where c > (select 8 - 5 from dual)",
+	} {
+		comment := Commentf("sql:%s", sql)
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, comment)
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
+		c.Assert(err, IsNil, comment)
+		plan := core.ToString(p)
+		c.Assert(strings.Contains(plan, "Index(t.c_d_e)[(3,+inf]]"), IsTrue, Commentf("sql:%s plan:%s", sql, plan))
+	}
+}
+
+func (s *testPlanSuite) TestPartialIndex(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	tbl := core.MockSignedTable()
+	tbl.Indices = append(tbl.Indices, &model.IndexInfo{
+		Name: model.NewCIStr("p"),
+		Columns: []*model.IndexColumn{
+			{
+				Name:   model.NewCIStr("b"),
+				Length: types.UnspecifiedLength,
+				Offset: 1,
+			},
+		},
+		State:     model.StatePublic,
+		Condition: "b > 10 and b < 100",
+	})
+	is := infoschema.MockInfoSchema([]*model.TableInfo{tbl})
+
+	tests := []struct {
+		sql  string
+		used bool
+	}{
+		// The query filter is subsumed by the filter of the partial index.
+		{sql: "select * from t use index(p) where b > 20 and b < 50", used: true},
+		{sql: "select * from t use index(p) where b < 100 and b > 10 and c = 1", used: true},
+		{sql: "select * from t where b = 15", used: true},
+		// The rows the query filter matches may be missing from the partial index.
+		{sql: "select * from t use index(p) where b > 5 and b < 50", used: false},
+		{sql: "select * from t use index(p) where b > 20", used: false},
+		{sql: "select * from t use index(p) where b = 15 or b = 200", used: false},
+		{sql: "select * from t use index(p)", used: false},
+		{sql: "select * from t where b = 150", used: false},
+	}
+	for _, tt := range tests {
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, Commentf("sql:%s", tt.sql))
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, is)
+		c.Assert(err, IsNil, Commentf("sql:%s", tt.sql))
+		plan := core.ToString(p)
+		c.Assert(strings.Contains(plan, "Index(t.p)"), Equals, tt.used, Commentf("sql:%s plan:%s", tt.sql, plan))
+	}
+}
+
+func (s *testPlanSuite) TestNoIndexHint(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	tests := []string{
+		"select * from t where c = 1",
+		"select f from t where f = 1",
+		"select * from t where c = 1 and d = 2 and e = 3",
+		"select f from t where f > 1 order by f",
+		"select * from t use index(f) where f = 1",
+	}
+	optimize := func(sql string, hintTable string) string {
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, Commentf("sql:%s", sql))
+		stmt.(*ast.SelectStmt).TableHints = []*ast.TableOptimizerHint{{
+			HintName: model.NewCIStr(core.HintNoIndex),
+			Tables:   []ast.HintTable{{TableName: model.NewCIStr(hintTable)}},
+		}}
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
+		c.Assert(err, IsNil, Commentf("sql:%s", sql))
+		return core.ToString(p)
+	}
+	for _, sql := range tests {
+		plan := optimize(sql, "t")
+		c.Assert(strings.Contains(plan, "Table(t)"), IsTrue, Commentf("sql:%s plan:%s", sql, plan))
+		c.Assert(strings.Contains(plan, "Index("), IsFalse, Commentf("sql:%s plan:%s", sql, plan))
+		// The hint of another table doesn't affect the access path of t.
+		plan = optimize(sql, "t2")
+		c.Assert(strings.Contains(plan, "Index("), IsTrue, Commentf("sql:%s plan:%s", sql, plan))
+	}
+}
+
+func (s *testPlanSuite) TestDNFEqualsToInRanges(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	tests := []struct {
+		sql   string
+		inSQL string
+	}{
+		{
+			sql:   "select * from t where c = 1 or c = 3 or c = 2",
+			inSQL: "select * from t where c in (1, 3, 2)",
+		},
+		{
+			sql:   "select * from t where c = 1 or 3 = c or c in (2, 4)",
+			inSQL: "select * from t where c in (1, 3, 2, 4)",
+		},
+		{
+			sql:   "select f from t where f = 1 or f = 2",
+			inSQL: "select f from t where f in (1, 2)",
+		},
+		{
+			sql:   "select * from t where (c = 1 or c = 2) and d = 1",
+			inSQL: "select * from t where c in (1, 2) and d = 1",
+		},
+	}
+	optimize := func(sql string) string {
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, Commentf("sql:%s", sql))
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
+		c.Assert(err, IsNil, Commentf("sql:%s", sql))
+		return core.ToString(p)
+	}
+	for _, tt := range tests {
+		// The disjunction builds the same point ranges as the in list.
+		plan := optimize(tt.sql)
+		c.Assert(plan, Equals, optimize(tt.inSQL), Commentf("sql:%s", tt.sql))
+		c.Assert(strings.Contains(plan, "Index("), IsTrue, Commentf("sql:%s plan:%s", tt.sql, plan))
+	}
+
+}
+
+func (s *testPlanSuite) TestPhysicalPlanFingerprint(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	se, err := session.CreateSession4Test(store)
+	c.Assert(err, IsNil)
+	_, err = se.Execute(context.Background(), "use test")
+	c.Assert(err, IsNil)
+
+	fingerprint := func(sql string) (core.PhysicalPlan, string) {
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil)
+		p, _, err := planner.Optimize(context.TODO(), se, stmt, s.is)
+		c.Assert(err, IsNil)
+		pp, ok := p.(core.PhysicalPlan)
+		c.Assert(ok, IsTrue)
+		return pp, pp.Fingerprint()
+	}
+
+	p, fp := fingerprint("select * from t t1 join t t2 on t1.a = t2.b where t1.c > 1")
+	c.Assert(fp, Not(Equals), "")
+
+	// Structurally identical plans hash equal.
+	_, fp2 := fingerprint("select * from t t1 join t t2 on t1.a = t2.b where t1.c > 1")
+	c.Assert(fp2, Equals, fp)
+
+	// Constants are normalized.
+	_, fp2 = fingerprint("select * from t t1 join t t2 on t1.a = t2.b where t1.c > 10")
+	c.Assert(fp2, Equals, fp)
+
+	// Statistics do not affect the fingerprint.
+	p.Stats().RowCount *= 10
+	c.Assert(p.Fingerprint(), Equals, fp)
+
+	// A different join order hashes differently.
+	_, fp2 = fingerprint("select * from t t2 join t t1 on t1.a = t2.b where t1.c > 1")
+	c.Assert(fp2, Not(Equals), fp)
+
+	// Statements differing only in their literals share the fingerprint.
+	sameShape := []string{
+		"select a from t where c_str = 'abc' and e > 1 limit 10",
+		"select a from t where c_str = 'xyz' and e > 100 limit 10",
+		"select a from t where c_str = '' and e > -5 limit 10",
+	}
+	_, fp = fingerprint(sameShape[0])
+	for _, sql := range sameShape[1:] {
+		_, fp2 = fingerprint(sql)
+		c.Assert(fp2, Equals, fp, Commentf("for %s", sql))
+	}
+	_, fp = fingerprint("select a, b from t where b in (1, 2, 3)")
+	_, fp2 = fingerprint("select a, b from t where b in (4, 5, 6)")
+	c.Assert(fp2, Equals, fp)
+
+	// The same statement on a different table hashes differently.
+	_, fp = fingerprint("select a from t where a > 1")
+	_, fp2 = fingerprint("select a from t2 where a > 1")
+	c.Assert(fp2, Not(Equals), fp)
+	_, fp = fingerprint("select * from t t1 join t t2 on t1.a = t2.a")
+	_, fp2 = fingerprint("select * from t t1 join t2 on t1.a = t2.a")
+	c.Assert(fp2, Not(Equals), fp)
+}
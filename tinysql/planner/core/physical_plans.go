@@ -40,6 +40,7 @@ var (
 	_ PhysicalPlan = &PhysicalHashJoin{}
 	_ PhysicalPlan = &PhysicalMergeJoin{}
 	_ PhysicalPlan = &PhysicalUnionScan{}
+	_ PhysicalPlan = &PhysicalUnionAll{}
 )
 
 // PhysicalTableReader is the table reader in tidb.
@@ -122,6 +123,45 @@ type PhysicalIndexLookUpReader struct {
 	ExtraHandleCol *expression.Column
 }
 
+// GetPhysicalIndexLookUpReader returns PhysicalIndexLookUpReader for logical TiKVDoubleGather.
+// If keepOrder is true, the handle column is appended to the table side to restore the order
+// of the index side, the schema of the reader may contain one more column than `schema` then.
+func (dg *TiKVDoubleGather) GetPhysicalIndexLookUpReader(schema *expression.Schema, stats *property.StatsInfo, keepOrder bool, props ...*property.PhysicalProperty) *PhysicalIndexLookUpReader {
+	ds := dg.Source
+	ts := PhysicalTableScan{
+		Columns:     make([]*model.ColumnInfo, len(ds.Columns)),
+		Table:       ds.tableInfo,
+		TableAsName: ds.TableAsName,
+		DBName:      ds.DBName,
+	}.Init(dg.ctx)
+	copy(ts.Columns, ds.Columns)
+	ts.stats = stats
+	ts.SetSchema(schema.Clone())
+	var extraHandleCol *expression.Column
+	if keepOrder {
+		extraHandleCol, _ = ts.appendExtraHandleCol(ds)
+		// The handle column may have been pruned from the schema of the gather.
+		if ts.schema.ColumnIndex(extraHandleCol) == -1 {
+			if extraHandleCol.ID == model.ExtraHandleID {
+				ts.Columns = append(ts.Columns, model.NewExtraHandleColInfo())
+			} else {
+				ts.Columns = append(ts.Columns, ds.tableInfo.GetPkColInfo())
+			}
+			ts.schema.Append(extraHandleCol)
+		}
+	}
+	reader := PhysicalIndexLookUpReader{tablePlan: ts, ExtraHandleCol: extraHandleCol}.Init(dg.ctx)
+	reader.stats = stats
+	reader.childrenReqProps = props
+	return reader
+}
+
+// SetChildren overrides PhysicalPlan SetChildren interface.
+func (p *PhysicalIndexLookUpReader) SetChildren(children ...PhysicalPlan) {
+	p.indexPlan = children[0]
+	p.IndexPlans = flattenPushDownPlan(p.indexPlan)
+}
+
 // PhysicalIndexScan represents an index scan plan.
 type PhysicalIndexScan struct {
 	physicalSchemaProducer
@@ -342,6 +382,18 @@ type PhysicalTableDual struct {
 	names []*types.FieldName
 }
 
+// PhysicalValues is the physical operator of the VALUES table value constructor.
+type PhysicalValues struct {
+	physicalSchemaProducer
+
+	Lists [][]expression.Expression
+}
+
+// PhysicalUnionAll represents Union All plan.
+type PhysicalUnionAll struct {
+	physicalSchemaProducer
+}
+
 // OutputNames returns the outputting names of each column.
 func (p *PhysicalTableDual) OutputNames() types.NameSlice {
 	return p.names
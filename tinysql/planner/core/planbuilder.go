@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/planner/util"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
 	driver "github.com/pingcap/tidb/types/parser_driver"
@@ -184,6 +185,17 @@ type PlanBuilder struct {
 	//   If it's a join, we pop its children's out then merge them and push the new map to stack.
 	//   If we meet a subquery, it's clearly that it's a independent problem so we just pop one map out when we finish building the subquery.
 	handleHelper *handleColHelper
+
+	// outerSchema and outerNames are the output of the outer query when a subquery is being built,
+	// the columns which can't be found in the subquery are resolved in them. outerColRefs records
+	// the resolved references, the subquery is correlated if it isn't empty.
+	outerSchema  *expression.Schema
+	outerNames   types.NameSlice
+	outerColRefs []*expression.Column
+
+	// statsSnapshot is the statistics read by the statement, it's taken when the
+	// statistics are read for the first time.
+	statsSnapshot *statistics.StatsSnapshot
 }
 
 type handleColHelper struct {
@@ -253,13 +265,15 @@ func (b *PlanBuilder) Build(ctx context.Context, node ast.Node) (Plan, error) {
 		return b.buildInsert(ctx, x)
 	case *ast.SelectStmt:
 		return b.buildSelect(ctx, x)
+	case *ast.UnionStmt:
+		return b.buildUnion(ctx, x)
 	case *ast.ShowStmt:
 		return b.buildShow(ctx, x)
 	case *ast.SetStmt:
 		return b.buildSet(ctx, x)
 	case *ast.AnalyzeTableStmt:
 		return b.buildAnalyze(x)
-	case *ast.UseStmt, *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt:
+	case *ast.UseStmt, *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.KillStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(ctx, x)
@@ -340,6 +354,12 @@ func isPrimaryIndex(indexName model.CIStr) bool {
 	return indexName.L == "primary"
 }
 
+// isNonPublicIndex checks whether the table has an index with the name which isn't public yet.
+func isNonPublicIndex(tblInfo *model.TableInfo, indexName model.CIStr) bool {
+	idx := tblInfo.FindIndexByName(indexName.L)
+	return idx != nil && idx.State != model.StatePublic
+}
+
 func (b *PlanBuilder) getPossibleAccessPaths(indexHints []*ast.IndexHint, tbl table.Table, dbName, tblName model.CIStr) ([]*util.AccessPath, error) {
 	tblInfo := tbl.Meta()
 	publicPaths := make([]*util.AccessPath, 0, len(tblInfo.Indices)+2)
@@ -350,7 +370,7 @@ func (b *PlanBuilder) getPossibleAccessPaths(indexHints []*ast.IndexHint, tbl ta
 		}
 	}
 
-	hasScanHint, hasUseOrForce := false, false
+	hasScanHint, hasUseOrForce, ignoreAll := false, false, false
 	available := make([]*util.AccessPath, 0, len(publicPaths))
 	ignored := make([]*util.AccessPath, 0, len(publicPaths))
 
@@ -371,6 +391,11 @@ func (b *PlanBuilder) getPossibleAccessPaths(indexHints []*ast.IndexHint, tbl ta
 
 		hasScanHint = true
 
+		// It's the NO_INDEX hint, which overrides all the other index hints.
+		if hint.IndexNames == nil && hint.HintType == ast.HintIgnore {
+			ignoreAll = true
+			continue
+		}
 		// It is syntactically valid to omit index_list for USE INDEX, which means “use no indexes”.
 		// Omitting index_list for FORCE INDEX or IGNORE INDEX is a syntax error.
 		// See https://dev.mysql.com/doc/refman/8.0/en/index-hints.html.
@@ -383,6 +408,14 @@ func (b *PlanBuilder) getPossibleAccessPaths(indexHints []*ast.IndexHint, tbl ta
 		}
 		for _, idxName := range hint.IndexNames {
 			path := getPathByIndexName(publicPaths, idxName, tblInfo)
+			if path == nil && hint.HintType != ast.HintIgnore && isNonPublicIndex(tblInfo, idxName) {
+				// The index is still being built or dropped by online DDL, so it can't be read.
+				// Don't fail the query, fall back to the table scan instead.
+				hasUseOrForce = true
+				errMsg := fmt.Sprintf("Index %s of table %s is not public, fall back to table scan", idxName.O, tblInfo.Name.O)
+				b.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrInternal.GenWithStack(errMsg))
+				continue
+			}
 			if path == nil {
 				err := ErrKeyDoesNotExist.GenWithStackByArgs(idxName, tblInfo.Name)
 				// if hint is from comment-style sql hints, we should throw a warning instead of error.
@@ -413,8 +446,8 @@ func (b *PlanBuilder) getPossibleAccessPaths(indexHints []*ast.IndexHint, tbl ta
 
 	// If we have got "FORCE" or "USE" index hint but got no available index,
 	// we have to use table scan.
-	if len(available) == 0 {
-		available = append(available, &util.AccessPath{IsTablePath: true})
+	if len(available) == 0 || ignoreAll {
+		available = []*util.AccessPath{{IsTablePath: true}}
 	}
 	return available, nil
 }
@@ -607,7 +640,7 @@ func (b *PlanBuilder) buildShow(ctx context.Context, show *ast.ShowStmt) (Plan,
 		},
 	}.Init(b.ctx)
 	switch show.Tp {
-	case ast.ShowTables:
+	case ast.ShowTables, ast.ShowTableStatus:
 		if p.DBName == "" {
 			return nil, ErrNoDB
 		}
@@ -735,6 +768,14 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 		}
 	}
 
+	if len(insert.OnDuplicate) > 0 {
+		// Branch for `INSERT ... ON DUPLICATE KEY UPDATE ...`.
+		err := b.buildOnDuplicateOfInsert(ctx, insert, insertPlan, mockTablePlan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err := insertPlan.ResolveIndices()
 	return insertPlan, err
 }
@@ -801,6 +842,28 @@ func (b *PlanBuilder) buildSetValuesOfInsert(ctx context.Context, insert *ast.In
 	return nil
 }
 
+func (b *PlanBuilder) buildOnDuplicateOfInsert(ctx context.Context, insert *ast.InsertStmt, insertPlan *Insert, mockTablePlan *LogicalTableDual) error {
+	for _, assign := range insert.OnDuplicate {
+		idx, err := expression.FindFieldName(insertPlan.tableColNames, assign.Column)
+		if err != nil {
+			return err
+		}
+		if idx < 0 {
+			return ErrUnknownColumn.GenWithStackByArgs(assign.Column.OrigColName(), "field list")
+		}
+		expr, err := b.rewriteInsertOnDuplicateUpdate(ctx, assign.Expr, mockTablePlan, insertPlan)
+		if err != nil {
+			return err
+		}
+		insertPlan.OnDuplicate = append(insertPlan.OnDuplicate, &expression.Assignment{
+			Col:     insertPlan.tableSchema.Columns[idx],
+			ColName: assign.Column.Name,
+			Expr:    expr,
+		})
+	}
+	return nil
+}
+
 func (b *PlanBuilder) buildValuesListOfInsert(ctx context.Context, insert *ast.InsertStmt, insertPlan *Insert, mockTablePlan *LogicalTableDual, checkRefColumn func(n ast.Node) ast.Node) error {
 	affectedValuesCols, err := b.getAffectCols(insert, insertPlan)
 	if err != nil {
@@ -956,12 +1019,20 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema, outputNames []
 		if s.Full {
 			names = append(names, "Table_type")
 		}
+	case ast.ShowTableStatus:
+		names = []string{"Name", "Engine", "Rows", "Avg_row_length", "Data_length", "Collation", "Comment"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong,
+			mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowVariables:
 		names = []string{"Variable_name", "Value"}
 	case ast.ShowCreateTable:
 		names = []string{"Table", "Create Table"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowProcessList:
+		names = []string{"Id", "db", "Command", "Time", "State", "Info"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeLong, mysql.TypeVarchar, mysql.TypeVarchar}
 	}
 
 	schema = expression.NewSchema(make([]*expression.Column, 0, len(names))...)
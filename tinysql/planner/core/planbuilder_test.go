@@ -17,10 +17,13 @@ import (
 	"context"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/util"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/types"
 )
 
@@ -82,6 +85,82 @@ func (s *testPlanBuilderSuite) TestGetPathByIndexName(c *C) {
 	c.Assert(path, IsNil)
 }
 
+func (s *testPlanBuilderSuite) TestNonPublicIndexAccessPaths(c *C) {
+	newIndex := func(name string, state model.SchemaState) *model.IndexInfo {
+		return &model.IndexInfo{
+			Name:    model.NewCIStr(name),
+			Columns: []*model.IndexColumn{{Name: model.NewCIStr("a"), Offset: 0, Length: types.UnspecifiedLength}},
+			State:   state,
+		}
+	}
+	tblInfo := &model.TableInfo{
+		ID:   1,
+		Name: model.NewCIStr("t"),
+		Columns: []*model.ColumnInfo{
+			{Name: model.NewCIStr("a"), Offset: 0, State: model.StatePublic, FieldType: *types.NewFieldType(mysql.TypeLong)},
+		},
+		Indices: []*model.IndexInfo{
+			newIndex("idx_public", model.StatePublic),
+			newIndex("idx_reorg", model.StateWriteReorganization),
+			newIndex("idx_delete", model.StateDeleteOnly),
+		},
+	}
+	tbl := tables.MockTableFromMeta(tblInfo)
+	c.Assert(tbl, NotNil)
+	dbName := model.NewCIStr("test")
+
+	builder := NewPlanBuilder(MockContext(), nil)
+	paths, err := builder.getPossibleAccessPaths(nil, tbl, dbName, tblInfo.Name)
+	c.Assert(err, IsNil)
+	c.Assert(paths, HasLen, 2)
+	c.Assert(paths[0].IsTablePath, IsTrue)
+	c.Assert(paths[1].Index.Name.L, Equals, "idx_public")
+
+	// Forcing an index which isn't public falls back to the table scan with a warning.
+	for _, name := range []string{"idx_reorg", "idx_delete"} {
+		builder = NewPlanBuilder(MockContext(), nil)
+		hints := []*ast.IndexHint{{
+			IndexNames: []model.CIStr{model.NewCIStr(name)},
+			HintType:   ast.HintForce,
+			HintScope:  ast.HintForScan,
+		}}
+		paths, err = builder.getPossibleAccessPaths(hints, tbl, dbName, tblInfo.Name)
+		c.Assert(err, IsNil)
+		c.Assert(paths, HasLen, 1)
+		c.Assert(paths[0].IsTablePath, IsTrue)
+		c.Assert(builder.ctx.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	}
+
+	// An index which doesn't exist at all is still an error.
+	hints := []*ast.IndexHint{{
+		IndexNames: []model.CIStr{model.NewCIStr("idx_none")},
+		HintType:   ast.HintUse,
+		HintScope:  ast.HintForScan,
+	}}
+	_, err = builder.getPossibleAccessPaths(hints, tbl, dbName, tblInfo.Name)
+	c.Assert(err, NotNil)
+}
+
+func (s *testPlanBuilderSuite) TestStatsSnapshot(c *C) {
+	ctx := MockContext()
+	statsHandle := domain.GetDomain(ctx).StatsHandle()
+	tblInfo := MockSignedTable()
+	statsTbl := statsHandle.GetTableStats(tblInfo)
+
+	builder := NewPlanBuilder(ctx, nil)
+	c.Assert(builder.getStatsTable(tblInfo, tblInfo.ID), Equals, statsTbl)
+
+	// The stats are updated concurrently while the statement is being planned.
+	statsHandle.Clear()
+	newStatsTbl := statsHandle.GetTableStats(tblInfo)
+	c.Assert(newStatsTbl, Not(Equals), statsTbl)
+	c.Assert(builder.getStatsTable(tblInfo, tblInfo.ID), Equals, statsTbl)
+
+	// The next statement reads the updated stats.
+	builder = NewPlanBuilder(ctx, nil)
+	c.Assert(builder.getStatsTable(tblInfo, tblInfo.ID), Equals, newStatsTbl)
+}
+
 func (s *testPlanBuilderSuite) TestRewriterPool(c *C) {
 	builder := NewPlanBuilder(MockContext(), nil)
 
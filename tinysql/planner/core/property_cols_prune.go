@@ -79,6 +79,11 @@ func (p *TiKVSingleGather) PreparePossibleProperties(schema *expression.Schema,
 	return childrenProperties[0]
 }
 
+// PreparePossibleProperties implements LogicalPlan PreparePossibleProperties interface.
+func (p *TiKVDoubleGather) PreparePossibleProperties(schema *expression.Schema, childrenProperties ...[][]*expression.Column) [][]*expression.Column {
+	return childrenProperties[0]
+}
+
 // PreparePossibleProperties implements LogicalPlan PreparePossibleProperties interface.
 func (p *LogicalSelection) PreparePossibleProperties(schema *expression.Schema, childrenProperties ...[][]*expression.Column) [][]*expression.Column {
 	return childrenProperties[0]
@@ -155,7 +160,7 @@ func (p *LogicalJoin) PreparePossibleProperties(schema *expression.Schema, child
 	// TODO: We should consider properties propagation.
 	p.leftProperties = leftProperties
 	p.rightProperties = rightProperties
-	if p.JoinType == LeftOuterJoin {
+	if p.JoinType == LeftOuterJoin || p.JoinType == SemiJoin {
 		rightProperties = nil
 	} else if p.JoinType == RightOuterJoin {
 		leftProperties = nil
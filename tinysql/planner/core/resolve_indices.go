@@ -249,6 +249,12 @@ func (p *basePhysicalAgg) ResolveIndices() (err error) {
 				return err
 			}
 		}
+		for _, item := range aggFun.OrderByItems {
+			item.Expr, err = item.Expr.ResolveIndices(p.children[0].Schema())
+			if err != nil {
+				return err
+			}
+		}
 	}
 	for i, item := range p.GroupByItems {
 		p.GroupByItems[i], err = item.ResolveIndices(p.children[0].Schema())
@@ -306,6 +312,17 @@ func (p *Insert) ResolveIndices() (err error) {
 			return err
 		}
 	}
+	for _, assign := range p.OnDuplicate {
+		newCol, err := assign.Col.ResolveIndices(p.tableSchema)
+		if err != nil {
+			return err
+		}
+		assign.Col = newCol.(*expression.Column)
+		assign.Expr, err = assign.Expr.ResolveIndices(p.tableSchema)
+		if err != nil {
+			return err
+		}
+	}
 	return
 }
 
@@ -117,6 +117,9 @@ func (p *LogicalProjection) BuildKeyInfo(selfSchema *expression.Schema, childSch
 func (p *LogicalJoin) BuildKeyInfo(selfSchema *expression.Schema, childSchema []*expression.Schema) {
 	p.logicalSchemaProducer.BuildKeyInfo(selfSchema, childSchema)
 	switch p.JoinType {
+	case SemiJoin:
+		// The semi join outputs each row of the left child at most once.
+		selfSchema.Keys = append(selfSchema.Keys, childSchema[0].Keys...)
 	case InnerJoin, LeftOuterJoin, RightOuterJoin:
 		// If there is no equal conditions, then cartesian product can't be prevented and unique key information will destroy.
 		if len(p.EqualConditions) == 0 {
@@ -236,6 +239,11 @@ func (tg *TiKVSingleGather) BuildKeyInfo(selfSchema *expression.Schema, childSch
 	selfSchema.Keys = childSchema[0].Keys
 }
 
+// BuildKeyInfo implements LogicalPlan BuildKeyInfo interface.
+func (dg *TiKVDoubleGather) BuildKeyInfo(selfSchema *expression.Schema, childSchema []*expression.Schema) {
+	selfSchema.Keys = childSchema[0].Keys
+}
+
 func (*buildKeySolver) name() string {
 	return "build_keys"
 }
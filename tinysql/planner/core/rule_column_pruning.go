@@ -106,6 +106,9 @@ func (la *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column)
 	var selfUsedCols []*expression.Column
 	for _, aggrFunc := range la.AggFuncs {
 		selfUsedCols = expression.ExtractColumnsFromExpressions(selfUsedCols, aggrFunc.Args, nil)
+		for _, item := range aggrFunc.OrderByItems {
+			selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+		}
 	}
 	if len(la.AggFuncs) == 0 {
 		// If all the aggregate functions are pruned, we should add an aggregate function to keep the correctness.
@@ -212,6 +215,55 @@ func (p *LogicalTableDual) PruneColumns(parentUsedCols []*expression.Column) err
 	return nil
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalValues) PruneColumns(parentUsedCols []*expression.Column) error {
+	used := getUsedList(parentUsedCols, p.Schema())
+
+	for i := len(used) - 1; i >= 0; i-- {
+		if !used[i] {
+			p.schema.Columns = append(p.schema.Columns[:i], p.schema.Columns[i+1:]...)
+			p.names = append(p.names[:i], p.names[i+1:]...)
+			for j, list := range p.Lists {
+				p.Lists[j] = append(list[:i], list[i+1:]...)
+			}
+		}
+	}
+	return nil
+}
+
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalUnionAll) PruneColumns(parentUsedCols []*expression.Column) error {
+	used := getUsedList(parentUsedCols, p.schema)
+	hasBeenUsed := false
+	for i := range used {
+		hasBeenUsed = hasBeenUsed || used[i]
+		if hasBeenUsed {
+			break
+		}
+	}
+	if !hasBeenUsed {
+		parentUsedCols = make([]*expression.Column, len(p.schema.Columns))
+		copy(parentUsedCols, p.schema.Columns)
+	}
+	for _, child := range p.Children() {
+		err := child.PruneColumns(parentUsedCols)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasBeenUsed {
+		// keep the schema of LogicalUnionAll same as its children's
+		used := getUsedList(p.children[0].Schema().Columns, p.schema)
+		for i := len(used) - 1; i >= 0; i-- {
+			if !used[i] {
+				p.schema.Columns = append(p.schema.Columns[:i], p.schema.Columns[i+1:]...)
+			}
+		}
+	}
+	return nil
+}
+
 func (p *LogicalJoin) extractUsedCols(parentUsedCols []*expression.Column) (leftCols []*expression.Column, rightCols []*expression.Column) {
 	for _, eqCond := range p.EqualConditions {
 		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(eqCond)...)
@@ -240,6 +292,10 @@ func (p *LogicalJoin) extractUsedCols(parentUsedCols []*expression.Column) (left
 func (p *LogicalJoin) mergeSchema() {
 	lChild := p.children[0]
 	rChild := p.children[1]
+	if p.JoinType == SemiJoin {
+		p.schema = lChild.Schema().Clone()
+		return
+	}
 	p.schema = expression.MergeSchema(lChild.Schema(), rChild.Schema())
 }
 
@@ -112,7 +112,10 @@ func (pe *projectionEliminator) optimize(ctx context.Context, lp LogicalPlan) (L
 func (pe *projectionEliminator) eliminate(p LogicalPlan, replace map[string]*expression.Column, canEliminate bool) LogicalPlan {
 	proj, isProj := p.(*LogicalProjection)
 	childFlag := canEliminate
-	if _, isAgg := p.(*LogicalAggregation); isAgg || isProj {
+	if _, isUnion := p.(*LogicalUnionAll); isUnion {
+		// The projections below a union map the children onto the columns of the union.
+		childFlag = false
+	} else if _, isAgg := p.(*LogicalAggregation); isAgg || isProj {
 		childFlag = true
 	}
 	for i, child := range p.Children() {
@@ -129,11 +132,8 @@ func (pe *projectionEliminator) eliminate(p LogicalPlan, replace map[string]*exp
 	}
 	p.replaceExprColumns(replace)
 	if isProj {
-		if child, ok := p.Children()[0].(*LogicalProjection); ok && !ExprsHasSideEffects(child.Exprs) {
-			for i := range proj.Exprs {
-				proj.Exprs[i] = ReplaceColumnOfExpr(proj.Exprs[i], child, child.Schema())
-			}
-			p.Children()[0] = child.Children()[0]
+		if child, ok := p.Children()[0].(*LogicalProjection); ok && canProjectionBeMerged(proj, child) {
+			mergeProjection(proj, child)
 		}
 	}
 
@@ -147,6 +147,41 @@ func (pe *projectionEliminator) eliminate(p LogicalPlan, replace map[string]*exp
 	return p.Children()[0]
 }
 
+// canProjectionBeMerged checks whether the child projection can be merged into
+// its parent projection. It returns false if the child has side effects, or if
+// a non-column output of the child is referenced more than once by the parent,
+// because composing them would evaluate the same expression repeatedly.
+func canProjectionBeMerged(parent, child *LogicalProjection) bool {
+	if ExprsHasSideEffects(child.Exprs) {
+		return false
+	}
+	refCnt := make([]int, len(child.Exprs))
+	for _, col := range expression.ExtractColumnsFromExpressions(nil, parent.Exprs, nil) {
+		idx := child.Schema().ColumnIndex(col)
+		if idx == -1 || idx >= len(child.Exprs) {
+			continue
+		}
+		refCnt[idx]++
+		switch child.Exprs[idx].(type) {
+		case *expression.Column, *expression.Constant:
+		default:
+			if refCnt[idx] > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeProjection composes the expressions of the parent projection with the
+// ones of its child projection, and removes the child from the plan.
+func mergeProjection(parent, child *LogicalProjection) {
+	for i := range parent.Exprs {
+		parent.Exprs[i] = ReplaceColumnOfExpr(parent.Exprs[i], child, child.Schema())
+	}
+	parent.Children()[0] = child.Children()[0]
+}
+
 // ReplaceColumnOfExpr replaces column of expression by another LogicalProjection.
 func ReplaceColumnOfExpr(expr expression.Expression, proj *LogicalProjection, schema *expression.Schema) expression.Expression {
 	switch v := expr.(type) {
@@ -148,7 +148,7 @@ func (p *LogicalJoin) PredicatePushDown(predicates []expression.Expression) (ret
 		p.LeftConditions = nil
 		ret = append(expression.ScalarFuncs2Exprs(equalCond), otherCond...)
 		ret = append(ret, leftPushCond...)
-	case InnerJoin:
+	case InnerJoin, SemiJoin:
 		tempCond := make([]expression.Expression, 0, len(p.LeftConditions)+len(p.RightConditions)+len(p.EqualConditions)+len(p.OtherConditions)+len(predicates))
 		tempCond = append(tempCond, p.LeftConditions...)
 		tempCond = append(tempCond, p.RightConditions...)
@@ -397,6 +397,47 @@ func (p *LogicalLimit) PredicatePushDown(predicates []expression.Expression) ([]
 	return predicates, p
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+func (p *LogicalUnionAll) PredicatePushDown(predicates []expression.Expression) (ret []expression.Expression, retPlan LogicalPlan) {
+	// The children output their own types and the union converts them to its own, so a condition
+	// can only be evaluated below the union when it compares the same values on both sides.
+	canBePushed := make([]expression.Expression, 0, len(predicates))
+	for _, cond := range predicates {
+		if p.canPushDownCond(cond) {
+			canBePushed = append(canBePushed, cond)
+		} else {
+			ret = append(ret, cond)
+		}
+	}
+	for i, proj := range p.children {
+		newExprs := make([]expression.Expression, 0, len(canBePushed))
+		newExprs = append(newExprs, canBePushed...)
+		retCond, newChild := proj.PredicatePushDown(newExprs)
+		addSelection(p, newChild, retCond, i)
+	}
+	return ret, p
+}
+
+// canPushDownCond checks whether every column used by cond has the same evaluation type,
+// signedness and collation in the union as in all of its children.
+func (p *LogicalUnionAll) canPushDownCond(cond expression.Expression) bool {
+	for _, col := range expression.ExtractColumns(cond) {
+		idx := p.schema.ColumnIndex(col)
+		if idx < 0 {
+			return false
+		}
+		dstTp := p.schema.Columns[idx].RetType
+		for _, child := range p.children {
+			srcTp := child.Schema().Columns[idx].RetType
+			if srcTp.EvalType() != dstTp.EvalType() || srcTp.Collate != dstTp.Collate ||
+				mysql.HasUnsignedFlag(srcTp.Flag) != mysql.HasUnsignedFlag(dstTp.Flag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // deriveOtherConditions given a LogicalJoin, check the OtherConditions to see if we can derive more
 // conditions for left/right child pushdown.
 func deriveOtherConditions(p *LogicalJoin, deriveLeft bool, deriveRight bool) (leftCond []expression.Expression,
@@ -82,6 +82,16 @@ func (p *LogicalLimit) convertToTopN() *LogicalTopN {
 }
 
 func (p *LogicalLimit) pushDownTopN(topN *LogicalTopN) LogicalPlan {
+	if topN != nil && topN.isLimit() {
+		// The outer limit takes its rows from the output of this one, so they can be fused: the offsets add up
+		// and the count is bounded by the rows left in this limit after skipping the outer offset.
+		count := uint64(0)
+		if p.Count > topN.Offset {
+			count = mathutil.MinUint64(p.Count-topN.Offset, topN.Count)
+		}
+		p.Offset, p.Count = p.Offset+topN.Offset, count
+		return p.pushDownTopN(nil)
+	}
 	child := p.children[0].pushDownTopN(p.convertToTopN())
 	if topN != nil {
 		return topN.setChild(child)
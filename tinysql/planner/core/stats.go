@@ -44,6 +44,34 @@ func (p *LogicalTableDual) DeriveStats(childStats []*property.StatsInfo, selfSch
 	return p.stats, nil
 }
 
+// DeriveStats implement LogicalPlan DeriveStats interface.
+func (p *LogicalUnionAll) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
+	p.stats = &property.StatsInfo{
+		Cardinality: make([]float64, selfSchema.Len()),
+	}
+	for _, childProfile := range childStats {
+		p.stats.RowCount += childProfile.RowCount
+		for i := range p.stats.Cardinality {
+			p.stats.Cardinality[i] += childProfile.Cardinality[i]
+		}
+	}
+	return p.stats, nil
+}
+
+// DeriveStats implement LogicalPlan DeriveStats interface.
+func (p *LogicalValues) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
+	rowCount := float64(len(p.Lists))
+	profile := &property.StatsInfo{
+		RowCount:    rowCount,
+		Cardinality: make([]float64, selfSchema.Len()),
+	}
+	for i := range profile.Cardinality {
+		profile.Cardinality[i] = rowCount
+	}
+	p.stats = profile
+	return p.stats, nil
+}
+
 // DeriveStats implement LogicalPlan DeriveStats interface.
 func (p *LogicalMemTable) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
 	statsTable := statistics.PseudoTable(p.tableInfo)
@@ -171,18 +199,28 @@ func (ds *DataSource) deriveStatsByFilter(conds expression.CNFExprs, filledPaths
 func (ds *DataSource) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
 	ds.initStats()
 	// PushDownNot here can convert query 'not (a != 1)' to 'a = 1'.
+	// DNFEqualsToIn here can convert query 'a = 1 or a = 2' to 'a in (1, 2)'.
 	for i, expr := range ds.pushedDownConds {
-		ds.pushedDownConds[i] = expression.PushDownNot(ds.ctx, expr)
+		ds.pushedDownConds[i] = expression.DNFEqualsToIn(ds.ctx, expression.PushDownNot(ds.ctx, expr))
 	}
+	usablePaths := ds.possibleAccessPaths[:0]
 	for _, path := range ds.possibleAccessPaths {
-		if path.IsTablePath {
-			continue
-		}
-		err := ds.fillIndexPath(path, ds.pushedDownConds)
-		if err != nil {
-			return nil, err
+		if !path.IsTablePath {
+			usable, err := ds.fillIndexPath(path, ds.pushedDownConds)
+			if err != nil {
+				return nil, err
+			}
+			if !usable {
+				continue
+			}
 		}
+		usablePaths = append(usablePaths, path)
+	}
+	// If all the forced indexes can't be used, we have to use table scan.
+	if len(usablePaths) == 0 {
+		usablePaths = append(usablePaths, &util.AccessPath{IsTablePath: true})
 	}
+	ds.possibleAccessPaths = usablePaths
 	ds.stats = ds.deriveStatsByFilter(ds.pushedDownConds, ds.possibleAccessPaths)
 	for _, path := range ds.possibleAccessPaths {
 		if path.IsTablePath {
@@ -303,6 +341,30 @@ func getCardinality(cols []*expression.Column, schema *expression.Schema, profil
 	return cardinality
 }
 
+// getGroupCount estimates the number of the distinct groups of the columns. The columns are assumed to be independent,
+// so it's the product of their cardinalities, and it can't be larger than the row count. The pseudo cardinalities are
+// a fixed fraction of the row count and their product easily reaches it, so fall back to `getCardinality` for them.
+func getGroupCount(cols []*expression.Column, schema *expression.Schema, profile *property.StatsInfo) float64 {
+	if profile.StatsVersion == statistics.PseudoVersion {
+		return getCardinality(cols, schema, profile)
+	}
+	indices := schema.ColumnsIndices(cols)
+	if indices == nil {
+		logutil.BgLogger().Error("column not found in schema", zap.Any("columns", cols), zap.String("schema", schema.String()))
+		return 1.0
+	}
+	groupCount := 1.0
+	counted := make(map[int]struct{}, len(indices))
+	for _, idx := range indices {
+		if _, ok := counted[idx]; ok {
+			continue
+		}
+		counted[idx] = struct{}{}
+		groupCount *= math.Max(profile.Cardinality[idx], 1.0)
+	}
+	return math.Max(math.Min(groupCount, profile.RowCount), 1.0)
+}
+
 // DeriveStats implement LogicalPlan DeriveStats interface.
 func (p *LogicalProjection) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
 	childProfile := childStats[0]
@@ -325,7 +387,7 @@ func (la *LogicalAggregation) DeriveStats(childStats []*property.StatsInfo, self
 		cols := expression.ExtractColumns(gbyExpr)
 		gbyCols = append(gbyCols, cols...)
 	}
-	cardinality := getCardinality(gbyCols, childSchema[0], childProfile)
+	cardinality := getGroupCount(gbyCols, childSchema[0], childProfile)
 	la.stats = &property.StatsInfo{
 		RowCount:    cardinality,
 		Cardinality: make([]float64, selfSchema.Len()),
@@ -347,6 +409,10 @@ func (la *LogicalAggregation) DeriveStats(childStats []*property.StatsInfo, self
 // every matched bucket.
 func (p *LogicalJoin) DeriveStats(childStats []*property.StatsInfo, selfSchema *expression.Schema, childSchema []*expression.Schema) (*property.StatsInfo, error) {
 	leftProfile, rightProfile := childStats[0], childStats[1]
+	if p.JoinType == SemiJoin {
+		p.stats = leftProfile.Scale(selectionFactor)
+		return p.stats, nil
+	}
 	helper := &fullJoinRowCountHelper{
 		cartesian:     0 == len(p.EqualConditions),
 		leftProfile:   leftProfile,
@@ -393,5 +459,38 @@ func (h *fullJoinRowCountHelper) estimate() float64 {
 	leftKeyCardinality := getCardinality(h.leftJoinKeys, h.leftSchema, h.leftProfile)
 	rightKeyCardinality := getCardinality(h.rightJoinKeys, h.rightSchema, h.rightProfile)
 	count := h.leftProfile.RowCount * h.rightProfile.RowCount / math.Max(leftKeyCardinality, rightKeyCardinality)
-	return count
+	return count * h.skewFactor()
+}
+
+// skewFactor is the ratio of the join row count estimated by the histograms of the join keys to the one estimated
+// by assuming the values are uniformly distributed, which is how estimate works on the cardinalities. It corrects
+// the estimation when the keys are skewed. It's 1 if the join has multiple keys or the histograms are unavailable.
+func (h *fullJoinRowCountHelper) skewFactor() float64 {
+	if len(h.leftJoinKeys) != 1 {
+		return 1
+	}
+	leftHist := getColumnHistogram(h.leftProfile, h.leftJoinKeys[0])
+	rightHist := getColumnHistogram(h.rightProfile, h.rightJoinKeys[0])
+	if leftHist == nil || rightHist == nil || leftHist.Tp.EvalType() != rightHist.Tp.EvalType() {
+		return 1
+	}
+	leftNotNull := leftHist.TotalRowCount() - float64(leftHist.NullCount)
+	rightNotNull := rightHist.TotalRowCount() - float64(rightHist.NullCount)
+	uniform := leftNotNull * rightNotNull / math.Max(float64(leftHist.NDV), float64(rightHist.NDV))
+	if uniform <= 0 {
+		return 1
+	}
+	return statistics.EqualJoinRowCount(leftHist, rightHist) / uniform
+}
+
+// getColumnHistogram returns the histogram of the column in the stats, it returns nil if the histogram is unavailable.
+func getColumnHistogram(profile *property.StatsInfo, col *expression.Column) *statistics.Histogram {
+	if profile.HistColl == nil || profile.HistColl.Pseudo {
+		return nil
+	}
+	c, ok := profile.HistColl.Columns[col.UniqueID]
+	if !ok || c.IsInvalid(nil, false) || c.NDV <= 0 {
+		return nil
+	}
+	return &c.Histogram
 }
@@ -80,6 +80,8 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			id = "MergeRightOuterJoin"
 		case InnerJoin:
 			id = "MergeInnerJoin"
+		case SemiJoin:
+			id = "MergeSemiJoin"
 		}
 		str = id + "{" + strings.Join(children, "->") + "}"
 		for i := range x.LeftJoinKeys {
@@ -102,7 +104,11 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		idx := idxs[last]
 		children := strs[idx:]
 		strs = strs[:idx]
-		str = "Join{" + strings.Join(children, "->") + "}"
+		id := "Join"
+		if x.JoinType == SemiJoin {
+			id = "SemiJoin"
+		}
+		str = id + "{" + strings.Join(children, "->") + "}"
 		idxs = idxs[:last]
 		for _, eq := range x.EqualConditions {
 			l := eq.GetArgs()[0].String()
@@ -127,6 +133,15 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = fmt.Sprintf("TopN(%v,%d,%d)", x.ByItems, x.Offset, x.Count)
 	case *LogicalTableDual, *PhysicalTableDual:
 		str = "Dual"
+	case *LogicalValues, *PhysicalValues:
+		str = "Values"
+	case *LogicalUnionAll, *PhysicalUnionAll:
+		last := len(idxs) - 1
+		idx := idxs[last]
+		children := strs[idx:]
+		strs = strs[:idx]
+		str = "UnionAll{" + strings.Join(children, "->") + "}"
+		idxs = idxs[:last]
 	case *PhysicalHashAgg:
 		str = "HashAgg"
 	case *LogicalAggregation:
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // task is a new version of `PhysicalPlanInfo`. It stores cost information for a task.
@@ -137,6 +138,31 @@ func (t *copTask) finishIndexPlan() {
 	t.cst += cnt * rowSize * sessVars.ScanFactor
 }
 
+// doubleReadSeekCost computes the IO cost of seeking the handles read from the index in the table.
+func (t *copTask) doubleReadSeekCost(indexRows float64) float64 {
+	sessVars := t.indexPlan.SCtx().GetSessionVars()
+	seeks := DoubleReadSeekCount(indexRows, float64(t.tblColHists.Count), sessVars.IndexLookupSize)
+	return seeks * sessVars.SeekFactor
+}
+
+// DoubleReadSeekCount estimates the number of seeks on the table side of an index lookup.
+// Handles of a batch are sorted and adjacent handles are merged into one range, so assuming the
+// handles are distributed uniformly, a handle starts a new range unless the handle before it is
+// also selected, i.e. the number of seeks is indexRows * (1 - selectivity). It makes an index lookup
+// on a non-selective index more expensive than a full table scan. Every batch of handles needs at
+// least one seek, so the seeks of a full range lookup are never free.
+func DoubleReadSeekCount(indexRows, tableRows float64, batchSize int) float64 {
+	if indexRows <= 0 {
+		return 0
+	}
+	minSeeks := math.Ceil(indexRows / float64(batchSize))
+	if tableRows <= 0 {
+		return minSeeks
+	}
+	selectivity := math.Min(indexRows/tableRows, 1)
+	return math.Max(indexRows*(1-selectivity), minSeeks)
+}
+
 func (p *basePhysicalPlan) attach2Task(tasks ...task) task {
 	t := finishCopTask(p.ctx, tasks[0].copy())
 	return attachPlan2Task(p.self, t)
@@ -306,6 +332,8 @@ func finishCopTask(ctx sessionctx.Context, task task) task {
 		// Add cost of worker goroutines in index lookup.
 		numTblWorkers := float64(sessVars.IndexLookupConcurrency)
 		newTask.cst += (numTblWorkers + 1) * sessVars.ConcurrencyFactor
+		// Add cost of seeking the handles on the table side.
+		newTask.cst += t.doubleReadSeekCost(indexRows) / copIterWorkers
 		// When building table reader executor for each batch, we would sort the handles. CPU
 		// cost of sort is:
 		// CPUFactor * batchSize * Log2(batchSize) * (indexRows / batchSize)
@@ -409,6 +437,19 @@ func (p *PhysicalLimit) attach2Task(tasks ...task) task {
 	return attachPlan2Task(p, t)
 }
 
+func (p *PhysicalUnionAll) attach2Task(tasks ...task) task {
+	t := &rootTask{p: p}
+	childPlans := make([]PhysicalPlan, 0, len(tasks))
+	for _, task := range tasks {
+		task = finishCopTask(p.ctx, task)
+		// Children of UnionExec are executed one by one.
+		t.cst += task.cost()
+		childPlans = append(childPlans, task.plan())
+	}
+	p.SetChildren(childPlans...)
+	return t
+}
+
 // GetCost computes cost of TopN operator itself.
 func (p *PhysicalTopN) GetCost(count float64, isRoot bool) float64 {
 	heapSize := float64(p.Offset + p.Count)
@@ -433,9 +474,17 @@ func (p *PhysicalTopN) GetCost(count float64, isRoot bool) float64 {
 }
 
 // canPushDown checks if this topN can be pushed down. If each of the expression can be converted to pb, it can be pushed.
+// The coprocessor sorts strings byte by byte, so sorting by a string under a case-insensitive collation can't be pushed.
+// The coprocessor always treats NULLs as the smallest values, so an explicit NULL order can't be pushed either.
 func (p *PhysicalTopN) canPushDown() bool {
 	exprs := make([]expression.Expression, 0, len(p.ByItems))
 	for _, item := range p.ByItems {
+		if ft := item.Expr.GetType(); ft.EvalType() == types.ETString && collate.IsCICollation(ft.Collate) {
+			return false
+		}
+		if item.NullOrder != ast.NullOrderDefault {
+			return false
+		}
 		exprs = append(exprs, item.Expr)
 	}
 	_, _, remained := expression.ExpressionsToPB(p.ctx.GetSessionVars().StmtCtx, exprs, p.ctx.GetClient())
@@ -594,6 +643,10 @@ func BuildFinalModeAggregation(
 			args = append(args, partialSchema.Columns[partialCursor])
 			partialCursor++
 		}
+		if finalAggFunc.Name == ast.AggFuncGroupConcat {
+			// The partial results are concatenated with the same separator.
+			args = append(args, aggFunc.Args[len(aggFunc.Args)-1])
+		}
 		finalAggFunc.Args = args
 		finalAggFunc.Mode = aggregation.FinalMode
 		finalAggFunc.RetTp = aggFunc.RetTp
@@ -136,6 +136,9 @@ func (s *baseSchemaProducer) setSchemaAndNames(schema *expression.Schema, names
 
 func buildLogicalJoinSchema(joinType JoinType, join LogicalPlan) *expression.Schema {
 	leftSchema := join.Children()[0].Schema()
+	if joinType == SemiJoin {
+		return leftSchema.Clone()
+	}
 	newSchema := expression.MergeSchema(leftSchema, join.Children()[1].Schema())
 	if joinType == LeftOuterJoin {
 		resetNotNullFlag(newSchema, leftSchema.Len(), newSchema.Len())
@@ -147,5 +150,8 @@ func buildLogicalJoinSchema(joinType JoinType, join LogicalPlan) *expression.Sch
 
 // BuildPhysicalJoinSchema builds the schema of PhysicalJoin from it's children's schema.
 func BuildPhysicalJoinSchema(joinType JoinType, join PhysicalPlan) *expression.Schema {
+	if joinType == SemiJoin {
+		return join.Children()[0].Schema().Clone()
+	}
 	return expression.MergeSchema(join.Children()[0].Schema(), join.Children()[1].Schema())
 }
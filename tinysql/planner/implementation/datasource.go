@@ -170,3 +170,68 @@ func NewIndexScanImpl(scan *plannercore.PhysicalIndexScan, tblColHists *statisti
 		tblColHists: tblColHists,
 	}
 }
+
+// IndexLookUpReaderImpl is the Implementation of PhysicalIndexLookUpReader.
+type IndexLookUpReaderImpl struct {
+	baseImpl
+	// schema is the schema of the TiKVDoubleGather, the reader is wrapped by a Projection
+	// when attaching the children if the handle column is appended to the reader.
+	schema      *expression.Schema
+	tblColHists *statistics.HistColl
+	tblCols     []*expression.Column
+}
+
+// NewIndexLookUpReaderImpl creates a new IndexLookUpReader Implementation.
+func NewIndexLookUpReaderImpl(reader *plannercore.PhysicalIndexLookUpReader, schema *expression.Schema, tblColHists *statistics.HistColl, tblCols []*expression.Column) *IndexLookUpReaderImpl {
+	return &IndexLookUpReaderImpl{
+		baseImpl:    baseImpl{plan: reader},
+		schema:      schema,
+		tblColHists: tblColHists,
+		tblCols:     tblCols,
+	}
+}
+
+// ScaleCostLimit implements Implementation interface.
+func (impl *IndexLookUpReaderImpl) ScaleCostLimit(costLimit float64) float64 {
+	sessVars := impl.plan.SCtx().GetSessionVars()
+	copIterWorkers := float64(sessVars.DistSQLScanConcurrency)
+	if math.MaxFloat64/copIterWorkers < costLimit {
+		return math.MaxFloat64
+	}
+	return costLimit * copIterWorkers
+}
+
+// CalcCost implements Implementation interface.
+func (impl *IndexLookUpReaderImpl) CalcCost(outCount float64, children ...memo.Implementation) float64 {
+	reader := impl.plan.(*plannercore.PhysicalIndexLookUpReader)
+	sessVars := reader.SCtx().GetSessionVars()
+	indexPlan := children[0].GetPlan()
+	indexRows := indexPlan.Stats().RowCount
+	// Network cost of transferring the handles to TiDB, and the scan cost and network cost of the table side.
+	cost := children[0].GetCost()
+	cost += indexRows * sessVars.NetworkFactor * impl.tblColHists.GetAvgRowSize(indexPlan.Schema().Columns, true)
+	cost += indexRows * sessVars.ScanFactor * impl.tblColHists.GetTableAvgRowSize(impl.tblCols)
+	cost += outCount * sessVars.NetworkFactor * impl.tblColHists.GetAvgRowSize(reader.Schema().Columns, false)
+	// Cost of seeking the handles on the table side.
+	cost += plannercore.DoubleReadSeekCount(indexRows, float64(impl.tblColHists.Count), sessVars.IndexLookupSize) * sessVars.SeekFactor
+	copIterWorkers := float64(sessVars.DistSQLScanConcurrency)
+	cost /= copIterWorkers
+	// Cost of building the table reader executors and the worker goroutines.
+	numTblWorkers := float64(sessVars.IndexLookupConcurrency)
+	cost += indexRows*sessVars.CPUFactor + (numTblWorkers+1)*sessVars.ConcurrencyFactor
+	impl.cost = cost
+	return impl.cost
+}
+
+// AttachChildren implements Implementation AttachChildren interface.
+func (impl *IndexLookUpReaderImpl) AttachChildren(children ...memo.Implementation) memo.Implementation {
+	reader := impl.plan.(*plannercore.PhysicalIndexLookUpReader)
+	reader.SetChildren(children[0].GetPlan())
+	if reader.Schema().Len() > impl.schema.Len() {
+		proj := plannercore.PhysicalProjection{Exprs: expression.Column2Exprs(impl.schema.Columns)}.Init(reader.SCtx(), reader.Stats(), nil)
+		proj.SetSchema(impl.schema)
+		proj.SetChildren(reader)
+		impl.plan = proj
+	}
+	return impl
+}
@@ -43,6 +43,9 @@ type AccessPath struct {
 	IsTablePath bool
 	// Forced means this path is generated by `use/force index()`.
 	Forced bool
+	// PartialConds is the CNF filter of the partial index, the index can only be used when
+	// it's implied by the conditions of the query.
+	PartialConds []expression.Expression
 
 	IsDNFCond bool
 }
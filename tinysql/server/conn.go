@@ -45,6 +45,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -102,6 +103,12 @@ type clientConn struct {
 	status       int32             // dispatching/reading/shutdown/waitshutdown
 	lastCode     uint16            // last error code
 	collation    uint8             // collation used by client, may be different from the collation used by database.
+
+	// mu is used for cancelling the execution of the current command.
+	mu struct {
+		sync.RWMutex
+		cancelFunc context.CancelFunc
+	}
 }
 
 func (cc *clientConn) String() string {
@@ -671,6 +678,18 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 	cc.lastPacket = data
 	cmd := data[0]
 	data = data[1:]
+	ctx1, cancelFunc := context.WithCancel(ctx)
+	cc.mu.Lock()
+	cc.mu.cancelFunc = cancelFunc
+	cc.mu.Unlock()
+	defer func() {
+		cc.mu.Lock()
+		cc.mu.cancelFunc = nil
+		cc.mu.Unlock()
+		cancelFunc()
+	}()
+	ctx = ctx1
+
 	vars := cc.ctx.GetSessionVars()
 	atomic.StoreUint32(&vars.Killed, 0)
 	if cmd < mysql.ComEnd {
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"sync/atomic"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
@@ -262,3 +264,36 @@ func (ts *ConnTestSuite) TestShutdownOrNotify(c *C) {
 	c.Assert(cc.ShutdownOrNotify(), IsFalse)
 	c.Assert(cc.status, Equals, connStatusWaitShutdown)
 }
+
+func (ts *ConnTestSuite) TestKill(c *C) {
+	c.Parallel()
+	se, err := session.CreateSession4Test(ts.store)
+	c.Assert(err, IsNil)
+	srv := &Server{
+		capability: defaultCapability,
+		clients:    make(map[uint32]*clientConn),
+	}
+	cc := &clientConn{
+		connectionID: 1,
+		server:       srv,
+		status:       connStatusDispatching,
+		ctx:          &TiDBContext{session: se},
+	}
+	srv.clients[cc.connectionID] = cc
+	ctx, cancel := context.WithCancel(context.Background())
+	cc.mu.cancelFunc = cancel
+
+	// KILL QUERY interrupts the running statement and keeps the connection.
+	srv.Kill(1, true)
+	c.Assert(ctx.Err(), Equals, context.Canceled)
+	c.Assert(atomic.LoadUint32(&se.GetSessionVars().Killed), Equals, uint32(1))
+	c.Assert(atomic.LoadInt32(&cc.status), Equals, connStatusDispatching)
+
+	// An unknown connection is ignored.
+	srv.Kill(2, false)
+	c.Assert(atomic.LoadInt32(&cc.status), Equals, connStatusDispatching)
+
+	// KILL CONNECTION notifies the connection to exit.
+	srv.Kill(1, false)
+	c.Assert(atomic.LoadInt32(&cc.status), Equals, connStatusWaitShutdown)
+}
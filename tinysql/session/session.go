@@ -224,6 +224,12 @@ func (s *session) doCommit(ctx context.Context) error {
 		}
 	})
 
+	failpoint.Inject("mockInfoSchemaChanged", func(val failpoint.Value) {
+		if val.(bool) {
+			failpoint.Return(domain.ErrInfoSchemaChanged)
+		}
+	})
+
 	// Get the related table IDs.
 	relatedTables := s.GetSessionVars().TxnCtx.TableDeltaMap
 	tableIDs := make([]int64, 0, len(relatedTables))
@@ -313,6 +319,10 @@ func (s *session) String() string {
 	return string(b)
 }
 
+// maxSchemaChangedRetryCount is the max times to retry a statement failed with
+// ErrInfoSchemaChanged.
+const maxSchemaChangedRetryCount = 3
+
 // SchemaChangedWithoutRetry is used for testing.
 var SchemaChangedWithoutRetry uint32
 
@@ -533,8 +543,32 @@ func (s *session) executeStatement(ctx context.Context, connID uint64, stmtNode
 	} else {
 		s.ClearValue(sessionctx.LastExecuteDDL)
 	}
+	// A statement running in its own transaction can be retried as a whole when the
+	// schema is changed during its execution.
+	_, isDDL := stmtNode.(ast.DDLNode)
+	canRetry := !isDDL && !s.sessionVars.InTxn() && s.sessionVars.IsAutocommit()
+	s.setProcessInfo(stmt.OriginText(), "executing")
 	recordSet, err := runStmt(ctx, s, stmt)
+	for i := 0; canRetry && domain.IsRetryableSchemaError(err) && i < maxSchemaChangedRetryCount; i++ {
+		logutil.Logger(ctx).Info("retry statement for info schema changed",
+			zap.Int("retryCnt", i+1),
+			zap.Int64("schemaVersion", s.sessionVars.TxnCtx.SchemaVersion),
+			zap.Error(err))
+		if err = domain.GetDomain(s).Reload(); err != nil {
+			break
+		}
+		s.PrepareTxnCtx(ctx)
+		if err = executor.ResetContextOfStmt(s, stmtNode); err != nil {
+			break
+		}
+		compiler := executor.Compiler{Ctx: s}
+		if stmt, err = compiler.Compile(ctx, stmtNode); err != nil {
+			break
+		}
+		recordSet, err = runStmt(ctx, s, stmt)
+	}
 	if err != nil {
+		s.clearProcessInfo()
 		if !kv.ErrKeyExists.Equal(err) {
 			logutil.Logger(ctx).Warn("run statement failed",
 				zap.Int64("schemaVersion", s.sessionVars.TxnCtx.SchemaVersion),
@@ -544,6 +578,14 @@ func (s *session) executeStatement(ctx context.Context, connID uint64, stmtNode
 		return nil, err
 	}
 
+	if recordSet == nil {
+		s.clearProcessInfo()
+	} else {
+		// The statement keeps running until the client finishes reading the result.
+		s.setProcessInfo(stmt.OriginText(), "sending data")
+		recordSet = &processInfoRecordSet{RecordSet: recordSet, se: s}
+	}
+
 	if inMulitQuery && recordSet == nil {
 		recordSet = &multiQueryNoDelayRecordSet{
 			affectedRows: s.AffectedRows(),
@@ -559,6 +601,41 @@ func (s *session) executeStatement(ctx context.Context, connID uint64, stmtNode
 	return recordSets, nil
 }
 
+// setProcessInfo records the statement running on this session for show processlist.
+// Internal sessions have no connection id and are not recorded.
+func (s *session) setProcessInfo(sql, state string) {
+	connID := s.sessionVars.ConnectionID
+	if connID == 0 {
+		return
+	}
+	pi := &util.ProcessInfo{
+		ID:    connID,
+		DB:    s.sessionVars.CurrentDB,
+		Info:  sql,
+		State: state,
+		Time:  s.sessionVars.StartTime,
+	}
+	domain.GetDomain(s).SetProcessInfo(pi)
+}
+
+func (s *session) clearProcessInfo() {
+	if connID := s.sessionVars.ConnectionID; connID != 0 {
+		domain.GetDomain(s).ClearProcessInfo(connID)
+	}
+}
+
+// processInfoRecordSet clears the process info of the session when the record set is closed.
+type processInfoRecordSet struct {
+	sqlexec.RecordSet
+	se *session
+}
+
+func (rs *processInfoRecordSet) Close() error {
+	err := rs.RecordSet.Close()
+	rs.se.clearProcessInfo()
+	return err
+}
+
 func (s *session) Execute(ctx context.Context, sql string) (recordSets []sqlexec.RecordSet, err error) {
 	if recordSets, err = s.execute(ctx, sql); err != nil {
 		s.sessionVars.StmtCtx.AppendError(err)
@@ -743,6 +820,7 @@ func (s *session) ClearValue(key fmt.Stringer) {
 func (s *session) Close() {
 	ctx := context.TODO()
 	s.RollbackTxn(ctx)
+	s.clearProcessInfo()
 }
 
 // GetSessionVars implements the context.Context interface.
@@ -941,6 +1019,9 @@ var builtinGlobalVariable = []string{
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check,
 	variable.TiDBIndexLookupSize,
+	variable.TiDBIndexLookupCacheSize,
+	variable.TiDBIndexLookupMinBatchSize,
+	variable.TiDBMemQuotaMergeJoin,
 	variable.TiDBIndexLookupConcurrency,
 	variable.TiDBIndexLookupJoinConcurrency,
 	variable.TiDBIndexSerialScanConcurrency,
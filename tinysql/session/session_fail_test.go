@@ -18,6 +18,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/util/testkit"
 )
 
@@ -113,3 +114,30 @@ func (s *testSessionSuite2) TestGetTSFailDirtyStateInretry(c *C) {
 	tk.MustExec("insert into t values (2)")
 	tk.MustQuery(`select * from t`).Check(testkit.Rows("2"))
 }
+
+func (s *testSessionSuite2) TestRetryOnInfoSchemaChanged(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("create table t (id int)")
+
+	// An auto-commit statement is retried with the reloaded schema.
+	c.Assert(failpoint.Enable("github.com/pingcap/tidb/session/mockInfoSchemaChanged", `1*return(true)`), IsNil)
+	tk.MustExec("insert into t values (1)")
+	c.Assert(failpoint.Disable("github.com/pingcap/tidb/session/mockInfoSchemaChanged"), IsNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1"))
+
+	// The retry is bounded.
+	c.Assert(failpoint.Enable("github.com/pingcap/tidb/session/mockInfoSchemaChanged", `return(true)`), IsNil)
+	_, err := tk.Exec("insert into t values (2)")
+	c.Assert(domain.ErrInfoSchemaChanged.Equal(err), IsTrue)
+	c.Assert(failpoint.Disable("github.com/pingcap/tidb/session/mockInfoSchemaChanged"), IsNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1"))
+
+	// An explicit transaction is not retried.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (3)")
+	c.Assert(failpoint.Enable("github.com/pingcap/tidb/session/mockInfoSchemaChanged", `1*return(true)`), IsNil)
+	_, err = tk.Exec("commit")
+	c.Assert(domain.ErrInfoSchemaChanged.Equal(err), IsTrue)
+	c.Assert(failpoint.Disable("github.com/pingcap/tidb/session/mockInfoSchemaChanged"), IsNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1"))
+}
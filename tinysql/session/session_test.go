@@ -23,6 +23,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -841,3 +842,51 @@ func (s *testSessionSuite2) TestFailBusyServerCop(c *C) {
 
 	wg.Wait()
 }
+
+// mockSessionManager kills the statements of the sessions by the connection id.
+type mockSessionManager struct {
+	sessions map[uint64]session.Session
+	killed   []bool
+}
+
+func (sm *mockSessionManager) Kill(connectionID uint64, query bool) {
+	sm.killed = append(sm.killed, query)
+	if se, ok := sm.sessions[connectionID]; ok {
+		atomic.StoreUint32(&se.GetSessionVars().Killed, 1)
+	}
+}
+
+func (s *testSessionSuite2) TestKillQuery(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1), (2), (3)")
+
+	tk1 := testkit.NewTestKitWithInit(c, s.store)
+	rs1, err := tk1.Exec("select a from t")
+	c.Assert(err, IsNil)
+	tk2 := testkit.NewTestKitWithInit(c, s.store)
+	rs2, err := tk2.Exec("select a from t")
+	c.Assert(err, IsNil)
+
+	sm := &mockSessionManager{sessions: map[uint64]session.Session{
+		tk1.Se.GetSessionVars().ConnectionID: tk1.Se,
+		tk2.Se.GetSessionVars().ConnectionID: tk2.Se,
+	}}
+	domain.GetDomain(tk.Se).SetSessionManager(sm)
+	tk.MustExec(fmt.Sprintf("kill query %d", tk1.Se.GetSessionVars().ConnectionID))
+	c.Assert(sm.killed, DeepEquals, []bool{true})
+
+	err = rs1.Next(context.Background(), rs1.NewChunk())
+	c.Assert(err, Equals, executor.ErrQueryInterrupted)
+	c.Assert(rs1.Close(), IsNil)
+
+	// Other statements are not affected.
+	tk.ResultSetToResult(rs2, Commentf("sql: select a from t")).Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("select a from t").Check(testkit.Rows("1", "2", "3"))
+
+	// KILL and KILL CONNECTION terminate the whole connection.
+	tk.MustExec("kill 100")
+	tk.MustExec("kill connection 100")
+	c.Assert(sm.killed, DeepEquals, []bool{true, false, false})
+}
@@ -386,6 +386,7 @@ func (s *session) StmtCommit() error {
 		dirtyDB := executor.GetDirtyDB(s)
 		for _, op := range st.dirtyTableOP {
 			mergeToDirtyDB(dirtyDB, op)
+			s.sessionVars.TxnCtx.MarkTableDirty(op.tid)
 		}
 	}
 	return nil
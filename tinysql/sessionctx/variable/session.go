@@ -43,12 +43,15 @@ var (
 type TransactionContext struct {
 	forUpdateTS   uint64
 	DirtyDB       interface{}
+	HandleCache   interface{}
 	InfoSchema    interface{}
 	History       interface{}
 	SchemaVersion int64
 	StartTS       uint64
 	Shard         *int64
 	TableDeltaMap map[int64]TableDelta
+	// DirtyTables records the physical tables written by the committed statements of the transaction.
+	DirtyTables map[int64]struct{}
 
 	CreateTime     time.Time
 	StatementCount int
@@ -71,10 +74,26 @@ func (tc *TransactionContext) UpdateDeltaForTable(tableID int64, delta int64, co
 	tc.TableDeltaMap[tableID] = item
 }
 
+// MarkTableDirty records that the table is written in the transaction.
+func (tc *TransactionContext) MarkTableDirty(tableID int64) {
+	if tc.DirtyTables == nil {
+		tc.DirtyTables = make(map[int64]struct{})
+	}
+	tc.DirtyTables[tableID] = struct{}{}
+}
+
+// IsTableDirty returns whether the table is written in the transaction, the reads of the table
+// need to merge the uncommitted modifications.
+func (tc *TransactionContext) IsTableDirty(tableID int64) bool {
+	_, ok := tc.DirtyTables[tableID]
+	return ok
+}
+
 // Cleanup clears up transaction info that no longer use.
 func (tc *TransactionContext) Cleanup() {
 	// tc.InfoSchema = nil; we cannot do it now, because some operation like handleFieldList depend on this.
 	tc.DirtyDB = nil
+	tc.DirtyTables = nil
 	tc.History = nil
 	tc.TableDeltaMap = nil
 }
@@ -128,6 +147,7 @@ func (ib *WriteStmtBufs) clean() {
 type SessionVars struct {
 	Concurrency
 	BatchSize
+	MemQuota
 	// UsersLock is a lock for user defined variables.
 	UsersLock sync.RWMutex
 	// Users are user defined variables.
@@ -196,6 +216,9 @@ type SessionVars struct {
 	// AllowAggPushDown can be set to false to forbid aggregation push down.
 	AllowAggPushDown bool
 
+	// StableTopN can be set to true to break the ties of `order by ... limit n` by the handle of the table.
+	StableTopN bool
+
 	// AllowWriteRowID can be set to false to forbid write data to _tidb_rowid.
 	// This variable is currently not recommended to be turned on.
 	AllowWriteRowID bool
@@ -250,6 +273,14 @@ type SessionVars struct {
 	// EnableVectorizedExpression  enables the vectorized expression evaluation.
 	EnableVectorizedExpression bool
 
+	// IndexLookupCacheSize is the max number of table rows cached by the index double read executors in a transaction.
+	// The cache is disabled if it's 0.
+	IndexLookupCacheSize int
+
+	// IndexLookupMinBatchSize is the min number of handles for the first index lookup task in index double read executor.
+	// There is no floor if it's 0.
+	IndexLookupMinBatchSize int
+
 	// DDLReorgPriority is the operation priority of adding indices.
 	DDLReorgPriority int
 
@@ -365,6 +396,8 @@ func NewSessionVars() *SessionVars {
 		ConcurrencyFactor:           DefOptConcurrencyFactor,
 		EnableRadixJoin:             false,
 		EnableVectorizedExpression:  DefEnableVectorizedExpression,
+		IndexLookupCacheSize:        DefIndexLookupCacheSize,
+		IndexLookupMinBatchSize:     DefIndexLookupMinBatchSize,
 		CommandValue:                uint32(mysql.ComSleep),
 		TiDBOptJoinReorderThreshold: DefTiDBOptJoinReorderThreshold,
 		WaitSplitRegionFinish:       DefTiDBWaitSplitRegionFinish,
@@ -388,6 +421,9 @@ func NewSessionVars() *SessionVars {
 		InitChunkSize:   DefInitChunkSize,
 		MaxChunkSize:    DefMaxChunkSize,
 	}
+	vars.MemQuota = MemQuota{
+		MemQuotaMergeJoin: DefTiDBMemQuotaMergeJoin,
+	}
 	return vars
 }
 
@@ -566,6 +602,8 @@ func (s *SessionVars) SetSystemVar(name string, val string) error {
 		s.SkipUTF8Check = TiDBOptOn(val)
 	case TiDBOptAggPushDown:
 		s.AllowAggPushDown = TiDBOptOn(val)
+	case TiDBOptStableTopN:
+		s.StableTopN = TiDBOptOn(val)
 	case TiDBOptWriteRowID:
 		s.AllowWriteRowID = TiDBOptOn(val)
 	case TiDBOptInSubqToJoinAndAgg:
@@ -598,6 +636,12 @@ func (s *SessionVars) SetSystemVar(name string, val string) error {
 		s.IndexLookupJoinConcurrency = tidbOptPositiveInt32(val, DefIndexLookupJoinConcurrency)
 	case TiDBIndexLookupSize:
 		s.IndexLookupSize = tidbOptPositiveInt32(val, DefIndexLookupSize)
+	case TiDBIndexLookupCacheSize:
+		s.IndexLookupCacheSize = int(tidbOptInt64(val, DefIndexLookupCacheSize))
+	case TiDBIndexLookupMinBatchSize:
+		s.IndexLookupMinBatchSize = int(tidbOptInt64(val, DefIndexLookupMinBatchSize))
+	case TiDBMemQuotaMergeJoin:
+		s.MemQuotaMergeJoin = tidbOptInt64(val, DefTiDBMemQuotaMergeJoin)
 	case TiDBHashJoinConcurrency:
 		s.HashJoinConcurrency = tidbOptPositiveInt32(val, DefTiDBHashJoinConcurrency)
 	case TiDBProjectionConcurrency:
@@ -745,3 +789,9 @@ type BatchSize struct {
 	// MaxChunkSize defines max row count of a Chunk during query execution.
 	MaxChunkSize int
 }
+
+// MemQuota defines memory quota values.
+type MemQuota struct {
+	// MemQuotaMergeJoin is the memory quota of the inner rows with the same join key buffered by merge join executor.
+	MemQuotaMergeJoin int64
+}
@@ -40,6 +40,11 @@ const (
 	// tidb_opt_agg_push_down is used to enable/disable the optimizer rule of aggregation push down.
 	TiDBOptAggPushDown = "tidb_opt_agg_push_down"
 
+	// tidb_opt_stable_topn is used to enable/disable appending the handle of the table to the order by items of
+	// `order by ... limit n`, so the rows with the same order by values are always returned in the same order and
+	// the query returns the same rows across runs. It's disabled by default, as MySQL doesn't guarantee the order.
+	TiDBOptStableTopN = "tidb_opt_stable_topn"
+
 	// tidb_opt_write_row_id is used to enable/disable the operations of insert、replace and update to _tidb_rowid.
 	TiDBOptWriteRowID = "tidb_opt_write_row_id"
 
@@ -111,6 +116,17 @@ const (
 	// Large value may do more work than needed if the query has a limit.
 	TiDBIndexLookupSize = "tidb_index_lookup_size"
 
+	// tidb_index_lookup_cache_size is used for index lookup executor.
+	// It's the max number of the table rows cached by the index lookups in a transaction, so the lookups on
+	// the overlapping handles can skip reading the table again. Zero disables the cache.
+	TiDBIndexLookupCacheSize = "tidb_index_lookup_cache_size"
+
+	// tidb_index_lookup_min_batch_size is used for index lookup executor.
+	// The first batch of handles is sized by the rows required by the parent executor, which may be as small as 1,
+	// and it doubles for every next batch. This value is the floor of the first batch, so selective queries that
+	// still return several rows don't start with a tiny lookup task. Zero disables the floor.
+	TiDBIndexLookupMinBatchSize = "tidb_index_lookup_min_batch_size"
+
 	// tidb_index_lookup_concurrency is used for index lookup executor.
 	// A lookup task may have 'tidb_index_lookup_size' of handles at maximun, the handles may be distributed
 	// in many TiKV nodes, we executes multiple concurrent index lookup tasks concurrently to reduce the time
@@ -127,6 +143,11 @@ const (
 	// when we need to keep the data output order the same as the order of index data.
 	TiDBIndexSerialScanConcurrency = "tidb_index_serial_scan_concurrency"
 
+	// tidb_mem_quota_mergejoin is the memory quota in bytes of the inner rows with the same join key buffered by a
+	// merge join executor. The rows exceeding it are spilled to a temporary file, and the joined rows are produced by
+	// streaming them from disk. Zero or negative disables the quota.
+	TiDBMemQuotaMergeJoin = "tidb_mem_quota_mergejoin"
+
 	// TiDBMaxChunkSize is used to control the max chunk size during query execution.
 	TiDBMaxChunkSize = "tidb_max_chunk_size"
 
@@ -220,10 +241,14 @@ const (
 	DefIndexLookupJoinConcurrency    = 4
 	DefIndexSerialScanConcurrency    = 1
 	DefIndexLookupSize               = 20000
+	DefIndexLookupCacheSize          = 0
+	DefIndexLookupMinBatchSize       = 0
+	DefTiDBMemQuotaMergeJoin         = 32 << 30 // 32GB.
 	DefDistSQLScanConcurrency        = 15
 	DefBuildStatsConcurrency         = 4
 	DefSkipUTF8Check                 = false
 	DefOptAggPushDown                = false
+	DefOptStableTopN                 = false
 	DefOptWriteRowID                 = false
 	DefOptCorrelationThreshold       = 0.9
 	DefOptCorrelationExpFactor       = 1
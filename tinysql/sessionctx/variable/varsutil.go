@@ -362,7 +362,7 @@ func ValidateSetSystemVar(vars *SessionVars, name string, value string) (string,
 			return "1", nil
 		}
 		return value, ErrWrongValueForVar.GenWithStackByArgs(name, value)
-	case TiDBSkipUTF8Check, TiDBOptAggPushDown, TiDBOptInSubqToJoinAndAgg,
+	case TiDBSkipUTF8Check, TiDBOptAggPushDown, TiDBOptStableTopN, TiDBOptInSubqToJoinAndAgg,
 		TiDBEnableCascadesPlanner, TiDBEnableNoopFuncs,
 		TiDBScatterRegion, TiDBGeneralLog, TiDBConstraintCheckInPlace, TiDBEnableVectorizedExpression:
 		fallthrough
@@ -415,6 +415,10 @@ func ValidateSetSystemVar(vars *SessionVars, name string, value string) (string,
 		return checkUInt64SystemVar(name, value, uint64(MinDDLReorgBatchSize), uint64(MaxDDLReorgBatchSize), vars)
 	case TiDBDDLErrorCountLimit:
 		return checkUInt64SystemVar(name, value, uint64(0), math.MaxInt64, vars)
+	case TiDBIndexLookupCacheSize, TiDBIndexLookupMinBatchSize:
+		return checkUInt64SystemVar(name, value, uint64(0), math.MaxInt32, vars)
+	case TiDBMemQuotaMergeJoin:
+		return checkInt64SystemVar(name, value, math.MinInt64, math.MaxInt64, vars)
 	case TiDBIndexLookupConcurrency, TiDBIndexLookupJoinConcurrency,
 		TiDBIndexLookupSize,
 		TiDBHashJoinConcurrency,
@@ -189,6 +189,34 @@ func (h *Handle) GetPartitionStats(tblInfo *model.TableInfo, pid int64) *Table {
 	return tbl
 }
 
+// StatsSnapshot is a consistent view of the statistics cached by Handle, the later
+// updates of the cache are not visible to it.
+type StatsSnapshot struct {
+	cache statsCache
+}
+
+// Snapshot returns a snapshot of the current statistics cache. It's cheap since
+// the cache is never modified in place.
+func (h *Handle) Snapshot() *StatsSnapshot {
+	return &StatsSnapshot{cache: h.statsCache.Load().(statsCache)}
+}
+
+// GetTableStats retrieves the statistics table from the snapshot.
+func (s *StatsSnapshot) GetTableStats(tblInfo *model.TableInfo) *Table {
+	return s.GetPartitionStats(tblInfo, tblInfo.ID)
+}
+
+// GetPartitionStats retrieves the partition stats from the snapshot, it returns
+// the pseudo stats if the partition isn't in the snapshot.
+func (s *StatsSnapshot) GetPartitionStats(tblInfo *model.TableInfo, pid int64) *Table {
+	tbl, ok := s.cache.tables[pid]
+	if !ok {
+		tbl = PseudoTable(tblInfo)
+		tbl.PhysicalID = pid
+	}
+	return tbl
+}
+
 func (h *Handle) updateStatsCache(newCache statsCache) {
 	h.statsCache.Lock()
 	oldCache := h.statsCache.Load().(statsCache)
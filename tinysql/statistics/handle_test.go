@@ -72,6 +72,37 @@ func (s *testStatsSuite) TestStatsCache(c *C) {
 	c.Assert(statsTbl.Pseudo, IsFalse)
 }
 
+func (s *testStatsSuite) TestStatsSnapshot(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (c1 int, c2 int)")
+	testKit.MustExec("insert into t values(1, 2)")
+	testKit.MustExec("analyze table t")
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	snapshot := do.StatsHandle().Snapshot()
+	statsTbl := snapshot.GetTableStats(tableInfo)
+	c.Assert(statsTbl.Pseudo, IsFalse)
+	c.Assert(statsTbl.Count, Equals, int64(1))
+
+	// The stats updated after the snapshot is taken are not visible to it.
+	testKit.MustExec("insert into t values(3, 4), (5, 6)")
+	testKit.MustExec("analyze table t")
+	c.Assert(do.StatsHandle().GetTableStats(tableInfo).Count, Equals, int64(3))
+	c.Assert(snapshot.GetTableStats(tableInfo), Equals, statsTbl)
+	c.Assert(do.StatsHandle().Snapshot().GetTableStats(tableInfo).Count, Equals, int64(3))
+
+	// The tables not in the snapshot get the pseudo stats.
+	testKit.MustExec("create table t1 (c1 int)")
+	tbl, err = do.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t1"))
+	c.Assert(err, IsNil)
+	c.Assert(snapshot.GetTableStats(tbl.Meta()).Pseudo, IsTrue)
+}
+
 func assertTableEqual(c *C, a *statistics.Table, b *statistics.Table) {
 	c.Assert(a.Count, Equals, b.Count)
 	c.Assert(a.ModifyCount, Equals, b.ModifyCount)
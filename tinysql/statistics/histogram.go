@@ -322,6 +322,48 @@ func (hg *Histogram) BetweenRowCount(a, b types.Datum) float64 {
 	return lessCountB - lessCountA
 }
 
+// EqualJoinRowCount estimates the row count of the equal join on the columns of the two histograms. A value never
+// spans buckets, so the values with more rows than the average are the upper bounds of their buckets, and the rows
+// they match are counted by the repeats of the buckets. The other values are assumed to be uniformly distributed.
+func EqualJoinRowCount(l, r *Histogram) float64 {
+	lPopular, lPopularCount := l.popularValues()
+	rPopular, rPopularCount := r.popularValues()
+	count := 0.0
+	for _, val := range lPopular {
+		count += l.equalRowCount(val) * r.equalRowCount(val)
+	}
+	lAvg := l.notNullCount() / float64(l.NDV)
+	for _, val := range rPopular {
+		lCount := l.equalRowCount(val)
+		// The value is counted above if it's also popular in l.
+		if lCount <= lAvg {
+			count += lCount * r.equalRowCount(val)
+		}
+	}
+	lRest, rRest := l.notNullCount()-lPopularCount, r.notNullCount()-rPopularCount
+	lNDV, rNDV := float64(l.NDV-int64(len(lPopular))), float64(r.NDV-int64(len(rPopular)))
+	return count + lRest*rRest/math.Max(math.Max(lNDV, rNDV), 1)
+}
+
+// popularValues returns the values with more rows than the average, and the total row count of them.
+func (hg *Histogram) popularValues() ([]types.Datum, float64) {
+	if hg.NDV <= 0 {
+		return nil, 0
+	}
+	avg := hg.notNullCount() / float64(hg.NDV)
+	var (
+		values []types.Datum
+		count  float64
+	)
+	for i, bkt := range hg.Buckets {
+		if float64(bkt.Repeat) > avg {
+			values = append(values, *hg.GetUpper(i))
+			count += float64(bkt.Repeat)
+		}
+	}
+	return values, count
+}
+
 // TotalRowCount returns the total count of this histogram.
 func (hg *Histogram) TotalRowCount() float64 {
 	return hg.notNullCount() + float64(hg.NullCount)
@@ -27,7 +27,9 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	plannercore "github.com/pingcap/tidb/planner/core"
@@ -232,10 +234,6 @@ func (s *testStatsSuite) TestSelectivity(c *C) {
 	statsTbl := s.prepareSelectivity(testKit, c)
 	is := s.do.InfoSchema()
 
-	longExpr := "0 < a and a = 1 "
-	for i := 1; i < 64; i++ {
-		longExpr += fmt.Sprintf(" and a > %d ", i)
-	}
 	tests := []struct {
 		exprs       string
 		selectivity float64
@@ -272,10 +270,6 @@ func (s *testStatsSuite) TestSelectivity(c *C) {
 			exprs:       "a > 1 and b < 2 and c > 3 and d < 4 and e > 5",
 			selectivity: 0,
 		},
-		{
-			exprs:       longExpr,
-			selectivity: 0.001,
-		},
 	}
 
 	ctx := context.Background()
@@ -306,6 +300,33 @@ func (s *testStatsSuite) TestSelectivity(c *C) {
 		c.Assert(err, IsNil, comment)
 		c.Assert(math.Abs(ratio-tt.selectivity) < eps, IsTrue, Commentf("for %s, needed: %v, got: %v", tt.exprs, tt.selectivity, ratio))
 	}
+
+	// The redundant conditions are removed when building the selection, so the conditions of
+	// "0 < a and a = 1 and a > 1 and ... and a > 63" are built directly to have more than 64 of them.
+	sctx := testKit.Se.(sessionctx.Context)
+	stmts, err := session.Parse(sctx, "select * from t")
+	c.Assert(err, IsNil)
+	err = plannercore.Preprocess(sctx, stmts[0], is)
+	c.Assert(err, IsNil)
+	p, _, err := plannercore.BuildLogicalPlan(ctx, sctx, stmts[0], is)
+	c.Assert(err, IsNil)
+	ds := p.(plannercore.LogicalPlan).Children()[0].(*plannercore.DataSource)
+	colA := ds.Schema().Columns[0]
+	newIntConst := func(v int64) *expression.Constant {
+		return &expression.Constant{Value: types.NewIntDatum(v), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	}
+	boolType := types.NewFieldType(mysql.TypeTiny)
+	conds := []expression.Expression{
+		expression.NewFunctionInternal(sctx, ast.LT, boolType, newIntConst(0), colA),
+		expression.NewFunctionInternal(sctx, ast.EQ, boolType, colA, newIntConst(1)),
+	}
+	for i := 1; i < 64; i++ {
+		conds = append(conds, expression.NewFunctionInternal(sctx, ast.GT, boolType, colA, newIntConst(int64(i))))
+	}
+	histColl := statsTbl.GenerateHistCollFromColumnInfo(ds.Columns, ds.Schema().Columns)
+	ratio, err := histColl.Selectivity(sctx, conds, nil)
+	c.Assert(err, IsNil)
+	c.Assert(math.Abs(ratio-0.001) < eps, IsTrue, Commentf("needed: %v, got: %v", 0.001, ratio))
 }
 
 // TestDiscreteDistribution tests the estimation for discrete data distribution. This is more common when the index
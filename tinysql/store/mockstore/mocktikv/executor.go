@@ -443,12 +443,16 @@ func (e *topNExec) Next(ctx context.Context) (value [][]byte, err error) {
 				break
 			}
 		}
+		// Sort the rows in heap only once, the later calls just move the cursor.
+		sort.Sort(&e.heap.topNSorter)
+		if e.heap.err != nil {
+			return nil, errors.Trace(e.heap.err)
+		}
 		e.executed = true
 	}
 	if e.cursor >= len(e.heap.rows) {
 		return nil, nil
 	}
-	sort.Sort(&e.heap.topNSorter)
 	row := e.heap.rows[e.cursor]
 	e.cursor++
 
@@ -87,4 +87,6 @@ type Index interface {
 	// Param columns is a reused buffer, if it is not nil, FetchValues will fill the index values in it,
 	// and return the buffer, if it is nil, FetchValues will allocate the buffer instead.
 	FetchValues(row []types.Datum, columns []types.Datum) ([]types.Datum, error)
+	// Covers checks whether a row is in the index, a partial index only contains the rows satisfying its condition.
+	Covers(ctx sessionctx.Context, row []types.Datum) (bool, error)
 }
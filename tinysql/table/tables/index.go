@@ -18,9 +18,11 @@ import (
 	"context"
 	"encoding/binary"
 	"io"
+	"sync"
 	"unicode/utf8"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
@@ -29,9 +31,15 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/mock"
 )
 
+// BuildPartialIndexCond builds the condition of a partial index over the rows of its table.
+// It's set by the planner, which can rewrite the condition text to an expression.
+var BuildPartialIndexCond func(ctx sessionctx.Context, tblInfo *model.TableInfo, idxInfo *model.IndexInfo) (expression.Expression, error)
+
 // EncodeHandle encodes handle in data.
 func EncodeHandle(h int64) []byte {
 	var data [8]byte
@@ -97,6 +105,11 @@ type index struct {
 	idxInfo *model.IndexInfo
 	tblInfo *model.TableInfo
 	prefix  kv.Key
+
+	// condOnce builds cond of a partial index on the first write.
+	condOnce sync.Once
+	cond     expression.Expression
+	condErr  error
 }
 
 // NewIndex builds a new Index object.
@@ -359,6 +372,26 @@ func (c *index) Exist(sc *stmtctx.StatementContext, rm kv.RetrieverMutator, inde
 	return true, h, nil
 }
 
+// Covers implements table.Index Covers interface.
+func (c *index) Covers(ctx sessionctx.Context, row []types.Datum) (bool, error) {
+	if c.idxInfo.Condition == "" {
+		return true, nil
+	}
+	c.condOnce.Do(func() {
+		if BuildPartialIndexCond == nil {
+			c.condErr = errors.Errorf("can't build the condition of partial index %s", c.idxInfo.Name)
+			return
+		}
+		c.cond, c.condErr = BuildPartialIndexCond(mock.NewContext(), c.tblInfo, c.idxInfo)
+	})
+	if c.condErr != nil {
+		return false, c.condErr
+	}
+	// A row whose condition is NULL is not in the index, like a row filtered by WHERE.
+	covered, _, err := expression.EvalBool(ctx, []expression.Expression{c.cond}, chunk.MutRowFromDatums(row).ToRow())
+	return covered, err
+}
+
 func (c *index) FetchValues(r []types.Datum, vals []types.Datum) ([]types.Datum, error) {
 	needLength := len(c.idxInfo.Columns)
 	if vals == nil || cap(vals) < needLength {
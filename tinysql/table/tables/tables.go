@@ -309,33 +309,37 @@ func (t *TableCommon) rebuildIndices(ctx sessionctx.Context, rm kv.RetrieverMuta
 		return err
 	}
 	for _, idx := range t.DeletableIndices() {
-		for _, ic := range idx.Meta().Columns {
-			if !touched[ic.Offset] {
-				continue
-			}
-			oldVs, err := idx.FetchValues(oldData, nil)
-			if err != nil {
-				return err
-			}
-			if err = t.removeRowIndex(ctx.GetSessionVars().StmtCtx, rm, h, oldVs, idx, txn); err != nil {
-				return err
-			}
-			break
+		if !isIndexTouched(idx, touched) {
+			continue
+		}
+		covered, err := idx.Covers(ctx, oldData)
+		if err != nil {
+			return err
+		}
+		if !covered {
+			continue
+		}
+		oldVs, err := idx.FetchValues(oldData, nil)
+		if err != nil {
+			return err
+		}
+		if err = t.removeRowIndex(ctx.GetSessionVars().StmtCtx, rm, h, oldVs, idx, txn); err != nil {
+			return err
 		}
 	}
 	for _, idx := range t.WritableIndices() {
-		untouched := true
-		for _, ic := range idx.Meta().Columns {
-			if !touched[ic.Offset] {
-				continue
-			}
-			untouched = false
-			break
-		}
+		untouched := !isIndexTouched(idx, touched)
 		// If txn is auto commit and index is untouched, no need to write index value.
 		if untouched && !ctx.GetSessionVars().InTxn() {
 			continue
 		}
+		covered, err := idx.Covers(ctx, newData)
+		if err != nil {
+			return err
+		}
+		if !covered {
+			continue
+		}
 		newVs, err := idx.FetchValues(newData, nil)
 		if err != nil {
 			return err
@@ -347,6 +351,20 @@ func (t *TableCommon) rebuildIndices(ctx sessionctx.Context, rm kv.RetrieverMuta
 	return nil
 }
 
+// isIndexTouched checks whether an update of the touched columns changes the index entry of a row.
+// The condition of a partial index may refer to any column, so a partial index is always touched.
+func isIndexTouched(idx table.Index, touched []bool) bool {
+	if idx.Meta().Condition != "" {
+		return true
+	}
+	for _, ic := range idx.Meta().Columns {
+		if touched[ic.Offset] {
+			return true
+		}
+	}
+	return false
+}
+
 // adjustRowValuesBuf adjust writeBufs.AddRowValues length, AddRowValues stores the inserting values that is used
 // by tablecodec.EncodeRow, the encoded row format is `id1, colval, id2, colval`, so the correct length is rowLen * 2. If
 // the inserting row has null value, AddRecord will skip it, so the rowLen will be different, so we need to adjust it.
@@ -553,6 +571,13 @@ func (t *TableCommon) addIndices(sctx sessionctx.Context, recordID int64, r []ty
 	writeBufs := sctx.GetSessionVars().GetWriteStmtBufs()
 	indexVals := writeBufs.IndexValsBuf
 	for _, v := range t.WritableIndices() {
+		covered, err := v.Covers(sctx, r)
+		if err != nil {
+			return 0, err
+		}
+		if !covered {
+			continue
+		}
 		indexVals, err = v.FetchValues(r, indexVals)
 		if err != nil {
 			return 0, err
@@ -695,6 +720,13 @@ func (t *TableCommon) removeRowIndices(ctx sessionctx.Context, h int64, rec []ty
 		return err
 	}
 	for _, v := range t.DeletableIndices() {
+		covered, err := v.Covers(ctx, rec)
+		if err != nil {
+			return err
+		}
+		if !covered {
+			continue
+		}
 		vals, err := v.FetchValues(rec, nil)
 		if err != nil {
 			logutil.BgLogger().Info("remove row index failed", zap.Any("index", v.Meta()), zap.Uint64("txnStartTS", txn.StartTS()), zap.Int64("handle", h), zap.Any("record", rec), zap.Error(err))
@@ -18,6 +18,7 @@ import (
 
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // CompareFunc is a function to compare the two values in Row, the two columns must have the same type.
@@ -37,6 +38,9 @@ func GetCompareFunc(tp *types.FieldType) CompareFunc {
 		return cmpFloat64
 	case mysql.TypeString, mysql.TypeVarString, mysql.TypeVarchar,
 		mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		if collate.IsCICollation(tp.Collate) {
+			return genCmpStringFunc(collate.GetCollator(tp.Collate))
+		}
 		return cmpString
 	}
 	return nil
@@ -76,6 +80,16 @@ func cmpString(l Row, lCol int, r Row, rCol int) int {
 	return types.CompareString(l.GetString(lCol), r.GetString(rCol))
 }
 
+func genCmpStringFunc(collator collate.Collator) CompareFunc {
+	return func(l Row, lCol int, r Row, rCol int) int {
+		lNull, rNull := l.IsNull(lCol), r.IsNull(rCol)
+		if lNull || rNull {
+			return cmpNull(lNull, rNull)
+		}
+		return collator.Compare(l.GetString(lCol), r.GetString(rCol))
+	}
+}
+
 func cmpFloat32(l Row, lCol int, r Row, rCol int) int {
 	lNull, rNull := l.IsNull(lCol), r.IsNull(rCol)
 	if lNull || rNull {
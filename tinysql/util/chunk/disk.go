@@ -0,0 +1,175 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/types"
+)
+
+var _ Iterator = (*Iterator4ListInDisk)(nil)
+
+// ListInDisk holds a slice of chunks in a temporary file, it's used to hold the rows which
+// are too many to be kept in memory.
+type ListInDisk struct {
+	fieldTypes []*types.FieldType
+	codec      *Codec
+	// offsets stores the start offset of each chunk in the file, followed by the end of the file.
+	offsets []int64
+	length  int
+
+	disk *os.File
+}
+
+// NewListInDisk creates a new ListInDisk with field types, the temporary file is created
+// when the first chunk is added.
+func NewListInDisk(fieldTypes []*types.FieldType) *ListInDisk {
+	return &ListInDisk{
+		fieldTypes: fieldTypes,
+		codec:      NewCodec(fieldTypes),
+		offsets:    []int64{0},
+	}
+}
+
+// Len returns the number of rows in the ListInDisk.
+func (l *ListInDisk) Len() int {
+	return l.length
+}
+
+// NumChunks returns the number of chunks in the ListInDisk.
+func (l *ListInDisk) NumChunks() int {
+	return len(l.offsets) - 1
+}
+
+// Add writes a Chunk to the ListInDisk, the Chunk can be reused by the caller after that.
+func (l *ListInDisk) Add(chk *Chunk) (err error) {
+	if chk.NumRows() == 0 {
+		return errors.New("chunk appended to List should have at least 1 row")
+	}
+	if l.disk == nil {
+		l.disk, err = ioutil.TempFile("", "tidb-chunk-list-")
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	data := l.codec.Encode(chk)
+	offset := l.offsets[len(l.offsets)-1]
+	if _, err = l.disk.WriteAt(data, offset); err != nil {
+		return errors.Trace(err)
+	}
+	l.offsets = append(l.offsets, offset+int64(len(data)))
+	l.length += chk.NumRows()
+	return nil
+}
+
+// GetChunk reads the Chunk at chkIdx from the temporary file.
+func (l *ListInDisk) GetChunk(chkIdx int) (*Chunk, error) {
+	start, end := l.offsets[chkIdx], l.offsets[chkIdx+1]
+	data := make([]byte, end-start)
+	if _, err := l.disk.ReadAt(data, start); err != nil {
+		return nil, errors.Trace(err)
+	}
+	chk, _ := l.codec.Decode(data)
+	return chk, nil
+}
+
+// Close removes the temporary file of the ListInDisk.
+func (l *ListInDisk) Close() error {
+	if l.disk == nil {
+		return nil
+	}
+	name := l.disk.Name()
+	err := l.disk.Close()
+	l.disk = nil
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return errors.Trace(err)
+}
+
+// NewIterator4ListInDisk returns a Iterator for ListInDisk. Only one Chunk of the ListInDisk is
+// kept in memory by the iterator, and the Row returned is valid until the next Chunk is read.
+func NewIterator4ListInDisk(li *ListInDisk) *Iterator4ListInDisk {
+	return &Iterator4ListInDisk{li: li}
+}
+
+// Iterator4ListInDisk is used to iterate rows inside a ListInDisk.
+type Iterator4ListInDisk struct {
+	li     *ListInDisk
+	chk    *Chunk
+	chkIdx int
+	// rowIdx is the index of the next row in chk.
+	rowIdx int
+	cur    Row
+	err    error
+}
+
+// Begin implements the Iterator interface.
+func (it *Iterator4ListInDisk) Begin() Row {
+	it.chk = nil
+	it.chkIdx = -1
+	it.err = nil
+	return it.Next()
+}
+
+// Next implements the Iterator interface.
+func (it *Iterator4ListInDisk) Next() Row {
+	for it.chk == nil || it.rowIdx >= it.chk.NumRows() {
+		if it.err != nil || it.chkIdx+1 >= it.li.NumChunks() {
+			it.ReachEnd()
+			return it.cur
+		}
+		it.chkIdx++
+		it.chk, it.err = it.li.GetChunk(it.chkIdx)
+		if it.err != nil {
+			it.ReachEnd()
+			return it.cur
+		}
+		it.rowIdx = 0
+	}
+	it.cur = it.chk.GetRow(it.rowIdx)
+	it.rowIdx++
+	return it.cur
+}
+
+// Current implements the Iterator interface.
+func (it *Iterator4ListInDisk) Current() Row {
+	return it.cur
+}
+
+// End implements the Iterator interface.
+func (it *Iterator4ListInDisk) End() Row {
+	return Row{}
+}
+
+// ReachEnd implements the Iterator interface.
+func (it *Iterator4ListInDisk) ReachEnd() {
+	it.chk = nil
+	it.chkIdx = it.li.NumChunks()
+	it.cur = it.End()
+}
+
+// Len implements the Iterator interface.
+func (it *Iterator4ListInDisk) Len() int {
+	return it.li.Len()
+}
+
+// Error returns the error happened when reading the chunks from disk, the iterator reaches
+// the end when an error happens.
+func (it *Iterator4ListInDisk) Error() error {
+	return it.err
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"os"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+func (s *testChunkSuite) TestListInDisk(c *check.C) {
+	fields := []*types.FieldType{
+		types.NewFieldType(mysql.TypeLonglong),
+		types.NewFieldType(mysql.TypeVarString),
+	}
+	l := NewListInDisk(fields)
+	defer func() {
+		c.Assert(l.Close(), check.IsNil)
+	}()
+	c.Assert(l.Len(), check.Equals, 0)
+	c.Assert(l.NumChunks(), check.Equals, 0)
+	c.Assert(l.Add(New(fields, 4, 4)), check.NotNil)
+
+	numChk, numRow := 5, 7
+	chk := New(fields, numRow, numRow)
+	for i := 0; i < numChk; i++ {
+		chk.Reset()
+		for j := 0; j < numRow; j++ {
+			chk.AppendInt64(0, int64(i*numRow+j))
+			chk.AppendString(1, "abc")
+		}
+		c.Assert(l.Add(chk), check.IsNil)
+	}
+	c.Assert(l.Len(), check.Equals, numChk*numRow)
+	c.Assert(l.NumChunks(), check.Equals, numChk)
+
+	for i := 0; i < numChk; i++ {
+		chk, err := l.GetChunk(i)
+		c.Assert(err, check.IsNil)
+		c.Assert(chk.NumRows(), check.Equals, numRow)
+		for j := 0; j < numRow; j++ {
+			row := chk.GetRow(j)
+			c.Assert(row.GetInt64(0), check.Equals, int64(i*numRow+j))
+			c.Assert(row.GetString(1), check.Equals, "abc")
+		}
+	}
+
+	expected := make([]int64, 0, numChk*numRow)
+	for i := 0; i < numChk*numRow; i++ {
+		expected = append(expected, int64(i))
+	}
+	it := NewIterator4ListInDisk(l)
+	checkIterator(c, it, expected)
+	c.Assert(it.Error(), check.IsNil)
+	c.Assert(it.Len(), check.Equals, numChk*numRow)
+	it.Begin()
+	it.ReachEnd()
+	c.Assert(it.Current(), check.Equals, it.End())
+	c.Assert(it.Begin().GetInt64(0), check.Equals, int64(0))
+
+	name := l.disk.Name()
+	c.Assert(l.Close(), check.IsNil)
+	_, err := os.Stat(name)
+	c.Assert(os.IsNotExist(err), check.IsTrue)
+}
+
+func (s *testChunkSuite) TestIterator4EmptyListInDisk(c *check.C) {
+	l := NewListInDisk([]*types.FieldType{types.NewFieldType(mysql.TypeLonglong)})
+	it := NewIterator4ListInDisk(l)
+	c.Assert(it.Begin(), check.Equals, it.End())
+	c.Assert(it.Len(), check.Equals, 0)
+	c.Assert(l.Close(), check.IsNil)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // First byte in the encoded value which specifies the encoding type.
@@ -232,13 +233,22 @@ func encodeHashChunkRowIdx(sc *stmtctx.StatementContext, row chunk.Row, tp *type
 		b = row.GetRaw(idx)
 	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString, mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
 		flag = compactBytesFlag
-		b = row.GetBytes(idx)
+		b = collationKey(tp, row.GetBytes(idx))
 	default:
 		return 0, nil, errors.Errorf("unsupport column type for encode %d", tp.Tp)
 	}
 	return
 }
 
+// collationKey returns the key of the string in the collation of the field type, so the strings
+// which are equal in a case-insensitive collation are hashed and compared as the same bytes.
+func collationKey(tp *types.FieldType, b []byte) []byte {
+	if !collate.IsCICollation(tp.Collate) {
+		return b
+	}
+	return []byte(collate.GetCollator(tp.Collate).Key(string(b)))
+}
+
 // HashChunkColumns writes the encoded value of each row's column, which of index `colIdx`, to h.
 func HashChunkColumns(sc *stmtctx.StatementContext, h []hash.Hash64, chk *chunk.Chunk, tp *types.FieldType, colIdx int, buf []byte, isNull []bool) (err error) {
 	return HashChunkSelected(sc, h, chk, tp, colIdx, buf, isNull, nil)
@@ -323,7 +333,7 @@ func HashChunkSelected(sc *stmtctx.StatementContext, h []hash.Hash64, chk *chunk
 				isNull[i] = true
 			} else {
 				buf[0] = compactBytesFlag
-				b = column.GetBytes(i)
+				b = collationKey(tp, column.GetBytes(i))
 			}
 
 			// As the golang doc described, `Hash.Write` never returns an error.
@@ -736,7 +746,7 @@ func HashGroupKey(sc *stmtctx.StatementContext, n int, col *chunk.Column, buf []
 			if col.IsNull(i) {
 				buf[i] = append(buf[i], NilFlag)
 			} else {
-				buf[i] = encodeBytes(buf[i], col.GetBytes(i), false)
+				buf[i] = encodeBytes(buf[i], collationKey(ft, col.GetBytes(i)), false)
 			}
 		}
 	default:
@@ -0,0 +1,101 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Collator provides functionality for comparing strings for a given
+// collation order.
+type Collator interface {
+	// Compare returns an integer comparing the two strings.
+	// The result will be 0 if a == b, -1 if a < b, and +1 if a > b.
+	Compare(a, b string) int
+	// Key returns the key of the string, two strings have the same key if and
+	// only if they are equal in the collation.
+	Key(str string) string
+}
+
+var (
+	binCollatorInstance       = &binCollator{}
+	generalCICollatorInstance = &generalCICollator{}
+)
+
+// GetCollator gets the collator according to the collate name. Collations
+// ending with "_ci" are compared case-insensitively, all the others are
+// compared byte by byte.
+func GetCollator(collate string) Collator {
+	if IsCICollation(collate) {
+		return generalCICollatorInstance
+	}
+	return binCollatorInstance
+}
+
+// IsCICollation returns true if the collation is case-insensitive.
+func IsCICollation(collate string) bool {
+	return strings.HasSuffix(strings.ToLower(collate), "_ci")
+}
+
+type binCollator struct {
+}
+
+// Compare implements Collator interface.
+func (bc *binCollator) Compare(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// Key implements Collator interface.
+func (bc *binCollator) Key(str string) string {
+	return str
+}
+
+type generalCICollator struct {
+}
+
+// Compare implements Collator interface. Like MySQL, the trailing spaces are
+// ignored and the characters are compared by their upper case.
+func (gc *generalCICollator) Compare(a, b string) int {
+	a = truncateTailingSpace(a)
+	b = truncateTailingSpace(b)
+	for len(a) > 0 && len(b) > 0 {
+		r1, size1 := utf8.DecodeRuneInString(a)
+		r2, size2 := utf8.DecodeRuneInString(b)
+		if cmp := sign(int(unicode.ToUpper(r1)) - int(unicode.ToUpper(r2))); cmp != 0 {
+			return cmp
+		}
+		a, b = a[size1:], b[size2:]
+	}
+	return sign(len(a) - len(b))
+}
+
+// Key implements Collator interface.
+func (gc *generalCICollator) Key(str string) string {
+	return strings.Map(unicode.ToUpper, truncateTailingSpace(str))
+}
+
+func truncateTailingSpace(str string) string {
+	return strings.TrimRight(str, " ")
+}
+
+func sign(i int) int {
+	if i < 0 {
+		return -1
+	} else if i > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testCollateSuite{})
+
+type testCollateSuite struct {
+}
+
+func (s *testCollateSuite) TestCompare(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []struct {
+		collate string
+		a       string
+		b       string
+		expect  int
+	}{
+		{"utf8mb4_bin", "a", "A", 1},
+		{"utf8mb4_bin", "a", "a", 0},
+		{"utf8mb4_bin", "a ", "a", 1},
+		{"binary", "A", "a", -1},
+		{"utf8mb4_general_ci", "a", "A", 0},
+		{"utf8mb4_general_ci", "abc", "ABD", -1},
+		{"utf8mb4_general_ci", "b", "A", 1},
+		{"utf8mb4_general_ci", "a ", "A", 0},
+		{"utf8mb4_general_ci", "ab", "A", 1},
+		{"utf8mb4_general_ci", "", "a", -1},
+		{"UTF8_GENERAL_CI", "Ä", "ä", 0},
+	}
+	for _, t := range table {
+		comment := Commentf("%s: %q vs %q", t.collate, t.a, t.b)
+		collator := GetCollator(t.collate)
+		c.Assert(collator.Compare(t.a, t.b), Equals, t.expect, comment)
+		c.Assert(collator.Key(t.a) == collator.Key(t.b), Equals, t.expect == 0, comment)
+	}
+	c.Assert(IsCICollation("utf8mb4_general_ci"), IsTrue)
+	c.Assert(IsCICollation("utf8mb4_bin"), IsFalse)
+}
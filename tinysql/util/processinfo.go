@@ -0,0 +1,48 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+)
+
+// ProcessInfo is a struct used for show processlist statement.
+type ProcessInfo struct {
+	ID    uint64
+	DB    string
+	Info  string
+	State string
+	Time  time.Time
+}
+
+// truncatedInfoLen is the max length of the statement shown without FULL.
+const truncatedInfoLen = 100
+
+// ToRow returns []interface{} for the row data of "SHOW [FULL] PROCESSLIST".
+func (pi *ProcessInfo) ToRow(full bool) []interface{} {
+	info := pi.Info
+	if !full && len(info) > truncatedInfoLen {
+		info = info[:truncatedInfoLen]
+	}
+	t := uint64(time.Since(pi.Time) / time.Second)
+	return []interface{}{pi.ID, pi.DB, "Query", t, pi.State, info}
+}
+
+// SessionManager is an interface for the connection management, the KILL
+// statement relies on it.
+type SessionManager interface {
+	// Kill terminates the connection, or only the statement it is executing
+	// if query is true.
+	Kill(connectionID uint64, query bool)
+}
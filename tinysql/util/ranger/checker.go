@@ -17,6 +17,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // conditionChecker checks if this condition can be pushed to index planner.
@@ -43,6 +44,9 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 	case ast.LogicOr, ast.LogicAnd:
 		return c.check(scalar.GetArgs()[0]) && c.check(scalar.GetArgs()[1])
 	case ast.EQ, ast.NE, ast.GE, ast.GT, ast.LE, ast.LT:
+		if !c.checkCollation(scalar) {
+			return false
+		}
 		if _, ok := scalar.GetArgs()[0].(*expression.Constant); ok {
 			if c.checkColumn(scalar.GetArgs()[1]) {
 				return scalar.FuncName.L != ast.NE || c.length == types.UnspecifiedLength
@@ -62,7 +66,7 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 		// "not column" or "not constant" can't lead to a range.
 		return false
 	case ast.In:
-		if !c.checkColumn(scalar.GetArgs()[0]) {
+		if !c.checkColumn(scalar.GetArgs()[0]) || !c.checkCollation(scalar) {
 			return false
 		}
 		for _, v := range scalar.GetArgs()[1:] {
@@ -82,3 +86,10 @@ func (c *conditionChecker) checkColumn(expr expression.Expression) bool {
 	}
 	return c.colUniqueID == col.UniqueID
 }
+
+// checkCollation checks whether the comparison can be used to build ranges. The
+// ranges are built by comparing strings byte by byte, so the comparisons under
+// a case-insensitive collation can't be used.
+func (c *conditionChecker) checkCollation(scalar *expression.ScalarFunction) bool {
+	return !collate.IsCICollation(expression.DeriveCollationFromExprs(scalar.GetArgs()...))
+}
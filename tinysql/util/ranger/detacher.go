@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 )
 
 // detachColumnCNFConditions detaches the condition for calculating range from the other conditions.
@@ -95,6 +96,9 @@ func getEqOrInColOffset(expr expression.Expression, cols []*expression.Column) i
 	if !ok {
 		return -1
 	}
+	if collate.IsCICollation(expression.DeriveCollationFromExprs(f.GetArgs()...)) {
+		return -1
+	}
 	if f.FuncName.L == ast.EQ {
 		if c, ok := f.GetArgs()[0].(*expression.Column); ok {
 			if _, ok := f.GetArgs()[1].(*expression.Constant); ok {